@@ -1,17 +1,37 @@
 package main
 
 import (
+	"context"
 	"log"
+	"net"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/NakarinFIgo/Movies-App/configs"
 	_ "github.com/NakarinFIgo/Movies-App/docs"
+	"github.com/NakarinFIgo/Movies-App/internal/apikeys"
+	"github.com/NakarinFIgo/Movies-App/internal/credentials"
+	"github.com/NakarinFIgo/Movies-App/internal/enrichment"
 	"github.com/NakarinFIgo/Movies-App/internal/handler"
+	"github.com/NakarinFIgo/Movies-App/internal/jobs"
+	"github.com/NakarinFIgo/Movies-App/internal/loginguard"
+	"github.com/NakarinFIgo/Movies-App/internal/ratelimit"
 	"github.com/NakarinFIgo/Movies-App/internal/repository"
+	"github.com/NakarinFIgo/Movies-App/internal/scheduler"
+	"github.com/NakarinFIgo/Movies-App/internal/sso"
 	"github.com/NakarinFIgo/Movies-App/pkg/db"
+	"github.com/NakarinFIgo/Movies-App/pkg/jwtkeys"
+	"github.com/NakarinFIgo/Movies-App/pkg/mailer"
+	"github.com/NakarinFIgo/Movies-App/pkg/metadata"
 	"github.com/NakarinFIgo/Movies-App/pkg/middlewares"
+	"github.com/NakarinFIgo/Movies-App/pkg/rbac"
+	"github.com/NakarinFIgo/Movies-App/pkg/storage"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
 	"github.com/gofiber/swagger"
 	"github.com/joho/godotenv"
 )
@@ -50,6 +70,199 @@ func main() {
 	cfx.Domain = os.Getenv("DOMAIN")
 	cfx.APIKey = os.Getenv("API_KEY")
 
+	cfx.BookingHoldTTL, err = time.ParseDuration(os.Getenv("BOOKING_HOLD_TTL"))
+	if err != nil {
+		cfx.BookingHoldTTL = time.Minute * 10
+	}
+
+	cfx.MediaMaxUploadBytes, err = strconv.ParseInt(os.Getenv("MEDIA_MAX_UPLOAD_BYTES"), 10, 64)
+	if err != nil || cfx.MediaMaxUploadBytes <= 0 {
+		cfx.MediaMaxUploadBytes = 5 << 20
+	}
+
+	mediaRoot := os.Getenv("MEDIA_ROOT")
+	if mediaRoot == "" {
+		mediaRoot = "./media"
+	}
+	mediaBaseURL := os.Getenv("MEDIA_BASE_URL")
+	if mediaBaseURL == "" {
+		mediaBaseURL = "/media"
+	}
+
+	cfx.Storage, err = storage.NewLocal(mediaRoot, mediaBaseURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// METADATA_PROVIDER=none ปิดการดึง metadata จากภายนอก
+	switch provider := os.Getenv("METADATA_PROVIDER"); provider {
+	case "", "tmdb":
+		if cfx.APIKey == "" {
+			log.Println("API_KEY is not set, running without a metadata provider")
+			break
+		}
+		timeout, _ := time.ParseDuration(os.Getenv("TMDB_TIMEOUT"))
+		cfx.Metadata = metadata.NewTMDB(metadata.TMDBConfig{
+			APIKey:  cfx.APIKey,
+			BaseURL: os.Getenv("TMDB_BASE_URL"),
+			Timeout: timeout,
+		})
+	case "none":
+	default:
+		log.Fatalf("unknown METADATA_PROVIDER %q", provider)
+	}
+
+	cfx.FrontendURL = os.Getenv("FRONTEND_URL")
+	if cfx.FrontendURL == "" {
+		cfx.FrontendURL = "http://localhost:5173"
+	}
+
+	cfx.PasswordResetTTL, err = time.ParseDuration(os.Getenv("PASSWORD_RESET_TTL"))
+	if err != nil {
+		cfx.PasswordResetTTL = time.Minute * 30
+	}
+
+	cfx.EmailVerificationTTL, err = time.ParseDuration(os.Getenv("EMAIL_VERIFICATION_TTL"))
+	if err != nil {
+		cfx.EmailVerificationTTL = time.Hour * 24
+	}
+
+	cfx.AccountDeletionGrace, err = time.ParseDuration(os.Getenv("ACCOUNT_DELETION_GRACE"))
+	if err != nil || cfx.AccountDeletionGrace < 0 {
+		cfx.AccountDeletionGrace = time.Hour * 24 * 30
+	}
+
+	// เกณฑ์การหน่วงและล็อก login ค่าที่ไม่ได้ตั้งหรือไม่ถูกต้องใช้ค่าจาก loginguard.DefaultPolicy
+	loginPolicy := loginguard.DefaultPolicy
+	if n, err := strconv.Atoi(os.Getenv("LOGIN_MAX_FAILURES")); err == nil && n >= 0 {
+		loginPolicy.MaxAccountFailures = n
+	}
+	if n, err := strconv.Atoi(os.Getenv("LOGIN_IP_MAX_FAILURES")); err == nil && n >= 0 {
+		loginPolicy.MaxIPFailures = n
+	}
+	for _, setting := range []struct {
+		env   string
+		value *time.Duration
+	}{
+		{"LOGIN_FAILURE_WINDOW", &loginPolicy.Window},
+		{"LOGIN_LOCKOUT", &loginPolicy.Lockout},
+		{"LOGIN_BASE_DELAY", &loginPolicy.BaseDelay},
+		{"LOGIN_MAX_DELAY", &loginPolicy.MaxDelay},
+	} {
+		if d, err := time.ParseDuration(os.Getenv(setting.env)); err == nil && d >= 0 {
+			*setting.value = d
+		}
+	}
+
+	// นโยบาย rate limit ตั้งได้ด้วย RATE_LIMIT_<NAME> เช่น RATE_LIMIT_LOGIN=10/1m หรือ "off" เพื่อปิด
+	cfx.RateLimits = make(map[string]ratelimit.Policy, len(ratelimit.DefaultPolicies))
+	for name, policy := range ratelimit.DefaultPolicies {
+		if value := os.Getenv("RATE_LIMIT_" + strings.ToUpper(name)); value != "" {
+			policy.Requests, policy.Period, err = ratelimit.ParseLimit(value)
+			if err != nil {
+				log.Fatalf("RATE_LIMIT_%s: %v", strings.ToUpper(name), err)
+			}
+		}
+		cfx.RateLimits[name] = policy
+	}
+
+	// REGISTRATION_MODE คือ open (ค่าเริ่มต้น), invite_only หรือ closed
+	cfx.RegistrationMode = os.Getenv("REGISTRATION_MODE")
+	switch cfx.RegistrationMode {
+	case "":
+		cfx.RegistrationMode = configs.RegistrationOpen
+	case configs.RegistrationOpen, configs.RegistrationInviteOnly, configs.RegistrationClosed:
+	default:
+		log.Fatalf("unknown REGISTRATION_MODE %q", cfx.RegistrationMode)
+	}
+
+	cfx.InviteTTL, err = time.ParseDuration(os.Getenv("INVITE_TTL"))
+	if err != nil || cfx.InviteTTL <= 0 {
+		cfx.InviteTTL = time.Hour * 24 * 7
+	}
+
+	// เกณฑ์รหัสผ่าน ค่าที่ไม่ได้ตั้งใช้ค่าจาก credentials.DefaultPolicy
+	cfx.PasswordPolicy = credentials.DefaultPolicy
+	if n, err := strconv.Atoi(os.Getenv("PASSWORD_MIN_LENGTH")); err == nil {
+		cfx.PasswordPolicy.MinLength = n
+	}
+	if n, err := strconv.Atoi(os.Getenv("PASSWORD_BCRYPT_COST")); err == nil {
+		cfx.PasswordPolicy.Cost = n
+	}
+	// PASSWORD_BREACHED_LIST คือไฟล์รายการรหัสผ่านที่ห้ามใช้เพิ่มจากรายการที่ฝังมากับโปรแกรม
+	if path := os.Getenv("PASSWORD_BREACHED_LIST"); path != "" {
+		cfx.PasswordPolicy.Breached, err = credentials.LoadBreachedList(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	if err := cfx.PasswordPolicy.Validate(); err != nil {
+		log.Fatal(err)
+	}
+
+	cfx.APIKeyRateLimit, err = strconv.Atoi(os.Getenv("API_KEY_RATE_LIMIT"))
+	if err != nil || cfx.APIKeyRateLimit <= 0 {
+		cfx.APIKeyRateLimit = 60
+	}
+
+	cfx.TwoFactorIssuer = os.Getenv("TOTP_ISSUER")
+	if cfx.TwoFactorIssuer == "" {
+		cfx.TwoFactorIssuer = "Movies App"
+	}
+
+	// TWO_FACTOR_REQUIRED_ROLES คือบทบาทที่ต้องใช้ 2FA คั่นด้วยจุลภาค ตั้งเป็นค่าว่างเพื่อไม่บังคับบทบาทใด
+	requiredRoles, ok := os.LookupEnv("TWO_FACTOR_REQUIRED_ROLES")
+	if !ok {
+		requiredRoles = rbac.RoleAdmin
+	}
+	for _, role := range strings.Split(requiredRoles, ",") {
+		role = strings.TrimSpace(role)
+		if role == "" {
+			continue
+		}
+		if !rbac.ValidRole(role) {
+			log.Fatalf("unknown role %q in TWO_FACTOR_REQUIRED_ROLES", role)
+		}
+		cfx.TwoFactorRoles = append(cfx.TwoFactorRoles, role)
+	}
+
+	// OIDC_PROVIDERS คือชื่อ provider คั่นด้วยจุลภาค แต่ละตัวตั้งค่าด้วย OIDC_<NAME>_*
+	cfx.OIDCProviders = sso.Registry{}
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		cfx.OIDCProviders[name] = sso.NewProvider(oidcProviderConfig(name), nil)
+	}
+
+	// MAILER=smtp ส่งอีเมลจริง, file เขียนไฟล์ .eml ลง MAIL_DIR, log (ค่าเริ่มต้น) พิมพ์ลง log
+	mailFrom := os.Getenv("MAIL_FROM")
+	switch kind := os.Getenv("MAILER"); kind {
+	case "", "log":
+		cfx.Mailer = &mailer.Log{From: mailFrom}
+	case "file":
+		mailDir := os.Getenv("MAIL_DIR")
+		if mailDir == "" {
+			mailDir = "./mail"
+		}
+		cfx.Mailer = &mailer.File{Dir: mailDir, From: mailFrom}
+	case "smtp":
+		port, err := strconv.Atoi(os.Getenv("SMTP_PORT"))
+		if err != nil {
+			port = 587
+		}
+		cfx.Mailer = &mailer.SMTP{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     port,
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     mailFrom,
+		}
+	default:
+		log.Fatalf("unknown MAILER %q", kind)
+	}
+
 	databaseRepo := db.DBConnection()
 	if databaseRepo == nil {
 		log.Fatal("Failed to connect to the database")
@@ -57,50 +270,293 @@ func main() {
 
 	moviesRepo := &repository.PostgresRepository{DB: databaseRepo}
 	cfx.DB = moviesRepo
+	cfx.LoginGuard = loginguard.New(moviesRepo, loginPolicy)
+
+	// RATE_LIMIT_STORE คือ memory (ค่าเริ่มต้น) หรือ postgres เพื่อให้หลาย instance นับร่วมกัน
+	switch kind := os.Getenv("RATE_LIMIT_STORE"); kind {
+	case "", "memory":
+		cfx.RateLimitStore = ratelimit.NewMemoryStore()
+	case "postgres":
+		cfx.RateLimitStore = moviesRepo
+	default:
+		log.Fatalf("unknown RATE_LIMIT_STORE %q", kind)
+	}
 
 	cfx.Auth = middlewares.Auth{
-		Issuer:        cfx.JWTIssuer,
-		Audience:      cfx.JWTAudience,
-		Secret:        cfx.JWTSecret,
-		TokenExpiry:   time.Minute * 15,
-		RefreshExpiry: time.Hour * 24 * 7,
-		CookieDomain:  "localhost",
-		CookiePath:    "/",
-		CookieName:    "refresh_token",
+		Issuer:          cfx.JWTIssuer,
+		Audience:        cfx.JWTAudience,
+		Secret:          cfx.JWTSecret,
+		TokenExpiry:     time.Minute * 15,
+		RefreshExpiry:   time.Hour * 24 * 7,
+		ChallengeExpiry: time.Minute * 5,
+		CookieDomain:    "localhost",
+		CookiePath:      "/",
+		CookieName:      "refresh_token",
+	}
+
+	// JWT_KEYS_DIR คือไดเรกทอรีกุญแจที่สร้างด้วย cmd/jwtkeys ถ้าไม่ตั้งจะเซ็นด้วย HS256 และ JWT_SECRET
+	if keysDir := os.Getenv("JWT_KEYS_DIR"); keysDir != "" {
+		cfx.Auth.Keys, err = jwtkeys.LoadDir(keysDir)
+		if err != nil {
+			log.Fatalf("loading JWT keys from %s: %v", keysDir, err)
+		}
+		log.Printf("signing JWTs with %s key %s", cfx.Auth.Keys.Signing.Algorithm, cfx.Auth.Keys.Signing.ID)
+	} else {
+		log.Println("JWT_KEYS_DIR is not set, signing JWTs with HS256 and JWT_SECRET")
 	}
 
-	app := fiber.New()
+	cfx.APIKeys = apikeys.New(moviesRepo)
+	cfx.Auth.APIKeys = cfx.APIKeys
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// worker ของ job queue รันในโปรเซสเดียวกับ API
+	workers, _ := strconv.Atoi(os.Getenv("JOB_WORKERS"))
+	pool := jobs.NewPool(moviesRepo, workers)
+	enricher := &enrichment.Enricher{DB: moviesRepo, Provider: cfx.Metadata}
+	pool.Handle(jobs.KindEnrichMovie, enricher.HandleEnrichMovieJob)
+	pool.Handle(jobs.KindResyncMovie, enricher.HandleResyncMovieJob)
+	pool.Start(ctx)
+
+	historyRetention, err := time.ParseDuration(os.Getenv("HISTORY_RETENTION"))
+	if err != nil {
+		historyRetention = time.Hour * 24 * 30
+	}
+
+	// schedule ของแต่ละ task ตั้งได้ด้วย SCHEDULE_<TASK> เช่น SCHEDULE_MOVIES_RESYNC="0 4 * * *" หรือ "off" เพื่อปิด
+	cfx.Scheduler = scheduler.New(moviesRepo)
+	for _, task := range []struct {
+		name string
+		env  string
+		fn   scheduler.TaskFunc
+	}{
+		{scheduler.TaskResyncMovies, "SCHEDULE_MOVIES_RESYNC", scheduler.ResyncMovies(moviesRepo)},
+		{scheduler.TaskExpireSeatHolds, "SCHEDULE_SEAT_HOLDS_EXPIRE", scheduler.ExpireSeatHolds(moviesRepo)},
+		{scheduler.TaskPurgeHistory, "SCHEDULE_HISTORY_PURGE", scheduler.PurgeHistory(moviesRepo, historyRetention)},
+		{scheduler.TaskPurgeSessions, "SCHEDULE_SESSIONS_PURGE", scheduler.PurgeSessions(moviesRepo, time.Hour*24)},
+		{scheduler.TaskPurgeAccounts, "SCHEDULE_ACCOUNTS_PURGE", scheduler.PurgeAccounts(moviesRepo)},
+		{scheduler.TaskPurgePasswordResets, "SCHEDULE_PASSWORD_RESETS_PURGE", scheduler.PurgePasswordResets(moviesRepo, time.Hour*24)},
+		{scheduler.TaskPurgeEmailVerifications, "SCHEDULE_EMAIL_VERIFICATIONS_PURGE", scheduler.PurgeEmailVerifications(moviesRepo, time.Hour*24)},
+		{scheduler.TaskPurgeLoginThrottles, "SCHEDULE_LOGIN_THROTTLES_PURGE", scheduler.PurgeLoginThrottles(moviesRepo, time.Hour*24)},
+		{scheduler.TaskPurgeLoginAttempts, "SCHEDULE_LOGIN_ATTEMPTS_PURGE", scheduler.PurgeLoginAttempts(moviesRepo, historyRetention)},
+		{scheduler.TaskPurgeAuditLogs, "SCHEDULE_AUDIT_LOGS_PURGE", scheduler.PurgeAuditLogs(moviesRepo, historyRetention)},
+		{scheduler.TaskPurgeOIDCStates, "SCHEDULE_OIDC_STATES_PURGE", scheduler.PurgeOIDCStates(moviesRepo)},
+		{scheduler.TaskPurgeRateLimitBuckets, "SCHEDULE_RATE_LIMIT_BUCKETS_PURGE", scheduler.PurgeRateLimitBuckets(moviesRepo)},
+	} {
+		spec := os.Getenv(task.env)
+		if spec == "" {
+			spec = scheduler.DefaultSchedules[task.name]
+		}
+		if err := cfx.Scheduler.Add(task.name, spec, task.fn); err != nil {
+			log.Fatal(err)
+		}
+	}
+	cfx.Scheduler.Start(ctx)
+
+	// IP ของ client ใช้เป็น key ของ rate limit และ login guard ถ้า API อยู่หลัง reverse proxy
+	// ให้ตั้ง PROXY_HEADER เป็น header ที่ proxy ใส่ IP ของ client และ TRUSTED_PROXIES เป็น IP หรือ CIDR ของ proxy
+	// header นี้จะเชื่อเฉพาะ request ที่มาจาก proxy ในรายการ ส่วน request อื่นใช้ IP ของการเชื่อมต่อ
+	proxyHeader := os.Getenv("PROXY_HEADER")
+	var trustedProxies []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			log.Fatalf("TRUSTED_PROXIES: invalid IP or CIDR %q", proxy)
+		}
+		trustedProxies = append(trustedProxies, proxy)
+	}
+	if proxyHeader != "" && len(trustedProxies) == 0 {
+		log.Fatal("PROXY_HEADER requires TRUSTED_PROXIES, otherwise any client can choose its own IP")
+	}
+
+	app := fiber.New(fiber.Config{
+		// เผื่อขนาดของ multipart header นอกเหนือจากตัวไฟล์
+		BodyLimit:               int(cfx.MediaMaxUploadBytes) + 1<<20,
+		ErrorHandler:            handler.ErrorHandler,
+		ProxyHeader:             proxyHeader,
+		EnableTrustedProxyCheck: len(trustedProxies) > 0,
+		TrustedProxies:          trustedProxies,
+		EnableIPValidation:      true,
+	})
 
 	h := &handler.Handler{
 		App: cfx,
 	}
 
+	app.Use(requestid.New())
 	app.Use(middlewares.Enablecors())
 	app.Get("/swagger/*", swagger.HandlerDefault)
+	app.Get("/.well-known/jwks.json", h.JWKS)
+	app.Get("/media/*", h.Media)
 
 	// API Routes
 	app.Route("/api/v1", func(router fiber.Router) {
-		router.Post("/login", h.Login)
-		router.Get("/refresh", h.RefreshToken)
-		router.Get("/register", h.Register)
+		router.Use(h.AuditTrail)
+		router.Use(cfx.RateLimit(ratelimit.PolicyDefault))
+
+		loginLimit := cfx.RateLimit(ratelimit.PolicyLogin)
+		accountLimit := cfx.RateLimit(ratelimit.PolicyAccount)
+
+		router.Post("/login", loginLimit, h.Login)
+		router.Post("/refresh", h.RefreshToken)
+		router.Post("/register", accountLimit, h.Register)
+		router.Get("/verify-email", h.VerifyEmail)
+		router.Post("/verify-email/resend", accountLimit, h.ResendVerification)
 		router.Get("/logout", h.Logout)
+		router.Post("/password/forgot", accountLimit, h.ForgotPassword)
+		router.Post("/password/reset", accountLimit, h.ResetPassword)
+
+		authRequired := cfx.Auth.AuthRequired()
+
+		router.Get("/auth/oidc/providers", h.OIDCProviders)
+		router.Get("/auth/oidc/:provider/login", h.OIDCLogin)
+		router.Get("/auth/oidc/:provider/callback", h.OIDCCallback)
+
+		router.Get("/me", authRequired, h.Me)
+		router.Patch("/me", authRequired, h.UpdateMe)
+		router.Delete("/me", authRequired, h.DeleteMe)
+		router.Delete("/me/deletion", authRequired, h.CancelMyDeletion)
+		router.Post("/me/password", authRequired, h.ChangeMyPassword)
+		router.Get("/me/export", authRequired, h.ExportMe)
+		router.Get("/me/identities", authRequired, h.MyIdentities)
+		router.Delete("/me/identities/:id", authRequired, h.UnlinkIdentity)
+		router.Post("/login/2fa", loginLimit, h.LoginTwoFactor)
+		router.Post("/login/2fa/enroll", loginLimit, h.LoginTwoFactorEnroll)
+		router.Get("/me/2fa", authRequired, h.MyTwoFactor)
+		router.Post("/me/2fa/enroll", authRequired, h.EnrollTwoFactor)
+		router.Post("/me/2fa/confirm", authRequired, h.ConfirmTwoFactor)
+		router.Post("/me/2fa/recovery-codes", authRequired, h.RegenerateRecoveryCodes)
+		router.Delete("/me/2fa", authRequired, h.DisableTwoFactor)
+		router.Get("/me/sessions", authRequired, h.MySessions)
+		router.Delete("/me/sessions", authRequired, h.RevokeMySessions)
+		router.Delete("/me/sessions/:id", authRequired, h.RevokeMySession)
 
 		router.Get("/movies", h.AllMovies)
 		router.Get("/movies/:id", h.GetMovie)
+		router.Get("/movies/:id/showtimes", h.MovieShowtimes)
+		router.Get("/movies/:id/images", h.MovieImages)
+		router.Get("/showtimes/:id/seats", h.ShowtimeSeats)
 		router.Get("/genres", h.AllGenres)
 
+		// Booking routes for signed-in users
+		bookings := router.Group("/bookings")
+		bookings.Use(authRequired)
+		bookings.Get("/", h.MyBookings)
+		bookings.Post("/", h.HoldSeats)
+		bookings.Post("/:id/confirm", h.ConfirmBooking)
+		bookings.Delete("/:id", h.CancelBooking)
+
 		// Admin routes with JWT middleware
 		admin := router.Group("/admin")
-		admin.Use(middlewares.JwtMiddleware())
-		admin.Get("/movies", h.MovieCatalog)
-		admin.Get("/movies/:id", h.MovieForEdit)
-		admin.Post("/movies", h.InsertMovie)
-		admin.Put("/movies/:id", h.UpdateMovie)
-		admin.Delete("/movies/:id", h.DeleteMovie)
+		admin.Use(authRequired)
+		admin.Use(cfx.RateLimit(ratelimit.PolicyAdmin))
+		moviesWrite := middlewares.RequirePermission(rbac.PermMoviesWrite)
+		moviesDelete := middlewares.RequirePermission(rbac.PermMoviesDelete)
+		systemManage := middlewares.RequirePermission(rbac.PermSystemManage)
+		usersManage := middlewares.RequirePermission(rbac.PermUsersManage)
+
+		admin.Get("/movies", moviesWrite, h.MovieCatalog)
+		admin.Get("/movies/:id", moviesWrite, h.MovieForEdit)
+		admin.Post("/movies", moviesWrite, h.InsertMovie)
+		admin.Post("/movies/import/tmdb/:tmdb_id", moviesWrite, h.ImportTMDBMovie)
+		admin.Put("/movies/:id", moviesWrite, h.UpdateMovie)
+		admin.Delete("/movies/:id", moviesDelete, h.DeleteMovie)
+		admin.Post("/movies/:id/images", moviesWrite, h.UploadMovieImage)
+		admin.Get("/movies/:id/metadata-candidates", moviesWrite, h.MetadataCandidates)
+		admin.Post("/movies/:id/metadata-candidates", moviesWrite, h.ApplyMetadataCandidate)
+
+		admin.Get("/theaters", moviesWrite, h.AllTheaters)
+		admin.Post("/theaters", moviesWrite, h.InsertTheater)
+		admin.Post("/theaters/:id/screens", moviesWrite, h.InsertScreen)
+		admin.Post("/showtimes", moviesWrite, h.InsertShowtime)
+		admin.Delete("/showtimes/:id", moviesWrite, h.DeleteShowtime)
+
+		admin.Get("/jobs", systemManage, h.AllJobs)
+		admin.Get("/jobs/stats", systemManage, h.JobStats)
+		admin.Get("/jobs/:id", systemManage, h.GetJob)
+		admin.Post("/jobs/:id/retry", systemManage, h.RetryJob)
+		admin.Get("/scheduler", systemManage, h.SchedulerStatus)
+
+		admin.Get("/users", usersManage, h.AllUsers)
+		admin.Get("/users/:id", usersManage, h.GetUser)
+		admin.Put("/users/:id/role", usersManage, h.UpdateUserRole)
+		admin.Post("/users/:id/disable", usersManage, h.DisableUser)
+		admin.Post("/users/:id/enable", usersManage, h.EnableUser)
+		admin.Post("/users/:id/password-reset", usersManage, h.ForceUserPasswordReset)
+		admin.Get("/users/:id/sessions", usersManage, h.UserSessions)
+		admin.Get("/users/:id/login-attempts", usersManage, h.UserLoginAttempts)
+		admin.Post("/users/:id/unlock", usersManage, h.UnlockUserLogin)
+		admin.Delete("/users/:id/2fa", usersManage, h.ResetUserTwoFactor)
+
+		admin.Get("/invites", usersManage, h.AllInvites)
+		admin.Post("/invites", usersManage, h.CreateInvite)
+		admin.Get("/invites/:id", usersManage, h.GetInvite)
+		admin.Delete("/invites/:id", usersManage, h.RevokeInvite)
+
+		admin.Get("/api-keys", systemManage, h.AllAPIKeys)
+		admin.Post("/api-keys", systemManage, h.CreateAPIKey)
+		admin.Delete("/api-keys/:id", systemManage, h.RevokeAPIKey)
+		admin.Get("/audit-logs", systemManage, h.AuditLogs)
+		admin.Delete("/users/:id/sessions", usersManage, h.RevokeUserSessions)
+		admin.Delete("/users/:id/sessions/:session_id", usersManage, h.RevokeUserSession)
 	})
 
+	go func() {
+		<-ctx.Done()
+		if err := app.Shutdown(); err != nil {
+			log.Println(err)
+		}
+	}()
+
 	err = app.Listen(":8080")
 	if err != nil {
 		log.Fatal(err)
 	}
 }
+
+// oidcProviderConfig อ่านการตั้งค่าของ provider ชื่อ name จาก OIDC_<NAME>_*
+func oidcProviderConfig(name string) sso.ProviderConfig {
+	prefix := "OIDC_" + strings.ToUpper(name) + "_"
+	env := func(key string) string { return strings.TrimSpace(os.Getenv(prefix + key)) }
+	list := func(key string, lower bool) []string {
+		var values []string
+		for _, v := range strings.Split(env(key), ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				if lower {
+					v = strings.ToLower(v)
+				}
+				values = append(values, v)
+			}
+		}
+		return values
+	}
+
+	cfg := sso.ProviderConfig{
+		Name:           name,
+		Issuer:         env("ISSUER"),
+		ClientID:       env("CLIENT_ID"),
+		ClientSecret:   env("CLIENT_SECRET"),
+		RedirectURL:    env("REDIRECT_URL"),
+		Scopes:         list("SCOPES", false),
+		AllowedDomains: list("ALLOWED_DOMAINS", true),
+		DefaultRole:    env("DEFAULT_ROLE"),
+	}
+	if cfg.Issuer == "" || cfg.ClientID == "" || cfg.RedirectURL == "" {
+		log.Fatalf("%sISSUER, %sCLIENT_ID and %sREDIRECT_URL are required", prefix, prefix, prefix)
+	}
+
+	autoCreate, err := strconv.ParseBool(env("AUTO_CREATE"))
+	cfg.AutoCreate = err == nil && autoCreate
+
+	if cfg.DefaultRole == "" {
+		cfg.DefaultRole = rbac.RoleViewer
+	}
+	if !rbac.ValidRole(cfg.DefaultRole) {
+		log.Fatalf("unknown role %q in %sDEFAULT_ROLE", cfg.DefaultRole, prefix)
+	}
+	return cfg
+}
@@ -0,0 +1,123 @@
+// bootstrap สร้างผู้ดูแลระบบคนแรก
+//
+//	go run ./cmd/bootstrap -email admin@example.com -first-name Admin -last-name User
+//
+// รหัสผ่านอ่านจาก BOOTSTRAP_ADMIN_PASSWORD หรือจาก stdin ถ้าไม่ได้ตั้งไว้
+// ถ้ามีผู้ใช้อีเมลนี้อยู่แล้วจะเปลี่ยนบทบาทเป็น admin แทนการสร้างใหม่
+// จะไม่ทำอะไรถ้ามี admin อยู่แล้ว เว้นแต่ใช้ -force
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/NakarinFIgo/Movies-App/internal/credentials"
+	"github.com/NakarinFIgo/Movies-App/internal/entities"
+	"github.com/NakarinFIgo/Movies-App/internal/repository"
+	"github.com/NakarinFIgo/Movies-App/pkg/db"
+	"github.com/NakarinFIgo/Movies-App/pkg/rbac"
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	email := flag.String("email", "", "admin email (required)")
+	firstName := flag.String("first-name", "Admin", "admin first name")
+	lastName := flag.String("last-name", "User", "admin last name")
+	force := flag.Bool("force", false, "create or promote even if an admin already exists")
+	flag.Parse()
+
+	if *email == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("no .env file, using environment only")
+	}
+
+	adminEmail, err := credentials.NormalizeEmail(*email)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	databaseRepo := db.DBConnection()
+	if databaseRepo == nil {
+		log.Fatal("Failed to connect to the database")
+	}
+	repo := &repository.PostgresRepository{DB: databaseRepo}
+
+	admins, err := repo.CountUsersByRole(rbac.RoleAdmin)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if admins > 0 && !*force {
+		log.Fatalf("%d admin account(s) already exist, use -force to add another", admins)
+	}
+
+	existing, err := repo.GetUserByEmail(adminEmail)
+	if err == nil && existing != nil {
+		if err := repo.UpdateUserRole(existing.ID, rbac.RoleAdmin, existing.Permissions); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("promoted user %d (%s) to admin", existing.ID, existing.Email)
+		return
+	}
+
+	password, err := readPassword()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// ใช้ cost เริ่มต้น ถ้า PASSWORD_BCRYPT_COST ต่างออกไป hash จะถูกสร้างใหม่ตอน login ครั้งแรก
+	policy := credentials.DefaultPolicy
+	if err := policy.Check(password, adminEmail); err != nil {
+		log.Fatal(err)
+	}
+	hashedPassword, err := policy.Hash(password)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	now := time.Now()
+
+	id, err := repo.InsertUser(entities.User{
+		FirstName: *firstName,
+		LastName:  *lastName,
+		Email:     adminEmail,
+		Password:  hashedPassword,
+		Role:      rbac.RoleAdmin,
+		// admin คนแรกสร้างจาก command line จึงถือว่ายืนยันอีเมลแล้ว
+		EmailVerifiedAt: &now,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("created admin user %d (%s)", id, adminEmail)
+}
+
+func readPassword() (string, error) {
+	if password := os.Getenv("BOOTSTRAP_ADMIN_PASSWORD"); password != "" {
+		return password, nil
+	}
+
+	fmt.Fprint(os.Stderr, "Password: ")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+
+	password := strings.TrimRight(line, "\r\n")
+	if password == "" {
+		return "", errors.New("password must not be empty")
+	}
+	return password, nil
+}
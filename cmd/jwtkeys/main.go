@@ -0,0 +1,196 @@
+// jwtkeys สร้างและเปลี่ยนกุญแจที่ใช้เซ็น JWT ในไดเรกทอรี JWT_KEYS_DIR
+//
+//	go run ./cmd/jwtkeys generate -alg EdDSA
+//	go run ./cmd/jwtkeys rotate -keep 3
+//	go run ./cmd/jwtkeys list
+//
+// generate สร้างกุญแจใหม่ กุญแจจะถูกเผยแพร่ใน /.well-known/jwks.json หลัง restart แต่ยังไม่ใช้เซ็น
+// เว้นแต่ยังไม่มีกุญแจที่ใช้เซ็นหรือใช้ -activate
+// rotate ตั้งกุญแจใหม่ (หรือกุญแจที่ระบุด้วย -kid) เป็นกุญแจที่ใช้เซ็น เขียนกุญแจที่เคยเซ็นใหม่เป็น public key
+// เพื่อให้ยังตรวจ token เก่าได้ และลบกุญแจที่เก่ากว่า -keep ตัวล่าสุด
+//
+// ถ้ารัน API หลาย instance ให้ generate ก่อน restart ทุก instance แล้วจึง rotate -kid
+// ทุก instance จะได้รู้จักกุญแจใหม่ก่อนมี token ที่เซ็นด้วยกุญแจนั้น
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/NakarinFIgo/Movies-App/pkg/jwtkeys"
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("no .env file, using environment only")
+	}
+
+	defaultDir := os.Getenv("JWT_KEYS_DIR")
+	if defaultDir == "" {
+		defaultDir = "./keys"
+	}
+
+	flags := flag.NewFlagSet(os.Args[1], flag.ExitOnError)
+	dir := flags.String("dir", defaultDir, "key directory")
+
+	var err error
+	switch os.Args[1] {
+	case "generate":
+		alg := flags.String("alg", jwtkeys.EdDSA, "signing algorithm, RS256 or EdDSA")
+		activate := flags.Bool("activate", false, "use the new key for signing right away")
+		flags.Parse(os.Args[2:])
+		err = generate(*dir, *alg, *activate)
+	case "rotate":
+		alg := flags.String("alg", jwtkeys.EdDSA, "signing algorithm of the new key, RS256 or EdDSA")
+		kid := flags.String("kid", "", "activate this existing key instead of generating a new one")
+		keep := flags.Int("keep", 3, "number of most recent keys to keep for verification")
+		flags.Parse(os.Args[2:])
+		err = rotate(*dir, *alg, *kid, *keep)
+	case "list":
+		flags.Parse(os.Args[2:])
+		err = list(*dir)
+	default:
+		usage()
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: jwtkeys generate|rotate|list [flags]")
+	os.Exit(2)
+}
+
+func newKey(dir, alg string) (*jwtkeys.Key, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	kid, err := jwtkeys.NewKeyID(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := jwtkeys.Generate(kid, alg)
+	if err != nil {
+		return nil, err
+	}
+	if err := jwtkeys.WriteKey(dir, key, false); err != nil {
+		return nil, err
+	}
+
+	log.Printf("generated %s key %s", alg, kid)
+	return key, nil
+}
+
+func generate(dir, alg string, activate bool) error {
+	key, err := newKey(dir, alg)
+	if err != nil {
+		return err
+	}
+
+	if _, err := jwtkeys.ReadCurrent(dir); err == nil && !activate {
+		log.Printf("key %s is published but not used for signing, activate it with: jwtkeys rotate -kid %s", key.ID, key.ID)
+		return nil
+	} else if err != nil && !errors.Is(err, jwtkeys.ErrNoKeys) {
+		return err
+	}
+
+	if err := jwtkeys.WriteCurrent(dir, key.ID); err != nil {
+		return err
+	}
+	log.Printf("key %s is now used for signing", key.ID)
+	return nil
+}
+
+func rotate(dir, alg, kid string, keep int) error {
+	if keep < 1 {
+		return errors.New("-keep must be at least 1")
+	}
+
+	previous, err := jwtkeys.ReadCurrent(dir)
+	if err != nil && !errors.Is(err, jwtkeys.ErrNoKeys) {
+		return err
+	}
+
+	if kid == "" {
+		key, err := newKey(dir, alg)
+		if err != nil {
+			return err
+		}
+		kid = key.ID
+	}
+
+	keys, err := jwtkeys.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	// ตรวจว่ากุญแจใหม่ใช้เซ็นได้ก่อนเปลี่ยนไฟล์ current
+	if _, err := jwtkeys.NewKeySet(keys, kid); err != nil {
+		return err
+	}
+	if err := jwtkeys.WriteCurrent(dir, kid); err != nil {
+		return err
+	}
+	log.Printf("key %s is now used for signing", kid)
+
+	for i, key := range keys {
+		if key.ID == kid {
+			continue
+		}
+
+		// ลบกุญแจที่เก่ากว่า keep ตัวล่าสุด token ที่เซ็นด้วยกุญแจเหล่านี้จะใช้ไม่ได้อีก
+		if i < len(keys)-keep {
+			if err := jwtkeys.RemoveKey(dir, key.ID); err != nil {
+				return err
+			}
+			log.Printf("removed key %s", key.ID)
+			continue
+		}
+
+		// กุญแจที่เคยเซ็นไม่ต้องใช้ private key อีก เหลือไว้เฉพาะ public key สำหรับตรวจ
+		if key.ID == previous && key.Private != nil {
+			if err := jwtkeys.WriteKey(dir, key, true); err != nil {
+				return err
+			}
+			log.Printf("retired key %s, kept for verification only", key.ID)
+		}
+	}
+	return nil
+}
+
+func list(dir string) error {
+	keys, err := jwtkeys.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	current, err := jwtkeys.ReadCurrent(dir)
+	if err != nil && !errors.Is(err, jwtkeys.ErrNoKeys) {
+		return err
+	}
+
+	for _, key := range keys {
+		status := "verify"
+		switch {
+		case key.ID == current:
+			status = "signing"
+		case key.Private != nil:
+			status = "pending"
+		}
+		fmt.Printf("%s\t%s\t%s\n", key.ID, key.Algorithm, status)
+	}
+	return nil
+}
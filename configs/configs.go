@@ -1,8 +1,30 @@
 package configs
 
 import (
+	"time"
+
+	"github.com/NakarinFIgo/Movies-App/internal/apikeys"
+	"github.com/NakarinFIgo/Movies-App/internal/credentials"
+	"github.com/NakarinFIgo/Movies-App/internal/loginguard"
+	"github.com/NakarinFIgo/Movies-App/internal/ratelimit"
 	"github.com/NakarinFIgo/Movies-App/internal/repository"
+	"github.com/NakarinFIgo/Movies-App/internal/scheduler"
+	"github.com/NakarinFIgo/Movies-App/internal/sso"
+	"github.com/NakarinFIgo/Movies-App/pkg/mailer"
+	"github.com/NakarinFIgo/Movies-App/pkg/metadata"
 	"github.com/NakarinFIgo/Movies-App/pkg/middlewares"
+	"github.com/NakarinFIgo/Movies-App/pkg/storage"
+	"github.com/gofiber/fiber/v2"
+)
+
+// โหมดการสมัครสมาชิก
+const (
+	// RegistrationOpen ใครก็สมัครได้ ถ้าส่งรหัสเชิญมาด้วยจะได้บทบาทตามรหัส
+	RegistrationOpen = "open"
+	// RegistrationInviteOnly ต้องมีรหัสเชิญที่ admin สร้างไว้
+	RegistrationInviteOnly = "invite_only"
+	// RegistrationClosed ปิดรับสมัคร
+	RegistrationClosed = "closed"
 )
 
 type Application struct {
@@ -15,4 +37,61 @@ type Application struct {
 	JWTAudience  string
 	CookieDomain string
 	APIKey       string
+
+	// BookingHoldTTL คือระยะเวลาที่ hold ที่นั่งไว้ก่อนต้องยืนยันการจอง
+	BookingHoldTTL time.Duration
+
+	// Storage คือที่เก็บไฟล์ media เช่น poster ที่ admin อัปโหลด
+	Storage             storage.Storage
+	MediaMaxUploadBytes int64
+
+	// Metadata คือแหล่ง metadata ของหนัง เป็น nil ได้ถ้าไม่ได้ตั้งค่า provider
+	Metadata metadata.Provider
+
+	// Mailer ใช้ส่งอีเมล เช่นลิงก์ตั้งรหัสผ่านใหม่
+	Mailer mailer.Sender
+	// FrontendURL คือ URL ของหน้าเว็บ ใช้สร้างลิงก์ในอีเมล
+	FrontendURL          string
+	PasswordResetTTL     time.Duration
+	EmailVerificationTTL time.Duration
+	// AccountDeletionGrace คือเวลาที่ผู้ใช้ยกเลิกการลบบัญชีได้ก่อนบัญชีถูกลบจริง
+	AccountDeletionGrace time.Duration
+
+	// RegistrationMode คือ RegistrationOpen, RegistrationInviteOnly หรือ RegistrationClosed
+	// มีผลกับ /register เท่านั้น provider ที่ตั้ง AUTO_CREATE ยังสร้างบัญชีจาก domain ที่อนุญาตได้
+	RegistrationMode string
+	// InviteTTL คืออายุเริ่มต้นของรหัสเชิญที่ไม่ได้ระบุ expires_at
+	InviteTTL time.Duration
+
+	// LoginGuard หน่วงและล็อกการ login เมื่อใส่รหัสผ่านผิดติดกัน
+	LoginGuard *loginguard.Guard
+
+	// RateLimits คือนโยบาย rate limit ของแต่ละกลุ่ม route key คือชื่อนโยบาย เช่น ratelimit.PolicyLogin
+	RateLimits map[string]ratelimit.Policy
+	// RateLimitStore เก็บตัวนับของ rate limit
+	RateLimitStore ratelimit.Store
+
+	// PasswordPolicy คือเกณฑ์ของรหัสผ่านใหม่และ bcrypt cost ที่ใช้ hash
+	PasswordPolicy credentials.Policy
+
+	// TwoFactorIssuer คือชื่อที่แสดงในแอป authenticator
+	TwoFactorIssuer string
+	// TwoFactorRoles คือบทบาทที่ต้องใช้ 2FA ทุกครั้งที่ login
+	TwoFactorRoles []string
+
+	// OIDCProviders คือ identity provider ที่ผู้ใช้ login ผ่านได้ ว่างคือปิด login ผ่าน provider
+	OIDCProviders sso.Registry
+
+	// APIKeys ตรวจ API key ของ client ที่ไม่ใช่คน
+	APIKeys *apikeys.Service
+	// APIKeyRateLimit คือจำนวน request ต่อนาทีของ key ที่ไม่ได้ระบุ rate_limit
+	APIKeyRateLimit int
+
+	// Scheduler รัน task ที่ต้องทำเป็นรอบ เช่น re-sync metadata
+	Scheduler *scheduler.Scheduler
+}
+
+// RateLimit คืน middleware ที่จำกัด request ตามนโยบายชื่อ name นโยบายที่ไม่ได้ตั้งไว้คือไม่จำกัด
+func (a *Application) RateLimit(name string) fiber.Handler {
+	return ratelimit.Middleware(a.RateLimitStore, a.RateLimits[name])
 }
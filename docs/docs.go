@@ -24,38 +24,50 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/v1/admin/movies": {
+        "/.well-known/jwks.json": {
+            "get": {
+                "description": "แสดง public key ทุกตัวที่ยังใช้ตรวจ token ได้ในรูป JSON Web Key Set ใช้ kid ใน header ของ token เลือกกุญแจ\nถ้า server ยังใช้ HS256 จะไม่มีกุญแจในรายการ",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "public key สำหรับตรวจ JWT",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/jwtkeys.JWKS"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/api-keys": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "ดึงข้อมูลหนังทั้งหมดจากแคตตาล็อก",
+                "description": "แสดง API key ทั้งหมด รวมที่หมดอายุหรือถูก revoke แล้ว ไม่แสดงตัว key มีเฉพาะ prefix",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Movies"
+                    "API Keys"
                 ],
-                "summary": "แสดงรายชื่อหนังในแคตตาล็อก",
+                "summary": "แสดงรายการ API key",
                 "responses": {
                     "200": {
-                        "description": "List of movies in catalog\" example([{\"id\":1,\"title\":\"Catalog Movie Title\",\"release_date\":\"2024-08-28\",\"mpaa_rating\":\"PG\",\"run_time\":90,\"description\":\"Description of the catalog movie\"}])",
+                        "description": "API keys",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "type": "object",
-                                "additionalProperties": true
+                                "$ref": "#/definitions/entities.APIKey"
                             }
                         }
-                    },
-                    "500": {
-                        "description": "Internal Server Error\" example({\"error\":\"Internal Server Error\"})",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
                     }
                 }
             },
@@ -65,7 +77,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "เพิ่มหนังใหม่ไปยังฐานข้อมูล",
+                "description": "สร้าง key สำหรับสคริปต์หรือระบบอื่น ใช้กับ header Authorization: ApiKey \u003ckey\u003e key แสดงครั้งเดียวในคำตอบนี้ ระบบเก็บไว้เฉพาะ hash สิทธิ์ของ key ต้องเป็นสิทธิ์ที่ผู้สร้างมีอยู่แล้ว",
                 "consumes": [
                     "application/json"
                 ],
@@ -73,100 +85,188 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Movies"
+                    "API Keys"
                 ],
-                "summary": "เพิ่มหนังใหม่",
+                "summary": "สร้าง API key",
                 "parameters": [
                     {
-                        "description": "Movie data",
-                        "name": "movie",
+                        "description": "API key",
+                        "name": "requestPayload",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "type": "object"
+                            "$ref": "#/definitions/handler.CreateAPIKeyPayload"
                         }
                     }
                 ],
                 "responses": {
-                    "202": {
-                        "description": "Movie created\" example({\"message\":\"movie updated\"})",
+                    "201": {
+                        "description": "Created key",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/handler.CreatedAPIKey"
                         }
                     },
                     "400": {
-                        "description": "Bad Request\" example({\"error\":\"Invalid data\"})",
+                        "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error\" example({\"error\":\"Internal Server Error\"})",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/v1/admin/movies/{id}": {
+        "/api/v1/admin/api-keys/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "ทำให้ key ใช้ไม่ได้อีกทันที",
+                "tags": [
+                    "API Keys"
+                ],
+                "summary": "ยกเลิก API key",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "API key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Revoked"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/audit-logs": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "ดึงข้อมูลหนังและประเภทหนังสำหรับการแก้ไขตาม ID",
+                "description": "แสดง request ที่เปลี่ยนข้อมูลของผู้ใช้และทุก request ที่ใช้ API key ล่าสุดก่อน กรองตามประเภทและ ID ของผู้กระทำได้",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Movies"
+                    "API Keys"
                 ],
-                "summary": "ดึงข้อมูลหนังและประเภทหนังสำหรับการแก้ไข",
+                "summary": "แสดงบันทึกการกระทำ",
                 "parameters": [
+                    {
+                        "enum": [
+                            "user",
+                            "api_key"
+                        ],
+                        "type": "string",
+                        "description": "ประเภทของผู้กระทำ",
+                        "name": "actor_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "user id หรือ API key id",
+                        "name": "actor_id",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
-                        "description": "Movie ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "จำนวนรายการ (ค่าเริ่มต้น 50 สูงสุด 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ข้ามกี่รายการ",
+                        "name": "offset",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Movie and genres details\" example({\"movie\":{\"id\":1,\"title\":\"Movie Title\"},\"genres\":[{\"id\":1,\"name\":\"Genre Name\"}]})",
+                        "description": "Audit log",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entities.AuditLog"
+                            }
                         }
                     },
                     "400": {
-                        "description": "Bad Request\" example({\"error\":\"Invalid ID\"})",
+                        "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/utils.Problem"
                         }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/invites": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "แสดงรหัสเชิญล่าสุดก่อน รวมที่หมดอายุ ใช้ครบ หรือถูก revoke แล้ว ไม่แสดงตัวรหัส มีเฉพาะ prefix",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Invites"
+                ],
+                "summary": "แสดงรายการรหัสเชิญ",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "จำนวนรายการ (ค่าเริ่มต้น 50 สูงสุด 200)",
+                        "name": "limit",
+                        "in": "query"
                     },
-                    "500": {
-                        "description": "Internal Server Error\" example({\"error\":\"Internal Server Error\"})",
+                    {
+                        "type": "integer",
+                        "description": "ข้ามกี่รายการ",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Invites",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entities.Invite"
+                            }
                         }
                     }
                 }
             },
-            "put": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "แก้ไขข้อมูลหนังตาม ID ที่กำหนด",
+                "description": "สร้างรหัสสำหรับสมัครสมาชิกเมื่อเปิดรับสมัครแบบเชิญเท่านั้น ผู้ที่สมัครด้วยรหัสจะได้บทบาทตามรหัส รหัสแสดงครั้งเดียวในคำตอบนี้ ระบบเก็บไว้เฉพาะ hash บทบาทของรหัสต้องไม่มีสิทธิ์ที่ผู้สร้างไม่มี",
                 "consumes": [
                     "application/json"
                 ],
@@ -174,217 +274,319 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Movies"
+                    "Invites"
                 ],
-                "summary": "แก้ไขข้อมูลหนัง",
+                "summary": "สร้างรหัสเชิญ",
                 "parameters": [
                     {
-                        "description": "Updated movie data",
-                        "name": "movie",
+                        "description": "Invite",
+                        "name": "requestPayload",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "type": "object"
+                            "$ref": "#/definitions/handler.CreateInvitePayload"
                         }
                     }
                 ],
                 "responses": {
-                    "202": {
-                        "description": "Movie updated\" example({\"message\":\"movie updated\"})",
+                    "201": {
+                        "description": "Created invite",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/handler.CreatedInvite"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request\" example({\"error\":\"Invalid data\"})",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error\" example({\"error\":\"Internal Server Error\"})",
+                    "422": {
+                        "description": "Invalid fields",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/api/v1/admin/invites/{id}": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "ลบข้อมูลหนังตาม ID ที่กำหนด",
+                "description": "แสดงรหัสเชิญและรายการผู้ใช้ที่สมัครด้วยรหัสนี้ตามลำดับเวลา",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Movies"
+                    "Invites"
                 ],
-                "summary": "ลบหนังตาม ID",
+                "summary": "แสดงรหัสเชิญและการใช้รหัส",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Movie ID",
+                        "description": "Invite ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "202": {
-                        "description": "Movie deleted\" example({\"message\":\"movie deleted\"})",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request\" example({\"error\":\"Invalid ID\"})",
+                    "200": {
+                        "description": "Invite",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/handler.InviteDetail"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error\" example({\"error\":\"Internal Server Error\"})",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/genres": {
-            "get": {
-                "description": "ดึงข้อมูลประเภทหนังทั้งหมด",
-                "produces": [
-                    "application/json"
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "ทำให้รหัสใช้สมัครไม่ได้อีกทันที ผู้ใช้ที่สมัครไปแล้วไม่ได้รับผลกระทบ",
                 "tags": [
-                    "Genres"
+                    "Invites"
+                ],
+                "summary": "ยกเลิกรหัสเชิญ",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Invite ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "แสดงประเภทหนังทั้งหมด",
                 "responses": {
-                    "200": {
-                        "description": "List of all genres\" example([{\"id\":1,\"name\":\"Action\"},{\"id\":2,\"name\":\"Drama\"}])",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "type": "object",
-                                "additionalProperties": true
-                            }
-                        }
+                    "204": {
+                        "description": "Revoked"
                     },
-                    "500": {
-                        "description": "Internal Server Error\" example({\"error\":\"Internal Server Error\"})",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/v1/login": {
-            "post": {
-                "description": "รับข้อมูลอีเมลและรหัสผ่านของผู้ใช้และตรวจสอบความถูกต้อง หลังจากนั้นสร้าง JWT TokenPairs",
-                "consumes": [
-                    "application/json"
+        "/api/v1/admin/jobs": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "ดึง job ล่าสุด กรองตามสถานะ pending, running, succeeded หรือ dead ได้",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Authentication"
+                    "Jobs"
                 ],
-                "summary": "Authentication และสร้าง TokenPairs",
+                "summary": "แสดงรายการ job ในคิว",
                 "parameters": [
                     {
-                        "description": "User credentials",
-                        "name": "requestPayload",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handler.UserLoginPayload"
-                        }
+                        "enum": [
+                            "pending",
+                            "running",
+                            "succeeded",
+                            "dead"
+                        ],
+                        "type": "string",
+                        "description": "สถานะของ job",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "จำนวนรายการ (ค่าเริ่มต้น 50 สูงสุด 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ข้ามกี่รายการ",
+                        "name": "offset",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "202": {
-                        "description": "Token pairs\" example({\"access_token\": \"string\", \"refresh_token\": \"string\"})",
+                    "200": {
+                        "description": "List of jobs",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entities.Job"
+                            }
                         }
                     },
                     "400": {
-                        "description": "Bad Request\" example({\"error\": \"Bad Request\"})",
+                        "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error\" example({\"error\": \"Internal Server Error\"})",
+                        "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/v1/logout": {
+        "/api/v1/admin/jobs/stats": {
             "get": {
-                "description": "ลบโทเคนรีเฟรชของผู้ใช้ออกจากระบบ",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "นับจำนวน job ในแต่ละสถานะ ใช้ดูว่าคิวค้างหรือมี job ที่ dead หรือไม่",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Authentication"
+                    "Jobs"
                 ],
-                "summary": "ออกจากระบบ",
+                "summary": "แสดงจำนวน job แยกตามสถานะ",
                 "responses": {
-                    "202": {
-                        "description": "Accepted\" example({\"message\": \"Accepted\"})",
+                    "200": {
+                        "description": "Job counts\" example({\"pending\":0,\"running\":1,\"succeeded\":42,\"dead\":2})",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
-                                "type": "string"
+                                "type": "integer"
                             }
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error\" example({\"error\": \"Internal Server Error\"})",
+                        "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/v1/movies": {
+        "/api/v1/admin/jobs/{id}": {
             "get": {
-                "description": "ดึงข้อมูลหนังทั้งหมดจาก database",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "ดึง job ตาม ID รวมถึงจำนวนครั้งที่ลองและ error ล่าสุด",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Movies"
+                    "Jobs"
+                ],
+                "summary": "แสดงรายละเอียดของ job",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "แสดงรายชื่อหนังทั้งหมด",
                 "responses": {
                     "200": {
-                        "description": "List of all movies\" example([{\"id\":1,\"title\":\"Movie Title\",\"release_date\":\"2024-08-28\",\"mpaa_rating\":\"PG\",\"run_time\":120,\"description\":\"Description of the movie\"}])",
+                        "description": "Job",
+                        "schema": {
+                            "$ref": "#/definitions/entities.Job"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/jobs/{id}/retry": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "คืน job ที่ dead กลับเข้าคิวโดยเริ่มนับจำนวนครั้งใหม่และล้าง error เดิม job ในสถานะอื่นจะตอบ 409",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Jobs"
+                ],
+                "summary": "สั่งรัน job ใหม่",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Job requeued\" example({\"message\":\"job requeued\"})",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Job is not dead",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/movies": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "ดึงข้อมูลหนังทั้งหมดจากแคตตาล็อก",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Movies"
+                ],
+                "summary": "แสดงรายชื่อหนังในแคตตาล็อก",
+                "responses": {
+                    "200": {
+                        "description": "List of movies in catalog\" example([{\"id\":1,\"title\":\"Catalog Movie Title\",\"release_date\":\"2024-08-28\",\"mpaa_rating\":\"PG\",\"run_time\":90,\"description\":\"Description of the catalog movie\"}])",
                         "schema": {
                             "type": "array",
                             "items": {
@@ -394,103 +596,193 @@ const docTemplate = `{
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error\" example({\"error\":\"Internal Server Error\"})",
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "เพิ่มหนังใหม่ไปยังฐานข้อมูล ถ้ามี field ไม่ถูกต้องจะตอบ 422 พร้อม error ของทุก field",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Movies"
+                ],
+                "summary": "เพิ่มหนังใหม่",
+                "parameters": [
+                    {
+                        "description": "Movie data",
+                        "name": "movie",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.MoviePayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Movie created\" example({\"message\":\"movie updated\"})",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid fields",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
                     }
                 }
             }
         },
-        "/api/v1/movies/{id}": {
-            "get": {
-                "description": "ดึงข้อมูลหนังตาม ID ที่กำหนด",
+        "/api/v1/admin/movies/import/tmdb/{tmdb_id}": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "ดึงชื่อ เรื่องย่อ วันฉาย ความยาว rating poster backdrop และ genre จาก TMDB แล้วบันทึกเป็นหนังใหม่ หรือ re-sync หนังที่มี tmdb_id เดียวกัน ใช้ preview=true เพื่อดูค่าที่จะบันทึกโดยยังไม่บันทึก",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Movies"
                 ],
-                "summary": "แสดงรายละเอียดของหนังตาม ID",
+                "summary": "นำเข้าหนังจาก TMDB ด้วย TMDB ID",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Movie ID",
-                        "name": "id",
+                        "description": "TMDB movie ID",
+                        "name": "tmdb_id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "แสดงค่าที่จะบันทึกโดยไม่บันทึก",
+                        "name": "preview",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Movie details\" example({\"id\":1,\"title\":\"Movie Title\",\"release_date\":\"2024-08-28\",\"mpaa_rating\":\"PG\",\"run_time\":120,\"description\":\"Description of the movie\"})",
+                        "description": "Preview or re-synced movie",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/enrichment.Proposal"
+                        }
+                    },
+                    "201": {
+                        "description": "Imported movie",
+                        "schema": {
+                            "$ref": "#/definitions/enrichment.Proposal"
                         }
                     },
                     "400": {
-                        "description": "Bad Request\" example({\"error\":\"Invalid ID\"})",
+                        "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error\" example({\"error\":\"Internal Server Error\"})",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/v1/refresh": {
+        "/api/v1/admin/movies/{id}": {
             "get": {
-                "description": "ตรวจสอบโทเคนที่หมดอายุและสร้างโทเคนใหม่สำหรับผู้ใช้",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "ดึงข้อมูลหนังและประเภทหนังสำหรับการแก้ไขตาม ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Authentication"
+                    "Movies"
+                ],
+                "summary": "ดึงข้อมูลหนังและประเภทหนังสำหรับการแก้ไข",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Movie ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "รีเฟรชโทเคน JWT",
                 "responses": {
                     "200": {
-                        "description": "Token pairs\" example({\"access_token\": \"string\", \"refresh_token\": \"string\"})",
+                        "description": "Movie and genres details\" example({\"movie\":{\"id\":1,\"title\":\"Movie Title\"},\"genres\":[{\"id\":1,\"name\":\"Genre Name\"}]})",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "additionalProperties": true
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized\" example({\"error\": \"Unauthorized\"})",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error\" example({\"error\": \"Internal Server Error\"})",
+                        "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/register": {
-            "post": {
-                "description": "รับข้อมูลผู้ใช้ใหม่และบันทึกลงในระบบ",
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "แก้ไขข้อมูลหนังตาม ID ที่กำหนด ถ้ามี field ไม่ถูกต้องจะตอบ 422 พร้อม error ของทุก field\ntitle และ description ถูกแทนที่ทุกครั้ง ส่ง description ว่างเพื่อล้างค่า\nrelease_date, runtime, mpaa_rating, tmdb_id และ genres_array ที่ไม่ได้ส่งมาจะใช้ค่าเดิม ส่ง genres_array เป็น [] เพื่อล้าง genre",
                 "consumes": [
                     "application/json"
                 ],
@@ -498,77 +790,4257 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Authentication"
+                    "Movies"
                 ],
-                "summary": "เพิ่มผู้ใช้ใหม่",
+                "summary": "แก้ไขข้อมูลหนัง",
                 "parameters": [
                     {
-                        "description": "User registration data",
-                        "name": "requestPayload",
+                        "type": "integer",
+                        "description": "Movie ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated movie data",
+                        "name": "movie",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handler.UserRegisterPayload"
+                            "$ref": "#/definitions/handler.MoviePayload"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "message\" example({\"message\": \"User created\"})",
+                    "202": {
+                        "description": "Movie updated\" example({\"message\":\"movie updated\"})",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "Bad Request\" example({\"error\": \"Bad Request\"})",
+                        "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid fields",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error\" example({\"error\": \"Internal Server Error\"})",
+                        "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     }
                 }
-            }
-        }
-    },
-    "definitions": {
-        "handler.UserLoginPayload": {
-            "type": "object",
-            "properties": {
-                "email": {
-                    "description": "Required: true\nExample: \"",
-                    "type": "string"
-                },
-                "password": {
-                    "description": "Required: true\nExample: \"password123\"",
-                    "type": "string"
-                }
-            }
-        },
-        "handler.UserRegisterPayload": {
-            "type": "object",
-            "properties": {
-                "email": {
-                    "description": "Required: true\nExample: \"john@example.com\"",
-                    "type": "string"
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "ลบข้อมูลหนังตาม ID ที่กำหนด รอบฉายของหนังจะถูกลบด้วย ถ้ามีการจองในรอบฉายใดจะลบไม่ได้",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Movies"
+                ],
+                "summary": "ลบหนังตาม ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Movie ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Movie deleted\" example({\"message\":\"movie deleted\"})",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Movie has bookings",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/movies/{id}/images": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "อัปโหลดรูปแบบ multipart (JPEG, PNG หรือ WebP) ตรวจชนิดไฟล์จากเนื้อหาจริง สร้างรูปย่อหลายขนาด และตั้งเป็นรูปปัจจุบันของหนัง",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Movies"
+                ],
+                "summary": "อัปโหลด poster หรือ backdrop ของหนัง",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Movie ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Image file",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "poster หรือ backdrop (ค่าเริ่มต้น poster)",
+                        "name": "kind",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Uploaded image with variants",
+                        "schema": {
+                            "$ref": "#/definitions/entities.MovieImage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Payload Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/movies/{id}/metadata-candidates": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "ค้นหาหนังจาก provider ด้วยชื่อเรื่อง แล้วเรียงตามความใกล้เคียงของชื่อและปีที่ฉาย เพื่อให้ admin เลือกผลลัพธ์ที่ถูกต้อง",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Movies"
+                ],
+                "summary": "แสดงผลลัพธ์จาก metadata provider ที่ใกล้เคียงกับหนัง",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Movie ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "จำนวนผลลัพธ์ (ค่าเริ่มต้น 5 สูงสุด 20)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ranked candidates",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/metadata.Candidate"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "ดึงข้อมูลเต็มของผลลัพธ์ที่เลือกจาก provider แล้วบันทึกลงหนัง รวมถึง poster, backdrop, genre และ tmdb_id ใช้ preview=true เพื่อดูค่าที่จะบันทึกโดยยังไม่บันทึก",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Movies"
+                ],
+                "summary": "ใช้ผลลัพธ์ที่ admin เลือกกับหนัง",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Movie ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "แสดงค่าที่จะบันทึกโดยไม่บันทึก",
+                        "name": "preview",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Chosen candidate",
+                        "name": "requestPayload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.ApplyCandidatePayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated movie",
+                        "schema": {
+                            "$ref": "#/definitions/enrichment.Proposal"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/scheduler": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "แสดง task ทั้งหมดพร้อม schedule และเวลารันครั้งถัดไป และประวัติการรันล่าสุดจากทุก instance",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Jobs"
+                ],
+                "summary": "แสดง scheduled task และประวัติการรัน",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "กรองประวัติตามชื่อ task",
+                        "name": "task",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "จำนวนประวัติ (ค่าเริ่มต้น 50 สูงสุด 200)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tasks and run history",
+                        "schema": {
+                            "$ref": "#/definitions/handler.SchedulerStatus"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/showtimes": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "เพิ่มรอบฉายของหนังในโรงฉาย รอบที่เวลาซ้อนกันในโรงเดียวกันจะถูกปฏิเสธ",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Showtimes"
+                ],
+                "summary": "เพิ่มรอบฉาย",
+                "parameters": [
+                    {
+                        "description": "Showtime data",
+                        "name": "requestPayload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.ShowtimePayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Showtime created\" example({\"message\":\"showtime created\",\"data\":{\"id\":1}})",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/showtimes/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "ลบรอบฉายที่ยังไม่มีการจอง",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Showtimes"
+                ],
+                "summary": "ลบรอบฉาย",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Showtime ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Showtime deleted\" example({\"message\":\"showtime deleted\"})",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/theaters": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "ดึงสาขาโรงภาพยนตร์ทั้งหมดพร้อมโรงฉาย",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Showtimes"
+                ],
+                "summary": "แสดงสาขาโรงภาพยนตร์ทั้งหมด",
+                "responses": {
+                    "200": {
+                        "description": "List of theaters",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entities.Theater"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "เพิ่มสาขาโรงภาพยนตร์ใหม่",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Showtimes"
+                ],
+                "summary": "เพิ่มสาขาโรงภาพยนตร์",
+                "parameters": [
+                    {
+                        "description": "Theater data",
+                        "name": "requestPayload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.TheaterPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Theater created\" example({\"message\":\"theater created\",\"data\":{\"id\":1}})",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/theaters/{id}/screens": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "เพิ่มโรงฉายในสาขา โดยสร้างที่นั่งตามแถวที่กำหนด เช่น แถว A มี 12 ที่นั่ง จะได้ A1 ถึง A12",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Showtimes"
+                ],
+                "summary": "เพิ่มโรงฉายพร้อมผังที่นั่ง",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Theater ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Screen and seat map",
+                        "name": "requestPayload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.ScreenPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Screen created\" example({\"message\":\"screen created\",\"data\":{\"id\":1}})",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/users": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "ค้นจากชื่อหรืออีเมล กรองตามบทบาทและสถานะได้ จำนวนผู้ใช้ทั้งหมดที่ตรงเงื่อนไขอยู่ใน header X-Total-Count",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "ค้นหาผู้ใช้",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ชื่อหรืออีเมล",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "admin",
+                            "editor",
+                            "viewer"
+                        ],
+                        "type": "string",
+                        "description": "บทบาท",
+                        "name": "role",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "active",
+                            "disabled",
+                            "unverified",
+                            "pending_deletion"
+                        ],
+                        "type": "string",
+                        "description": "สถานะของบัญชี",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "จำนวนรายการ (ค่าเริ่มต้น 50 สูงสุด 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ข้ามกี่รายการ",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of users",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/handler.AdminUser"
+                            }
+                        },
+                        "headers": {
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "จำนวนผู้ใช้ทั้งหมดที่ตรงเงื่อนไข"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/users/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "แสดงข้อมูลบัญชี สถานะ 2FA บัญชีภายนอกที่ผูกไว้ และ session ที่ยังใช้งานได้",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "แสดงรายละเอียดของผู้ใช้",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User",
+                        "schema": {
+                            "$ref": "#/definitions/handler.AdminUserDetail"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/users/{id}/2fa": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "ให้ admin ลบ 2FA ของผู้ใช้ที่ทำอุปกรณ์และ recovery code หาย ถ้าบทบาทบังคับใช้ 2FA ผู้ใช้จะต้องลงทะเบียนใหม่ตอน login ครั้งถัดไป",
+                "tags": [
+                    "Two-Factor"
+                ],
+                "summary": "ล้าง 2FA ของผู้ใช้",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Two-factor authentication reset"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/users/{id}/disable": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "บัญชีที่ถูกปิดจะ login ไม่ได้และทุก session ถูก revoke ทันที ปิดบัญชีของตัวเองไม่ได้",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "ปิดบัญชีของผู้ใช้",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Disabled\" example({\"message\":\"account disabled\"})",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Own account",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/users/{id}/enable": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "ให้ผู้ใช้ login ได้อีกครั้ง session ที่ถูก revoke ตอนปิดบัญชีจะไม่กลับมา",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "เปิดบัญชีที่ถูกปิดไว้",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Enabled\" example({\"message\":\"account enabled\"})",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/users/{id}/login-attempts": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "แสดงการ login ล่าสุดของบัญชี ทั้งที่สำเร็จและไม่สำเร็จ พร้อม IP และ user agent",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "แสดงประวัติการ login ของผู้ใช้",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Login attempts",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entities.LoginAttempt"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/users/{id}/password-reset": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "เปลี่ยนรหัสผ่านเดิมเป็นค่าสุ่มที่ไม่มีใครรู้ revoke ทุก session แล้วส่งลิงก์ตั้งรหัสผ่านใหม่ไปที่อีเมลของผู้ใช้",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "บังคับให้ผู้ใช้ตั้งรหัสผ่านใหม่",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Reset link sent\" example({\"message\":\"password reset, a reset link has been sent\",\"data\":{\"revoked\":2}})",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/users/{id}/role": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "เปลี่ยนบทบาทและสิทธิ์เพิ่มเติม มีผลกับ token ที่ออกหลังจากนี้ เอาบทบาท admin ออกจาก admin คนสุดท้ายไม่ได้",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "เปลี่ยนบทบาทของผู้ใช้",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Role and extra permissions",
+                        "name": "requestPayload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.UpdateUserRolePayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated user",
+                        "schema": {
+                            "$ref": "#/definitions/handler.AdminUser"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Last admin",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/users/{id}/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "ให้ admin ดูการ login ที่ยังใช้งานได้ของผู้ใช้คนใดก็ได้",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "แสดงอุปกรณ์ที่ผู้ใช้ login อยู่",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Active sessions",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entities.ActiveSession"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "ให้ admin revoke ทุก session ของผู้ใช้",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "ออกจากระบบทุกอุปกรณ์ของผู้ใช้",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Sessions revoked\" example({\"message\":\"sessions revoked\",\"data\":{\"revoked\":3}})",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/users/{id}/sessions/{session_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "ให้ admin revoke session หนึ่งของผู้ใช้ เช่นเมื่อสงสัยว่าบัญชีถูกใช้โดยผู้อื่น",
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "ออกจากระบบบนอุปกรณ์หนึ่งของผู้ใช้",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "session_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Session revoked"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/users/{id}/unlock": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "ล้างตัวนับการ login ผิดของบัญชี ทำให้ login ได้ทันที ถ้าระบุ ip_address จะปลดล็อก IP นั้นด้วย",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "ปลดล็อกการ login ของผู้ใช้",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "IP address to unlock",
+                        "name": "requestPayload",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/handler.UnlockLoginPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Unlocked\" example({\"message\":\"login unlocked\"})",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/auth/oidc/providers": {
+            "get": {
+                "description": "แสดงชื่อ provider ที่ตั้งค่าไว้และ URL สำหรับเริ่ม login",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "identity provider ที่ login ได้",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/handler.OIDCProvider"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/auth/oidc/{provider}/callback": {
+            "get": {
+                "description": "ตรวจ state กับ cookie แลก code เป็น token ตรวจ ID token แล้วผูกบัญชีกับผู้ใช้ ผลลัพธ์เหมือน /api/v1/login รวมถึงการขอรหัส 2FA\nบัญชีที่ยังไม่ได้ผูกจะผูกกับผู้ใช้ที่อีเมลตรงกันและยืนยันอีเมลแล้ว หรือสร้างผู้ใช้ใหม่ถ้า provider เปิด auto create",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "callback ของ identity provider",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ชื่อ provider",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization code",
+                        "name": "code",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "State ที่ส่งไปตอนเริ่ม login",
+                        "name": "state",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Token pairs, or TwoFactorChallenge when the account uses 2FA",
+                        "schema": {
+                            "$ref": "#/definitions/handler.LoginResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Provider rejected the login",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Identity not linked or account disabled",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Unknown provider",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid state",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many failed attempts, see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/auth/oidc/{provider}/login": {
+            "get": {
+                "description": "สร้าง state, nonce และ PKCE verifier เก็บไว้ฝั่ง server แล้ว redirect ไปหน้า login ของ provider",
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "เริ่ม login ผ่าน identity provider",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ชื่อ provider",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "302": {
+                        "description": "Redirect ไปที่ provider"
+                    },
+                    "404": {
+                        "description": "Unknown provider",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "502": {
+                        "description": "Provider unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/bookings": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "ดึงการจองทั้งหมดของผู้ใช้ที่ login อยู่",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Bookings"
+                ],
+                "summary": "แสดงการจองของผู้ใช้",
+                "responses": {
+                    "200": {
+                        "description": "List of bookings",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entities.Booking"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "hold ที่นั่งของรอบฉายไว้ตามเวลาที่กำหนด ต้องยืนยันก่อนหมดเวลา ไม่เช่นนั้นที่นั่งจะถูกปล่อย",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Bookings"
+                ],
+                "summary": "จองที่นั่งแบบ hold ชั่วคราว",
+                "parameters": [
+                    {
+                        "description": "Seats to hold",
+                        "name": "requestPayload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.HoldSeatsPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Held booking",
+                        "schema": {
+                            "$ref": "#/definitions/entities.Booking"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/bookings/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "ยกเลิกการจองที่ยัง hold อยู่และปล่อยที่นั่งคืน",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Bookings"
+                ],
+                "summary": "ยกเลิกการจอง",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Booking ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Booking cancelled\" example({\"message\":\"booking cancelled\"})",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/bookings/{id}/confirm": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "ยืนยันการจองที่ยัง hold อยู่ให้เป็นการจองจริง",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Bookings"
+                ],
+                "summary": "ยืนยันการจอง",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Booking ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Confirmed booking",
+                        "schema": {
+                            "$ref": "#/definitions/entities.Booking"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "410": {
+                        "description": "Gone",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/genres": {
+            "get": {
+                "description": "ดึงข้อมูลประเภทหนังทั้งหมด",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Genres"
+                ],
+                "summary": "แสดงประเภทหนังทั้งหมด",
+                "responses": {
+                    "200": {
+                        "description": "List of all genres\" example([{\"id\":1,\"name\":\"Action\"},{\"id\":2,\"name\":\"Drama\"}])",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "object",
+                                "additionalProperties": true
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/login": {
+            "post": {
+                "description": "รับข้อมูลอีเมลและรหัสผ่านของผู้ใช้และตรวจสอบความถูกต้อง หลังจากนั้นสร้าง JWT TokenPairs ถ้า login ผิดติดกันต้องรอนานขึ้นเรื่อย ๆ ก่อนลองใหม่ และบัญชีหรือ IP จะถูกล็อกชั่วคราวเมื่อผิดครบจำนวนที่กำหนด ถ้าบัญชีเปิดใช้ 2FA หรือบทบาทบังคับใช้ 2FA จะได้ challenge_token แทน แล้วต้องส่งรหัสที่ /api/v1/login/2fa",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Authentication และสร้าง TokenPairs",
+                "parameters": [
+                    {
+                        "description": "User credentials",
+                        "name": "requestPayload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.UserLoginPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Token pairs, or TwoFactorChallenge when the account uses 2FA",
+                        "schema": {
+                            "$ref": "#/definitions/handler.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Email not verified or account disabled",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many failed attempts, see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/login/2fa": {
+            "post": {
+                "description": "ขั้นที่สองของการ login ส่ง challenge_token ที่ได้จาก /api/v1/login พร้อมรหัส TOTP หรือ recovery code แล้วจึงได้ TokenPairs ถ้าบทบาทบังคับใช้ 2FA แต่ยังไม่ได้ลงทะเบียน ให้เรียก /api/v1/login/2fa/enroll ก่อน แล้วส่งรหัสแรกที่นี่ จะได้ recovery code ชุดแรกพร้อม token รหัสที่ผิดนับรวมกับการ login ผิด",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "ยืนยันรหัส 2FA เพื่อ login ให้เสร็จ",
+                "parameters": [
+                    {
+                        "description": "Challenge token and code",
+                        "name": "requestPayload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.TwoFactorLoginPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Token pairs",
+                        "schema": {
+                            "$ref": "#/definitions/handler.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many failed attempts, see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/login/2fa/enroll": {
+            "post": {
+                "description": "ใช้เมื่อ /api/v1/login ตอบ enrollment_required เพราะบทบาทบังคับใช้ 2FA คืน secret และ otpauth URI สำหรับแอป authenticator จากนั้นส่งรหัสแรกที่ /api/v1/login/2fa",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "ลงทะเบียน 2FA ระหว่าง login",
+                "parameters": [
+                    {
+                        "description": "Challenge token",
+                        "name": "requestPayload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.ChallengeTokenPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Secret and otpauth URI",
+                        "schema": {
+                            "$ref": "#/definitions/handler.TwoFactorEnrollment"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/logout": {
+            "get": {
+                "description": "revoke refresh token ของการ login นี้ที่ฝั่ง server และลบ cookie",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "ออกจากระบบ",
+                "responses": {
+                    "202": {
+                        "description": "Accepted\" example({\"message\": \"Accepted\"})",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/me": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "ดึงข้อมูลผู้ใช้เจ้าของ access token พร้อมบทบาทและสิทธิ์ปัจจุบัน",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "แสดงข้อมูลของผู้ใช้ที่ login อยู่",
+                "responses": {
+                    "200": {
+                        "description": "Current user",
+                        "schema": {
+                            "$ref": "#/definitions/handler.UserProfile"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "ต้องใส่รหัสผ่านปัจจุบัน บัญชีจะถูกลบถาวรเมื่อพ้นช่วงผ่อนผัน ระหว่างนั้นทุก session ถูก revoke แต่ยัง login เพื่อยกเลิกได้ที่ DELETE /api/v1/me/deletion\nadmin คนสุดท้ายลบบัญชีไม่ได้ ต้องตั้ง admin คนอื่นก่อน",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Account"
+                ],
+                "summary": "ขอลบบัญชี",
+                "parameters": [
+                    {
+                        "description": "Current password",
+                        "name": "requestPayload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.DeleteAccountPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Deletion scheduled\" example({\"message\":\"account scheduled for deletion\",\"data\":{\"deletion_scheduled_at\":\"2026-11-17T12:00:00Z\"}})",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Last admin",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many failed attempts, see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "แก้ไขเฉพาะ field ที่ส่งมา การเปลี่ยนอีเมลจะส่งลิงก์ยืนยันไปที่อีเมลใหม่และแจ้งอีเมลเดิม อีเมลของบัญชีจะเปลี่ยนเมื่อกดลิงก์แล้วเท่านั้น",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Account"
+                ],
+                "summary": "แก้ไขชื่อและอีเมลของผู้ใช้ที่ login อยู่",
+                "parameters": [
+                    {
+                        "description": "Fields to change",
+                        "name": "requestPayload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.UpdateProfilePayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated profile\" example({\"message\":\"profile updated\",\"data\":{\"id\":1}})",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid fields",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many email changes",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/me/2fa": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "บอกว่าผู้ใช้เปิดใช้ 2FA แล้วหรือยัง บทบาทบังคับใช้หรือไม่ และเหลือ recovery code กี่ชุด",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Two-Factor"
+                ],
+                "summary": "แสดงสถานะ 2FA",
+                "responses": {
+                    "200": {
+                        "description": "Two-factor status",
+                        "schema": {
+                            "$ref": "#/definitions/handler.TwoFactorStatus"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "ยืนยันด้วยรหัส TOTP หรือ recovery code แล้วลบ secret และ recovery code ทั้งหมด ปิดไม่ได้ถ้าบทบาทบังคับใช้ 2FA",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Two-Factor"
+                ],
+                "summary": "ปิด 2FA",
+                "parameters": [
+                    {
+                        "description": "Current code",
+                        "name": "requestPayload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.TwoFactorCodePayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Two-factor authentication disabled"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Required by role",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/me/2fa/confirm": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "ตรวจรหัสแรกจากแอป authenticator แล้วเปิดใช้ 2FA คืน recovery code ที่ใช้แทนรหัสได้ครั้งละหนึ่งรหัส แสดงครั้งเดียวเท่านั้น",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Two-Factor"
+                ],
+                "summary": "ยืนยันการลงทะเบียน 2FA",
+                "parameters": [
+                    {
+                        "description": "First code",
+                        "name": "requestPayload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.TwoFactorCodePayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Recovery codes",
+                        "schema": {
+                            "$ref": "#/definitions/handler.RecoveryCodesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/me/2fa/enroll": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "สร้าง secret ใหม่และคืน otpauth URI สำหรับสแกนในแอป authenticator 2FA ยังไม่เปิดใช้จนกว่าจะยืนยันด้วยรหัสแรกที่ /api/v1/me/2fa/confirm",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Two-Factor"
+                ],
+                "summary": "เริ่มลงทะเบียน 2FA",
+                "responses": {
+                    "200": {
+                        "description": "Secret and otpauth URI",
+                        "schema": {
+                            "$ref": "#/definitions/handler.TwoFactorEnrollment"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/me/2fa/recovery-codes": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "ยืนยันด้วยรหัส TOTP หรือ recovery code แล้วแทนที่ recovery code ทั้งหมดด้วยชุดใหม่ ชุดเดิมใช้ไม่ได้อีก",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Two-Factor"
+                ],
+                "summary": "ออก recovery code ชุดใหม่",
+                "parameters": [
+                    {
+                        "description": "Current code",
+                        "name": "requestPayload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.TwoFactorCodePayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Recovery codes",
+                        "schema": {
+                            "$ref": "#/definitions/handler.RecoveryCodesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/me/deletion": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "ยกเลิกการลบบัญชีที่ขอไว้ ใช้ได้ก่อนพ้นช่วงผ่อนผัน",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Account"
+                ],
+                "summary": "ยกเลิกการลบบัญชี",
+                "responses": {
+                    "200": {
+                        "description": "Current user",
+                        "schema": {
+                            "$ref": "#/definitions/handler.UserProfile"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/me/export": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "รวมข้อมูลทั้งหมดที่เก็บเกี่ยวกับผู้ใช้เป็นไฟล์ JSON ได้แก่ โปรไฟล์ การจอง session ประวัติการ login บัญชีภายนอก API key ที่สร้าง และ audit log ของการกระทำของผู้ใช้ ไม่รวมรหัสผ่านหรือ secret ใดๆ",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Account"
+                ],
+                "summary": "ดาวน์โหลดข้อมูลของฉัน",
+                "responses": {
+                    "200": {
+                        "description": "Export archive",
+                        "schema": {
+                            "$ref": "#/definitions/handler.UserExport"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/me/identities": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "แสดงบัญชีจาก identity provider ที่ผูกกับผู้ใช้ที่ login อยู่",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "บัญชีภายนอกที่ผูกไว้",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entities.UserIdentity"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/me/identities/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "ยกเลิกการผูกบัญชีจาก identity provider กับผู้ใช้ที่ login อยู่",
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "ยกเลิกการผูกบัญชีภายนอก",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Identity ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/me/password": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "ต้องใส่รหัสผ่านปัจจุบัน หลังเปลี่ยนแล้วทุก session อื่นจะถูก revoke ส่วน session ที่ใช้เรียก API นี้ยังใช้ได้ต่อ",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Account"
+                ],
+                "summary": "เปลี่ยนรหัสผ่าน",
+                "parameters": [
+                    {
+                        "description": "Current and new password",
+                        "name": "requestPayload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.ChangePasswordPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Password changed\" example({\"message\":\"password changed\",\"data\":{\"revoked\":2}})",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "New password does not meet the policy",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many failed attempts, see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/me/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "แสดงการ login ที่ยังใช้งานได้ของผู้ใช้ พร้อม user agent, IP, เวลาที่ login และเวลาที่ใช้ล่าสุด",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "แสดงอุปกรณ์ที่ login อยู่",
+                "responses": {
+                    "200": {
+                        "description": "Active sessions",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entities.ActiveSession"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "revoke refresh token ทุก session ของผู้ใช้ รวมถึง session ปัจจุบัน",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "ออกจากระบบทุกอุปกรณ์",
+                "responses": {
+                    "200": {
+                        "description": "Sessions revoked\" example({\"message\":\"sessions revoked\",\"data\":{\"revoked\":3}})",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/me/sessions/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "revoke refresh token ของ session นั้น access token ที่ออกไปแล้วยังใช้ได้จนหมดอายุ",
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "ออกจากระบบบนอุปกรณ์หนึ่ง",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Session revoked"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/movies": {
+            "get": {
+                "description": "ดึงข้อมูลหนังทั้งหมดจาก database",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Movies"
+                ],
+                "summary": "แสดงรายชื่อหนังทั้งหมด",
+                "responses": {
+                    "200": {
+                        "description": "List of all movies\" example([{\"id\":1,\"title\":\"Movie Title\",\"release_date\":\"2024-08-28\",\"mpaa_rating\":\"PG\",\"run_time\":120,\"description\":\"Description of the movie\"}])",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "object",
+                                "additionalProperties": true
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/movies/{id}": {
+            "get": {
+                "description": "ดึงข้อมูลหนังตาม ID ที่กำหนด",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Movies"
+                ],
+                "summary": "แสดงรายละเอียดของหนังตาม ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Movie ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Movie details\" example({\"id\":1,\"title\":\"Movie Title\",\"release_date\":\"2024-08-28\",\"mpaa_rating\":\"PG\",\"run_time\":120,\"description\":\"Description of the movie\"})",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/movies/{id}/images": {
+            "get": {
+                "description": "ดึงรูป poster และ backdrop ที่อัปโหลดของหนังพร้อม URL ของรูปย่อแต่ละขนาด",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Movies"
+                ],
+                "summary": "แสดงรูปที่อัปโหลดของหนัง",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Movie ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of images",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entities.MovieImage"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/movies/{id}/showtimes": {
+            "get": {
+                "description": "ดึงรอบฉายที่ยังไม่เริ่มของหนังตาม ID พร้อมข้อมูลโรงและสาขา",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Showtimes"
+                ],
+                "summary": "แสดงรอบฉายของหนัง",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Movie ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of showtimes",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entities.Showtime"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/password/forgot": {
+            "post": {
+                "description": "ส่งลิงก์ตั้งรหัสผ่านใหม่ไปที่อีเมล ถ้าอีเมลนี้มีบัญชีอยู่ ตอบเหมือนกันทุกกรณีเพื่อไม่ให้รู้ว่าอีเมลใดมีบัญชี",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "ขอลิงก์ตั้งรหัสผ่านใหม่",
+                "parameters": [
+                    {
+                        "description": "Email",
+                        "name": "requestPayload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.ForgotPasswordPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted\" example({\"message\":\"if the email is registered, a reset link has been sent\"})",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/password/reset": {
+            "post": {
+                "description": "ตั้งรหัสผ่านใหม่ด้วย token จากอีเมล token ใช้ได้ครั้งเดียว และทุก session ที่ login อยู่จะถูก revoke",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "ตั้งรหัสผ่านใหม่ด้วย reset token",
+                "parameters": [
+                    {
+                        "description": "Reset token and new password",
+                        "name": "requestPayload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.ResetPasswordPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Password updated\" example({\"message\":\"password updated\"})",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Password does not meet the policy",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/refresh": {
+            "post": {
+                "description": "แลก refresh token จาก cookie (หรือจาก body) เป็น token คู่ใหม่ refresh token เดิมจะใช้ไม่ได้อีก ถ้านำ refresh token ที่ใช้ไปแล้วมาใช้ซ้ำ ทุก token ที่ต่อมาจากการ login ครั้งเดียวกันจะถูก revoke",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "รีเฟรชโทเคน JWT",
+                "parameters": [
+                    {
+                        "description": "Refresh token สำหรับ client ที่ไม่ใช้ cookie",
+                        "name": "requestPayload",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/handler.RefreshTokenPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Token pairs\" example({\"access_token\": \"string\", \"refresh_token\": \"string\"})",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/register": {
+            "post": {
+                "description": "รับข้อมูลผู้ใช้ใหม่และบันทึกลงในระบบ อีเมลจะถูกเก็บเป็นตัวพิมพ์เล็ก รหัสผ่านต้องผ่านเกณฑ์ของระบบ ถ้าเปิดรับสมัครแบบเชิญเท่านั้นต้องส่ง invite_code ที่ยังใช้ได้ และผู้ใช้จะได้บทบาทตามรหัส ถ้าข้อมูลไม่ถูกต้องจะตอบ 422 พร้อม error ของแต่ละ field บัญชีใหม่ยังไม่ได้ยืนยันอีเมล ระบบจะส่งลิงก์ยืนยันไปที่อีเมล และจะ login ได้หลังยืนยันแล้ว",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "เพิ่มผู้ใช้ใหม่",
+                "parameters": [
+                    {
+                        "description": "User registration data",
+                        "name": "requestPayload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.UserRegisterPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "message\" example({\"message\": \"User created, check your email to verify your account\"})",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Registration closed",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid fields",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/showtimes/{id}/seats": {
+            "get": {
+                "description": "ดึงผังที่นั่งของรอบฉายพร้อมสถานะว่าง/ไม่ว่างของแต่ละที่นั่ง",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Showtimes"
+                ],
+                "summary": "แสดงผังที่นั่งของรอบฉาย",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Showtime ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Seat map",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entities.SeatAvailability"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/verify-email": {
+            "get": {
+                "description": "ยืนยันอีเมลด้วย token จากลิงก์ที่ส่งไปตอนสมัคร หลังยืนยันแล้วจึง login ได้ ถ้าเป็นลิงก์เปลี่ยนอีเมลจะเปลี่ยนอีเมลของบัญชีเป็นอีเมลใหม่",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "ยืนยันอีเมล",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Verification token",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Email verified\" example({\"message\":\"email verified\"})",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/verify-email/resend": {
+            "post": {
+                "description": "ส่งลิงก์ยืนยันใหม่ถ้าบัญชียังไม่ได้ยืนยัน ส่งได้ไม่เกินหนึ่งครั้งต่อนาทีและห้าครั้งต่อชั่วโมง ตอบเหมือนกันทุกกรณีเพื่อไม่ให้รู้ว่าอีเมลใดมีบัญชี",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "ส่งลิงก์ยืนยันอีเมลอีกครั้ง",
+                "parameters": [
+                    {
+                        "description": "Email",
+                        "name": "requestPayload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.ResendVerificationPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted\" example({\"message\":\"if the account exists and is not verified, a new link has been sent\"})",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "enrichment.Proposal": {
+            "type": "object",
+            "properties": {
+                "existing": {
+                    "description": "Existing เป็น true ถ้ามีหนังที่ผูกกับ tmdb_id นี้อยู่แล้ว การบันทึกจะเป็นการ re-sync",
+                    "type": "boolean"
+                },
+                "movie": {
+                    "$ref": "#/definitions/entities.Movie"
+                },
+                "unmapped_genres": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/metadata.Genre"
+                    }
+                }
+            }
+        },
+        "entities.APIKey": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "integer"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "permissions": {
+                    "description": "Permissions คือสิทธิ์ของ key ต้องเป็นสิทธิ์ที่ผู้สร้างมีอยู่แล้ว",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "prefix": {
+                    "description": "Prefix คือส่วนต้นของ key ที่แสดงได้ ใช้บอกว่าเป็น key ไหนโดยไม่ต้องเห็น key ทั้งหมด",
+                    "type": "string"
+                },
+                "rate_limit": {
+                    "description": "RateLimit คือจำนวน request ต่อนาทีที่ key นี้ใช้ได้",
+                    "type": "integer"
+                },
+                "revoked_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "entities.ActiveSession": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "current": {
+                    "description": "Current เป็น true ถ้าเป็น session ของ access token ที่ใช้เรียก API นี้",
+                    "type": "boolean"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "ip_address": {
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "user_agent": {
+                    "type": "string"
+                }
+            }
+        },
+        "entities.AuditLog": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "actor_id": {
+                    "type": "string"
+                },
+                "actor_type": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "ip_address": {
+                    "type": "string"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "user_agent": {
+                    "type": "string"
+                }
+            }
+        },
+        "entities.Booking": {
+            "type": "object",
+            "properties": {
+                "confirmed_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "seats": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entities.BookingSeat"
+                    }
+                },
+                "showtime": {
+                    "$ref": "#/definitions/entities.Showtime"
+                },
+                "showtime_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entities.BookingSeat": {
+            "type": "object",
+            "properties": {
+                "seat": {
+                    "$ref": "#/definitions/entities.Seat"
+                },
+                "seat_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entities.Genre": {
+            "type": "object",
+            "properties": {
+                "genre": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "tmdb_id": {
+                    "description": "TMDBID คือ genre id ฝั่ง TMDB ที่ map มาที่ genre นี้",
+                    "type": "integer"
+                }
+            }
+        },
+        "entities.Invite": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "integer"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "max_uses": {
+                    "description": "MaxUses คือจำนวนครั้งที่ใช้สมัครได้ 1 คือใช้ได้ครั้งเดียว",
+                    "type": "integer"
+                },
+                "note": {
+                    "description": "Note คือข้อความที่ admin ใส่ไว้ เช่นเชิญใคร",
+                    "type": "string"
+                },
+                "prefix": {
+                    "description": "Prefix คือส่วนต้นของรหัสที่แสดงได้ ใช้บอกว่าเป็นรหัสไหนโดยไม่ต้องเห็นรหัสทั้งหมด",
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "role": {
+                    "description": "Role คือบทบาทที่ผู้ใช้ได้เมื่อสมัครด้วยรหัสนี้",
+                    "type": "string"
+                },
+                "uses": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entities.InviteRedemption": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "invite_id": {
+                    "type": "integer"
+                },
+                "redeemed_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entities.Job": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "locked_at": {
+                    "type": "string"
+                },
+                "locked_by": {
+                    "type": "string"
+                },
+                "max_attempts": {
+                    "type": "integer"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "run_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "entities.LoginAttempt": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "ip_address": {
+                    "type": "string"
+                },
+                "result": {
+                    "type": "string"
+                },
+                "user_agent": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entities.Movie": {
+            "type": "object",
+            "properties": {
+                "backdrop": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "genres": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entities.Genre"
+                    }
+                },
+                "genres_array": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "image": {
+                    "type": "string"
+                },
+                "mpaa_rating": {
+                    "type": "string"
+                },
+                "release_date": {
+                    "type": "string"
+                },
+                "runtime": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "tmdb_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entities.MovieImage": {
+            "type": "object",
+            "properties": {
+                "content_type": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "height": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "movie_id": {
+                    "type": "integer"
+                },
+                "size_bytes": {
+                    "type": "integer"
+                },
+                "url": {
+                    "type": "string"
+                },
+                "variants": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entities.MovieImageVariant"
+                    }
+                },
+                "width": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entities.MovieImageVariant": {
+            "type": "object",
+            "properties": {
+                "height": {
+                    "type": "integer"
+                },
+                "url": {
+                    "type": "string"
+                },
+                "width": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entities.SchedulerRun": {
+            "type": "object",
+            "properties": {
+                "detail": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "instance": {
+                    "type": "string"
+                },
+                "scheduled_for": {
+                    "type": "string"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "task": {
+                    "type": "string"
+                }
+            }
+        },
+        "entities.Screen": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "seats": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entities.Seat"
+                    }
+                },
+                "theater": {
+                    "$ref": "#/definitions/entities.Theater"
+                },
+                "theater_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entities.Seat": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "number": {
+                    "type": "integer"
+                },
+                "row": {
+                    "type": "string"
+                },
+                "screen_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entities.SeatAvailability": {
+            "type": "object",
+            "properties": {
+                "available": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "number": {
+                    "type": "integer"
+                },
+                "row": {
+                    "type": "string"
+                },
+                "screen_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entities.Session": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "family_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "ip_address": {
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "rotated_at": {
+                    "type": "string"
+                },
+                "user_agent": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entities.Showtime": {
+            "type": "object",
+            "properties": {
+                "ends_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "movie": {
+                    "$ref": "#/definitions/entities.Movie"
+                },
+                "movie_id": {
+                    "type": "integer"
+                },
+                "price_cents": {
+                    "type": "integer"
+                },
+                "screen": {
+                    "$ref": "#/definitions/entities.Screen"
+                },
+                "screen_id": {
+                    "type": "integer"
+                },
+                "starts_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "entities.Theater": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "location": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "screens": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entities.Screen"
+                    }
+                }
+            }
+        },
+        "entities.UserIdentity": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_login_at": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "subject": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.AdminUser": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "deletion_scheduled_at": {
+                    "description": "DeletionScheduledAt มีค่าเมื่อผู้ใช้ขอลบบัญชี บัญชีจะถูกลบเมื่อถึงเวลานี้",
+                    "type": "string"
+                },
+                "disabled_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "email_verified_at": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "role": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Status คือ active, disabled, unverified หรือ pending_deletion",
+                    "type": "string"
+                }
+            }
+        },
+        "handler.AdminUserDetail": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "deletion_scheduled_at": {
+                    "description": "DeletionScheduledAt มีค่าเมื่อผู้ใช้ขอลบบัญชี บัญชีจะถูกลบเมื่อถึงเวลานี้",
+                    "type": "string"
+                },
+                "disabled_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "email_verified_at": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "identities": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entities.UserIdentity"
+                    }
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "role": {
+                    "type": "string"
+                },
+                "sessions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entities.ActiveSession"
+                    }
+                },
+                "status": {
+                    "description": "Status คือ active, disabled, unverified หรือ pending_deletion",
+                    "type": "string"
+                },
+                "two_factor_enabled": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "handler.ApplyCandidatePayload": {
+            "type": "object",
+            "properties": {
+                "provider_id": {
+                    "description": "Required: true\nExample: \"603\"",
+                    "type": "string"
+                }
+            }
+        },
+        "handler.ChallengeTokenPayload": {
+            "type": "object",
+            "properties": {
+                "challenge_token": {
+                    "description": "Required: true",
+                    "type": "string"
+                }
+            }
+        },
+        "handler.ChangePasswordPayload": {
+            "type": "object",
+            "properties": {
+                "current_password": {
+                    "description": "Required: true",
+                    "type": "string"
+                },
+                "new_password": {
+                    "description": "Required: true",
+                    "type": "string"
+                }
+            }
+        },
+        "handler.CreateAPIKeyPayload": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "description": "ExpiresAt ไม่ระบุคือไม่หมดอายุ",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Required: true\nExample: \"nightly-import\"",
+                    "type": "string"
+                },
+                "permissions": {
+                    "description": "Required: true\nExample: [\"movies:write\"]",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "rate_limit": {
+                    "description": "RateLimit คือจำนวน request ต่อนาที ไม่ระบุคือใช้ค่าเริ่มต้นของระบบ\nExample: 120",
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.CreateInvitePayload": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "description": "ExpiresAt ไม่ระบุคือใช้อายุเริ่มต้นของระบบ",
+                    "type": "string"
+                },
+                "max_uses": {
+                    "description": "จำนวนครั้งที่ใช้สมัครได้ ไม่ระบุคือ 1\nExample: 1",
+                    "type": "integer"
+                },
+                "note": {
+                    "description": "Example: \"new reviewer team\"",
+                    "type": "string"
+                },
+                "role": {
+                    "description": "บทบาทของผู้ใช้ที่สมัครด้วยรหัสนี้ ไม่ระบุคือ viewer\nExample: \"editor\"",
+                    "type": "string"
+                }
+            }
+        },
+        "handler.CreatedAPIKey": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "integer"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "permissions": {
+                    "description": "Permissions คือสิทธิ์ของ key ต้องเป็นสิทธิ์ที่ผู้สร้างมีอยู่แล้ว",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "prefix": {
+                    "description": "Prefix คือส่วนต้นของ key ที่แสดงได้ ใช้บอกว่าเป็น key ไหนโดยไม่ต้องเห็น key ทั้งหมด",
+                    "type": "string"
+                },
+                "rate_limit": {
+                    "description": "RateLimit คือจำนวน request ต่อนาทีที่ key นี้ใช้ได้",
+                    "type": "integer"
+                },
+                "revoked_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.CreatedInvite": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "integer"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "max_uses": {
+                    "description": "MaxUses คือจำนวนครั้งที่ใช้สมัครได้ 1 คือใช้ได้ครั้งเดียว",
+                    "type": "integer"
+                },
+                "note": {
+                    "description": "Note คือข้อความที่ admin ใส่ไว้ เช่นเชิญใคร",
+                    "type": "string"
+                },
+                "prefix": {
+                    "description": "Prefix คือส่วนต้นของรหัสที่แสดงได้ ใช้บอกว่าเป็นรหัสไหนโดยไม่ต้องเห็นรหัสทั้งหมด",
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "role": {
+                    "description": "Role คือบทบาทที่ผู้ใช้ได้เมื่อสมัครด้วยรหัสนี้",
+                    "type": "string"
+                },
+                "uses": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.DeleteAccountPayload": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "description": "Required: true",
+                    "type": "string"
+                }
+            }
+        },
+        "handler.ExportedProfile": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "deletion_scheduled_at": {
+                    "description": "DeletionScheduledAt มีค่าเมื่อผู้ใช้ขอลบบัญชี บัญชีจะถูกลบเมื่อถึงเวลานี้",
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "email_verified_at": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "role": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.ForgotPasswordPayload": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "description": "Required: true\nExample: \"john@example.com\"",
+                    "type": "string"
+                }
+            }
+        },
+        "handler.HoldSeatsPayload": {
+            "type": "object",
+            "properties": {
+                "seat_ids": {
+                    "description": "Required: true",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "showtime_id": {
+                    "description": "Required: true",
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.InviteDetail": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "integer"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "max_uses": {
+                    "description": "MaxUses คือจำนวนครั้งที่ใช้สมัครได้ 1 คือใช้ได้ครั้งเดียว",
+                    "type": "integer"
+                },
+                "note": {
+                    "description": "Note คือข้อความที่ admin ใส่ไว้ เช่นเชิญใคร",
+                    "type": "string"
+                },
+                "prefix": {
+                    "description": "Prefix คือส่วนต้นของรหัสที่แสดงได้ ใช้บอกว่าเป็นรหัสไหนโดยไม่ต้องเห็นรหัสทั้งหมด",
+                    "type": "string"
+                },
+                "redemptions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entities.InviteRedemption"
+                    }
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "role": {
+                    "description": "Role คือบทบาทที่ผู้ใช้ได้เมื่อสมัครด้วยรหัสนี้",
+                    "type": "string"
+                },
+                "uses": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.LoginResponse": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "recovery_codes": {
+                    "description": "RecoveryCodes มีเฉพาะเมื่อเพิ่งเปิดใช้ 2FA ระหว่าง login แสดงครั้งเดียวเท่านั้น",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "refresh_token": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/handler.LoginUser"
+                }
+            }
+        },
+        "handler.LoginUser": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.MoviePayload": {
+            "type": "object",
+            "required": [
+                "title"
+            ],
+            "properties": {
+                "description": {
+                    "description": "Example: \"New movie description\"",
+                    "type": "string",
+                    "maxLength": 5000
+                },
+                "genres_array": {
+                    "description": "genre id ที่ต้องมีอยู่ใน /genres\nExample: [1, 2]",
+                    "type": "array",
+                    "maxItems": 20,
+                    "uniqueItems": true,
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "mpaa_rating": {
+                    "description": "Example: \"PG-13\"",
+                    "type": "string",
+                    "enum": [
+                        "G",
+                        "PG",
+                        "PG-13",
+                        "R",
+                        "NC-17",
+                        "NR"
+                    ]
+                },
+                "release_date": {
+                    "description": "วันฉายรูปแบบ YYYY-MM-DD หรือ RFC 3339 ไม่ระบุจะดึงจาก TMDB ภายหลัง\nExample: \"2024-08-28\"",
+                    "type": "string"
+                },
+                "runtime": {
+                    "description": "ความยาวเป็นนาที\nExample: 120",
+                    "type": "integer",
+                    "maximum": 600,
+                    "minimum": 1
+                },
+                "title": {
+                    "description": "Example: \"New Movie\"",
+                    "type": "string",
+                    "maxLength": 512
+                },
+                "tmdb_id": {
+                    "description": "Example: 603",
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.OIDCProvider": {
+            "type": "object",
+            "properties": {
+                "login_url": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.RecoveryCodesResponse": {
+            "type": "object",
+            "properties": {
+                "recovery_codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "handler.RefreshTokenPayload": {
+            "type": "object",
+            "properties": {
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.ResendVerificationPayload": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "description": "Required: true\nExample: \"john@example.com\"",
+                    "type": "string"
+                }
+            }
+        },
+        "handler.ResetPasswordPayload": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "description": "Required: true\nExample: \"new-password123\"",
+                    "type": "string"
+                },
+                "token": {
+                    "description": "Required: true",
+                    "type": "string"
+                }
+            }
+        },
+        "handler.SchedulerStatus": {
+            "type": "object",
+            "properties": {
+                "runs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entities.SchedulerRun"
+                    }
+                },
+                "tasks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/scheduler.Task"
+                    }
+                }
+            }
+        },
+        "handler.ScreenPayload": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "description": "Required: true\nExample: \"Screen 1\"",
+                    "type": "string"
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.SeatRowPayload"
+                    }
+                }
+            }
+        },
+        "handler.SeatRowPayload": {
+            "type": "object",
+            "properties": {
+                "kind": {
+                    "description": "Example: \"standard\"",
+                    "type": "string"
+                },
+                "row": {
+                    "description": "Required: true\nExample: \"A\"",
+                    "type": "string"
+                },
+                "seats": {
+                    "description": "Required: true\nExample: 12",
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.ShowtimePayload": {
+            "type": "object",
+            "properties": {
+                "ends_at": {
+                    "description": "ถ้าไม่ระบุ จะคำนวณจาก runtime ของหนัง",
+                    "type": "string"
+                },
+                "movie_id": {
+                    "description": "Required: true",
+                    "type": "integer"
+                },
+                "price_cents": {
+                    "type": "integer"
+                },
+                "screen_id": {
+                    "description": "Required: true",
+                    "type": "integer"
+                },
+                "starts_at": {
+                    "description": "Required: true\nExample: \"2024-11-01T19:30:00+07:00\"",
+                    "type": "string"
+                }
+            }
+        },
+        "handler.TheaterPayload": {
+            "type": "object",
+            "properties": {
+                "location": {
+                    "description": "Example: \"Bangkok\"",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Required: true\nExample: \"Central World\"",
+                    "type": "string"
+                }
+            }
+        },
+        "handler.TwoFactorCodePayload": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Example: \"123456\"",
+                    "type": "string"
+                },
+                "recovery_code": {
+                    "description": "Example: \"abcde-fghij\"",
+                    "type": "string"
+                }
+            }
+        },
+        "handler.TwoFactorEnrollment": {
+            "type": "object",
+            "properties": {
+                "otpauth_uri": {
+                    "type": "string"
+                },
+                "secret": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.TwoFactorLoginPayload": {
+            "type": "object",
+            "properties": {
+                "challenge_token": {
+                    "description": "Required: true",
+                    "type": "string"
+                },
+                "code": {
+                    "description": "Example: \"123456\"",
+                    "type": "string"
+                },
+                "recovery_code": {
+                    "description": "Example: \"abcde-fghij\"",
+                    "type": "string"
+                }
+            }
+        },
+        "handler.TwoFactorStatus": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "recovery_codes_remaining": {
+                    "type": "integer"
+                },
+                "required": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "handler.UnlockLoginPayload": {
+            "type": "object",
+            "properties": {
+                "ip_address": {
+                    "description": "Example: \"203.0.113.7\"",
+                    "type": "string"
+                }
+            }
+        },
+        "handler.UpdateProfilePayload": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "description": "อีเมลใหม่ จะเปลี่ยนหลังยืนยันจากลิงก์ที่ส่งไปที่อีเมลใหม่\nExample: \"john.new@example.com\"",
+                    "type": "string"
+                },
+                "first_name": {
+                    "description": "Example: \"John\"",
+                    "type": "string"
+                },
+                "last_name": {
+                    "description": "Example: \"Doe\"",
+                    "type": "string"
+                }
+            }
+        },
+        "handler.UpdateUserRolePayload": {
+            "type": "object",
+            "properties": {
+                "permissions": {
+                    "description": "สิทธิ์ที่ให้เพิ่มจากสิทธิ์ของบทบาท\nExample: [\"movies:delete\"]",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "role": {
+                    "description": "Required: true\nExample: \"editor\"",
+                    "type": "string"
+                }
+            }
+        },
+        "handler.UserExport": {
+            "type": "object",
+            "properties": {
+                "api_keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entities.APIKey"
+                    }
+                },
+                "audit_log": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entities.AuditLog"
+                    }
+                },
+                "bookings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entities.Booking"
+                    }
+                },
+                "exported_at": {
+                    "type": "string"
+                },
+                "identities": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entities.UserIdentity"
+                    }
+                },
+                "login_history": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entities.LoginAttempt"
+                    }
+                },
+                "profile": {
+                    "$ref": "#/definitions/handler.ExportedProfile"
+                },
+                "sessions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entities.Session"
+                    }
+                },
+                "two_factor": {
+                    "$ref": "#/definitions/handler.TwoFactorStatus"
+                }
+            }
+        },
+        "handler.UserLoginPayload": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "description": "Required: true\nExample: \"",
+                    "type": "string"
+                },
+                "password": {
+                    "description": "Required: true\nExample: \"password123\"",
+                    "type": "string"
+                }
+            }
+        },
+        "handler.UserProfile": {
+            "type": "object",
+            "properties": {
+                "deletion_scheduled_at": {
+                    "description": "DeletionScheduledAt มีค่าเมื่อผู้ใช้ขอลบบัญชี บัญชีจะถูกลบเมื่อถึงเวลานี้",
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "role": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.UserRegisterPayload": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "description": "Required: true\nExample: \"john@example.com\"",
+                    "type": "string"
                 },
                 "first_name": {
                     "description": "Required: true\nExample: \"John\"",
                     "type": "string"
                 },
+                "invite_code": {
+                    "description": "InviteCode ต้องระบุเมื่อเปิดรับสมัครแบบเชิญเท่านั้น ผู้ใช้จะได้บทบาทตามรหัส\nExample: \"inv_3q2-7wEB...\"",
+                    "type": "string"
+                },
                 "last_name": {
                     "description": "Required: true\nExample: \"Doe\"",
                     "type": "string"
@@ -578,6 +5050,149 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "jwtkeys.JWK": {
+            "type": "object",
+            "properties": {
+                "alg": {
+                    "type": "string"
+                },
+                "crv": {
+                    "description": "Curve และ X ใช้กับ Ed25519",
+                    "type": "string"
+                },
+                "e": {
+                    "type": "string"
+                },
+                "kid": {
+                    "type": "string"
+                },
+                "kty": {
+                    "type": "string"
+                },
+                "n": {
+                    "description": "N และ E ใช้กับ RSA",
+                    "type": "string"
+                },
+                "use": {
+                    "type": "string"
+                },
+                "x": {
+                    "type": "string"
+                }
+            }
+        },
+        "jwtkeys.JWKS": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/jwtkeys.JWK"
+                    }
+                }
+            }
+        },
+        "metadata.Candidate": {
+            "type": "object",
+            "properties": {
+                "overview": {
+                    "type": "string"
+                },
+                "poster_path": {
+                    "type": "string"
+                },
+                "poster_url": {
+                    "type": "string"
+                },
+                "provider_id": {
+                    "type": "string"
+                },
+                "release_date": {
+                    "type": "string"
+                },
+                "score": {
+                    "type": "number"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "year": {
+                    "type": "integer"
+                }
+            }
+        },
+        "metadata.Genre": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "scheduler.Task": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "next_run": {
+                    "type": "string"
+                },
+                "schedule": {
+                    "type": "string"
+                }
+            }
+        },
+        "utils.FieldErrors": {
+            "type": "object",
+            "additionalProperties": {
+                "type": "string"
+            }
+        },
+        "utils.Problem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Code คือรหัสของ error ที่ client ใช้แยกกรณีได้โดยไม่ต้องอ่าน Detail\nExample: not_found",
+                    "type": "string"
+                },
+                "detail": {
+                    "description": "Detail คือคำอธิบายของปัญหาครั้งนี้ ไม่มีรายละเอียดภายในอย่าง SQL\nExample: movie not found",
+                    "type": "string"
+                },
+                "fields": {
+                    "description": "Fields คือข้อความ error ของแต่ละ field มีเฉพาะเมื่อ Code เป็น validation_failed",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/utils.FieldErrors"
+                        }
+                    ]
+                },
+                "instance": {
+                    "description": "Instance คือ path ของ request ที่เกิดปัญหา\nExample: /api/v1/movies/42",
+                    "type": "string"
+                },
+                "request_id": {
+                    "description": "RequestID ตรงกับ header X-Request-ID ใช้ค้น log ของ request นี้",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Example: 404",
+                    "type": "integer"
+                },
+                "title": {
+                    "description": "Example: Not Found",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "Type คือ URI ที่บอกชนิดของปัญหา\nExample: /problems/not_found",
+                    "type": "string"
+                }
+            }
         }
     },
     "securityDefinitions": {
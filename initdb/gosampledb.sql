@@ -16,30 +16,43 @@ SET xmloption = content;
 SET client_min_messages = warning;
 SET row_security = off;
 
+--
+-- Name: btree_gist; Type: EXTENSION; Schema: -; Owner: -
+--
+
+CREATE EXTENSION IF NOT EXISTS btree_gist WITH SCHEMA public;
+
 SET default_tablespace = '';
 
 SET default_table_access_method = heap;
 
 --
--- Name: genres; Type: TABLE; Schema: public; Owner: postgres
+-- Name: api_keys; Type: TABLE; Schema: public; Owner: postgres
 --
 
-CREATE TABLE public.genres (
-    id integer NOT NULL,
-    genre character varying(255),
-    created_at timestamp without time zone,
-    updated_at timestamp without time zone
+CREATE TABLE public.api_keys (
+    id bigint NOT NULL,
+    name character varying(100) NOT NULL,
+    prefix character varying(20) NOT NULL,
+    key_hash character(64) NOT NULL,
+    permissions jsonb DEFAULT '[]'::jsonb NOT NULL,
+    rate_limit integer DEFAULT 60 NOT NULL,
+    created_by integer,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    expires_at timestamp with time zone,
+    last_used_at timestamp with time zone,
+    revoked_at timestamp with time zone
 );
 
 
-ALTER TABLE public.genres OWNER TO postgres;
+ALTER TABLE public.api_keys OWNER TO postgres;
 
 --
--- Name: genres_id_seq; Type: SEQUENCE; Schema: public; Owner: postgres
+-- Name: api_keys_id_seq; Type: SEQUENCE; Schema: public; Owner: postgres
 --
 
-ALTER TABLE public.genres ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
-    SEQUENCE NAME public.genres_id_seq
+ALTER TABLE public.api_keys ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME public.api_keys_id_seq
     START WITH 1
     INCREMENT BY 1
     NO MINVALUE
@@ -49,43 +62,94 @@ ALTER TABLE public.genres ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
 
 
 --
--- Name: movies; Type: TABLE; Schema: public; Owner: postgres
+-- Name: audit_logs; Type: TABLE; Schema: public; Owner: postgres
 --
 
-CREATE TABLE public.movies (
+CREATE TABLE public.audit_logs (
+    id bigint NOT NULL,
+    actor_type character varying(20) NOT NULL,
+    actor_id character varying(50) NOT NULL,
+    action character varying(255) NOT NULL,
+    method character varying(10) NOT NULL,
+    path character varying(2048) NOT NULL,
+    status integer NOT NULL,
+    ip_address character varying(64) DEFAULT ''::character varying NOT NULL,
+    user_agent character varying(512) DEFAULT ''::character varying NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT audit_logs_actor_type_check CHECK (((actor_type)::text = ANY ((ARRAY['user'::character varying, 'api_key'::character varying])::text[])))
+);
+
+
+ALTER TABLE public.audit_logs OWNER TO postgres;
+
+--
+-- Name: audit_logs_id_seq; Type: SEQUENCE; Schema: public; Owner: postgres
+--
+
+ALTER TABLE public.audit_logs ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME public.audit_logs_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1
+);
+
+
+--
+-- Name: booking_seats; Type: TABLE; Schema: public; Owner: postgres
+--
+
+CREATE TABLE public.booking_seats (
     id integer NOT NULL,
-    title character varying(512),
-    release_date date,
-    runtime integer,
-    mpaa_rating character varying(10),
-    description text,
-    image character varying(255),
-    created_at timestamp without time zone,
-    updated_at timestamp without time zone
+    booking_id integer NOT NULL,
+    showtime_id integer NOT NULL,
+    seat_id integer NOT NULL,
+    released boolean DEFAULT false NOT NULL
 );
 
 
-ALTER TABLE public.movies OWNER TO postgres;
+ALTER TABLE public.booking_seats OWNER TO postgres;
 
 --
--- Name: movies_genres; Type: TABLE; Schema: public; Owner: postgres
+-- Name: booking_seats_id_seq; Type: SEQUENCE; Schema: public; Owner: postgres
 --
 
-CREATE TABLE public.movies_genres (
+ALTER TABLE public.booking_seats ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME public.booking_seats_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1
+);
+
+
+--
+-- Name: bookings; Type: TABLE; Schema: public; Owner: postgres
+--
+
+CREATE TABLE public.bookings (
     id integer NOT NULL,
-    movie_id integer,
-    genre_id integer
+    showtime_id integer NOT NULL,
+    user_id integer NOT NULL,
+    status character varying(20) DEFAULT 'held'::character varying NOT NULL,
+    expires_at timestamp with time zone NOT NULL,
+    confirmed_at timestamp with time zone,
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone,
+    CONSTRAINT bookings_status_check CHECK (((status)::text = ANY ((ARRAY['held'::character varying, 'confirmed'::character varying, 'cancelled'::character varying, 'expired'::character varying])::text[])))
 );
 
 
-ALTER TABLE public.movies_genres OWNER TO postgres;
+ALTER TABLE public.bookings OWNER TO postgres;
 
 --
--- Name: movies_genres_id_seq; Type: SEQUENCE; Schema: public; Owner: postgres
+-- Name: bookings_id_seq; Type: SEQUENCE; Schema: public; Owner: postgres
 --
 
-ALTER TABLE public.movies_genres ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
-    SEQUENCE NAME public.movies_genres_id_seq
+ALTER TABLE public.bookings ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME public.bookings_id_seq
     START WITH 1
     INCREMENT BY 1
     NO MINVALUE
@@ -95,11 +159,28 @@ ALTER TABLE public.movies_genres ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTIT
 
 
 --
--- Name: movies_id_seq; Type: SEQUENCE; Schema: public; Owner: postgres
+-- Name: email_verifications; Type: TABLE; Schema: public; Owner: postgres
 --
 
-ALTER TABLE public.movies ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
-    SEQUENCE NAME public.movies_id_seq
+CREATE TABLE public.email_verifications (
+    id bigint NOT NULL,
+    user_id integer NOT NULL,
+    token_hash character(64) NOT NULL,
+    new_email character varying(255),
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    expires_at timestamp with time zone NOT NULL,
+    used_at timestamp with time zone
+);
+
+
+ALTER TABLE public.email_verifications OWNER TO postgres;
+
+--
+-- Name: email_verifications_id_seq; Type: SEQUENCE; Schema: public; Owner: postgres
+--
+
+ALTER TABLE public.email_verifications ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME public.email_verifications_id_seq
     START WITH 1
     INCREMENT BY 1
     NO MINVALUE
@@ -109,28 +190,26 @@ ALTER TABLE public.movies ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
 
 
 --
--- Name: users; Type: TABLE; Schema: public; Owner: postgres
+-- Name: genres; Type: TABLE; Schema: public; Owner: postgres
 --
 
-CREATE TABLE public.users (
+CREATE TABLE public.genres (
     id integer NOT NULL,
-    first_name character varying(255),
-    last_name character varying(255),
-    email character varying(255),
-    password character varying(255),
+    genre character varying(255),
+    tmdb_id integer,
     created_at timestamp without time zone,
     updated_at timestamp without time zone
 );
 
 
-ALTER TABLE public.users OWNER TO postgres;
+ALTER TABLE public.genres OWNER TO postgres;
 
 --
--- Name: users_id_seq; Type: SEQUENCE; Schema: public; Owner: postgres
+-- Name: genres_id_seq; Type: SEQUENCE; Schema: public; Owner: postgres
 --
 
-ALTER TABLE public.users ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
-    SEQUENCE NAME public.users_id_seq
+ALTER TABLE public.genres ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME public.genres_id_seq
     START WITH 1
     INCREMENT BY 1
     NO MINVALUE
@@ -140,136 +219,1368 @@ ALTER TABLE public.users ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
 
 
 --
--- Data for Name: genres; Type: TABLE DATA; Schema: public; Owner: postgres
+-- Name: invite_redemptions; Type: TABLE; Schema: public; Owner: postgres
 --
 
-COPY public.genres (id, genre, created_at, updated_at) FROM stdin;
-1	Comedy	2022-09-23 00:00:00	2022-09-23 00:00:00
-2	Sci-Fi	2022-09-23 00:00:00	2022-09-23 00:00:00
-3	Horror	2022-09-23 00:00:00	2022-09-23 00:00:00
-4	Romance	2022-09-23 00:00:00	2022-09-23 00:00:00
-5	Action	2022-09-23 00:00:00	2022-09-23 00:00:00
-6	Thriller	2022-09-23 00:00:00	2022-09-23 00:00:00
-7	Drama	2022-09-23 00:00:00	2022-09-23 00:00:00
-8	Mystery	2022-09-23 00:00:00	2022-09-23 00:00:00
-9	Crime	2022-09-23 00:00:00	2022-09-23 00:00:00
-10	Animation	2022-09-23 00:00:00	2022-09-23 00:00:00
-11	Adventure	2022-09-23 00:00:00	2022-09-23 00:00:00
-12	Fantasy	2022-09-23 00:00:00	2022-09-23 00:00:00
-13	Superhero	2022-09-23 00:00:00	2022-09-23 00:00:00
-\.
+CREATE TABLE public.invite_redemptions (
+    id bigint NOT NULL,
+    invite_id bigint NOT NULL,
+    user_id integer NOT NULL,
+    redeemed_at timestamp with time zone DEFAULT now() NOT NULL
+);
 
 
+ALTER TABLE public.invite_redemptions OWNER TO postgres;
+
 --
--- Data for Name: movies; Type: TABLE DATA; Schema: public; Owner: postgres
+-- Name: invite_redemptions_id_seq; Type: SEQUENCE; Schema: public; Owner: postgres
 --
 
-COPY public.movies (id, title, release_date, runtime, mpaa_rating, description, image, created_at, updated_at) FROM stdin;
-1	Highlander	1986-03-07	116	R	He fought his first battle on the Scottish Highlands in 1536. He will fight his greatest battle on the streets of New York City in 1986. His name is Connor MacLeod. He is immortal.	/8Z8dptJEypuLoOQro1WugD855YE.jpg	2022-09-23 00:00:00	2022-09-23 00:00:00
-2	Raiders of the Lost Ark	1981-06-12	115	PG-13	Archaeology professor Indiana Jones ventures to seize a biblical artefact known as the Ark of the Covenant. While doing so, he puts up a fight against Renee and a troop of Nazis.	/ceG9VzoRAVGwivFU403Wc3AHRys.jpg	2022-09-23 00:00:00	2022-09-23 00:00:00
-3	The Godfather	1972-03-24	175	18A	The aging patriarch of an organized crime dynasty in postwar New York City transfers control of his clandestine empire to his reluctant youngest son.	/3bhkrj58Vtu7enYsRolD1fZdja1.jpg	2022-09-23 00:00:00	2022-09-23 00:00:00
-4	Figomovie	1986-03-07	200	R	He fought his first battle on the Scottish Highlands in 1536. He will fight his greatest battle on the streets of New York City in 1986. His name is Connor MacLeod. He is immortal.	/8Z8dptJEypuLoOQro1WugD855YE.jpg	2024-10-17 21:22:16.180886	2024-10-17 21:43:39.711559
-10		0001-01-01	0				2024-10-19 21:54:08.477096	2024-10-19 21:54:08.477096
-\.
+ALTER TABLE public.invite_redemptions ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME public.invite_redemptions_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1
+);
 
 
 --
--- Data for Name: movies_genres; Type: TABLE DATA; Schema: public; Owner: postgres
+-- Name: invites; Type: TABLE; Schema: public; Owner: postgres
 --
 
-COPY public.movies_genres (id, movie_id, genre_id) FROM stdin;
-1	1	5
-2	1	12
-3	2	5
-4	2	11
-5	3	9
-6	3	7
-\.
+CREATE TABLE public.invites (
+    id bigint NOT NULL,
+    prefix character varying(20) NOT NULL,
+    code_hash character(64) NOT NULL,
+    role character varying(20) DEFAULT 'viewer'::character varying NOT NULL,
+    note character varying(255) DEFAULT ''::character varying NOT NULL,
+    max_uses integer DEFAULT 1 NOT NULL,
+    uses integer DEFAULT 0 NOT NULL,
+    created_by integer,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    expires_at timestamp with time zone NOT NULL,
+    revoked_at timestamp with time zone,
+    CONSTRAINT invites_max_uses_check CHECK ((max_uses > 0)),
+    CONSTRAINT invites_role_check CHECK (((role)::text = ANY ((ARRAY['admin'::character varying, 'editor'::character varying, 'viewer'::character varying])::text[]))),
+    CONSTRAINT invites_uses_check CHECK (((uses >= 0) AND (uses <= max_uses)))
+);
+
 
+ALTER TABLE public.invites OWNER TO postgres;
 
 --
--- Data for Name: users; Type: TABLE DATA; Schema: public; Owner: postgres
+-- Name: invites_id_seq; Type: SEQUENCE; Schema: public; Owner: postgres
 --
 
-COPY public.users (id, first_name, last_name, email, password, created_at, updated_at) FROM stdin;
-1	Admin	User	admin@example.com	$2a$14$wVsaPvJnJJsomWArouWCtusem6S/.Gauq/GjOIEHpyh2DAMmso1wy	2022-09-23 00:00:00	2022-09-23 00:00:00
-\.
+ALTER TABLE public.invites ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME public.invites_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1
+);
 
 
 --
--- Name: genres_id_seq; Type: SEQUENCE SET; Schema: public; Owner: postgres
+-- Name: jobs; Type: TABLE; Schema: public; Owner: postgres
 --
 
-SELECT pg_catalog.setval('public.genres_id_seq', 13, true);
+CREATE TABLE public.jobs (
+    id bigint NOT NULL,
+    kind character varying(100) NOT NULL,
+    payload jsonb DEFAULT '{}'::jsonb NOT NULL,
+    status character varying(20) DEFAULT 'pending'::character varying NOT NULL,
+    attempts integer DEFAULT 0 NOT NULL,
+    max_attempts integer DEFAULT 5 NOT NULL,
+    run_at timestamp with time zone DEFAULT now() NOT NULL,
+    last_error text DEFAULT ''::text NOT NULL,
+    locked_by character varying(255) DEFAULT ''::character varying NOT NULL,
+    locked_at timestamp with time zone,
+    finished_at timestamp with time zone,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT jobs_status_check CHECK (((status)::text = ANY ((ARRAY['pending'::character varying, 'running'::character varying, 'succeeded'::character varying, 'dead'::character varying])::text[])))
+);
+
 
+ALTER TABLE public.jobs OWNER TO postgres;
 
 --
--- Name: movies_genres_id_seq; Type: SEQUENCE SET; Schema: public; Owner: postgres
+-- Name: jobs_id_seq; Type: SEQUENCE; Schema: public; Owner: postgres
 --
 
-SELECT pg_catalog.setval('public.movies_genres_id_seq', 6, true);
+ALTER TABLE public.jobs ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME public.jobs_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1
+);
 
 
 --
--- Name: movies_id_seq; Type: SEQUENCE SET; Schema: public; Owner: postgres
+-- Name: login_attempts; Type: TABLE; Schema: public; Owner: postgres
 --
 
-SELECT pg_catalog.setval('public.movies_id_seq', 10, true);
+CREATE TABLE public.login_attempts (
+    id bigint NOT NULL,
+    email character varying(255) NOT NULL,
+    user_id integer,
+    ip_address character varying(64) DEFAULT ''::character varying NOT NULL,
+    user_agent character varying(512) DEFAULT ''::character varying NOT NULL,
+    result character varying(30) NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
 
+ALTER TABLE public.login_attempts OWNER TO postgres;
 
 --
--- Name: users_id_seq; Type: SEQUENCE SET; Schema: public; Owner: postgres
+-- Name: login_attempts_id_seq; Type: SEQUENCE; Schema: public; Owner: postgres
 --
 
-SELECT pg_catalog.setval('public.users_id_seq', 1, true);
+ALTER TABLE public.login_attempts ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME public.login_attempts_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1
+);
 
 
 --
--- Name: genres genres_pkey; Type: CONSTRAINT; Schema: public; Owner: postgres
+-- Name: login_throttles; Type: TABLE; Schema: public; Owner: postgres
 --
 
-ALTER TABLE ONLY public.genres
-    ADD CONSTRAINT genres_pkey PRIMARY KEY (id);
+CREATE TABLE public.login_throttles (
+    key character varying(320) NOT NULL,
+    failures integer DEFAULT 0 NOT NULL,
+    last_failure_at timestamp with time zone NOT NULL,
+    locked_until timestamp with time zone
+);
 
 
+ALTER TABLE public.login_throttles OWNER TO postgres;
+
 --
--- Name: movies_genres movies_genres_pkey; Type: CONSTRAINT; Schema: public; Owner: postgres
+-- Name: movie_image_variants; Type: TABLE; Schema: public; Owner: postgres
 --
 
-ALTER TABLE ONLY public.movies_genres
-    ADD CONSTRAINT movies_genres_pkey PRIMARY KEY (id);
+CREATE TABLE public.movie_image_variants (
+    id integer NOT NULL,
+    image_id integer NOT NULL,
+    storage_key character varying(512) NOT NULL,
+    width integer NOT NULL,
+    height integer NOT NULL
+);
 
 
+ALTER TABLE public.movie_image_variants OWNER TO postgres;
+
 --
--- Name: movies movies_pkey; Type: CONSTRAINT; Schema: public; Owner: postgres
+-- Name: movie_image_variants_id_seq; Type: SEQUENCE; Schema: public; Owner: postgres
 --
 
-ALTER TABLE ONLY public.movies
-    ADD CONSTRAINT movies_pkey PRIMARY KEY (id);
+ALTER TABLE public.movie_image_variants ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME public.movie_image_variants_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1
+);
 
 
 --
--- Name: users users_pkey; Type: CONSTRAINT; Schema: public; Owner: postgres
+-- Name: movie_images; Type: TABLE; Schema: public; Owner: postgres
 --
 
-ALTER TABLE ONLY public.users
-    ADD CONSTRAINT users_pkey PRIMARY KEY (id);
+CREATE TABLE public.movie_images (
+    id integer NOT NULL,
+    movie_id integer NOT NULL,
+    kind character varying(20) NOT NULL,
+    storage_key character varying(512) NOT NULL,
+    content_type character varying(50) NOT NULL,
+    width integer NOT NULL,
+    height integer NOT NULL,
+    size_bytes integer NOT NULL,
+    created_at timestamp with time zone,
+    CONSTRAINT movie_images_kind_check CHECK (((kind)::text = ANY ((ARRAY['poster'::character varying, 'backdrop'::character varying])::text[])))
+);
+
 
+ALTER TABLE public.movie_images OWNER TO postgres;
 
 --
--- Name: movies_genres movies_genres_genre_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: postgres
+-- Name: movie_images_id_seq; Type: SEQUENCE; Schema: public; Owner: postgres
 --
 
-ALTER TABLE ONLY public.movies_genres
-    ADD CONSTRAINT movies_genres_genre_id_fkey FOREIGN KEY (genre_id) REFERENCES public.genres(id) ON UPDATE CASCADE ON DELETE CASCADE;
+ALTER TABLE public.movie_images ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME public.movie_images_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1
+);
 
 
 --
--- Name: movies_genres movies_genres_movie_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: postgres
+-- Name: movies; Type: TABLE; Schema: public; Owner: postgres
 --
 
-ALTER TABLE ONLY public.movies_genres
-    ADD CONSTRAINT movies_genres_movie_id_fkey FOREIGN KEY (movie_id) REFERENCES public.movies(id) ON UPDATE CASCADE ON DELETE CASCADE;
+CREATE TABLE public.movies (
+    id integer NOT NULL,
+    title character varying(512),
+    release_date date,
+    runtime integer,
+    mpaa_rating character varying(10),
+    description text,
+    image character varying(255),
+    backdrop character varying(255),
+    tmdb_id integer,
+    created_at timestamp without time zone,
+    updated_at timestamp without time zone
+);
+
+
+ALTER TABLE public.movies OWNER TO postgres;
+
+--
+-- Name: movies_genres; Type: TABLE; Schema: public; Owner: postgres
+--
+
+CREATE TABLE public.movies_genres (
+    id integer NOT NULL,
+    movie_id integer,
+    genre_id integer
+);
+
+
+ALTER TABLE public.movies_genres OWNER TO postgres;
+
+--
+-- Name: movies_genres_id_seq; Type: SEQUENCE; Schema: public; Owner: postgres
+--
+
+ALTER TABLE public.movies_genres ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME public.movies_genres_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1
+);
+
+
+--
+-- Name: movies_id_seq; Type: SEQUENCE; Schema: public; Owner: postgres
+--
+
+ALTER TABLE public.movies ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME public.movies_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1
+);
+
+
+--
+-- Name: oidc_states; Type: TABLE; Schema: public; Owner: postgres
+--
+
+CREATE TABLE public.oidc_states (
+    state_hash character(64) NOT NULL,
+    provider character varying(50) NOT NULL,
+    nonce character varying(64) NOT NULL,
+    code_verifier character varying(128) NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    expires_at timestamp with time zone NOT NULL
+);
+
+
+ALTER TABLE public.oidc_states OWNER TO postgres;
+
+--
+-- Name: password_resets; Type: TABLE; Schema: public; Owner: postgres
+--
+
+CREATE TABLE public.password_resets (
+    id bigint NOT NULL,
+    user_id integer NOT NULL,
+    token_hash character(64) NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    expires_at timestamp with time zone NOT NULL,
+    used_at timestamp with time zone
+);
+
+
+ALTER TABLE public.password_resets OWNER TO postgres;
+
+--
+-- Name: password_resets_id_seq; Type: SEQUENCE; Schema: public; Owner: postgres
+--
+
+ALTER TABLE public.password_resets ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME public.password_resets_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1
+);
+
+
+--
+-- Name: rate_limit_buckets; Type: TABLE; Schema: public; Owner: postgres
+--
+
+CREATE TABLE public.rate_limit_buckets (
+    key character varying(400) NOT NULL,
+    tokens double precision NOT NULL,
+    refilled_at timestamp with time zone NOT NULL,
+    expires_at timestamp with time zone NOT NULL
+);
+
+
+ALTER TABLE public.rate_limit_buckets OWNER TO postgres;
+
+--
+-- Name: recovery_codes; Type: TABLE; Schema: public; Owner: postgres
+--
+
+CREATE TABLE public.recovery_codes (
+    id bigint NOT NULL,
+    user_id integer NOT NULL,
+    code_hash character(64) NOT NULL,
+    used_at timestamp with time zone,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+ALTER TABLE public.recovery_codes OWNER TO postgres;
+
+--
+-- Name: recovery_codes_id_seq; Type: SEQUENCE; Schema: public; Owner: postgres
+--
+
+ALTER TABLE public.recovery_codes ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME public.recovery_codes_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1
+);
+
+
+--
+-- Name: scheduler_runs; Type: TABLE; Schema: public; Owner: postgres
+--
+
+CREATE TABLE public.scheduler_runs (
+    id bigint NOT NULL,
+    task character varying(100) NOT NULL,
+    instance character varying(255) NOT NULL,
+    status character varying(20) NOT NULL,
+    detail text DEFAULT ''::text NOT NULL,
+    error text DEFAULT ''::text NOT NULL,
+    scheduled_for timestamp with time zone NOT NULL,
+    started_at timestamp with time zone DEFAULT now() NOT NULL,
+    finished_at timestamp with time zone,
+    CONSTRAINT scheduler_runs_status_check CHECK (((status)::text = ANY ((ARRAY['running'::character varying, 'succeeded'::character varying, 'failed'::character varying])::text[])))
+);
+
+
+ALTER TABLE public.scheduler_runs OWNER TO postgres;
+
+--
+-- Name: scheduler_runs_id_seq; Type: SEQUENCE; Schema: public; Owner: postgres
+--
+
+ALTER TABLE public.scheduler_runs ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME public.scheduler_runs_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1
+);
+
+
+--
+-- Name: screens; Type: TABLE; Schema: public; Owner: postgres
+--
+
+CREATE TABLE public.screens (
+    id integer NOT NULL,
+    theater_id integer NOT NULL,
+    name character varying(255) NOT NULL,
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone
+);
+
+
+ALTER TABLE public.screens OWNER TO postgres;
+
+--
+-- Name: screens_id_seq; Type: SEQUENCE; Schema: public; Owner: postgres
+--
+
+ALTER TABLE public.screens ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME public.screens_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1
+);
+
+
+--
+-- Name: seats; Type: TABLE; Schema: public; Owner: postgres
+--
+
+CREATE TABLE public.seats (
+    id integer NOT NULL,
+    screen_id integer NOT NULL,
+    row_label character varying(5) NOT NULL,
+    seat_number integer NOT NULL,
+    kind character varying(20) DEFAULT 'standard'::character varying NOT NULL
+);
+
+
+ALTER TABLE public.seats OWNER TO postgres;
+
+--
+-- Name: seats_id_seq; Type: SEQUENCE; Schema: public; Owner: postgres
+--
+
+ALTER TABLE public.seats ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME public.seats_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1
+);
+
+
+--
+-- Name: sessions; Type: TABLE; Schema: public; Owner: postgres
+--
+
+CREATE TABLE public.sessions (
+    id bigint NOT NULL,
+    user_id integer NOT NULL,
+    family_id uuid NOT NULL,
+    token_hash character(64) NOT NULL,
+    user_agent character varying(512) DEFAULT ''::character varying NOT NULL,
+    ip_address character varying(45) DEFAULT ''::character varying NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    expires_at timestamp with time zone NOT NULL,
+    rotated_at timestamp with time zone,
+    revoked_at timestamp with time zone
+);
+
+
+ALTER TABLE public.sessions OWNER TO postgres;
+
+--
+-- Name: sessions_id_seq; Type: SEQUENCE; Schema: public; Owner: postgres
+--
+
+ALTER TABLE public.sessions ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME public.sessions_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1
+);
+
+
+--
+-- Name: showtimes; Type: TABLE; Schema: public; Owner: postgres
+--
+
+CREATE TABLE public.showtimes (
+    id integer NOT NULL,
+    movie_id integer NOT NULL,
+    screen_id integer NOT NULL,
+    starts_at timestamp with time zone NOT NULL,
+    ends_at timestamp with time zone NOT NULL,
+    price_cents integer DEFAULT 0 NOT NULL,
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone,
+    CONSTRAINT showtimes_check CHECK ((ends_at > starts_at))
+);
+
+
+ALTER TABLE public.showtimes OWNER TO postgres;
+
+--
+-- Name: showtimes_id_seq; Type: SEQUENCE; Schema: public; Owner: postgres
+--
+
+ALTER TABLE public.showtimes ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME public.showtimes_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1
+);
+
+
+--
+-- Name: theaters; Type: TABLE; Schema: public; Owner: postgres
+--
+
+CREATE TABLE public.theaters (
+    id integer NOT NULL,
+    name character varying(255) NOT NULL,
+    location character varying(255),
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone
+);
+
+
+ALTER TABLE public.theaters OWNER TO postgres;
+
+--
+-- Name: theaters_id_seq; Type: SEQUENCE; Schema: public; Owner: postgres
+--
+
+ALTER TABLE public.theaters ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME public.theaters_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1
+);
+
+
+--
+-- Name: user_identities; Type: TABLE; Schema: public; Owner: postgres
+--
+
+CREATE TABLE public.user_identities (
+    id bigint NOT NULL,
+    user_id integer NOT NULL,
+    provider character varying(50) NOT NULL,
+    subject character varying(255) NOT NULL,
+    email character varying(255) NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    last_login_at timestamp with time zone
+);
+
+
+ALTER TABLE public.user_identities OWNER TO postgres;
+
+--
+-- Name: user_identities_id_seq; Type: SEQUENCE; Schema: public; Owner: postgres
+--
+
+ALTER TABLE public.user_identities ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME public.user_identities_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1
+);
+
+
+--
+-- Name: user_totps; Type: TABLE; Schema: public; Owner: postgres
+--
+
+CREATE TABLE public.user_totps (
+    user_id integer NOT NULL,
+    secret character varying(64) NOT NULL,
+    last_used_step bigint DEFAULT 0 NOT NULL,
+    confirmed_at timestamp with time zone,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+ALTER TABLE public.user_totps OWNER TO postgres;
+
+--
+-- Name: users; Type: TABLE; Schema: public; Owner: postgres
+--
+
+CREATE TABLE public.users (
+    id integer NOT NULL,
+    first_name character varying(255),
+    last_name character varying(255),
+    email character varying(255),
+    password character varying(255),
+    role character varying(20) DEFAULT 'viewer'::character varying NOT NULL,
+    permissions jsonb DEFAULT '[]'::jsonb NOT NULL,
+    email_verified_at timestamp with time zone,
+    disabled_at timestamp with time zone,
+    deletion_scheduled_at timestamp with time zone,
+    created_at timestamp without time zone,
+    updated_at timestamp without time zone,
+    CONSTRAINT users_role_check CHECK (((role)::text = ANY ((ARRAY['admin'::character varying, 'editor'::character varying, 'viewer'::character varying])::text[])))
+);
+
+
+ALTER TABLE public.users OWNER TO postgres;
+
+--
+-- Name: users_id_seq; Type: SEQUENCE; Schema: public; Owner: postgres
+--
+
+ALTER TABLE public.users ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME public.users_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1
+);
+
+
+--
+-- Data for Name: genres; Type: TABLE DATA; Schema: public; Owner: postgres
+--
+
+COPY public.genres (id, genre, tmdb_id, created_at, updated_at) FROM stdin;
+1	Comedy	35	2022-09-23 00:00:00	2022-09-23 00:00:00
+2	Sci-Fi	878	2022-09-23 00:00:00	2022-09-23 00:00:00
+3	Horror	27	2022-09-23 00:00:00	2022-09-23 00:00:00
+4	Romance	10749	2022-09-23 00:00:00	2022-09-23 00:00:00
+5	Action	28	2022-09-23 00:00:00	2022-09-23 00:00:00
+6	Thriller	53	2022-09-23 00:00:00	2022-09-23 00:00:00
+7	Drama	18	2022-09-23 00:00:00	2022-09-23 00:00:00
+8	Mystery	9648	2022-09-23 00:00:00	2022-09-23 00:00:00
+9	Crime	80	2022-09-23 00:00:00	2022-09-23 00:00:00
+10	Animation	16	2022-09-23 00:00:00	2022-09-23 00:00:00
+11	Adventure	12	2022-09-23 00:00:00	2022-09-23 00:00:00
+12	Fantasy	14	2022-09-23 00:00:00	2022-09-23 00:00:00
+13	Superhero	\N	2022-09-23 00:00:00	2022-09-23 00:00:00
+\.
+
+
+--
+-- Data for Name: movies; Type: TABLE DATA; Schema: public; Owner: postgres
+--
+
+COPY public.movies (id, title, release_date, runtime, mpaa_rating, description, image, created_at, updated_at) FROM stdin;
+1	Highlander	1986-03-07	116	R	He fought his first battle on the Scottish Highlands in 1536. He will fight his greatest battle on the streets of New York City in 1986. His name is Connor MacLeod. He is immortal.	/8Z8dptJEypuLoOQro1WugD855YE.jpg	2022-09-23 00:00:00	2022-09-23 00:00:00
+2	Raiders of the Lost Ark	1981-06-12	115	PG-13	Archaeology professor Indiana Jones ventures to seize a biblical artefact known as the Ark of the Covenant. While doing so, he puts up a fight against Renee and a troop of Nazis.	/ceG9VzoRAVGwivFU403Wc3AHRys.jpg	2022-09-23 00:00:00	2022-09-23 00:00:00
+3	The Godfather	1972-03-24	175	18A	The aging patriarch of an organized crime dynasty in postwar New York City transfers control of his clandestine empire to his reluctant youngest son.	/3bhkrj58Vtu7enYsRolD1fZdja1.jpg	2022-09-23 00:00:00	2022-09-23 00:00:00
+4	Figomovie	1986-03-07	200	R	He fought his first battle on the Scottish Highlands in 1536. He will fight his greatest battle on the streets of New York City in 1986. His name is Connor MacLeod. He is immortal.	/8Z8dptJEypuLoOQro1WugD855YE.jpg	2024-10-17 21:22:16.180886	2024-10-17 21:43:39.711559
+10		0001-01-01	0				2024-10-19 21:54:08.477096	2024-10-19 21:54:08.477096
+\.
+
+
+--
+-- Data for Name: movies_genres; Type: TABLE DATA; Schema: public; Owner: postgres
+--
+
+COPY public.movies_genres (id, movie_id, genre_id) FROM stdin;
+1	1	5
+2	1	12
+3	2	5
+4	2	11
+5	3	9
+6	3	7
+\.
+
+
+--
+-- Data for Name: users; Type: TABLE DATA; Schema: public; Owner: postgres
+--
+
+COPY public.users (id, first_name, last_name, email, password, role, permissions, email_verified_at, created_at, updated_at) FROM stdin;
+1	Admin	User	admin@example.com	$2a$14$wVsaPvJnJJsomWArouWCtusem6S/.Gauq/GjOIEHpyh2DAMmso1wy	admin	[]	2022-09-23 00:00:00+00	2022-09-23 00:00:00	2022-09-23 00:00:00
+\.
+
+
+--
+-- Name: genres_id_seq; Type: SEQUENCE SET; Schema: public; Owner: postgres
+--
+
+SELECT pg_catalog.setval('public.genres_id_seq', 13, true);
+
+
+--
+-- Name: movies_genres_id_seq; Type: SEQUENCE SET; Schema: public; Owner: postgres
+--
+
+SELECT pg_catalog.setval('public.movies_genres_id_seq', 6, true);
+
+
+--
+-- Name: movies_id_seq; Type: SEQUENCE SET; Schema: public; Owner: postgres
+--
+
+SELECT pg_catalog.setval('public.movies_id_seq', 10, true);
+
+
+--
+-- Name: users_id_seq; Type: SEQUENCE SET; Schema: public; Owner: postgres
+--
+
+SELECT pg_catalog.setval('public.users_id_seq', 1, true);
+
+
+--
+-- Name: api_keys api_keys_key_hash_key; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.api_keys
+    ADD CONSTRAINT api_keys_key_hash_key UNIQUE (key_hash);
+
+
+--
+-- Name: api_keys api_keys_pkey; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.api_keys
+    ADD CONSTRAINT api_keys_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: audit_logs audit_logs_pkey; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.audit_logs
+    ADD CONSTRAINT audit_logs_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: booking_seats booking_seats_pkey; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.booking_seats
+    ADD CONSTRAINT booking_seats_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: bookings bookings_pkey; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.bookings
+    ADD CONSTRAINT bookings_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: email_verifications email_verifications_pkey; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.email_verifications
+    ADD CONSTRAINT email_verifications_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: email_verifications email_verifications_token_hash_key; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.email_verifications
+    ADD CONSTRAINT email_verifications_token_hash_key UNIQUE (token_hash);
+
+
+--
+-- Name: genres genres_pkey; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.genres
+    ADD CONSTRAINT genres_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: genres genres_tmdb_id_key; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.genres
+    ADD CONSTRAINT genres_tmdb_id_key UNIQUE (tmdb_id);
+
+
+--
+-- Name: invite_redemptions invite_redemptions_pkey; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.invite_redemptions
+    ADD CONSTRAINT invite_redemptions_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: invite_redemptions invite_redemptions_user_id_key; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.invite_redemptions
+    ADD CONSTRAINT invite_redemptions_user_id_key UNIQUE (user_id);
+
+
+--
+-- Name: invites invites_code_hash_key; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.invites
+    ADD CONSTRAINT invites_code_hash_key UNIQUE (code_hash);
+
+
+--
+-- Name: invites invites_pkey; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.invites
+    ADD CONSTRAINT invites_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: jobs jobs_pkey; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.jobs
+    ADD CONSTRAINT jobs_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: login_attempts login_attempts_pkey; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.login_attempts
+    ADD CONSTRAINT login_attempts_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: login_throttles login_throttles_pkey; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.login_throttles
+    ADD CONSTRAINT login_throttles_pkey PRIMARY KEY (key);
+
+
+--
+-- Name: movie_image_variants movie_image_variants_pkey; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.movie_image_variants
+    ADD CONSTRAINT movie_image_variants_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: movie_images movie_images_pkey; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.movie_images
+    ADD CONSTRAINT movie_images_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: movies_genres movies_genres_pkey; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.movies_genres
+    ADD CONSTRAINT movies_genres_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: movies movies_pkey; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.movies
+    ADD CONSTRAINT movies_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: movies movies_tmdb_id_key; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.movies
+    ADD CONSTRAINT movies_tmdb_id_key UNIQUE (tmdb_id);
+
+
+--
+-- Name: oidc_states oidc_states_pkey; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.oidc_states
+    ADD CONSTRAINT oidc_states_pkey PRIMARY KEY (state_hash);
+
+
+--
+-- Name: password_resets password_resets_pkey; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.password_resets
+    ADD CONSTRAINT password_resets_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: password_resets password_resets_token_hash_key; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.password_resets
+    ADD CONSTRAINT password_resets_token_hash_key UNIQUE (token_hash);
+
+
+--
+-- Name: rate_limit_buckets rate_limit_buckets_pkey; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.rate_limit_buckets
+    ADD CONSTRAINT rate_limit_buckets_pkey PRIMARY KEY (key);
+
+
+--
+-- Name: recovery_codes recovery_codes_pkey; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.recovery_codes
+    ADD CONSTRAINT recovery_codes_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: recovery_codes recovery_codes_user_id_code_hash_key; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.recovery_codes
+    ADD CONSTRAINT recovery_codes_user_id_code_hash_key UNIQUE (user_id, code_hash);
+
+
+--
+-- Name: scheduler_runs scheduler_runs_pkey; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.scheduler_runs
+    ADD CONSTRAINT scheduler_runs_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: scheduler_runs scheduler_runs_task_scheduled_for_key; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.scheduler_runs
+    ADD CONSTRAINT scheduler_runs_task_scheduled_for_key UNIQUE (task, scheduled_for);
+
+
+--
+-- Name: screens screens_pkey; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.screens
+    ADD CONSTRAINT screens_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: seats seats_pkey; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.seats
+    ADD CONSTRAINT seats_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: seats seats_screen_id_row_label_seat_number_key; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.seats
+    ADD CONSTRAINT seats_screen_id_row_label_seat_number_key UNIQUE (screen_id, row_label, seat_number);
+
+
+--
+-- Name: sessions sessions_pkey; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.sessions
+    ADD CONSTRAINT sessions_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: sessions sessions_token_hash_key; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.sessions
+    ADD CONSTRAINT sessions_token_hash_key UNIQUE (token_hash);
+
+
+--
+-- Name: showtimes showtimes_no_overlap; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.showtimes
+    ADD CONSTRAINT showtimes_no_overlap EXCLUDE USING gist (screen_id WITH OPERATOR(pg_catalog.=), tstzrange(starts_at, ends_at) WITH OPERATOR(pg_catalog.&&));
+
+
+--
+-- Name: showtimes showtimes_pkey; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.showtimes
+    ADD CONSTRAINT showtimes_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: theaters theaters_pkey; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.theaters
+    ADD CONSTRAINT theaters_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: user_identities user_identities_pkey; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.user_identities
+    ADD CONSTRAINT user_identities_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: user_identities user_identities_provider_subject_key; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.user_identities
+    ADD CONSTRAINT user_identities_provider_subject_key UNIQUE (provider, subject);
+
+
+--
+-- Name: user_totps user_totps_pkey; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.user_totps
+    ADD CONSTRAINT user_totps_pkey PRIMARY KEY (user_id);
+
+
+--
+-- Name: users users_pkey; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.users
+    ADD CONSTRAINT users_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: audit_logs_actor_type_actor_id_idx; Type: INDEX; Schema: public; Owner: postgres
+--
+
+CREATE INDEX audit_logs_actor_type_actor_id_idx ON public.audit_logs USING btree (actor_type, actor_id);
+
+
+--
+-- Name: audit_logs_created_at_idx; Type: INDEX; Schema: public; Owner: postgres
+--
+
+CREATE INDEX audit_logs_created_at_idx ON public.audit_logs USING btree (created_at);
+
+
+--
+-- Name: booking_seats_active_seat_idx; Type: INDEX; Schema: public; Owner: postgres
+--
+
+CREATE UNIQUE INDEX booking_seats_active_seat_idx ON public.booking_seats USING btree (showtime_id, seat_id) WHERE (NOT released);
+
+
+--
+-- Name: bookings_user_id_idx; Type: INDEX; Schema: public; Owner: postgres
+--
+
+CREATE INDEX bookings_user_id_idx ON public.bookings USING btree (user_id);
+
+
+--
+-- Name: email_verifications_user_id_created_at_idx; Type: INDEX; Schema: public; Owner: postgres
+--
+
+CREATE INDEX email_verifications_user_id_created_at_idx ON public.email_verifications USING btree (user_id, created_at);
+
+
+--
+-- Name: invite_redemptions_invite_id_idx; Type: INDEX; Schema: public; Owner: postgres
+--
+
+CREATE INDEX invite_redemptions_invite_id_idx ON public.invite_redemptions USING btree (invite_id);
+
+
+--
+-- Name: jobs_status_run_at_idx; Type: INDEX; Schema: public; Owner: postgres
+--
+
+CREATE INDEX jobs_status_run_at_idx ON public.jobs USING btree (status, run_at);
+
+
+--
+-- Name: login_attempts_created_at_idx; Type: INDEX; Schema: public; Owner: postgres
+--
+
+CREATE INDEX login_attempts_created_at_idx ON public.login_attempts USING btree (created_at);
+
+
+--
+-- Name: login_attempts_lower_email_created_at_idx; Type: INDEX; Schema: public; Owner: postgres
+--
+
+CREATE INDEX login_attempts_lower_email_created_at_idx ON public.login_attempts USING btree (lower((email)::text), created_at);
+
+
+--
+-- Name: rate_limit_buckets_expires_at_idx; Type: INDEX; Schema: public; Owner: postgres
+--
+
+CREATE INDEX rate_limit_buckets_expires_at_idx ON public.rate_limit_buckets USING btree (expires_at);
+
+
+--
+-- Name: scheduler_runs_started_at_idx; Type: INDEX; Schema: public; Owner: postgres
+--
+
+CREATE INDEX scheduler_runs_started_at_idx ON public.scheduler_runs USING btree (started_at);
+
+
+--
+-- Name: sessions_family_id_idx; Type: INDEX; Schema: public; Owner: postgres
+--
+
+CREATE INDEX sessions_family_id_idx ON public.sessions USING btree (family_id);
+
+
+--
+-- Name: sessions_user_id_idx; Type: INDEX; Schema: public; Owner: postgres
+--
+
+CREATE INDEX sessions_user_id_idx ON public.sessions USING btree (user_id);
+
+
+--
+-- Name: oidc_states_expires_at_idx; Type: INDEX; Schema: public; Owner: postgres
+--
+
+CREATE INDEX oidc_states_expires_at_idx ON public.oidc_states USING btree (expires_at);
+
+
+--
+-- Name: showtimes_movie_id_starts_at_idx; Type: INDEX; Schema: public; Owner: postgres
+--
+
+CREATE INDEX showtimes_movie_id_starts_at_idx ON public.showtimes USING btree (movie_id, starts_at);
+
+
+--
+-- Name: user_identities_user_id_idx; Type: INDEX; Schema: public; Owner: postgres
+--
+
+CREATE INDEX user_identities_user_id_idx ON public.user_identities USING btree (user_id);
+
+
+--
+-- Name: users_deletion_scheduled_at_idx; Type: INDEX; Schema: public; Owner: postgres
+--
+
+CREATE INDEX users_deletion_scheduled_at_idx ON public.users USING btree (deletion_scheduled_at) WHERE (deletion_scheduled_at IS NOT NULL);
+
+
+--
+-- Name: users_email_lower_idx; Type: INDEX; Schema: public; Owner: postgres
+--
+
+CREATE UNIQUE INDEX users_email_lower_idx ON public.users USING btree (lower((email)::text));
+
+
+--
+-- Name: api_keys api_keys_created_by_fkey; Type: FK CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.api_keys
+    ADD CONSTRAINT api_keys_created_by_fkey FOREIGN KEY (created_by) REFERENCES public.users(id) ON DELETE SET NULL;
+
+
+--
+-- Name: booking_seats booking_seats_booking_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.booking_seats
+    ADD CONSTRAINT booking_seats_booking_id_fkey FOREIGN KEY (booking_id) REFERENCES public.bookings(id) ON DELETE CASCADE;
+
+
+--
+-- Name: booking_seats booking_seats_seat_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.booking_seats
+    ADD CONSTRAINT booking_seats_seat_id_fkey FOREIGN KEY (seat_id) REFERENCES public.seats(id);
+
+
+--
+-- Name: booking_seats booking_seats_showtime_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.booking_seats
+    ADD CONSTRAINT booking_seats_showtime_id_fkey FOREIGN KEY (showtime_id) REFERENCES public.showtimes(id) ON DELETE RESTRICT;
+
+
+--
+-- Name: bookings bookings_showtime_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.bookings
+    ADD CONSTRAINT bookings_showtime_id_fkey FOREIGN KEY (showtime_id) REFERENCES public.showtimes(id) ON DELETE RESTRICT;
+
+
+--
+-- Name: bookings bookings_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.bookings
+    ADD CONSTRAINT bookings_user_id_fkey FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE;
+
+
+--
+-- Name: email_verifications email_verifications_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.email_verifications
+    ADD CONSTRAINT email_verifications_user_id_fkey FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE;
+
+
+--
+-- Name: login_attempts login_attempts_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.login_attempts
+    ADD CONSTRAINT login_attempts_user_id_fkey FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE SET NULL;
+
+
+--
+-- Name: movie_image_variants movie_image_variants_image_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.movie_image_variants
+    ADD CONSTRAINT movie_image_variants_image_id_fkey FOREIGN KEY (image_id) REFERENCES public.movie_images(id) ON DELETE CASCADE;
+
+
+--
+-- Name: movie_images movie_images_movie_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.movie_images
+    ADD CONSTRAINT movie_images_movie_id_fkey FOREIGN KEY (movie_id) REFERENCES public.movies(id) ON DELETE CASCADE;
+
+
+--
+-- Name: movies_genres movies_genres_genre_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.movies_genres
+    ADD CONSTRAINT movies_genres_genre_id_fkey FOREIGN KEY (genre_id) REFERENCES public.genres(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: movies_genres movies_genres_movie_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.movies_genres
+    ADD CONSTRAINT movies_genres_movie_id_fkey FOREIGN KEY (movie_id) REFERENCES public.movies(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: screens screens_theater_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.screens
+    ADD CONSTRAINT screens_theater_id_fkey FOREIGN KEY (theater_id) REFERENCES public.theaters(id) ON DELETE CASCADE;
+
+
+--
+-- Name: seats seats_screen_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.seats
+    ADD CONSTRAINT seats_screen_id_fkey FOREIGN KEY (screen_id) REFERENCES public.screens(id) ON DELETE CASCADE;
+
+
+--
+-- Name: password_resets password_resets_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.password_resets
+    ADD CONSTRAINT password_resets_user_id_fkey FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE;
+
+
+--
+-- Name: sessions sessions_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.sessions
+    ADD CONSTRAINT sessions_user_id_fkey FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE;
+
+
+--
+-- Name: showtimes showtimes_movie_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.showtimes
+    ADD CONSTRAINT showtimes_movie_id_fkey FOREIGN KEY (movie_id) REFERENCES public.movies(id) ON DELETE CASCADE;
+
+
+--
+-- Name: showtimes showtimes_screen_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.showtimes
+    ADD CONSTRAINT showtimes_screen_id_fkey FOREIGN KEY (screen_id) REFERENCES public.screens(id);
+
+
+--
+-- Name: recovery_codes recovery_codes_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.recovery_codes
+    ADD CONSTRAINT recovery_codes_user_id_fkey FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE;
+
+
+--
+-- Name: user_totps user_totps_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.user_totps
+    ADD CONSTRAINT user_totps_user_id_fkey FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE;
+
+
+--
+-- Name: user_identities user_identities_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.user_identities
+    ADD CONSTRAINT user_identities_user_id_fkey FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE;
+
+
+--
+-- Name: invite_redemptions invite_redemptions_invite_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.invite_redemptions
+    ADD CONSTRAINT invite_redemptions_invite_id_fkey FOREIGN KEY (invite_id) REFERENCES public.invites(id) ON DELETE CASCADE;
+
+
+--
+-- Name: invite_redemptions invite_redemptions_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.invite_redemptions
+    ADD CONSTRAINT invite_redemptions_user_id_fkey FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE;
+
+
+--
+-- Name: invites invites_created_by_fkey; Type: FK CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.invites
+    ADD CONSTRAINT invites_created_by_fkey FOREIGN KEY (created_by) REFERENCES public.users(id) ON DELETE SET NULL;
 
 
 --
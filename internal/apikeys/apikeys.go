@@ -0,0 +1,176 @@
+// Package apikeys ออกและตรวจ API key สำหรับ client ที่ไม่ใช่คน
+// key จริงแสดงครั้งเดียวตอนสร้าง ใน database เก็บเฉพาะ SHA-256 ของ key
+package apikeys
+
+import (
+	"errors"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/NakarinFIgo/Movies-App/internal/repository"
+	"github.com/NakarinFIgo/Movies-App/pkg/middlewares"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// keyPrefix นำหน้า key ทุกตัว ทำให้ค้นเจอได้ง่ายถ้า key หลุดไปอยู่ใน log หรือ repository
+const keyPrefix = "mk_"
+
+// prefixLength คือความยาวของส่วนต้นของ key ที่เก็บไว้แสดงในรายการ
+const prefixLength = 12
+
+// touchInterval คือระยะห่างขั้นต่ำของการบันทึกเวลาที่ใช้ key ล่าสุด
+const touchInterval = time.Minute
+
+var (
+	ErrInvalidAPIKey = errors.New("invalid api key")
+	ErrAPIKeyExpired = errors.New("api key has expired")
+	ErrAPIKeyRevoked = errors.New("api key has been revoked")
+)
+
+// Clock คืนเวลาปัจจุบัน แยกออกมาเพื่อให้ test ควบคุมเวลาได้
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// Generate สุ่ม key ใหม่ คืน key ที่ให้ผู้ใช้, ส่วนต้นที่แสดงได้ และ hash ที่เก็บใน database
+func Generate() (key, prefix, hash string, err error) {
+	token, err := middlewares.GenerateOpaqueToken()
+	if err != nil {
+		return "", "", "", err
+	}
+
+	key = keyPrefix + token
+	return key, key[:prefixLength], middlewares.HashToken(key), nil
+}
+
+// Service ตรวจ API key และจำกัดจำนวน request ต่อนาทีของแต่ละ key
+// ใช้เป็น middlewares.APIKeyAuthenticator ได้
+type Service struct {
+	DB    repository.DatabaseRepo
+	Clock Clock
+
+	mu      sync.Mutex
+	buckets map[int64]*bucket
+	touched map[int64]time.Time
+}
+
+// New สร้าง Service ที่ใช้เวลาจริง
+func New(db repository.DatabaseRepo) *Service {
+	return &Service{DB: db, Clock: systemClock{}}
+}
+
+// AuthenticateAPIKey ตรวจว่า key ถูกต้อง ยังไม่หมดอายุหรือถูก revoke และยังไม่เกิน rate limit
+func (s *Service) AuthenticateAPIKey(key string) (*middlewares.Claims, error) {
+	apiKey, err := s.DB.APIKeyByHash(middlewares.HashToken(key))
+	if err != nil {
+		if errors.Is(err, repository.ErrAPIKeyNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+
+	now := s.Clock.Now()
+
+	if apiKey.RevokedAt != nil {
+		return nil, ErrAPIKeyRevoked
+	}
+	if apiKey.ExpiresAt != nil && !now.Before(*apiKey.ExpiresAt) {
+		return nil, ErrAPIKeyExpired
+	}
+
+	if wait, ok := s.allow(apiKey.ID, apiKey.RateLimit, now); !ok {
+		return nil, &middlewares.RateLimitError{RetryAfter: wait}
+	}
+
+	s.touch(apiKey.ID, now)
+
+	claims := &middlewares.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID: strconv.FormatInt(apiKey.ID, 10),
+		},
+		TokenType:   middlewares.TokenTypeAPIKey,
+		Name:        apiKey.Name,
+		Permissions: apiKey.Permissions,
+	}
+	if apiKey.ExpiresAt != nil {
+		claims.ExpiresAt = jwt.NewNumericDate(*apiKey.ExpiresAt)
+	}
+
+	return claims, nil
+}
+
+// touch บันทึกเวลาที่ใช้ key ล่าสุด ไม่เกินนาทีละครั้งต่อ key
+func (s *Service) touch(id int64, now time.Time) {
+	s.mu.Lock()
+	if s.touched == nil {
+		s.touched = make(map[int64]time.Time)
+	}
+	last, ok := s.touched[id]
+	if ok && now.Sub(last) < touchInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.touched[id] = now
+	s.mu.Unlock()
+
+	if err := s.DB.TouchAPIKey(id, now); err != nil {
+		log.Printf("api keys: failed to record use of key %d: %v", id, err)
+	}
+}
+
+// bucket คือ token bucket ของ key หนึ่ง เติม limit token ต่อนาที จุได้ไม่เกิน limit
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// allow หัก token หนึ่งตัวจาก bucket ของ key ถ้าไม่พอจะคืนเวลาที่ต้องรอ limit ที่ไม่เกิน 0 คือไม่จำกัด
+func (s *Service) allow(id int64, limit int, now time.Time) (time.Duration, bool) {
+	if limit <= 0 {
+		return 0, true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.buckets == nil {
+		s.buckets = make(map[int64]*bucket)
+	}
+
+	capacity := float64(limit)
+	perSecond := capacity / time.Minute.Seconds()
+
+	b, ok := s.buckets[id]
+	if !ok {
+		b = &bucket{tokens: capacity, last: now}
+		s.buckets[id] = b
+	}
+
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = min(capacity, b.tokens+elapsed*perSecond)
+		b.last = now
+	}
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / perSecond * float64(time.Second))
+		return wait, false
+	}
+
+	b.tokens--
+	return 0, true
+}
+
+// Forget ล้าง rate limit และเวลาที่ใช้ล่าสุดของ key ที่ถูก revoke ออกจากหน่วยความจำ
+func (s *Service) Forget(id int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.buckets, id)
+	delete(s.touched, id)
+}
@@ -0,0 +1,47 @@
+// Package credentials ตรวจและจัดรูปแบบอีเมลและรหัสผ่านของผู้ใช้ก่อนบันทึก
+package credentials
+
+import (
+	"errors"
+	"net/mail"
+	"strings"
+)
+
+// maxEmailLength คือความยาวของคอลัมน์ users.email
+const maxEmailLength = 255
+
+var (
+	ErrEmailRequired = errors.New("email is required")
+	ErrEmailInvalid  = errors.New("email address is not valid")
+)
+
+// NormalizeEmail ตัดช่องว่างและเปลี่ยนอีเมลเป็นตัวพิมพ์เล็ก คืน error ถ้าไม่ใช่อีเมลเดี่ยวที่ถูกรูปแบบ
+// เช่นมีชื่อแสดงผล "John <john@example.com>" หรือโดเมนไม่มีจุด
+func NormalizeEmail(email string) (string, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return "", ErrEmailRequired
+	}
+	if len(email) > maxEmailLength {
+		return "", ErrEmailInvalid
+	}
+
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email || addr.Name != "" {
+		return "", ErrEmailInvalid
+	}
+
+	at := strings.LastIndex(email, "@")
+	domain := email[at+1:]
+	if at < 1 || !strings.Contains(domain, ".") || strings.HasPrefix(domain, ".") || strings.HasSuffix(domain, ".") {
+		return "", ErrEmailInvalid
+	}
+
+	return email, nil
+}
+
+// LookupEmail จัดรูปแบบอีเมลที่ใช้ค้นหาบัญชี เช่นตอน login โดยไม่ตรวจรูปแบบ
+// บัญชีเก่าที่อีเมลไม่ผ่าน NormalizeEmail จึงยัง login ได้
+func LookupEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
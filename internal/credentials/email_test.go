@@ -0,0 +1,65 @@
+package credentials
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeEmail(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr error
+	}{
+		{in: "alice@example.com", want: "alice@example.com"},
+		{in: "  Alice@Example.COM ", want: "alice@example.com"},
+		{in: "first.last+tag@mail.example.co.th", want: "first.last+tag@mail.example.co.th"},
+		{in: "", wantErr: ErrEmailRequired},
+		{in: "   ", wantErr: ErrEmailRequired},
+		{in: "alice", wantErr: ErrEmailInvalid},
+		{in: "@example.com", wantErr: ErrEmailInvalid},
+		{in: "alice@", wantErr: ErrEmailInvalid},
+		{in: "alice@localhost", wantErr: ErrEmailInvalid},
+		{in: "alice@.example.com", wantErr: ErrEmailInvalid},
+		{in: "alice@example.com.", wantErr: ErrEmailInvalid},
+		{in: "Alice <alice@example.com>", wantErr: ErrEmailInvalid},
+		{in: "<alice@example.com>", wantErr: ErrEmailInvalid},
+		{in: "alice@example.com, bob@example.com", wantErr: ErrEmailInvalid},
+		{in: "alice smith@example.com", wantErr: ErrEmailInvalid},
+		// ยาวได้ไม่เกิน maxEmailLength ตามคอลัมน์ users.email
+		{in: strings.Repeat("a", 243) + "@example.com", want: strings.Repeat("a", 243) + "@example.com"},
+		{in: strings.Repeat("a", 244) + "@example.com", wantErr: ErrEmailInvalid},
+	}
+
+	for _, tt := range tests {
+		got, err := NormalizeEmail(tt.in)
+		if tt.wantErr != nil {
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("NormalizeEmail(%q) err = %v, want %v", tt.in, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("NormalizeEmail(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestLookupEmail(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"  Alice@Example.COM ", "alice@example.com"},
+		// ไม่ตรวจรูปแบบ บัญชีเก่าที่อีเมลไม่ผ่าน NormalizeEmail จึงยังค้นหาได้
+		{"Legacy@LocalHost", "legacy@localhost"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := LookupEmail(tt.in); got != tt.want {
+			t.Errorf("LookupEmail(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
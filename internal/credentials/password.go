@@ -0,0 +1,169 @@
+package credentials
+
+import (
+	"bufio"
+	"crypto/sha1"
+	_ "embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// bcrypt ใช้แค่ 72 byte แรกของรหัสผ่าน รหัสผ่านที่ยาวกว่านี้จึงไม่ได้ปลอดภัยขึ้น
+const bcryptMaxLength = 72
+
+// minEmailPartLength คือความยาวขั้นต่ำของชื่อหน้า @ ที่ห้ามอยู่ในรหัสผ่าน ชื่อที่สั้นกว่านี้เจอในคำทั่วไปได้ง่าย
+const minEmailPartLength = 4
+
+var (
+	ErrPasswordRequired  = errors.New("password is required")
+	ErrPasswordBreached  = errors.New("password is too common or has appeared in a data breach")
+	ErrPasswordHasEmail  = errors.New("password must not contain your email address")
+	ErrInvalidBcryptCost = fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+)
+
+//go:embed common.txt
+var commonPasswords string
+
+// Policy คือเกณฑ์ของรหัสผ่านและ cost ของ bcrypt ที่ใช้ hash
+type Policy struct {
+	MinLength int
+	// MaxLength ต้องไม่เกิน 72 ซึ่งเป็นความยาวสูงสุดที่ bcrypt ใช้
+	MaxLength int
+	// Cost คือ bcrypt cost hash เดิมที่ cost ไม่ตรงจะถูก hash ใหม่ตอน login
+	Cost int
+	// Breached คือรายการรหัสผ่านที่ห้ามใช้ เป็น nil ได้ถ้าไม่ตรวจ
+	Breached BreachedList
+}
+
+// DefaultPolicy คือค่าเริ่มต้นเมื่อไม่ได้ตั้งค่าใน .env
+var DefaultPolicy = Policy{
+	MinLength: 8,
+	MaxLength: bcryptMaxLength,
+	Cost:      bcrypt.DefaultCost,
+	Breached:  DefaultBreachedList(),
+}
+
+// Validate ตรวจว่าค่าของ Policy ใช้งานได้
+func (p Policy) Validate() error {
+	if p.Cost < bcrypt.MinCost || p.Cost > bcrypt.MaxCost {
+		return ErrInvalidBcryptCost
+	}
+	if p.MinLength < 1 || p.MaxLength < p.MinLength || p.MaxLength > bcryptMaxLength {
+		return fmt.Errorf("password length must be between 1 and %d with min <= max", bcryptMaxLength)
+	}
+	return nil
+}
+
+// Check ตรวจรหัสผ่านตามเกณฑ์ email คืออีเมลของบัญชี ใช้ห้ามรหัสผ่านที่มีอีเมลอยู่ข้างใน
+func (p Policy) Check(password, email string) error {
+	if password == "" {
+		return ErrPasswordRequired
+	}
+	if n := len([]rune(password)); n < p.MinLength {
+		return fmt.Errorf("password must be at least %d characters", p.MinLength)
+	}
+	if len(password) > p.MaxLength {
+		return fmt.Errorf("password must be at most %d bytes", p.MaxLength)
+	}
+
+	lower := strings.ToLower(password)
+	if email = LookupEmail(email); email != "" {
+		local, _, _ := strings.Cut(email, "@")
+		if strings.Contains(lower, email) || (len(local) >= minEmailPartLength && strings.Contains(lower, local)) {
+			return ErrPasswordHasEmail
+		}
+	}
+
+	if p.Breached.Contains(password) {
+		return ErrPasswordBreached
+	}
+	return nil
+}
+
+// Hash hash รหัสผ่านด้วย bcrypt ตาม Cost
+func (p Policy) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), p.Cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// NeedsRehash บอกว่า hash ถูกสร้างด้วย cost อื่นและควร hash ใหม่หลังผู้ใช้ login สำเร็จ
+func (p Policy) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err == nil && cost != p.Cost
+}
+
+// BreachedList คือชุดรหัสผ่านที่ห้ามใช้ เก็บเป็นตัวพิมพ์เล็กหรือเป็น SHA-1 hex
+type BreachedList map[string]struct{}
+
+// Contains บอกว่ารหัสผ่านอยู่ในรายการหรือไม่ ตรวจทั้งตัวรหัสผ่านและ SHA-1 ของรหัสผ่าน
+func (l BreachedList) Contains(password string) bool {
+	if len(l) == 0 {
+		return false
+	}
+	if _, ok := l[strings.ToLower(password)]; ok {
+		return true
+	}
+
+	sum := sha1.Sum([]byte(password))
+	_, ok := l[hex.EncodeToString(sum[:])]
+	return ok
+}
+
+// DefaultBreachedList คือรายการรหัสผ่านที่พบบ่อยที่ฝังมากับโปรแกรม
+func DefaultBreachedList() BreachedList {
+	list := make(BreachedList)
+	if err := list.read(strings.NewReader(commonPasswords)); err != nil {
+		panic(err)
+	}
+	return list
+}
+
+// LoadBreachedList อ่านรายการรหัสผ่านจากไฟล์ บรรทัดละหนึ่งรหัสผ่าน หรือ SHA-1 hex แบบไฟล์ของ
+// Have I Been Pwned ("HASH:COUNT") บรรทัดว่างและบรรทัดที่ขึ้นต้นด้วย # จะถูกข้าม
+// รายการที่อ่านได้จะรวมกับ DefaultBreachedList
+func LoadBreachedList(path string) (BreachedList, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	list := DefaultBreachedList()
+	if err := list.read(f); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return list, nil
+}
+
+func (l BreachedList) read(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if hash, _, ok := strings.Cut(line, ":"); ok && isSHA1(hash) {
+			line = hash
+		}
+		l[strings.ToLower(line)] = struct{}{}
+	}
+	return scanner.Err()
+}
+
+func isSHA1(s string) bool {
+	if len(s) != sha1.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
@@ -0,0 +1,229 @@
+package credentials
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func sha1Hex(s string) string {
+	sum := sha1.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func testPolicy() Policy {
+	return Policy{
+		MinLength: 8,
+		MaxLength: 16,
+		Cost:      bcrypt.MinCost,
+		Breached: BreachedList{
+			"letmein123":           {},
+			sha1Hex("Tr0ub4dor&3"): {},
+		},
+	}
+}
+
+func TestPolicyCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		email    string
+		wantErr  error
+		wantMsg  string
+	}{
+		{name: "valid", password: "correct horse", email: "alice@example.com"},
+		{name: "empty", password: "", wantErr: ErrPasswordRequired},
+		{name: "too short", password: "short12", wantMsg: "password must be at least 8 characters"},
+		{name: "exactly min length", password: "abcdefgh"},
+		// ความยาวขั้นต่ำนับเป็นตัวอักษร ส่วนความยาวสูงสุดนับเป็น byte ตามที่ bcrypt ใช้
+		{name: "seven runes of multibyte text", password: "ééééééé", wantMsg: "password must be at least 8 characters"},
+		{name: "eight runes of multibyte text", password: "éééééééé"},
+		{name: "exactly max length", password: strings.Repeat("a", 16)},
+		{name: "too long", password: strings.Repeat("a", 17), wantMsg: "password must be at most 16 bytes"},
+		{name: "max length counts bytes", password: "abcdefghijklmnoé", wantMsg: "password must be at most 16 bytes"},
+		// ชื่อหน้า @ สั้นกว่า minEmailPartLength แต่อีเมลทั้งอันยังห้ามใช้
+		{name: "contains the whole email", password: "1AL@EX.IO2", email: "al@ex.io", wantErr: ErrPasswordHasEmail},
+		{name: "contains the local part", password: "my-alice-pw", email: "alice@example.com", wantErr: ErrPasswordHasEmail},
+		{name: "local part check ignores case", password: "MY-ALICE-PW", email: " Alice@Example.com ", wantErr: ErrPasswordHasEmail},
+		{name: "short local part is allowed", password: "bobcat-runs", email: "bob@example.com"},
+		{name: "local part of four characters", password: "annas-garden", email: "anna@example.com", wantErr: ErrPasswordHasEmail},
+		{name: "no email skips the rule", password: "my-alice-pw"},
+		{name: "breached plain text ignores case", password: "LetMeIn123", wantErr: ErrPasswordBreached},
+		{name: "breached sha1 is case sensitive", password: "Tr0ub4dor&3", wantErr: ErrPasswordBreached},
+		{name: "sha1 of another case is not breached", password: "tr0ub4dor&3"},
+	}
+
+	p := testPolicy()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := p.Check(tt.password, tt.email)
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Check(%q) = %v, want %v", tt.password, err, tt.wantErr)
+				}
+			case tt.wantMsg != "":
+				if err == nil || err.Error() != tt.wantMsg {
+					t.Errorf("Check(%q) = %v, want %q", tt.password, err, tt.wantMsg)
+				}
+			case err != nil:
+				t.Errorf("Check(%q) = %v, want nil", tt.password, err)
+			}
+		})
+	}
+}
+
+func TestPolicyCheckWithoutBreachedList(t *testing.T) {
+	p := testPolicy()
+	p.Breached = nil
+
+	if err := p.Check("letmein123", ""); err != nil {
+		t.Errorf("Check with no breached list = %v, want nil", err)
+	}
+}
+
+func TestPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(p *Policy)
+		wantErr bool
+	}{
+		{name: "default", modify: func(p *Policy) { *p = DefaultPolicy }},
+		{name: "test policy", modify: func(p *Policy) {}},
+		{name: "cost too low", modify: func(p *Policy) { p.Cost = bcrypt.MinCost - 1 }, wantErr: true},
+		{name: "cost too high", modify: func(p *Policy) { p.Cost = bcrypt.MaxCost + 1 }, wantErr: true},
+		{name: "zero min length", modify: func(p *Policy) { p.MinLength = 0 }, wantErr: true},
+		{name: "max below min", modify: func(p *Policy) { p.MaxLength = p.MinLength - 1 }, wantErr: true},
+		{name: "max beyond bcrypt", modify: func(p *Policy) { p.MaxLength = bcryptMaxLength + 1 }, wantErr: true},
+		{name: "max at bcrypt limit", modify: func(p *Policy) { p.MaxLength = bcryptMaxLength }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := testPolicy()
+			tt.modify(&p)
+			if err := p.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPolicyNeedsRehash(t *testing.T) {
+	hashAt := func(cost int) string {
+		hash, err := bcrypt.GenerateFromPassword([]byte("correct horse"), cost)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(hash)
+	}
+
+	p := testPolicy()
+	tests := []struct {
+		name string
+		hash string
+		want bool
+	}{
+		{name: "same cost", hash: hashAt(bcrypt.MinCost), want: false},
+		{name: "different cost", hash: hashAt(bcrypt.MinCost + 1), want: true},
+		{name: "not a bcrypt hash", hash: "plain-text", want: false},
+		{name: "empty", hash: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.NeedsRehash(tt.hash); got != tt.want {
+				t.Errorf("NeedsRehash(%q) = %v, want %v", tt.hash, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicyHash(t *testing.T) {
+	p := testPolicy()
+
+	hash, err := p.Hash("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte("correct horse")); err != nil {
+		t.Errorf("hash does not match the password: %v", err)
+	}
+	if p.NeedsRehash(hash) {
+		t.Error("a fresh hash needs a rehash")
+	}
+}
+
+func TestDefaultBreachedList(t *testing.T) {
+	list := DefaultBreachedList()
+
+	for _, password := range []string{"password", "PASSWORD", "qwerty", "123456789"} {
+		if !list.Contains(password) {
+			t.Errorf("default list does not contain %q", password)
+		}
+	}
+	for _, line := range []string{"", "#"} {
+		if _, ok := list[line]; ok {
+			t.Errorf("default list contains the comment or blank line %q", line)
+		}
+	}
+	if list.Contains("correct horse battery staple") {
+		t.Error("default list contains a strong passphrase")
+	}
+}
+
+func TestLoadBreachedList(t *testing.T) {
+	content := strings.Join([]string{
+		"# comment",
+		"",
+		"  Hunter2-Extra  ",
+		strings.ToUpper(sha1Hex("S3cret!pass")) + ":42",
+		sha1Hex("another-one"),
+		"not:a-hash",
+	}, "\n")
+
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := LoadBreachedList(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		password string
+		want     bool
+	}{
+		{"hunter2-extra", true},
+		{"HUNTER2-EXTRA", true},
+		// บรรทัดแบบ Have I Been Pwned ("HASH:COUNT") ตัวพิมพ์ใหญ่ ต้องตรงกับ SHA-1 ของรหัสผ่าน
+		{"S3cret!pass", true},
+		{"s3cret!pass", false},
+		{"another-one", true},
+		// บรรทัดที่มี ":" แต่ไม่ใช่ hash เก็บทั้งบรรทัด
+		{"not:a-hash", true},
+		{"not", false},
+		// รายการจากไฟล์รวมกับรายการที่ฝังมา
+		{"password", true},
+		{"# comment", false},
+	}
+	for _, tt := range tests {
+		if got := list.Contains(tt.password); got != tt.want {
+			t.Errorf("Contains(%q) = %v, want %v", tt.password, got, tt.want)
+		}
+	}
+}
+
+func TestLoadBreachedListMissingFile(t *testing.T) {
+	if _, err := LoadBreachedList(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("LoadBreachedList of a missing file returned no error")
+	}
+}
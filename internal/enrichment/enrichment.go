@@ -0,0 +1,330 @@
+package enrichment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/NakarinFIgo/Movies-App/internal/entities"
+	"github.com/NakarinFIgo/Movies-App/internal/jobs"
+	"github.com/NakarinFIgo/Movies-App/internal/repository"
+	"github.com/NakarinFIgo/Movies-App/pkg/metadata"
+	"gorm.io/gorm"
+)
+
+var ErrNoProvider = errors.New("no TMDB metadata provider is configured")
+
+// Enricher เติมข้อมูลหนังจาก metadata provider ลงในหนังของเรา
+// ตอนนี้รองรับเฉพาะ TMDB เพราะ ID ที่เก็บไว้ในหนังคือ tmdb_id
+type Enricher struct {
+	DB       repository.DatabaseRepo
+	Provider metadata.Provider
+}
+
+// Proposal คือค่าที่จะบันทึกลงหนัง ใช้แสดงใน preview ก่อนบันทึกจริง
+type Proposal struct {
+	Movie *entities.Movie `json:"movie"`
+	// Existing เป็น true ถ้ามีหนังที่ผูกกับ tmdb_id นี้อยู่แล้ว การบันทึกจะเป็นการ re-sync
+	Existing       bool             `json:"existing"`
+	UnmappedGenres []metadata.Genre `json:"unmapped_genres,omitempty"`
+}
+
+func (e *Enricher) provider() (metadata.Provider, error) {
+	if e.Provider == nil || e.Provider.Name() != "tmdb" {
+		return nil, ErrNoProvider
+	}
+	return e.Provider, nil
+}
+
+// Propose ดึงข้อมูลเต็มของหนังจาก TMDB แล้วสร้างค่าที่จะบันทึก
+// ถ้า target ไม่เป็น nil จะเติมข้อมูลลงหนังเรื่องนั้น ไม่เช่นนั้นจะหาหนังที่มี tmdb_id เดียวกัน หรือสร้างเรื่องใหม่
+func (e *Enricher) Propose(ctx context.Context, tmdbID int, target *entities.Movie) (*Proposal, error) {
+	provider, err := e.provider()
+	if err != nil {
+		return nil, err
+	}
+
+	details, err := provider.MovieDetails(ctx, strconv.Itoa(tmdbID))
+	if err != nil {
+		return nil, err
+	}
+
+	proposal := &Proposal{}
+
+	movie := target
+	if movie == nil {
+		movie, err = e.DB.MovieByTMDBID(tmdbID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		if movie == nil {
+			movie = &entities.Movie{}
+		}
+	}
+	proposal.Existing = movie.ID != 0
+
+	Apply(movie, details)
+	movie.TMDBID = &tmdbID
+
+	var tmdbGenreIDs []int
+	for _, g := range details.Genres {
+		tmdbGenreIDs = append(tmdbGenreIDs, g.ID)
+	}
+
+	genres, err := e.DB.GenresByTMDBIDs(tmdbGenreIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	mapped := make(map[int]bool)
+	movie.Genres = genres
+	movie.GenresArray = nil
+	for _, g := range genres {
+		movie.GenresArray = append(movie.GenresArray, g.ID)
+		if g.TMDBID != nil {
+			mapped[*g.TMDBID] = true
+		}
+	}
+	for _, g := range details.Genres {
+		if !mapped[g.ID] {
+			proposal.UnmappedGenres = append(proposal.UnmappedGenres, g)
+		}
+	}
+
+	proposal.Movie = movie
+
+	return proposal, nil
+}
+
+// Save บันทึก proposal คืน true ถ้าเป็นการสร้างหนังเรื่องใหม่
+func (e *Enricher) Save(proposal *Proposal) (bool, error) {
+	movie := proposal.Movie
+	movie.UpdatedAt = time.Now()
+
+	created := movie.ID == 0
+	if created {
+		movie.CreatedAt = movie.UpdatedAt
+
+		// genre บันทึกผ่าน UpdateMovieGenres ด้านล่าง จึงไม่ส่งไปกับ InsertMovie
+		insert := *movie
+		insert.Genres = nil
+
+		newID, err := e.DB.InsertMovie(insert)
+		if err != nil {
+			return false, err
+		}
+		movie.ID = newID
+	} else {
+		if err := e.DB.UpdateMovie(*movie); err != nil {
+			return false, err
+		}
+	}
+
+	if err := e.DB.UpdateMovieGenres(movie.ID, movie.GenresArray); err != nil {
+		return false, err
+	}
+
+	return created, nil
+}
+
+// Apply คัดลอกข้อมูลจาก provider ลงหนัง ค่าว่างจาก provider จะไม่ทับค่าเดิม
+func Apply(movie *entities.Movie, details *metadata.MovieDetails) {
+	if details.Title != "" {
+		movie.Title = details.Title
+	}
+	if details.Overview != "" {
+		movie.Description = details.Overview
+	}
+	if releaseDate, err := time.Parse("2006-01-02", details.ReleaseDate); err == nil {
+		movie.ReleaseDate = releaseDate
+	}
+	if details.Runtime > 0 {
+		movie.RunTime = details.Runtime
+	}
+	if details.Certification != "" {
+		movie.MPAARating = details.Certification
+	}
+	if details.PosterPath != "" {
+		movie.Image = details.PosterPath
+	}
+	if details.BackdropPath != "" {
+		movie.Backdrop = details.BackdropPath
+	}
+}
+
+// FillMissing คัดลอกข้อมูลจาก provider เฉพาะช่องที่หนังยังไม่มีค่า ข้อมูลที่ admin กรอกไว้จึงไม่ถูกทับ
+func FillMissing(movie *entities.Movie, details *metadata.MovieDetails) {
+	if movie.Description == "" {
+		movie.Description = details.Overview
+	}
+	if movie.ReleaseDate.Year() <= 1 {
+		if releaseDate, err := time.Parse("2006-01-02", details.ReleaseDate); err == nil {
+			movie.ReleaseDate = releaseDate
+		}
+	}
+	if movie.RunTime == 0 {
+		movie.RunTime = details.Runtime
+	}
+	if movie.MPAARating == "" {
+		movie.MPAARating = details.Certification
+	}
+	if movie.Image == "" {
+		movie.Image = details.PosterPath
+	}
+	if movie.Backdrop == "" {
+		movie.Backdrop = details.BackdropPath
+	}
+}
+
+// ReleaseYear คืนปีที่ฉายของหนัง หรือ 0 ถ้ายังไม่ได้กรอกวันฉาย
+func ReleaseYear(movie *entities.Movie) int {
+	if movie.ReleaseDate.Year() <= 1 {
+		return 0
+	}
+	return movie.ReleaseDate.Year()
+}
+
+// EnrichMovie เติม poster และ metadata ที่ยังขาดของหนังจาก TMDB
+// ถ้าหนังยังไม่มี tmdb_id จะค้นหาด้วยชื่อและใช้ผลลัพธ์ที่คะแนนถึง metadata.MinAutoMatchScore เท่านั้น
+// ถ้าไม่ได้ตั้งค่า provider หรือหาไม่เจอ จะถือว่าสำเร็จโดยไม่เปลี่ยนแปลงอะไร
+func (e *Enricher) EnrichMovie(ctx context.Context, movieID int) error {
+	provider, err := e.provider()
+	if err != nil {
+		return nil
+	}
+
+	movie, err := e.DB.OneMovie(movieID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return jobs.Permanent(err)
+		}
+		return err
+	}
+
+	linkTMDB := false
+	var tmdbID int
+	if movie.TMDBID != nil {
+		tmdbID = *movie.TMDBID
+	} else {
+		results, err := provider.SearchMovies(ctx, movie.Title)
+		if err != nil {
+			return err
+		}
+
+		candidates := metadata.Rank(movie.Title, ReleaseYear(movie), results)
+		if len(candidates) == 0 || candidates[0].Score < metadata.MinAutoMatchScore {
+			return nil
+		}
+
+		tmdbID, err = strconv.Atoi(candidates[0].ProviderID)
+		if err != nil {
+			return jobs.Permanent(err)
+		}
+
+		// ผูก tmdb_id เฉพาะเมื่อยังไม่มีหนังเรื่องอื่นผูกไว้
+		linked, err := e.DB.MovieByTMDBID(tmdbID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		linkTMDB = linked == nil
+	}
+
+	details, err := provider.MovieDetails(ctx, strconv.Itoa(tmdbID))
+	if err != nil {
+		if errors.Is(err, metadata.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	// การค้นหาและดึงข้อมูลจาก TMDB อาจใช้เวลาหลายวินาที ข้อมูลใน movie อาจเก่าแล้ว
+	// จึงส่งเฉพาะค่าจาก provider ให้ FillMovie ซึ่งเติมเฉพาะคอลัมน์ที่ยังว่างอยู่ตอนบันทึก
+	var fill entities.Movie
+	FillMissing(&fill, details)
+	if linkTMDB {
+		fill.TMDBID = &tmdbID
+	}
+
+	var genreIDs []int
+	if len(details.Genres) > 0 {
+		var tmdbGenreIDs []int
+		for _, g := range details.Genres {
+			tmdbGenreIDs = append(tmdbGenreIDs, g.ID)
+		}
+
+		genres, err := e.DB.GenresByTMDBIDs(tmdbGenreIDs)
+		if err != nil {
+			return err
+		}
+		for _, g := range genres {
+			genreIDs = append(genreIDs, g.ID)
+		}
+	}
+
+	err = e.DB.FillMovie(movie.ID, fill, genreIDs)
+	if errors.Is(err, repository.ErrMovieNotFound) {
+		return jobs.Permanent(err)
+	}
+	return err
+}
+
+// HandleEnrichMovieJob คือ jobs.HandlerFunc ของ jobs.KindEnrichMovie
+func (e *Enricher) HandleEnrichMovieJob(ctx context.Context, job *entities.Job) error {
+	var payload jobs.EnrichMoviePayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return jobs.Permanent(err)
+	}
+
+	return e.EnrichMovie(ctx, payload.MovieID)
+}
+
+// ResyncMovie อัปเดตข้อมูลของหนังที่ผูกกับ TMDB แล้วให้ตรงกับ provider
+// poster และ backdrop ที่มีอยู่จะไม่ถูกทับ เพราะอาจเป็นรูปที่ admin อัปโหลดหรือเลือกไว้เอง
+func (e *Enricher) ResyncMovie(ctx context.Context, movieID int) error {
+	if _, err := e.provider(); err != nil {
+		return nil
+	}
+
+	movie, err := e.DB.OneMovie(movieID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return jobs.Permanent(err)
+		}
+		return err
+	}
+	if movie.TMDBID == nil {
+		return nil
+	}
+
+	image, backdrop := movie.Image, movie.Backdrop
+
+	proposal, err := e.Propose(ctx, *movie.TMDBID, movie)
+	if err != nil {
+		if errors.Is(err, metadata.ErrNotFound) {
+			return jobs.Permanent(err)
+		}
+		return err
+	}
+
+	if image != "" {
+		proposal.Movie.Image = image
+	}
+	if backdrop != "" {
+		proposal.Movie.Backdrop = backdrop
+	}
+
+	_, err = e.Save(proposal)
+	return err
+}
+
+// HandleResyncMovieJob คือ jobs.HandlerFunc ของ jobs.KindResyncMovie
+func (e *Enricher) HandleResyncMovieJob(ctx context.Context, job *entities.Job) error {
+	var payload jobs.EnrichMoviePayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return jobs.Permanent(err)
+	}
+
+	return e.ResyncMovie(ctx, payload.MovieID)
+}
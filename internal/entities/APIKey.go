@@ -0,0 +1,41 @@
+package entities
+
+import "time"
+
+// APIKey คือ key สำหรับ client ที่ไม่ใช่คน เช่นสคริปต์นำเข้าข้อมูล เก็บเฉพาะ hash ของ key
+type APIKey struct {
+	ID   int64  `json:"id" gorm:"primaryKey"`
+	Name string `json:"name"`
+	// Prefix คือส่วนต้นของ key ที่แสดงได้ ใช้บอกว่าเป็น key ไหนโดยไม่ต้องเห็น key ทั้งหมด
+	Prefix  string `json:"prefix"`
+	KeyHash string `json:"-"`
+	// Permissions คือสิทธิ์ของ key ต้องเป็นสิทธิ์ที่ผู้สร้างมีอยู่แล้ว
+	Permissions []string `json:"permissions" gorm:"serializer:json"`
+	// RateLimit คือจำนวน request ต่อนาทีที่ key นี้ใช้ได้
+	RateLimit  int        `json:"rate_limit"`
+	CreatedBy  *int       `json:"created_by,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// ประเภทของผู้กระทำใน AuditLog
+const (
+	AuditActorUser   = "user"
+	AuditActorAPIKey = "api_key"
+)
+
+// AuditLog คือบันทึกการกระทำหนึ่งครั้งของผู้ใช้หรือ API key
+type AuditLog struct {
+	ID        int64     `json:"id" gorm:"primaryKey"`
+	ActorType string    `json:"actor_type"`
+	ActorID   string    `json:"actor_id"`
+	Action    string    `json:"action"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	Status    int       `json:"status"`
+	IPAddress string    `json:"ip_address"`
+	UserAgent string    `json:"user_agent"`
+	CreatedAt time.Time `json:"created_at"`
+}
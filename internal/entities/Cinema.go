@@ -0,0 +1,79 @@
+package entities
+
+import "time"
+
+// สถานะของการจองที่นั่ง
+const (
+	BookingStatusHeld      = "held"
+	BookingStatusConfirmed = "confirmed"
+	BookingStatusCancelled = "cancelled"
+	BookingStatusExpired   = "expired"
+)
+
+type Theater struct {
+	ID        int       `json:"id" gorm:"primaryKey"`
+	Name      string    `json:"name"`
+	Location  string    `json:"location"`
+	Screens   []*Screen `json:"screens,omitempty"`
+	CreatedAt time.Time `json:"-"`
+	UpdatedAt time.Time `json:"-"`
+}
+
+type Screen struct {
+	ID        int       `json:"id" gorm:"primaryKey"`
+	TheaterID int       `json:"theater_id"`
+	Name      string    `json:"name"`
+	Theater   *Theater  `json:"theater,omitempty"`
+	Seats     []*Seat   `json:"seats,omitempty"`
+	CreatedAt time.Time `json:"-"`
+	UpdatedAt time.Time `json:"-"`
+}
+
+type Seat struct {
+	ID         int    `json:"id" gorm:"primaryKey"`
+	ScreenID   int    `json:"screen_id"`
+	RowLabel   string `json:"row"`
+	SeatNumber int    `json:"number"`
+	Kind       string `json:"kind"`
+}
+
+type Showtime struct {
+	ID         int       `json:"id" gorm:"primaryKey"`
+	MovieID    int       `json:"movie_id"`
+	ScreenID   int       `json:"screen_id"`
+	StartsAt   time.Time `json:"starts_at"`
+	EndsAt     time.Time `json:"ends_at"`
+	PriceCents int       `json:"price_cents"`
+	Movie      *Movie    `json:"movie,omitempty"`
+	Screen     *Screen   `json:"screen,omitempty"`
+	CreatedAt  time.Time `json:"-"`
+	UpdatedAt  time.Time `json:"-"`
+}
+
+// SeatAvailability คือที่นั่งหนึ่งที่ในผังที่นั่งของรอบฉาย พร้อมสถานะว่าง/ไม่ว่าง
+type SeatAvailability struct {
+	Seat
+	Available bool `json:"available"`
+}
+
+type Booking struct {
+	ID          int            `json:"id" gorm:"primaryKey"`
+	ShowtimeID  int            `json:"showtime_id"`
+	UserID      int            `json:"user_id"`
+	Status      string         `json:"status"`
+	ExpiresAt   time.Time      `json:"expires_at"`
+	ConfirmedAt *time.Time     `json:"confirmed_at,omitempty"`
+	Seats       []*BookingSeat `json:"seats,omitempty"`
+	Showtime    *Showtime      `json:"showtime,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"-"`
+}
+
+type BookingSeat struct {
+	ID         int   `json:"-" gorm:"primaryKey"`
+	BookingID  int   `json:"-"`
+	ShowtimeID int   `json:"-"`
+	SeatID     int   `json:"seat_id"`
+	Released   bool  `json:"-"`
+	Seat       *Seat `json:"seat,omitempty"`
+}
@@ -0,0 +1,15 @@
+package entities
+
+import "time"
+
+// EmailVerification คือ token ในลิงก์ยืนยันอีเมล เก็บเฉพาะ hash ใช้ได้ครั้งเดียว
+// ถ้ามี NewEmail คือลิงก์ยืนยันการเปลี่ยนอีเมล อีเมลของผู้ใช้จะเปลี่ยนเมื่อยืนยันแล้วเท่านั้น
+type EmailVerification struct {
+	ID        int64      `json:"id" gorm:"primaryKey"`
+	UserID    int        `json:"user_id"`
+	TokenHash string     `json:"-"`
+	NewEmail  *string    `json:"new_email,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+}
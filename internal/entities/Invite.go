@@ -0,0 +1,30 @@
+package entities
+
+import "time"
+
+// Invite คือรหัสเชิญสำหรับสมัครสมาชิกเมื่อเปิดรับสมัครแบบเชิญเท่านั้น เก็บเฉพาะ hash ของรหัส
+type Invite struct {
+	ID int64 `json:"id" gorm:"primaryKey"`
+	// Prefix คือส่วนต้นของรหัสที่แสดงได้ ใช้บอกว่าเป็นรหัสไหนโดยไม่ต้องเห็นรหัสทั้งหมด
+	Prefix   string `json:"prefix"`
+	CodeHash string `json:"-"`
+	// Role คือบทบาทที่ผู้ใช้ได้เมื่อสมัครด้วยรหัสนี้
+	Role string `json:"role"`
+	// Note คือข้อความที่ admin ใส่ไว้ เช่นเชิญใคร
+	Note string `json:"note"`
+	// MaxUses คือจำนวนครั้งที่ใช้สมัครได้ 1 คือใช้ได้ครั้งเดียว
+	MaxUses   int        `json:"max_uses"`
+	Uses      int        `json:"uses"`
+	CreatedBy *int       `json:"created_by,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// InviteRedemption คือการสมัครหนึ่งครั้งด้วยรหัสเชิญ
+type InviteRedemption struct {
+	ID         int64     `json:"id" gorm:"primaryKey"`
+	InviteID   int64     `json:"invite_id"`
+	UserID     int       `json:"user_id"`
+	RedeemedAt time.Time `json:"redeemed_at"`
+}
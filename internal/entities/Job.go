@@ -0,0 +1,31 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// สถานะของ job ในคิว
+const (
+	JobStatusPending   = "pending"
+	JobStatusRunning   = "running"
+	JobStatusSucceeded = "succeeded"
+	// JobStatusDead คือ job ที่ลองครบจำนวนครั้งแล้วยังไม่สำเร็จ (dead letter) ต้องให้ admin สั่ง retry เอง
+	JobStatusDead = "dead"
+)
+
+type Job struct {
+	ID          int64           `json:"id" gorm:"primaryKey"`
+	Kind        string          `json:"kind"`
+	Payload     json.RawMessage `json:"payload" gorm:"type:jsonb" swaggertype:"object"`
+	Status      string          `json:"status"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"max_attempts"`
+	RunAt       time.Time       `json:"run_at"`
+	LastError   string          `json:"last_error,omitempty"`
+	LockedBy    string          `json:"locked_by,omitempty"`
+	LockedAt    *time.Time      `json:"locked_at,omitempty"`
+	FinishedAt  *time.Time      `json:"finished_at,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
@@ -0,0 +1,35 @@
+package entities
+
+import "time"
+
+// ผลของการ login แต่ละครั้งที่บันทึกใน LoginAttempt.Result
+const (
+	LoginSucceeded          = "succeeded"
+	LoginInvalidCredentials = "invalid_credentials"
+	LoginEmailNotVerified   = "email_not_verified"
+	LoginAccountDisabled    = "account_disabled"
+	LoginThrottled          = "throttled"
+	LoginLocked             = "locked"
+	LoginTwoFactorRequired  = "two_factor_required"
+	LoginInvalidTwoFactor   = "invalid_two_factor"
+)
+
+// LoginAttempt คือบันทึกการ login หนึ่งครั้ง ทั้งที่สำเร็จและไม่สำเร็จ
+type LoginAttempt struct {
+	ID        int64     `json:"id" gorm:"primaryKey"`
+	Email     string    `json:"email"`
+	UserID    *int      `json:"user_id,omitempty"`
+	IPAddress string    `json:"ip_address"`
+	UserAgent string    `json:"user_agent"`
+	Result    string    `json:"result"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// LoginThrottle คือจำนวนครั้งที่ login ผิดติดกันของบัญชีหรือ IP หนึ่ง
+// Key อยู่ในรูป "account:<email>" หรือ "ip:<address>"
+type LoginThrottle struct {
+	Key           string     `json:"key" gorm:"primaryKey"`
+	Failures      int        `json:"failures"`
+	LastFailureAt time.Time  `json:"last_failure_at"`
+	LockedUntil   *time.Time `json:"locked_until,omitempty"`
+}
@@ -10,6 +10,8 @@ type Movie struct {
 	MPAARating  string    `json:"mpaa_rating"`
 	Description string    `json:"description"`
 	Image       string    `json:"image"`
+	Backdrop    string    `json:"backdrop"`
+	TMDBID      *int      `json:"tmdb_id,omitempty" gorm:"column:tmdb_id"`
 	CreatedAt   time.Time `json:"-"`
 	UpdatedAt   time.Time `json:"-"`
 	Genres      []*Genre  `json:"genres,omitempty" gorm:"many2many:movies_genres"`
@@ -19,7 +21,48 @@ type Movie struct {
 type Genre struct {
 	ID    int    `json:"id"`
 	Genre string `json:"genre"`
+	// TMDBID คือ genre id ฝั่ง TMDB ที่ map มาที่ genre นี้
+	TMDBID *int `json:"tmdb_id,omitempty" gorm:"column:tmdb_id"`
 	//Checked   bool      `json:"checked" gorm:"default:false"`
 	CreatedAt time.Time `json:"-"`
 	UpdatedAt time.Time `json:"-"`
 }
+
+// ชนิดของรูปที่อัปโหลดให้หนัง
+const (
+	ImageKindPoster   = "poster"
+	ImageKindBackdrop = "backdrop"
+)
+
+type MovieImage struct {
+	ID          int                  `json:"id" gorm:"primaryKey"`
+	MovieID     int                  `json:"movie_id"`
+	Kind        string               `json:"kind"`
+	StorageKey  string               `json:"-"`
+	URL         string               `json:"url" gorm:"-"`
+	ContentType string               `json:"content_type"`
+	Width       int                  `json:"width"`
+	Height      int                  `json:"height"`
+	SizeBytes   int                  `json:"size_bytes"`
+	Variants    []*MovieImageVariant `json:"variants,omitempty" gorm:"foreignKey:ImageID"`
+	CreatedAt   time.Time            `json:"created_at"`
+}
+
+type MovieImageVariant struct {
+	ID         int    `json:"-" gorm:"primaryKey"`
+	ImageID    int    `json:"-"`
+	StorageKey string `json:"-"`
+	URL        string `json:"url" gorm:"-"`
+	Width      int    `json:"width"`
+	Height     int    `json:"height"`
+}
+
+// เรต MPAA ที่รับได้ NR คือยังไม่ได้จัดเรต
+const (
+	MPAARatingG    = "G"
+	MPAARatingPG   = "PG"
+	MPAARatingPG13 = "PG-13"
+	MPAARatingR    = "R"
+	MPAARatingNC17 = "NC-17"
+	MPAARatingNR   = "NR"
+)
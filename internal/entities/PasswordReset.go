@@ -0,0 +1,13 @@
+package entities
+
+import "time"
+
+// PasswordReset คือ token สำหรับตั้งรหัสผ่านใหม่ เก็บเฉพาะ hash ใช้ได้ครั้งเดียวและมีอายุสั้น
+type PasswordReset struct {
+	ID        int64      `json:"id" gorm:"primaryKey"`
+	UserID    int        `json:"user_id"`
+	TokenHash string     `json:"-"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+}
@@ -0,0 +1,34 @@
+package entities
+
+import "time"
+
+// RateLimitBucket คือ token bucket ของ key หนึ่ง Key อยู่ในรูป "<นโยบาย>:<ip|user|apikey>:<id>"
+type RateLimitBucket struct {
+	Key    string  `gorm:"primaryKey"`
+	Tokens float64 // จำนวน token ที่เหลือ ณ RefilledAt
+	// RefilledAt คือเวลาที่เติม token ครั้งล่าสุด
+	RefilledAt time.Time
+	// ExpiresAt คือเวลาที่ bucket จะเต็มอีกครั้ง หลังจากนั้นลบทิ้งได้โดยไม่เปลี่ยนผล
+	ExpiresAt time.Time
+}
+
+// Take เติม token ตามเวลาที่ผ่านไปแล้วหักหนึ่งตัว bucket จุได้ capacity token และเติมจากว่างจนเต็มใน period
+// คืน false โดยไม่หัก token ถ้าเหลือไม่ถึงหนึ่งตัว
+func (b *RateLimitBucket) Take(capacity int, period time.Duration, now time.Time) bool {
+	full := float64(capacity)
+	perSecond := full / period.Seconds()
+
+	// นาฬิกาของแต่ละ instance อาจไม่ตรงกัน เวลาที่ย้อนหลังจึงไม่เติมและไม่ขยับ RefilledAt
+	if elapsed := now.Sub(b.RefilledAt).Seconds(); elapsed > 0 {
+		b.Tokens = min(full, b.Tokens+elapsed*perSecond)
+		b.RefilledAt = now
+	}
+
+	allowed := b.Tokens >= 1
+	if allowed {
+		b.Tokens--
+	}
+
+	b.ExpiresAt = b.RefilledAt.Add(time.Duration((full - b.Tokens) / perSecond * float64(time.Second)))
+	return allowed
+}
@@ -0,0 +1,102 @@
+package entities
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+var bucketStart = time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+// newBucket คือ bucket ที่เต็ม เหมือน key ที่ยังไม่เคยส่ง request
+func newBucket(capacity int) *RateLimitBucket {
+	return &RateLimitBucket{Key: "test:ip:192.0.2.1", Tokens: float64(capacity), RefilledAt: bucketStart}
+}
+
+func takeN(b *RateLimitBucket, n, capacity int, period time.Duration, now time.Time) int {
+	allowed := 0
+	for i := 0; i < n; i++ {
+		if b.Take(capacity, period, now) {
+			allowed++
+		}
+	}
+	return allowed
+}
+
+func TestRateLimitBucketBurstUpToCapacity(t *testing.T) {
+	b := newBucket(3)
+
+	if got := takeN(b, 5, 3, 3*time.Second, bucketStart); got != 3 {
+		t.Errorf("allowed %d of 5, want 3", got)
+	}
+	if b.Tokens < 0 || b.Tokens >= 1 {
+		t.Errorf("tokens = %v, want less than one left", b.Tokens)
+	}
+}
+
+func TestRateLimitBucketRefill(t *testing.T) {
+	b := newBucket(3)
+	takeN(b, 3, 3, 3*time.Second, bucketStart)
+
+	// เติมหนึ่ง token ต่อวินาที
+	if b.Take(3, 3*time.Second, bucketStart.Add(500*time.Millisecond)) {
+		t.Fatal("allowed after half a token refilled")
+	}
+	now := bucketStart.Add(time.Second)
+	if got := takeN(b, 2, 3, 3*time.Second, now); got != 1 {
+		t.Errorf("allowed %d after one second, want 1", got)
+	}
+	if !b.RefilledAt.Equal(now) {
+		t.Errorf("RefilledAt = %v, want %v", b.RefilledAt, now)
+	}
+}
+
+func TestRateLimitBucketRefillIsCapped(t *testing.T) {
+	b := newBucket(3)
+	takeN(b, 3, 3, 3*time.Second, bucketStart)
+
+	now := bucketStart.Add(time.Hour)
+	if got := takeN(b, 10, 3, 3*time.Second, now); got != 3 {
+		t.Errorf("allowed %d after an hour idle, want capacity 3", got)
+	}
+}
+
+func TestRateLimitBucketLoweredCapacity(t *testing.T) {
+	b := newBucket(10)
+
+	if got := takeN(b, 10, 2, time.Minute, bucketStart.Add(time.Second)); got != 2 {
+		t.Errorf("allowed %d after capacity lowered to 2, want 2", got)
+	}
+}
+
+func TestRateLimitBucketClockGoingBackwards(t *testing.T) {
+	b := newBucket(2)
+	takeN(b, 2, 2, 2*time.Second, bucketStart)
+
+	past := bucketStart.Add(-time.Minute)
+	if b.Take(2, 2*time.Second, past) {
+		t.Error("allowed with a clock that went backwards")
+	}
+	if !b.RefilledAt.Equal(bucketStart) {
+		t.Errorf("RefilledAt moved to %v, want it kept at %v", b.RefilledAt, bucketStart)
+	}
+	if b.Tokens < 0 {
+		t.Errorf("tokens = %v, want no negative balance", b.Tokens)
+	}
+
+	// เมื่อนาฬิกากลับมาตรง ยังเติมจาก RefilledAt เดิม ไม่ได้ token เพิ่มจากช่วงที่ย้อนหลัง
+	if got := takeN(b, 3, 2, 2*time.Second, bucketStart.Add(time.Second)); got != 1 {
+		t.Errorf("allowed %d one second later, want 1", got)
+	}
+}
+
+func TestRateLimitBucketExpiresWhenFull(t *testing.T) {
+	b := newBucket(4)
+	takeN(b, 2, 4, 4*time.Second, bucketStart)
+
+	// ใช้ไปสอง token เติมหนึ่ง token ต่อวินาที จึงเต็มอีกครั้งในสองวินาที
+	want := bucketStart.Add(2 * time.Second)
+	if diff := b.ExpiresAt.Sub(want); math.Abs(float64(diff)) > float64(time.Millisecond) {
+		t.Errorf("ExpiresAt = %v, want %v", b.ExpiresAt, want)
+	}
+}
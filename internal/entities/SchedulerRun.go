@@ -0,0 +1,24 @@
+package entities
+
+import "time"
+
+// สถานะของการรัน scheduled task แต่ละครั้ง
+const (
+	SchedulerRunRunning   = "running"
+	SchedulerRunSucceeded = "succeeded"
+	SchedulerRunFailed    = "failed"
+)
+
+// SchedulerRun คือประวัติการรัน scheduled task หนึ่งครั้ง
+// ScheduledFor คือรอบเวลาที่ task ถูกตั้งให้รัน (ปัดเป็นนาที) ใช้กันไม่ให้หลาย instance รันรอบเดียวกันซ้ำ
+type SchedulerRun struct {
+	ID           int64      `json:"id" gorm:"primaryKey"`
+	Task         string     `json:"task"`
+	Instance     string     `json:"instance"`
+	Status       string     `json:"status"`
+	Detail       string     `json:"detail,omitempty"`
+	Error        string     `json:"error,omitempty"`
+	ScheduledFor time.Time  `json:"scheduled_for"`
+	StartedAt    time.Time  `json:"started_at"`
+	FinishedAt   *time.Time `json:"finished_at,omitempty"`
+}
@@ -0,0 +1,32 @@
+package entities
+
+import "time"
+
+// Session คือ refresh token หนึ่งตัวที่ออกให้ผู้ใช้ เก็บเฉพาะ hash ของ token
+// ทุกครั้งที่ refresh จะได้ token ใหม่ใน family เดียวกัน และ token เดิมถูกทำเครื่องหมายว่า rotated
+// ถ้ามีคนนำ token ที่ rotated แล้วมาใช้อีก แปลว่า token รั่ว ทั้ง family จะถูก revoke
+type Session struct {
+	ID        int64      `json:"id" gorm:"primaryKey"`
+	UserID    int        `json:"user_id"`
+	FamilyID  string     `json:"family_id" gorm:"type:uuid"`
+	TokenHash string     `json:"-"`
+	UserAgent string     `json:"user_agent"`
+	IPAddress string     `json:"ip_address"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	RotatedAt *time.Time `json:"rotated_at,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// ActiveSession คือการ login หนึ่งครั้งที่ยังใช้งานได้ (refresh token หนึ่ง family)
+// ID คือ family ID ส่วน user agent และ IP มาจากการ refresh ครั้งล่าสุด
+type ActiveSession struct {
+	ID         string    `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	// Current เป็น true ถ้าเป็น session ของ access token ที่ใช้เรียก API นี้
+	Current bool `json:"current" gorm:"-"`
+}
@@ -0,0 +1,22 @@
+package entities
+
+import "time"
+
+// UserTOTP คือ secret ของ TOTP ของผู้ใช้ ConfirmedAt เป็น nil ระหว่างที่ลงทะเบียนแต่ยังไม่ได้ยืนยันด้วยรหัสแรก
+type UserTOTP struct {
+	UserID int    `json:"user_id" gorm:"primaryKey"`
+	Secret string `json:"-"`
+	// LastUsedStep คือช่วงเวลาของรหัสล่าสุดที่ใช้แล้ว กันการใช้รหัสเดิมซ้ำ
+	LastUsedStep int64      `json:"-"`
+	ConfirmedAt  *time.Time `json:"confirmed_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// RecoveryCode คือรหัสสำรองที่ใช้แทน TOTP ได้ครั้งเดียว เก็บเฉพาะ hash
+type RecoveryCode struct {
+	ID        int64      `json:"id" gorm:"primaryKey"`
+	UserID    int        `json:"user_id"`
+	CodeHash  string     `json:"-"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
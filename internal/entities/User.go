@@ -8,15 +8,34 @@ import (
 )
 
 type User struct {
-	ID        int       `json:"id"`
-	FirstName string    `json:"first_name"`
-	LastName  string    `json:"last_name"`
-	Email     string    `json:"email"`
-	Password  string    `json:"password"`
-	CreatedAt time.Time `json:"-"`
-	UpdatedAt time.Time `json:"-"`
+	ID        int    `json:"id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Email     string `json:"email"`
+	// Password คือ bcrypt hash ห้ามส่งออกไปใน response
+	Password string `json:"-"`
+	// Role คือบทบาทของผู้ใช้ (rbac.RoleAdmin, rbac.RoleEditor หรือ rbac.RoleViewer)
+	Role string `json:"role"`
+	// Permissions คือสิทธิ์ที่ให้เพิ่มจากสิทธิ์ของบทบาท
+	Permissions []string `json:"permissions" gorm:"serializer:json"`
+	// EmailVerifiedAt เป็น nil จนกว่าผู้ใช้จะกดลิงก์ยืนยันอีเมล บัญชีที่ยังไม่ยืนยันจะ login ไม่ได้
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	// DisabledAt มีค่าเมื่อ admin ปิดบัญชี บัญชีที่ถูกปิดจะ login ไม่ได้
+	DisabledAt *time.Time `json:"disabled_at,omitempty"`
+	// DeletionScheduledAt คือเวลาที่บัญชีจะถูกลบ ผู้ใช้ยกเลิกได้ก่อนถึงเวลานั้น
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"`
+	CreatedAt           time.Time  `json:"-"`
+	UpdatedAt           time.Time  `json:"-"`
 }
 
+// สถานะของบัญชีที่ใช้กรองรายชื่อผู้ใช้
+const (
+	UserStatusActive          = "active"
+	UserStatusDisabled        = "disabled"
+	UserStatusUnverified      = "unverified"
+	UserStatusPendingDeletion = "pending_deletion"
+)
+
 // PasswordMatches ฟังก์ชันสำหรับตรวจสอบรหัสผ่าน
 func (u *User) PasswordMatches(plainText string) (bool, error) {
 
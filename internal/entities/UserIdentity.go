@@ -0,0 +1,25 @@
+package entities
+
+import "time"
+
+// UserIdentity ผูกบัญชีของ identity provider ภายนอก (OpenID Connect) เข้ากับผู้ใช้
+// Subject คือ claim sub ของ provider ซึ่งไม่เปลี่ยนแม้ผู้ใช้จะเปลี่ยนอีเมล
+type UserIdentity struct {
+	ID          int64      `json:"id" gorm:"primaryKey"`
+	UserID      int        `json:"user_id"`
+	Provider    string     `json:"provider"`
+	Subject     string     `json:"subject"`
+	Email       string     `json:"email"`
+	CreatedAt   time.Time  `json:"created_at"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+}
+
+// OIDCState คือ state ของการ login ผ่าน provider ที่รอ callback ใช้ได้ครั้งเดียว
+type OIDCState struct {
+	StateHash    string `gorm:"primaryKey"`
+	Provider     string
+	Nonce        string
+	CodeVerifier string
+	CreatedAt    time.Time
+	ExpiresAt    time.Time
+}
@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NakarinFIgo/Movies-App/internal/apikeys"
+	"github.com/NakarinFIgo/Movies-App/internal/entities"
+	"github.com/NakarinFIgo/Movies-App/internal/repository"
+	"github.com/NakarinFIgo/Movies-App/pkg/middlewares"
+	"github.com/NakarinFIgo/Movies-App/pkg/rbac"
+	"github.com/NakarinFIgo/Movies-App/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	maxAPIKeyNameLength = 100
+	maxAPIKeyRateLimit  = 10000
+)
+
+// CreateAPIKeyPayload is the request payload for creating an API key
+type CreateAPIKeyPayload struct {
+	// Required: true
+	// Example: "nightly-import"
+	Name string `json:"name"`
+	// Required: true
+	// Example: ["movies:write"]
+	Permissions []string `json:"permissions"`
+	// ExpiresAt ไม่ระบุคือไม่หมดอายุ
+	ExpiresAt *time.Time `json:"expires_at"`
+	// RateLimit คือจำนวน request ต่อนาที ไม่ระบุคือใช้ค่าเริ่มต้นของระบบ
+	// Example: 120
+	RateLimit *int `json:"rate_limit"`
+}
+
+// CreatedAPIKey is the response of creating an API key; Key is shown only once
+type CreatedAPIKey struct {
+	entities.APIKey
+	Key string `json:"key"`
+}
+
+// CreateAPIKey สร้าง API key
+// @Summary สร้าง API key
+// @Description สร้าง key สำหรับสคริปต์หรือระบบอื่น ใช้กับ header Authorization: ApiKey <key> key แสดงครั้งเดียวในคำตอบนี้ ระบบเก็บไว้เฉพาะ hash สิทธิ์ของ key ต้องเป็นสิทธิ์ที่ผู้สร้างมีอยู่แล้ว
+// @Tags API Keys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param requestPayload body CreateAPIKeyPayload true "API key"
+// @Success 201 {object} CreatedAPIKey "Created key"
+// @Failure 400 {object} utils.Problem "Bad Request"
+// @Failure 403 {object} utils.Problem "Forbidden"
+// @Router /api/v1/admin/api-keys [post]
+func (h *Handler) CreateAPIKey(c *fiber.Ctx) error {
+	claims, ok := middlewares.ClaimsFromContext(c)
+	if !ok {
+		return utils.ErrorJSON(c, errors.New("unauthorized"), http.StatusUnauthorized)
+	}
+	// key ต้องมีเจ้าของที่เป็นคน จึงใช้ API key สร้าง key อื่นไม่ได้
+	userID, err := claims.UserID()
+	if err != nil {
+		return utils.ErrorJSON(c, errors.New("api keys can only be created by a signed-in user"), http.StatusForbidden)
+	}
+
+	var payload CreateAPIKeyPayload
+	if err := utils.ReadJSON(c, &payload); err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	name := strings.TrimSpace(payload.Name)
+	if name == "" || len(name) > maxAPIKeyNameLength {
+		return utils.ErrorJSON(c, fmt.Errorf("name is required and must be at most %d characters", maxAPIKeyNameLength))
+	}
+
+	if len(payload.Permissions) == 0 {
+		return utils.ErrorJSON(c, errors.New("at least one permission is required"))
+	}
+	for _, p := range payload.Permissions {
+		if !rbac.ValidPermission(p) {
+			return utils.ErrorJSON(c, fmt.Errorf("unknown permission %s", p))
+		}
+		if !claims.HasPermission(p) {
+			return utils.ErrorJSON(c, fmt.Errorf("cannot grant permission %s", p), http.StatusForbidden)
+		}
+	}
+
+	if payload.ExpiresAt != nil && !payload.ExpiresAt.After(time.Now()) {
+		return utils.ErrorJSON(c, errors.New("expires_at must be in the future"))
+	}
+
+	rateLimit := h.App.APIKeyRateLimit
+	if payload.RateLimit != nil {
+		rateLimit = *payload.RateLimit
+		if rateLimit < 1 || rateLimit > maxAPIKeyRateLimit {
+			return utils.ErrorJSON(c, fmt.Errorf("rate_limit must be between 1 and %d", maxAPIKeyRateLimit))
+		}
+	}
+
+	key, prefix, hash, err := apikeys.Generate()
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+
+	apiKey := entities.APIKey{
+		Name:        name,
+		Prefix:      prefix,
+		KeyHash:     hash,
+		Permissions: rbac.Effective("", payload.Permissions),
+		RateLimit:   rateLimit,
+		CreatedBy:   &userID,
+		CreatedAt:   time.Now(),
+		ExpiresAt:   payload.ExpiresAt,
+	}
+
+	apiKey.ID, err = h.App.DB.InsertAPIKey(apiKey)
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+
+	return utils.WriteJSON(c, fiber.StatusCreated, CreatedAPIKey{APIKey: apiKey, Key: key})
+}
+
+// AllAPIKeys แสดงรายการ API key
+// @Summary แสดงรายการ API key
+// @Description แสดง API key ทั้งหมด รวมที่หมดอายุหรือถูก revoke แล้ว ไม่แสดงตัว key มีเฉพาะ prefix
+// @Tags API Keys
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} entities.APIKey "API keys"
+// @Router /api/v1/admin/api-keys [get]
+func (h *Handler) AllAPIKeys(c *fiber.Ctx) error {
+	keys, err := h.App.DB.AllAPIKeys()
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+
+	return utils.WriteJSON(c, fiber.StatusOK, keys)
+}
+
+// RevokeAPIKey ยกเลิก API key
+// @Summary ยกเลิก API key
+// @Description ทำให้ key ใช้ไม่ได้อีกทันที
+// @Tags API Keys
+// @Security BearerAuth
+// @Param id path int true "API key ID"
+// @Success 204 "Revoked"
+// @Failure 404 {object} utils.Problem "Not Found"
+// @Router /api/v1/admin/api-keys/{id} [delete]
+func (h *Handler) RevokeAPIKey(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	if err := h.App.DB.RevokeAPIKey(id); err != nil {
+		if errors.Is(err, repository.ErrAPIKeyNotFound) {
+			return utils.ErrorJSON(c, err, http.StatusNotFound)
+		}
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+
+	h.App.APIKeys.Forget(id)
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/NakarinFIgo/Movies-App/internal/entities"
+	"github.com/NakarinFIgo/Movies-App/pkg/middlewares"
+	"github.com/NakarinFIgo/Movies-App/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	defaultAuditLimit = 50
+	maxAuditLimit     = 200
+)
+
+// AuditTrail บันทึกทุก request ที่ใช้ API key และทุก request ที่เปลี่ยนข้อมูลของผู้ใช้ที่ login อยู่
+// ต้องใช้ก่อน route ที่มี AuthRequired เพราะอ่าน claims หลัง handler ทำงานเสร็จแล้ว
+func (h *Handler) AuditTrail(c *fiber.Ctx) error {
+	err := c.Next()
+
+	claims, ok := middlewares.ClaimsFromContext(c)
+	if !ok {
+		return err
+	}
+
+	entry := entities.AuditLog{
+		Method:    c.Method(),
+		Path:      c.Path(),
+		Action:    c.Method() + " " + c.Route().Path,
+		IPAddress: c.IP(),
+		UserAgent: userAgent(c),
+	}
+
+	switch {
+	case claims.IsAPIKey():
+		entry.ActorType = entities.AuditActorAPIKey
+		entry.ActorID = claims.ID
+	case c.Method() == fiber.MethodGet || c.Method() == fiber.MethodHead || c.Method() == fiber.MethodOptions:
+		return err
+	default:
+		entry.ActorType = entities.AuditActorUser
+		entry.ActorID = claims.Subject
+	}
+
+	// เขียน error ที่ handler คืนมาเป็น response ก่อน จึงบันทึก status จริงที่ client ได้รับ
+	if err != nil {
+		if handlerErr := c.App().ErrorHandler(c, err); handlerErr != nil {
+			_ = c.SendStatus(fiber.StatusInternalServerError)
+		}
+		err = nil
+	}
+	entry.Status = c.Response().StatusCode()
+
+	if logErr := h.App.DB.InsertAuditLog(entry); logErr != nil {
+		log.Println("audit:", logErr)
+	}
+
+	return err
+}
+
+// AuditLogs แสดงบันทึกการกระทำ
+// @Summary แสดงบันทึกการกระทำ
+// @Description แสดง request ที่เปลี่ยนข้อมูลของผู้ใช้และทุก request ที่ใช้ API key ล่าสุดก่อน กรองตามประเภทและ ID ของผู้กระทำได้
+// @Tags API Keys
+// @Produce json
+// @Security BearerAuth
+// @Param actor_type query string false "ประเภทของผู้กระทำ" Enums(user, api_key)
+// @Param actor_id query string false "user id หรือ API key id"
+// @Param limit query int false "จำนวนรายการ (ค่าเริ่มต้น 50 สูงสุด 200)"
+// @Param offset query int false "ข้ามกี่รายการ"
+// @Success 200 {array} entities.AuditLog "Audit log"
+// @Failure 400 {object} utils.Problem "Bad Request"
+// @Router /api/v1/admin/audit-logs [get]
+func (h *Handler) AuditLogs(c *fiber.Ctx) error {
+	actorType := c.Query("actor_type")
+	switch actorType {
+	case "", entities.AuditActorUser, entities.AuditActorAPIKey:
+	default:
+		return utils.ErrorJSON(c, errors.New("invalid actor_type"))
+	}
+
+	actorID := c.Query("actor_id")
+	if actorID != "" {
+		if _, err := strconv.ParseInt(actorID, 10, 64); err != nil {
+			return utils.ErrorJSON(c, errors.New("invalid actor_id"))
+		}
+	}
+
+	limit := c.QueryInt("limit", defaultAuditLimit)
+	if limit <= 0 || limit > maxAuditLimit {
+		limit = defaultAuditLimit
+	}
+	offset := c.QueryInt("offset", 0)
+	if offset < 0 {
+		offset = 0
+	}
+
+	logs, err := h.App.DB.AuditLogs(actorType, actorID, limit, offset)
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+
+	return utils.WriteJSON(c, fiber.StatusOK, logs)
+}
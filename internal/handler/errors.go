@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/NakarinFIgo/Movies-App/internal/repository"
+	"github.com/NakarinFIgo/Movies-App/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+)
+
+// รหัสของ error ที่มาจากประเภทของ error ใน repository
+const (
+	CodeNotFound    = "not_found"
+	CodeConflict    = "conflict"
+	CodeInvalidData = "invalid_data"
+)
+
+// problemTypeBase นำหน้า code เพื่อเป็น type ของ problem response
+const problemTypeBase = "/problems/"
+
+// detailInternal ใช้แทนข้อความของ error 5xx ซึ่งอาจมี SQL หรือรายละเอียดภายใน
+const detailInternal = "the server could not complete the request"
+
+// kindProblem คือ status รหัส และข้อความที่ใช้กับ error แต่ละประเภทของ repository
+// ข้อความใช้เมื่อ error เป็น error ดิบของฐานข้อมูลที่ส่งให้ client ไม่ได้
+var kindProblem = map[error]struct {
+	status int
+	code   string
+	detail string
+}{
+	repository.ErrNotFound:   {http.StatusNotFound, CodeNotFound, "resource not found"},
+	repository.ErrConflict:   {http.StatusConflict, CodeConflict, "request conflicts with existing data"},
+	repository.ErrValidation: {http.StatusUnprocessableEntity, CodeInvalidData, "request contains invalid data"},
+}
+
+// ErrorHandler คือ error handler กลางของ Fiber เขียน error ทุกตัวเป็น application/problem+json
+// status ที่ handler ระบุมาจะใช้ตามนั้น ยกเว้น 500 ซึ่งจะแก้เป็น status ตามประเภทของ error ถ้ารู้จัก
+// error 5xx และ error ดิบของฐานข้อมูลจะไม่ส่งข้อความจริงให้ client แต่เขียนลง log พร้อม request ID
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	var status int
+	var code string
+	var httpErr *utils.HTTPError
+	if errors.As(err, &httpErr) {
+		status, code = httpErr.Status, httpErr.Code
+	}
+
+	kind, known := kindProblem[repository.Kind(err)]
+	if known && (status == 0 || status == http.StatusInternalServerError) {
+		status = kind.status
+	}
+
+	var fiberErr *fiber.Error
+	isFiberErr := errors.As(err, &fiberErr)
+	if status == 0 {
+		switch {
+		case isFiberErr:
+			status = fiberErr.Code
+		case repository.IsDatabaseError(err):
+			status = http.StatusInternalServerError
+		default:
+			status = http.StatusBadRequest
+		}
+	}
+
+	if code == "" {
+		if known && status == kind.status {
+			code = kind.code
+		} else {
+			code = statusCode(status)
+		}
+	}
+
+	detail := err.Error()
+	switch {
+	case status >= http.StatusInternalServerError && !isFiberErr:
+		detail = detailInternal
+	case repository.IsDatabaseError(err):
+		detail = http.StatusText(status)
+		if known {
+			detail = kind.detail
+		}
+	}
+
+	requestID, _ := c.Locals(requestid.ConfigDefault.ContextKey).(string)
+	if status >= http.StatusInternalServerError {
+		log.Printf("%s %s [%s]: %v", c.Method(), c.Path(), requestID, err)
+	}
+
+	problem := utils.Problem{
+		Type:      problemTypeBase + code,
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Instance:  c.Path(),
+		RequestID: requestID,
+		Code:      code,
+	}
+	if problem.Title == "" {
+		problem.Title = "Error"
+	}
+
+	var fields utils.FieldErrors
+	if errors.As(err, &fields) {
+		problem.Detail = "one or more fields are invalid"
+		problem.Fields = fields
+	}
+
+	return utils.WriteProblem(c, problem)
+}
+
+var codeReplacer = strings.NewReplacer(" ", "_", "-", "_", "'", "")
+
+// statusCode แปลง status เป็นรหัส เช่น 429 เป็น too_many_requests
+func statusCode(status int) string {
+	text := http.StatusText(status)
+	if text == "" {
+		return "error"
+	}
+	return codeReplacer.Replace(strings.ToLower(text))
+}
@@ -1,23 +1,26 @@
 package handler
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
-	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/NakarinFIgo/Movies-App/configs"
+	"github.com/NakarinFIgo/Movies-App/internal/credentials"
 	"github.com/NakarinFIgo/Movies-App/internal/entities"
+	"github.com/NakarinFIgo/Movies-App/internal/jobs"
+	"github.com/NakarinFIgo/Movies-App/internal/repository"
 	"github.com/NakarinFIgo/Movies-App/pkg/middlewares"
+	"github.com/NakarinFIgo/Movies-App/pkg/rbac"
 	"github.com/NakarinFIgo/Movies-App/pkg/utils"
+	"github.com/NakarinFIgo/Movies-App/pkg/validation"
 	"github.com/gofiber/fiber/v2"
-	"github.com/golang-jwt/jwt/v4"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type Handler struct {
@@ -50,22 +53,51 @@ type UserRegisterPayload struct {
 	// Required: true
 	// Example: "password123"
 	Password string `json:"password"`
+	// InviteCode ต้องระบุเมื่อเปิดรับสมัครแบบเชิญเท่านั้น ผู้ใช้จะได้บทบาทตามรหัส
+	// Example: "inv_3q2-7wEB..."
+	InviteCode string `json:"invite_code"`
 }
 
-type Claims struct {
-	jwt.RegisteredClaims
+// jwtUser สร้างข้อมูลที่ฝังใน token จากผู้ใช้ รวมถึงสิทธิ์ทั้งหมดที่ผู้ใช้มีและ session ที่ token สังกัด
+func jwtUser(user *entities.User, sessionID string) *middlewares.JWTUser {
+	return &middlewares.JWTUser{
+		ID:          user.ID,
+		FirstName:   user.FirstName,
+		LastName:    user.LastName,
+		Role:        user.Role,
+		Permissions: rbac.Effective(user.Role, user.Permissions),
+		SessionID:   sessionID,
+	}
+}
+
+// userAgent คืน User-Agent ของ request ตัดให้ไม่ยาวเกินคอลัมน์
+func userAgent(c *fiber.Ctx) string {
+	const maxUserAgent = 512
+
+	ua := c.Get(fiber.HeaderUserAgent)
+	if len(ua) > maxUserAgent {
+		ua = ua[:maxUserAgent]
+	}
+	return ua
+}
+
+// RefreshTokenPayload is the optional request payload for refreshing tokens without the cookie
+type RefreshTokenPayload struct {
+	RefreshToken string `json:"refresh_token"`
 }
 
 // login ทำการ login และสร้าง TokenPairs
 // @Summary Authentication และสร้าง TokenPairs
-// @Description รับข้อมูลอีเมลและรหัสผ่านของผู้ใช้และตรวจสอบความถูกต้อง หลังจากนั้นสร้าง JWT TokenPairs
+// @Description รับข้อมูลอีเมลและรหัสผ่านของผู้ใช้และตรวจสอบความถูกต้อง หลังจากนั้นสร้าง JWT TokenPairs ถ้า login ผิดติดกันต้องรอนานขึ้นเรื่อย ๆ ก่อนลองใหม่ และบัญชีหรือ IP จะถูกล็อกชั่วคราวเมื่อผิดครบจำนวนที่กำหนด ถ้าบัญชีเปิดใช้ 2FA หรือบทบาทบังคับใช้ 2FA จะได้ challenge_token แทน แล้วต้องส่งรหัสที่ /api/v1/login/2fa
 // @Tags Authentication
 // @Accept json
 // @Produce json
 // @Param requestPayload body UserLoginPayload true "User credentials" example({"email": "string", "password": "string"})
-// @Success 202 {object} map[string]interface{} "Token pairs" example({"access_token": "string", "refresh_token": "string"})
-// @Failure 400 {object} map[string]interface{} "Bad Request" example({"error": "Bad Request"})
-// @Failure 500 {object} map[string]interface{} "Internal Server Error" example({"error": "Internal Server Error"})
+// @Success 202 {object} LoginResponse "Token pairs, or TwoFactorChallenge when the account uses 2FA"
+// @Failure 400 {object} utils.Problem "Bad Request"
+// @Failure 403 {object} utils.Problem "Email not verified or account disabled"
+// @Failure 429 {object} utils.Problem "Too many failed attempts, see Retry-After"
+// @Failure 500 {object} utils.Problem "Internal Server Error"
 // @Router /api/v1/login [post]
 func (h *Handler) Login(c *fiber.Ctx) error {
 
@@ -76,171 +108,272 @@ func (h *Handler) Login(c *fiber.Ctx) error {
 		return utils.ErrorJSON(c, err)
 	}
 
-	user, err := h.App.DB.GetUserByEmail(requestPayload.Email)
+	email := credentials.LookupEmail(requestPayload.Email)
+	ip := c.IP()
+
+	decision, err := h.App.LoginGuard.Check(email, ip)
 	if err != nil {
-		return utils.ErrorJSON(c, errors.New("invalid credentials"), fiber.StatusBadRequest)
+		return utils.ErrorJSON(c, err, fiber.StatusInternalServerError)
+	}
+	if !decision.Allowed {
+		return h.loginBlocked(c, email, decision)
+	}
+
+	user, err := h.App.DB.GetUserByEmail(email)
+	if err != nil {
+		return h.loginFailed(c, email, nil)
 	}
 
 	valid, err := user.PasswordMatches(requestPayload.Password)
 	if err != nil || !valid {
-		return utils.ErrorJSON(c, errors.New("invalid credentials"), fiber.StatusBadRequest)
+		return h.loginFailed(c, email, &user.ID)
+	}
+	h.rehashPassword(user, requestPayload.Password)
+
+	// ตรวจหลังรหัสผ่านถูกต้องแล้ว เพื่อไม่ให้ใช้ตรวจได้ว่าอีเมลใดมีบัญชี
+	if user.EmailVerifiedAt == nil {
+		h.resetLoginFailures(email, user.ID)
+		h.recordLoginAttempt(c, email, &user.ID, entities.LoginEmailNotVerified)
+		return utils.ErrorCodeJSON(c, errors.New("email address has not been verified"), CodeEmailNotVerified, fiber.StatusForbidden)
 	}
-	u := &middlewares.JWTUser{
-		ID:        user.ID,
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
+	if user.DisabledAt != nil {
+		h.resetLoginFailures(email, user.ID)
+		h.recordLoginAttempt(c, email, &user.ID, entities.LoginAccountDisabled)
+		return utils.ErrorCodeJSON(c, errAccountDisabled, CodeAccountDisabled, fiber.StatusForbidden)
 	}
 
-	tokens, err := h.App.Auth.GenerateTokenPair(u)
+	// บัญชีที่เปิด 2FA หรือบทบาทที่บังคับใช้ 2FA ต้องยืนยันรหัสอีกขั้นก่อนได้ TokenPairs
+	// ตัวนับการ login ผิดจะถูกล้างหลังรหัส 2FA ถูกต้องเท่านั้น ไม่อย่างนั้นคนที่รู้รหัสผ่าน
+	// จะล้างตัวนับระหว่างเดารหัส 2FA ได้
+	challenge, err := h.twoFactorChallenge(user)
 	if err != nil {
-		return utils.ErrorJSON(c, err)
-
+		return utils.ErrorJSON(c, err, fiber.StatusInternalServerError)
+	}
+	if challenge != nil {
+		h.recordLoginAttempt(c, email, &user.ID, entities.LoginTwoFactorRequired)
+		return utils.WriteJSON(c, http.StatusAccepted, challenge)
 	}
 
-	refreshCookie := h.App.Auth.GetRefreshCookie(tokens.RefreshToken)
-	c.Cookie(refreshCookie)
+	h.resetLoginFailures(email, user.ID)
+
+	return h.completeLogin(c, email, user, nil)
+}
+
+// LoginResponse is the response of a successful login
+type LoginResponse struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token"`
+	User         LoginUser `json:"user"`
+	// RecoveryCodes มีเฉพาะเมื่อเพิ่งเปิดใช้ 2FA ระหว่าง login แสดงครั้งเดียวเท่านั้น
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
+}
+
+// LoginUser is the user part of LoginResponse
+type LoginUser struct {
+	ID        int    `json:"id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Email     string `json:"email"`
+}
 
-	utils.WriteJSON(c, fiber.StatusOK, tokens)
+// completeLogin บันทึกการ login ที่สำเร็จและออก TokenPairs
+func (h *Handler) completeLogin(c *fiber.Ctx, email string, user *entities.User, recoveryCodes []string) error {
+	h.recordLoginAttempt(c, email, &user.ID, entities.LoginSucceeded)
+
+	tokens, err := h.startSession(c, user)
+	if err != nil {
+		return utils.ErrorJSON(c, err, fiber.StatusInternalServerError)
+	}
 
 	// create the response payload (สร้าง payload สำหรับ response)
-	responsePayload := struct {
-		AccessToken  string `json:"access_token"`
-		RefreshToken string `json:"refresh_token"`
-		User         struct {
-			ID        int    `json:"id"`
-			FirstName string `json:"first_name"`
-			LastName  string `json:"last_name"`
-			Email     string `json:"email"`
-		} `json:"user"`
-	}{
-		AccessToken:  tokens.Token, // แก้ไขให้ตรงกับฟิลด์ Token ของคุณ
+	responsePayload := LoginResponse{
+		AccessToken:  tokens.Token,
 		RefreshToken: tokens.RefreshToken,
-		User: struct {
-			ID        int    `json:"id"`
-			FirstName string `json:"first_name"`
-			LastName  string `json:"last_name"`
-			Email     string `json:"email"`
-		}{
+		User: LoginUser{
 			ID:        user.ID,
 			FirstName: user.FirstName,
 			LastName:  user.LastName,
 			Email:     user.Email,
 		},
+		RecoveryCodes: recoveryCodes,
 	}
 
 	// write the response as JSON (เขียน response เป็น JSON)
-	utils.WriteJSON(c, http.StatusAccepted, responsePayload)
-
-	return nil
+	return utils.WriteJSON(c, http.StatusAccepted, responsePayload)
 }
 
 // register เพิ่มผู้ใช้ใหม่ในระบบ
 // @Summary เพิ่มผู้ใช้ใหม่
-// @Description รับข้อมูลผู้ใช้ใหม่และบันทึกลงในระบบ
+// @Description รับข้อมูลผู้ใช้ใหม่และบันทึกลงในระบบ อีเมลจะถูกเก็บเป็นตัวพิมพ์เล็ก รหัสผ่านต้องผ่านเกณฑ์ของระบบ ถ้าเปิดรับสมัครแบบเชิญเท่านั้นต้องส่ง invite_code ที่ยังใช้ได้ และผู้ใช้จะได้บทบาทตามรหัส ถ้าข้อมูลไม่ถูกต้องจะตอบ 422 พร้อม error ของแต่ละ field บัญชีใหม่ยังไม่ได้ยืนยันอีเมล ระบบจะส่งลิงก์ยืนยันไปที่อีเมล และจะ login ได้หลังยืนยันแล้ว
 // @Tags Authentication
 // @Accept json
 // @Produce json
 // @Param requestPayload body UserRegisterPayload true "User registration data" example({"first_name": "John", "last_name": "Doe", "email": "john@example.com", "password": "password123"})
-// @Success 201 {object} map[string]string "message" example({"message": "User created"})
-// @Failure 400 {object} map[string]string "Bad Request" example({"error": "Bad Request"})
-// @Failure 500 {object} map[string]string "Internal Server Error" example({"error": "Internal Server Error"})
+// @Success 201 {object} map[string]string "message" example({"message": "User created, check your email to verify your account"})
+// @Failure 400 {object} utils.Problem "Bad Request"
+// @Failure 403 {object} utils.Problem "Registration closed"
+// @Failure 422 {object} utils.Problem "Invalid fields"
+// @Failure 500 {object} utils.Problem "Internal Server Error"
 // @Router /api/v1/register [post]
 func (h *Handler) Register(c *fiber.Ctx) error {
+	if h.App.RegistrationMode == configs.RegistrationClosed {
+		return utils.ErrorCodeJSON(c, errRegistrationClosed, CodeRegistrationClosed, http.StatusForbidden)
+	}
+
 	var requestPayload UserRegisterPayload
 
 	err := utils.ReadJSON(c, &requestPayload)
 	if err != nil {
-		utils.ErrorJSON(c, err, http.StatusBadRequest)
-
+		return utils.ErrorJSON(c, err, http.StatusBadRequest)
 	}
 
-	// ตรวจสอบว่าอีเมลนี้มีอยู่แล้วในระบบหรือไม่
-	existingUser, _ := h.App.DB.GetUserByEmail(requestPayload.Email)
-	if existingUser != nil {
-		utils.ErrorJSON(c, errors.New("email already exists"), http.StatusBadRequest)
+	errs := utils.FieldErrors{}
+	firstName := checkName(errs, "first_name", requestPayload.FirstName)
+	lastName := checkName(errs, "last_name", requestPayload.LastName)
+
+	email, err := credentials.NormalizeEmail(requestPayload.Email)
+	if err != nil {
+		errs.Add("email", err)
+		email = requestPayload.Email
+	}
+	if err := h.App.PasswordPolicy.Check(requestPayload.Password, email); err != nil {
+		errs.Add("password", err)
+	}
+	inviteCode := strings.TrimSpace(requestPayload.InviteCode)
+	if inviteCode == "" && h.App.RegistrationMode == configs.RegistrationInviteOnly {
+		errs.Add("invite_code", errInviteCodeRequired)
+	}
+	if len(errs) > 0 {
+		return utils.ValidationErrorJSON(c, errs)
+	}
 
+	// ตรวจสอบว่าอีเมลนี้มีอยู่แล้วในระบบหรือไม่ ไม่สนตัวพิมพ์ใหญ่เล็ก
+	// ถ้าสมัครด้วยรหัสเชิญจะตรวจหลังรหัสถูกต้องแล้ว คนที่ไม่มีรหัสจึงใช้ตรวจไม่ได้ว่าอีเมลใดมีบัญชี
+	if inviteCode == "" {
+		existingUser, _ := h.App.DB.GetUserByEmail(email)
+		if existingUser != nil {
+			return utils.ValidationErrorJSON(c, utils.FieldErrors{"email": repository.ErrEmailTaken.Error()})
+		}
 	}
 
 	// Hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(requestPayload.Password), bcrypt.DefaultCost)
+	hashedPassword, err := h.App.PasswordPolicy.Hash(requestPayload.Password)
 	if err != nil {
-		utils.ErrorJSON(c, err)
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
 	}
 
 	// Create new user
 	user := entities.User{
-		FirstName: requestPayload.FirstName,
-		LastName:  requestPayload.LastName,
-		Email:     requestPayload.Email,
-		Password:  string(hashedPassword),
+		FirstName: firstName,
+		LastName:  lastName,
+		Email:     email,
+		Password:  hashedPassword,
+		Role:      rbac.RoleViewer,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
 
-	// Insert user to database
-	if _, err := h.App.DB.InsertUser(user); err != nil {
-		return utils.ErrorJSON(c, err)
+	// Insert user to database รหัสเชิญกำหนดบทบาทของผู้ใช้และถูกนับการใช้ไปพร้อมกัน
+	if inviteCode != "" {
+		var created *entities.User
+		created, err = h.App.DB.RegisterWithInvite(user, middlewares.HashToken(inviteCode))
+		if err == nil {
+			user = *created
+		}
+	} else {
+		user.ID, err = h.App.DB.InsertUser(user)
 	}
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrInviteInvalid):
+			return utils.ValidationErrorJSON(c, utils.FieldErrors{"invite_code": err.Error()})
+		// มีคนสมัครด้วยอีเมลเดียวกันพร้อมกัน unique index จึงกันไว้
+		case errors.Is(err, repository.ErrEmailTaken):
+			return utils.ValidationErrorJSON(c, utils.FieldErrors{"email": err.Error()})
+		}
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+
+	go h.sendEmailVerification(&user)
+
 	resp := utils.JSONResponse{
 		Error:   false,
-		Message: "User created",
+		Message: "User created, check your email to verify your account",
 	}
 
-	utils.WriteJSON(c, fiber.StatusAccepted, resp)
-
-	return nil
+	return utils.WriteJSON(c, fiber.StatusCreated, resp)
 }
 
 // refreshToken รีเฟรชโทเคน JWT
 // @Summary รีเฟรชโทเคน JWT
-// @Description ตรวจสอบโทเคนที่หมดอายุและสร้างโทเคนใหม่สำหรับผู้ใช้
+// @Description แลก refresh token จาก cookie (หรือจาก body) เป็น token คู่ใหม่ refresh token เดิมจะใช้ไม่ได้อีก ถ้านำ refresh token ที่ใช้ไปแล้วมาใช้ซ้ำ ทุก token ที่ต่อมาจากการ login ครั้งเดียวกันจะถูก revoke
 // @Tags Authentication
+// @Accept json
 // @Produce json
+// @Param requestPayload body RefreshTokenPayload false "Refresh token สำหรับ client ที่ไม่ใช้ cookie"
 // @Success 200 {object} map[string]string "Token pairs" example({"access_token": "string", "refresh_token": "string"})
-// @Failure 401 {object} map[string]string "Unauthorized" example({"error": "Unauthorized"})
-// @Failure 500 {object} map[string]string "Internal Server Error" example({"error": "Internal Server Error"})
-// @Router /api/v1/refresh [get]
+// @Failure 401 {object} utils.Problem "Unauthorized"
+// @Failure 500 {object} utils.Problem "Internal Server Error"
+// @Router /api/v1/refresh [post]
 func (h *Handler) RefreshToken(c *fiber.Ctx) error {
-
-	// อ่านคุกกี้จาก Fiber context
-	cookie := c.Cookies(h.App.Auth.CookieName)
-	if cookie == "refresh_token" {
-		return utils.ErrorJSON(c, fiber.NewError(fiber.StatusUnauthorized, "unauthorized"))
+	refreshToken := h.refreshTokenFromRequest(c)
+	if refreshToken == "" {
+		return utils.ErrorJSON(c, fiber.NewError(fiber.StatusUnauthorized, "unauthorized"), fiber.StatusUnauthorized)
 	}
 
-	claims := &Claims{}
-	refreshToken := cookie
-
-	// parse the token to get the claims
-	_, err := jwt.ParseWithClaims(refreshToken, claims, func(token *jwt.Token) (interface{}, error) {
-		return []byte(h.App.JWTSecret), nil
-	})
+	// ตรวจว่าเป็น refresh token ที่ถูกต้อง access token จะใช้ที่นี่ไม่ได้
+	claims, err := h.App.Auth.VerifyToken(refreshToken, middlewares.TokenTypeRefresh)
 	if err != nil {
-		return utils.ErrorJSON(c, fiber.NewError(fiber.StatusUnauthorized, "unauthorized"))
+		return utils.ErrorJSON(c, fiber.NewError(fiber.StatusUnauthorized, "unauthorized"), fiber.StatusUnauthorized)
 	}
 
 	// get the user id from the token claims
-	userID, err := strconv.Atoi(claims.Subject)
+	userID, err := claims.UserID()
 	if err != nil {
-		return utils.ErrorJSON(c, fiber.NewError(fiber.StatusUnauthorized, "unknown user"))
+		return utils.ErrorJSON(c, fiber.NewError(fiber.StatusUnauthorized, "unknown user"), fiber.StatusUnauthorized)
 	}
 
 	user, err := h.App.DB.GetUserByID(userID)
 	if err != nil {
-		return utils.ErrorJSON(c, fiber.NewError(fiber.StatusUnauthorized, "unknown user"))
+		return utils.ErrorJSON(c, fiber.NewError(fiber.StatusUnauthorized, "unknown user"), fiber.StatusUnauthorized)
 	}
 
-	u := middlewares.JWTUser{
-		ID:        user.ID,
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
+	current, err := h.App.DB.SessionByTokenHash(middlewares.HashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, repository.ErrSessionNotFound) {
+			c.Cookie(h.App.Auth.GetExpiredRefreshCookie())
+			return utils.ErrorJSON(c, err, fiber.StatusUnauthorized)
+		}
+		return utils.ErrorJSON(c, err, fiber.StatusInternalServerError)
 	}
 
-	tokenPairs, err := h.App.Auth.GenerateTokenPair(&u)
+	tokenPairs, err := h.App.Auth.GenerateTokenPair(jwtUser(user, current.FamilyID))
 	if err != nil {
 		return utils.ErrorJSON(c, fiber.NewError(fiber.StatusUnauthorized, "error generating tokens"))
 	}
 
+	next := entities.Session{
+		TokenHash: middlewares.HashToken(tokenPairs.RefreshToken),
+		UserAgent: userAgent(c),
+		IPAddress: c.IP(),
+		ExpiresAt: time.Now().Add(h.App.Auth.RefreshExpiry),
+	}
+
+	if _, err := h.App.DB.RotateSession(current.TokenHash, user.ID, next); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrSessionReused):
+			log.Printf("refresh token reuse detected for user %d, session family revoked", user.ID)
+			fallthrough
+		case errors.Is(err, repository.ErrSessionNotFound),
+			errors.Is(err, repository.ErrSessionRevoked),
+			errors.Is(err, repository.ErrSessionExpired):
+			c.Cookie(h.App.Auth.GetExpiredRefreshCookie())
+			return utils.ErrorJSON(c, err, fiber.StatusUnauthorized)
+		default:
+			return utils.ErrorJSON(c, err)
+		}
+	}
+
 	// ใช้ Fiber ในการตั้งค่า cookie
 	refreshCookie := h.App.Auth.GetRefreshCookie(tokenPairs.RefreshToken)
 	c.Cookie(refreshCookie)
@@ -251,25 +384,72 @@ func (h *Handler) RefreshToken(c *fiber.Ctx) error {
 
 // logout ออกจากระบบ
 // @Summary ออกจากระบบ
-// @Description ลบโทเคนรีเฟรชของผู้ใช้ออกจากระบบ
+// @Description revoke refresh token ของการ login นี้ที่ฝั่ง server และลบ cookie
 // @Tags Authentication
 // @Produce json
 // @Success 202 {object} map[string]string "Accepted" example({"message": "Accepted"})
-// @Failure 500 {object} map[string]string "Internal Server Error" example({"error": "Internal Server Error"})
+// @Failure 500 {object} utils.Problem "Internal Server Error"
 // @Router /api/v1/logout [get]
 func (h *Handler) Logout(c *fiber.Ctx) error {
+	if refreshToken := h.refreshTokenFromRequest(c); refreshToken != "" {
+		err := h.App.DB.RevokeSessionFamily(middlewares.HashToken(refreshToken))
+		if err != nil && !errors.Is(err, repository.ErrSessionNotFound) {
+			return utils.ErrorJSON(c, err, fiber.StatusInternalServerError)
+		}
+	}
+
 	expiredCookie := h.App.Auth.GetExpiredRefreshCookie()
 	c.Cookie(expiredCookie)
 	return c.SendStatus(fiber.StatusAccepted)
 }
 
+// refreshTokenFromRequest อ่าน refresh token จาก cookie หรือจาก body ถ้าไม่มี cookie
+func (h *Handler) refreshTokenFromRequest(c *fiber.Ctx) string {
+	if token := c.Cookies(h.App.Auth.CookieName); token != "" {
+		return token
+	}
+
+	var payload RefreshTokenPayload
+	if len(c.Body()) > 0 && utils.ReadJSON(c, &payload) == nil {
+		return payload.RefreshToken
+	}
+	return ""
+}
+
+// startSession ออก token คู่ใหม่ให้ผู้ใช้ บันทึก refresh token เป็น session family ใหม่ และตั้ง cookie
+func (h *Handler) startSession(c *fiber.Ctx, user *entities.User) (middlewares.TokenPairs, error) {
+	familyID := uuid.NewString()
+
+	tokens, err := h.App.Auth.GenerateTokenPair(jwtUser(user, familyID))
+	if err != nil {
+		return middlewares.TokenPairs{}, err
+	}
+
+	session := entities.Session{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: middlewares.HashToken(tokens.RefreshToken),
+		UserAgent: userAgent(c),
+		IPAddress: c.IP(),
+		ExpiresAt: time.Now().Add(h.App.Auth.RefreshExpiry),
+	}
+
+	if _, err := h.App.DB.InsertSession(session); err != nil {
+		return middlewares.TokenPairs{}, err
+	}
+
+	c.Cookie(h.App.Auth.GetRefreshCookie(tokens.RefreshToken))
+
+	return tokens, nil
+}
+
 // AllMovies แสดงรายชื่อหนังทั้งหมด
 // @Summary แสดงรายชื่อหนังทั้งหมด
 // @Description ดึงข้อมูลหนังทั้งหมดจาก database
 // @Tags Movies
 // @Produce json
 // @Success 200 {array} map[string]interface{} "List of all movies" example([{"id":1,"title":"Movie Title","release_date":"2024-08-28","mpaa_rating":"PG","run_time":120,"description":"Description of the movie"}])
-// @Failure 500 {object} map[string]interface{} "Internal Server Error" example({"error":"Internal Server Error"})
+// @Failure 500 {object} utils.Problem "Internal Server Error"
 // @Router /api/v1/movies [get]
 func (h *Handler) AllMovies(c *fiber.Ctx) error {
 
@@ -292,8 +472,9 @@ func (h *Handler) AllMovies(c *fiber.Ctx) error {
 // @Produce json
 // @Param id path int true "Movie ID"
 // @Success 200 {object} map[string]interface{} "Movie details" example({"id":1,"title":"Movie Title","release_date":"2024-08-28","mpaa_rating":"PG","run_time":120,"description":"Description of the movie"})
-// @Failure 400 {object} map[string]interface{} "Bad Request" example({"error":"Invalid ID"})
-// @Failure 500 {object} map[string]interface{} "Internal Server Error" example({"error":"Internal Server Error"})
+// @Failure 400 {object} utils.Problem "Bad Request"
+// @Failure 404 {object} utils.Problem "Not Found"
+// @Failure 500 {object} utils.Problem "Internal Server Error"
 // @Router /api/v1/movies/{id} [get]
 func (h *Handler) GetMovie(c *fiber.Ctx) error {
 	id := c.Params("id") // ใช้ c.Params เพื่อดึงค่า id จาก URL
@@ -318,8 +499,8 @@ func (h *Handler) GetMovie(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Param id path int true "Movie ID"
 // @Success 200 {object} map[string]interface{} "Movie and genres details" example({"movie":{"id":1,"title":"Movie Title"},"genres":[{"id":1,"name":"Genre Name"}]})
-// @Failure 400 {object} map[string]interface{} "Bad Request" example({"error":"Invalid ID"})
-// @Failure 500 {object} map[string]interface{} "Internal Server Error" example({"error":"Internal Server Error"})
+// @Failure 400 {object} utils.Problem "Bad Request"
+// @Failure 500 {object} utils.Problem "Internal Server Error"
 // @Router /api/v1/admin/movies/{id} [get]
 func (h *Handler) MovieForEdit(c *fiber.Ctx) error {
 	id := c.Params("id") // ใช้ c.Params เพื่อดึงค่า id จาก URL
@@ -351,7 +532,7 @@ func (h *Handler) MovieForEdit(c *fiber.Ctx) error {
 // @Produce json
 // @Security BearerAuth
 // @Success 200 {array} map[string]interface{} "List of movies in catalog" example([{"id":1,"title":"Catalog Movie Title","release_date":"2024-08-28","mpaa_rating":"PG","run_time":90,"description":"Description of the catalog movie"}])
-// @Failure 500 {object} map[string]interface{} "Internal Server Error" example({"error":"Internal Server Error"})
+// @Failure 500 {object} utils.Problem "Internal Server Error"
 // @Router /api/v1/admin/movies [get]
 func (h *Handler) MovieCatalog(c *fiber.Ctx) error {
 	movies, err := h.App.DB.AllMovies()
@@ -368,7 +549,7 @@ func (h *Handler) MovieCatalog(c *fiber.Ctx) error {
 // @Tags Genres
 // @Produce json
 // @Success 200 {array} map[string]interface{} "List of all genres" example([{"id":1,"name":"Action"},{"id":2,"name":"Drama"}])
-// @Failure 500 {object} map[string]interface{} "Internal Server Error" example({"error":"Internal Server Error"})
+// @Failure 500 {object} utils.Problem "Internal Server Error"
 // @Router /api/v1/genres [get]
 func (h *Handler) AllGenres(c *fiber.Ctx) error {
 	genres, err := h.App.DB.AllGenres()
@@ -381,96 +562,111 @@ func (h *Handler) AllGenres(c *fiber.Ctx) error {
 	return nil
 }
 
-func (h *Handler) GetPoster(movie entities.Movie) entities.Movie {
-	type TheMovieDB struct {
-		Page    int `json:"page"`
-		Results []struct {
-			PosterPath string `json:"poster_path"`
-		} `json:"results"`
-		TotalPages int `json:"total_pages"`
-	}
-
-	theUrl := fmt.Sprintf("https://api.themoviedb.org/3/search/movie?api_key=%s", h.App.APIKey)
-	queryUrl := theUrl + "&query=" + url.QueryEscape(movie.Title)
-
-	req, err := http.NewRequest("GET", queryUrl, nil)
-	if err != nil {
-		log.Println(err)
-		return movie
-	}
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Content-Type", "application/json")
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		log.Println(err)
-		return movie
-	}
-	defer resp.Body.Close()
-
-	bodyBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		log.Println(err)
-		return movie
-	}
-
-	var responseObject TheMovieDB
-	if err := json.Unmarshal(bodyBytes, &responseObject); err != nil {
-		log.Println(err)
-		return movie
-	}
-
-	if len(responseObject.Results) > 0 {
-		movie.Image = responseObject.Results[0].PosterPath
-	}
+// MoviePayload is the request payload for creating or updating a movie
+type MoviePayload struct {
+	// Example: "New Movie"
+	Title string `json:"title" validate:"required,notblank,max=512"`
+	// วันฉายรูปแบบ YYYY-MM-DD หรือ RFC 3339 ไม่ระบุจะดึงจาก TMDB ภายหลัง
+	// Example: "2024-08-28"
+	ReleaseDate validation.Date `json:"release_date" swaggertype:"string" validate:"omitempty,releasedate"`
+	// ความยาวเป็นนาที
+	// Example: 120
+	RunTime int `json:"runtime" validate:"omitempty,min=1,max=600"`
+	// Example: "PG-13"
+	MPAARating string `json:"mpaa_rating" validate:"omitempty,oneof=G PG PG-13 R NC-17 NR"`
+	// Example: "New movie description"
+	Description string `json:"description" validate:"max=5000"`
+	// Example: 603
+	TMDBID *int `json:"tmdb_id" validate:"omitempty,gt=0"`
+	// genre id ที่ต้องมีอยู่ใน /genres
+	// Example: [1, 2]
+	GenresArray []int `json:"genres_array" validate:"omitempty,max=20,unique,dive,gt=0"`
+}
 
-	return movie
+// readMoviePayload อ่านและตรวจ payload ของหนัง รวมถึงตรวจว่า genre มีอยู่จริง
+// คืน FieldErrors ที่มีทุก field ที่ไม่ผ่าน ถ้าว่างคือผ่านทั้งหมด
+func (h *Handler) readMoviePayload(c *fiber.Ctx) (*MoviePayload, utils.FieldErrors, error) {
+	var payload MoviePayload
+	if err := utils.ReadJSON(c, &payload); err != nil {
+		return nil, nil, err
+	}
+	payload.Title = strings.TrimSpace(payload.Title)
+	payload.Description = strings.TrimSpace(payload.Description)
+
+	errs := validation.Struct(payload)
+	if _, invalid := errs["genres_array"]; !invalid && len(payload.GenresArray) > 0 {
+		missing, err := h.App.DB.MissingGenreIDs(payload.GenresArray)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(missing) > 0 {
+			ids := make([]string, len(missing))
+			for i, id := range missing {
+				ids[i] = strconv.Itoa(id)
+			}
+			errs.Add("genres_array", fmt.Errorf("unknown genre ids: %s", strings.Join(ids, ", ")))
+		}
+	}
+
+	return &payload, errs, nil
 }
 
 // InsertMovie เพิ่มหนังใหม่
 // @Summary เพิ่มหนังใหม่
-// @Description เพิ่มหนังใหม่ไปยังฐานข้อมูล
+// @Description เพิ่มหนังใหม่ไปยังฐานข้อมูล ถ้ามี field ไม่ถูกต้องจะตอบ 422 พร้อม error ของทุก field
 // @Tags Movies
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param movie body object true "Movie data" example({"title":"New Movie","release_date":"2024-08-28","mpaa_rating":"PG","run_time":120,"description":"New movie description"})
+// @Param movie body MoviePayload true "Movie data"
 // @Success 202 {object} map[string]interface{} "Movie created" example({"message":"movie updated"})
-// @Failure 400 {object} map[string]interface{} "Bad Request" example({"error":"Invalid data"})
-// @Failure 500 {object} map[string]interface{} "Internal Server Error" example({"error":"Internal Server Error"})
+// @Failure 400 {object} utils.Problem "Bad Request"
+// @Failure 422 {object} utils.Problem "Invalid fields"
+// @Failure 500 {object} utils.Problem "Internal Server Error"
 // @Router /api/v1/admin/movies [post]
 func (h *Handler) InsertMovie(c *fiber.Ctx) error {
-	var movie entities.Movie
-
-	err := utils.ReadJSON(c, &movie)
+	payload, errs, err := h.readMoviePayload(c)
 	if err != nil {
 		return utils.ErrorJSON(c, err)
 	}
+	if len(errs) > 0 {
+		return utils.ValidationErrorJSON(c, errs)
+	}
 
-	movie = h.GetPoster(movie)
-
-	movie.CreatedAt = time.Now()
-	movie.UpdatedAt = time.Now()
+	movie := entities.Movie{
+		Title:       payload.Title,
+		ReleaseDate: payload.ReleaseDate.Time,
+		RunTime:     payload.RunTime,
+		MPAARating:  payload.MPAARating,
+		Description: payload.Description,
+		TMDBID:      payload.TMDBID,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
 
 	newID, err := h.App.DB.InsertMovie(movie)
 	if err != nil {
 		return utils.ErrorJSON(c, err)
 	}
 
-	err = h.App.DB.UpdateMovieGenres(newID, movie.GenresArray)
+	err = h.App.DB.UpdateMovieGenres(newID, payload.GenresArray)
 	if err != nil {
 		return utils.ErrorJSON(c, err)
 	}
 
-	err = h.App.DB.UpdateMovieGenres(newID, movie.GenresArray)
+	// ดึง poster และ metadata ผ่าน job queue เพื่อให้ request กลับทันที
+	job, err := jobs.New(jobs.KindEnrichMovie, jobs.EnrichMoviePayload{MovieID: newID})
+	if err == nil {
+		_, err = h.App.DB.EnqueueJob(job)
+	}
 	if err != nil {
-		return utils.ErrorJSON(c, err)
+		log.Printf("failed to enqueue metadata job for movie %d: %v", newID, err)
 	}
 
 	resp := utils.JSONResponse{
 		Error:   false,
 		Message: "movie updated",
+		Data:    fiber.Map{"id": newID},
 	}
 
 	utils.WriteJSON(c, fiber.StatusAccepted, resp)
@@ -480,44 +676,72 @@ func (h *Handler) InsertMovie(c *fiber.Ctx) error {
 
 // UpdateMovie แก้ไขข้อมูลหนัง
 // @Summary แก้ไขข้อมูลหนัง
-// @Description แก้ไขข้อมูลหนังตาม ID ที่กำหนด
+// @Description แก้ไขข้อมูลหนังตาม ID ที่กำหนด ถ้ามี field ไม่ถูกต้องจะตอบ 422 พร้อม error ของทุก field
+// @Description title และ description ถูกแทนที่ทุกครั้ง ส่ง description ว่างเพื่อล้างค่า
+// @Description release_date, runtime, mpaa_rating, tmdb_id และ genres_array ที่ไม่ได้ส่งมาจะใช้ค่าเดิม ส่ง genres_array เป็น [] เพื่อล้าง genre
 // @Tags Movies
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param movie body object true "Updated movie data" example({"id":1,"title":"Updated Movie Title","release_date":"2024-08-28","mpaa_rating":"PG","run_time":130,"description":"Updated movie description"})
+// @Param id path int true "Movie ID"
+// @Param movie body MoviePayload true "Updated movie data"
 // @Success 202 {object} map[string]interface{} "Movie updated" example({"message":"movie updated"})
-// @Failure 400 {object} map[string]interface{} "Bad Request" example({"error":"Invalid data"})
-// @Failure 500 {object} map[string]interface{} "Internal Server Error" example({"error":"Internal Server Error"})
+// @Failure 400 {object} utils.Problem "Bad Request"
+// @Failure 404 {object} utils.Problem "Not Found"
+// @Failure 422 {object} utils.Problem "Invalid fields"
+// @Failure 500 {object} utils.Problem "Internal Server Error"
 // @Router /api/v1/admin/movies/{id} [put]
 func (h *Handler) UpdateMovie(c *fiber.Ctx) error {
-	var payload entities.Movie
+	movieID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return utils.ErrorJSON(c, err)
+	}
 
-	err := utils.ReadJSON(c, &payload)
+	payload, errs, err := h.readMoviePayload(c)
 	if err != nil {
-		utils.ErrorJSON(c, err)
+		return utils.ErrorJSON(c, err)
+	}
+	if len(errs) > 0 {
+		return utils.ValidationErrorJSON(c, errs)
 	}
 
-	movie, err := h.App.DB.OneMovie(payload.ID)
+	movie, err := h.App.DB.OneMovie(movieID)
 	if err != nil {
-		utils.ErrorJSON(c, err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ErrorJSON(c, err, http.StatusNotFound)
+		}
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
 	}
 
+	// title และ description ถูกแทนที่ทุกครั้ง description ว่างคือล้างค่า
+	// release_date, runtime, mpaa_rating และ tmdb_id ที่ไม่ได้ส่งมาใช้ค่าเดิม เพราะค่าว่างของ field เหล่านี้
+	// ไม่ใช่ค่าที่บันทึกได้ ส่วน genres_array ที่ไม่ได้ส่งมาใช้ค่าเดิม และส่ง [] เพื่อล้าง genre
 	movie.Title = payload.Title
-	movie.ReleaseDate = payload.ReleaseDate
 	movie.Description = payload.Description
-	movie.MPAARating = payload.MPAARating
-	movie.RunTime = payload.RunTime
+	if !payload.ReleaseDate.IsZero() {
+		movie.ReleaseDate = payload.ReleaseDate.Time
+	}
+	if payload.MPAARating != "" {
+		movie.MPAARating = payload.MPAARating
+	}
+	if payload.RunTime != 0 {
+		movie.RunTime = payload.RunTime
+	}
+	if payload.TMDBID != nil {
+		movie.TMDBID = payload.TMDBID
+	}
 	movie.UpdatedAt = time.Now()
 
 	err = h.App.DB.UpdateMovie(*movie)
 	if err != nil {
-		utils.ErrorJSON(c, err)
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
 	}
 
-	err = h.App.DB.UpdateMovieGenres(movie.ID, payload.GenresArray)
-	if err != nil {
-		utils.ErrorJSON(c, err)
+	if payload.GenresArray != nil {
+		err = h.App.DB.UpdateMovieGenres(movie.ID, payload.GenresArray)
+		if err != nil {
+			return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+		}
 	}
 
 	resp := utils.JSONResponse{
@@ -532,14 +756,16 @@ func (h *Handler) UpdateMovie(c *fiber.Ctx) error {
 
 // DeleteMovie ลบหนังตาม ID
 // @Summary ลบหนังตาม ID
-// @Description ลบข้อมูลหนังตาม ID ที่กำหนด
+// @Description ลบข้อมูลหนังตาม ID ที่กำหนด รอบฉายของหนังจะถูกลบด้วย ถ้ามีการจองในรอบฉายใดจะลบไม่ได้
 // @Tags Movies
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "Movie ID"
 // @Success 202 {object} map[string]interface{} "Movie deleted" example({"message":"movie deleted"})
-// @Failure 400 {object} map[string]interface{} "Bad Request" example({"error":"Invalid ID"})
-// @Failure 500 {object} map[string]interface{} "Internal Server Error" example({"error":"Internal Server Error"})
+// @Failure 400 {object} utils.Problem "Bad Request"
+// @Failure 404 {object} utils.Problem "Not Found"
+// @Failure 409 {object} utils.Problem "Movie has bookings"
+// @Failure 500 {object} utils.Problem "Internal Server Error"
 // @Router /api/v1/admin/movies/{id} [delete]
 func (h *Handler) DeleteMovie(c *fiber.Ctx) error {
 	id := c.Params("id")
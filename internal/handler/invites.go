@@ -0,0 +1,242 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/NakarinFIgo/Movies-App/internal/entities"
+	"github.com/NakarinFIgo/Movies-App/internal/repository"
+	"github.com/NakarinFIgo/Movies-App/pkg/middlewares"
+	"github.com/NakarinFIgo/Movies-App/pkg/rbac"
+	"github.com/NakarinFIgo/Movies-App/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// CodeRegistrationClosed คือรหัส error ที่ Register ตอบเมื่อปิดรับสมัคร
+const CodeRegistrationClosed = "registration_closed"
+
+const (
+	// inviteCodePrefix นำหน้ารหัสเชิญทุกตัว ทำให้แยกออกจาก API key และ token อื่นได้
+	inviteCodePrefix    = "inv_"
+	invitePrefixLength  = 12
+	maxInviteUses       = 1000
+	maxInviteNoteLength = 255
+	defaultInviteLimit  = 50
+	maxInviteLimit      = 200
+)
+
+var (
+	errInviteCodeRequired = errors.New("an invite code is required to register")
+	errRegistrationClosed = errors.New("registration is closed")
+)
+
+// CreateInvitePayload is the request payload for creating an invite code
+type CreateInvitePayload struct {
+	// บทบาทของผู้ใช้ที่สมัครด้วยรหัสนี้ ไม่ระบุคือ viewer
+	// Example: "editor"
+	Role string `json:"role"`
+	// จำนวนครั้งที่ใช้สมัครได้ ไม่ระบุคือ 1
+	// Example: 1
+	MaxUses *int `json:"max_uses"`
+	// ExpiresAt ไม่ระบุคือใช้อายุเริ่มต้นของระบบ
+	ExpiresAt *time.Time `json:"expires_at"`
+	// Example: "new reviewer team"
+	Note string `json:"note"`
+}
+
+// CreatedInvite is the response of creating an invite; Code is shown only once
+type CreatedInvite struct {
+	entities.Invite
+	Code string `json:"code"`
+}
+
+// InviteDetail is an invite with the accounts that registered with it
+type InviteDetail struct {
+	entities.Invite
+	Redemptions []*entities.InviteRedemption `json:"redemptions"`
+}
+
+// inviteErrorStatus แปลง error จาก repository เป็น HTTP status
+func inviteErrorStatus(err error) int {
+	if errors.Is(err, repository.ErrInviteNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+// CreateInvite สร้างรหัสเชิญ (admin)
+// @Summary สร้างรหัสเชิญ
+// @Description สร้างรหัสสำหรับสมัครสมาชิกเมื่อเปิดรับสมัครแบบเชิญเท่านั้น ผู้ที่สมัครด้วยรหัสจะได้บทบาทตามรหัส รหัสแสดงครั้งเดียวในคำตอบนี้ ระบบเก็บไว้เฉพาะ hash บทบาทของรหัสต้องไม่มีสิทธิ์ที่ผู้สร้างไม่มี
+// @Tags Invites
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param requestPayload body CreateInvitePayload true "Invite"
+// @Success 201 {object} CreatedInvite "Created invite"
+// @Failure 403 {object} utils.Problem "Forbidden"
+// @Failure 422 {object} utils.Problem "Invalid fields"
+// @Router /api/v1/admin/invites [post]
+func (h *Handler) CreateInvite(c *fiber.Ctx) error {
+	claims, ok := middlewares.ClaimsFromContext(c)
+	if !ok {
+		return utils.ErrorJSON(c, errors.New("unauthorized"), http.StatusUnauthorized)
+	}
+
+	var payload CreateInvitePayload
+	if err := utils.ReadJSON(c, &payload); err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	now := time.Now()
+	errs := utils.FieldErrors{}
+
+	role := payload.Role
+	if role == "" {
+		role = rbac.RoleViewer
+	}
+	if !rbac.ValidRole(role) {
+		errs.Add("role", fmt.Errorf("unknown role %s", role))
+	}
+
+	maxUses := 1
+	if payload.MaxUses != nil {
+		maxUses = *payload.MaxUses
+		if maxUses < 1 || maxUses > maxInviteUses {
+			errs.Add("max_uses", fmt.Errorf("max_uses must be between 1 and %d", maxInviteUses))
+		}
+	}
+
+	expiresAt := now.Add(h.App.InviteTTL)
+	if payload.ExpiresAt != nil {
+		expiresAt = *payload.ExpiresAt
+		if !expiresAt.After(now) {
+			errs.Add("expires_at", errors.New("expires_at must be in the future"))
+		}
+	}
+
+	note := strings.TrimSpace(payload.Note)
+	if utf8.RuneCountInString(note) > maxInviteNoteLength {
+		errs.Add("note", fmt.Errorf("note must be at most %d characters", maxInviteNoteLength))
+	}
+
+	if len(errs) > 0 {
+		return utils.ValidationErrorJSON(c, errs)
+	}
+
+	// รหัสเชิญให้สิทธิ์ของบทบาทกับผู้สมัคร จึงต้องไม่ให้สิทธิ์เกินที่ผู้สร้างมี
+	for _, p := range rbac.RolePermissions[role] {
+		if !claims.HasPermission(p) {
+			return utils.ErrorJSON(c, fmt.Errorf("cannot invite users with role %s", role), http.StatusForbidden)
+		}
+	}
+
+	token, err := middlewares.GenerateOpaqueToken()
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+	code := inviteCodePrefix + token
+
+	invite := entities.Invite{
+		Prefix:    code[:invitePrefixLength],
+		CodeHash:  middlewares.HashToken(code),
+		Role:      role,
+		Note:      note,
+		MaxUses:   maxUses,
+		CreatedAt: now,
+		ExpiresAt: expiresAt,
+	}
+	if userID, err := claims.UserID(); err == nil {
+		invite.CreatedBy = &userID
+	}
+
+	invite.ID, err = h.App.DB.InsertInvite(invite)
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+
+	return utils.WriteJSON(c, fiber.StatusCreated, CreatedInvite{Invite: invite, Code: code})
+}
+
+// AllInvites แสดงรายการรหัสเชิญ (admin)
+// @Summary แสดงรายการรหัสเชิญ
+// @Description แสดงรหัสเชิญล่าสุดก่อน รวมที่หมดอายุ ใช้ครบ หรือถูก revoke แล้ว ไม่แสดงตัวรหัส มีเฉพาะ prefix
+// @Tags Invites
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "จำนวนรายการ (ค่าเริ่มต้น 50 สูงสุด 200)"
+// @Param offset query int false "ข้ามกี่รายการ"
+// @Success 200 {array} entities.Invite "Invites"
+// @Router /api/v1/admin/invites [get]
+func (h *Handler) AllInvites(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", defaultInviteLimit)
+	if limit <= 0 || limit > maxInviteLimit {
+		limit = defaultInviteLimit
+	}
+	offset := c.QueryInt("offset", 0)
+	if offset < 0 {
+		offset = 0
+	}
+
+	invites, err := h.App.DB.AllInvites(limit, offset)
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+
+	return utils.WriteJSON(c, fiber.StatusOK, invites)
+}
+
+// GetInvite แสดงรหัสเชิญพร้อมผู้ที่สมัครด้วยรหัสนี้ (admin)
+// @Summary แสดงรหัสเชิญและการใช้รหัส
+// @Description แสดงรหัสเชิญและรายการผู้ใช้ที่สมัครด้วยรหัสนี้ตามลำดับเวลา
+// @Tags Invites
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Invite ID"
+// @Success 200 {object} InviteDetail "Invite"
+// @Failure 404 {object} utils.Problem "Not Found"
+// @Router /api/v1/admin/invites/{id} [get]
+func (h *Handler) GetInvite(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	invite, err := h.App.DB.Invite(id)
+	if err != nil {
+		return utils.ErrorJSON(c, err, inviteErrorStatus(err))
+	}
+
+	redemptions, err := h.App.DB.InviteRedemptions(id)
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+
+	return utils.WriteJSON(c, fiber.StatusOK, InviteDetail{Invite: *invite, Redemptions: redemptions})
+}
+
+// RevokeInvite ยกเลิกรหัสเชิญ (admin)
+// @Summary ยกเลิกรหัสเชิญ
+// @Description ทำให้รหัสใช้สมัครไม่ได้อีกทันที ผู้ใช้ที่สมัครไปแล้วไม่ได้รับผลกระทบ
+// @Tags Invites
+// @Security BearerAuth
+// @Param id path int true "Invite ID"
+// @Success 204 "Revoked"
+// @Failure 404 {object} utils.Problem "Not Found"
+// @Router /api/v1/admin/invites/{id} [delete]
+func (h *Handler) RevokeInvite(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	if err := h.App.DB.RevokeInvite(id); err != nil {
+		return utils.ErrorJSON(c, err, inviteErrorStatus(err))
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
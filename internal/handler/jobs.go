@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/NakarinFIgo/Movies-App/internal/entities"
+	"github.com/NakarinFIgo/Movies-App/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultJobLimit = 50
+	maxJobLimit     = 200
+)
+
+// AllJobs แสดงรายการ job ในคิว
+// @Summary แสดงรายการ job ในคิว
+// @Description ดึง job ล่าสุด กรองตามสถานะ pending, running, succeeded หรือ dead ได้
+// @Tags Jobs
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "สถานะของ job" Enums(pending, running, succeeded, dead)
+// @Param limit query int false "จำนวนรายการ (ค่าเริ่มต้น 50 สูงสุด 200)"
+// @Param offset query int false "ข้ามกี่รายการ"
+// @Success 200 {array} entities.Job "List of jobs"
+// @Failure 400 {object} utils.Problem "Bad Request"
+// @Failure 500 {object} utils.Problem "Internal Server Error"
+// @Router /api/v1/admin/jobs [get]
+func (h *Handler) AllJobs(c *fiber.Ctx) error {
+	status := c.Query("status")
+	switch status {
+	case "", entities.JobStatusPending, entities.JobStatusRunning, entities.JobStatusSucceeded, entities.JobStatusDead:
+	default:
+		return utils.ErrorJSON(c, errors.New("invalid status"))
+	}
+
+	limit := c.QueryInt("limit", defaultJobLimit)
+	if limit <= 0 || limit > maxJobLimit {
+		limit = defaultJobLimit
+	}
+	offset := c.QueryInt("offset", 0)
+	if offset < 0 {
+		offset = 0
+	}
+
+	jobs, err := h.App.DB.AllJobs(status, limit, offset)
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+
+	return utils.WriteJSON(c, fiber.StatusOK, jobs)
+}
+
+// JobStats แสดงจำนวน job แยกตามสถานะ
+// @Summary แสดงจำนวน job แยกตามสถานะ
+// @Description นับจำนวน job ในแต่ละสถานะ ใช้ดูว่าคิวค้างหรือมี job ที่ dead หรือไม่
+// @Tags Jobs
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]int64 "Job counts" example({"pending":0,"running":1,"succeeded":42,"dead":2})
+// @Failure 500 {object} utils.Problem "Internal Server Error"
+// @Router /api/v1/admin/jobs/stats [get]
+func (h *Handler) JobStats(c *fiber.Ctx) error {
+	stats, err := h.App.DB.JobStats()
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+
+	return utils.WriteJSON(c, fiber.StatusOK, stats)
+}
+
+// GetJob แสดงรายละเอียดของ job
+// @Summary แสดงรายละเอียดของ job
+// @Description ดึง job ตาม ID รวมถึงจำนวนครั้งที่ลองและ error ล่าสุด
+// @Tags Jobs
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Job ID"
+// @Success 200 {object} entities.Job "Job"
+// @Failure 404 {object} utils.Problem "Not Found"
+// @Router /api/v1/admin/jobs/{id} [get]
+func (h *Handler) GetJob(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	job, err := h.App.DB.OneJob(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ErrorJSON(c, err, http.StatusNotFound)
+		}
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+
+	return utils.WriteJSON(c, fiber.StatusOK, job)
+}
+
+// RetryJob สั่งรัน job ใหม่
+// @Summary สั่งรัน job ใหม่
+// @Description คืน job ที่ dead กลับเข้าคิวโดยเริ่มนับจำนวนครั้งใหม่และล้าง error เดิม job ในสถานะอื่นจะตอบ 409
+// @Tags Jobs
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Job ID"
+// @Success 202 {object} map[string]interface{} "Job requeued" example({"message":"job requeued"})
+// @Failure 404 {object} utils.Problem "Not Found"
+// @Failure 409 {object} utils.Problem "Job is not dead"
+// @Router /api/v1/admin/jobs/{id}/retry [post]
+func (h *Handler) RetryJob(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	if err := h.App.DB.RequeueJob(id); err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	resp := utils.JSONResponse{
+		Error:   false,
+		Message: "job requeued",
+	}
+
+	return utils.WriteJSON(c, fiber.StatusAccepted, resp)
+}
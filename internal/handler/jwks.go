@@ -0,0 +1,20 @@
+package handler
+
+import (
+	"github.com/NakarinFIgo/Movies-App/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// JWKS เผยแพร่ public key ที่ใช้ตรวจ access token
+// @Summary public key สำหรับตรวจ JWT
+// @Description แสดง public key ทุกตัวที่ยังใช้ตรวจ token ได้ในรูป JSON Web Key Set ใช้ kid ใน header ของ token เลือกกุญแจ
+// @Description ถ้า server ยังใช้ HS256 จะไม่มีกุญแจในรายการ
+// @Tags Authentication
+// @Produce json
+// @Success 200 {object} jwtkeys.JWKS
+// @Router /.well-known/jwks.json [get]
+func (h *Handler) JWKS(c *fiber.Ctx) error {
+	// ให้ cache ได้ไม่นาน service อื่นจะเห็นกุญแจใหม่ภายในไม่กี่นาทีหลัง rotate
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return utils.WriteJSON(c, fiber.StatusOK, h.App.Auth.JWKS())
+}
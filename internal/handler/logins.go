@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/NakarinFIgo/Movies-App/internal/entities"
+	"github.com/NakarinFIgo/Movies-App/internal/loginguard"
+	"github.com/NakarinFIgo/Movies-App/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// รหัส error ที่ Login ตอบเมื่อถูกหน่วงหรือถูกล็อก
+const (
+	CodeLoginThrottled = "login_throttled"
+	CodeLoginLocked    = "login_locked"
+)
+
+const loginAttemptsLimit = 50
+
+// UnlockLoginPayload is the optional request payload for also unlocking an IP address
+type UnlockLoginPayload struct {
+	// Example: "203.0.113.7"
+	IPAddress string `json:"ip_address"`
+}
+
+// recordLoginAttempt บันทึกการ login ถ้าบันทึกไม่ได้จะ log ไว้แต่ไม่ทำให้ login ล้มเหลว
+func (h *Handler) recordLoginAttempt(c *fiber.Ctx, email string, userID *int, result string) {
+	attempt := entities.LoginAttempt{
+		Email:     email,
+		UserID:    userID,
+		IPAddress: c.IP(),
+		UserAgent: userAgent(c),
+		Result:    result,
+	}
+	if err := h.App.DB.RecordLoginAttempt(attempt); err != nil {
+		log.Println("login attempt:", err)
+	}
+}
+
+// resetLoginFailures ล้างตัวนับการ login ผิดของบัญชีหลังยืนยันตัวตนสำเร็จ
+func (h *Handler) resetLoginFailures(email string, userID int) {
+	if err := h.App.LoginGuard.Success(email); err != nil {
+		log.Printf("login guard: failed to reset counter of user %d: %v", userID, err)
+	}
+}
+
+// loginFailed นับการ login ผิดและตอบเหมือนกันไม่ว่าจะไม่มีบัญชีหรือรหัสผ่านผิด
+func (h *Handler) loginFailed(c *fiber.Ctx, email string, userID *int) error {
+	h.recordLoginAttempt(c, email, userID, entities.LoginInvalidCredentials)
+
+	if err := h.App.LoginGuard.Failure(email, c.IP()); err != nil {
+		return utils.ErrorJSON(c, err, fiber.StatusInternalServerError)
+	}
+
+	return utils.ErrorJSON(c, errors.New("invalid credentials"), fiber.StatusBadRequest)
+}
+
+// loginBlocked ตอบ 429 พร้อม Retry-After เมื่อบัญชีหรือ IP ยังต้องรอ
+func (h *Handler) loginBlocked(c *fiber.Ctx, email string, decision loginguard.Decision) error {
+	result, code := entities.LoginThrottled, CodeLoginThrottled
+	if decision.Locked {
+		result, code = entities.LoginLocked, CodeLoginLocked
+	}
+
+	h.recordLoginAttempt(c, email, nil, result)
+
+	seconds := int(math.Ceil(decision.RetryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(seconds))
+
+	return utils.ErrorCodeJSON(c, errors.New("too many failed login attempts, try again later"), code, fiber.StatusTooManyRequests)
+}
+
+// UnlockUserLogin ปลดล็อกการ login ของผู้ใช้ (admin)
+// @Summary ปลดล็อกการ login ของผู้ใช้
+// @Description ล้างตัวนับการ login ผิดของบัญชี ทำให้ login ได้ทันที ถ้าระบุ ip_address จะปลดล็อก IP นั้นด้วย
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param requestPayload body UnlockLoginPayload false "IP address to unlock"
+// @Success 200 {object} map[string]interface{} "Unlocked" example({"message":"login unlocked"})
+// @Failure 404 {object} utils.Problem "Not Found"
+// @Router /api/v1/admin/users/{id}/unlock [post]
+func (h *Handler) UnlockUserLogin(c *fiber.Ctx) error {
+	userID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	var payload UnlockLoginPayload
+	if len(c.Body()) > 0 {
+		if err := utils.ReadJSON(c, &payload); err != nil {
+			return utils.ErrorJSON(c, err)
+		}
+	}
+
+	user, err := h.App.DB.GetUserByID(userID)
+	if err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	if err := h.App.LoginGuard.Unlock(user.Email); err != nil {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+	if payload.IPAddress != "" {
+		if err := h.App.LoginGuard.UnlockIP(payload.IPAddress); err != nil {
+			return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+		}
+	}
+
+	resp := utils.JSONResponse{
+		Error:   false,
+		Message: "login unlocked",
+	}
+
+	return utils.WriteJSON(c, fiber.StatusOK, resp)
+}
+
+// UserLoginAttempts แสดงประวัติการ login ของผู้ใช้ (admin)
+// @Summary แสดงประวัติการ login ของผู้ใช้
+// @Description แสดงการ login ล่าสุดของบัญชี ทั้งที่สำเร็จและไม่สำเร็จ พร้อม IP และ user agent
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {array} entities.LoginAttempt "Login attempts"
+// @Failure 404 {object} utils.Problem "Not Found"
+// @Router /api/v1/admin/users/{id}/login-attempts [get]
+func (h *Handler) UserLoginAttempts(c *fiber.Ctx) error {
+	userID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	user, err := h.App.DB.GetUserByID(userID)
+	if err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	attempts, err := h.App.DB.LoginAttempts(user.Email, loginAttemptsLimit)
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+
+	return utils.WriteJSON(c, fiber.StatusOK, attempts)
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/NakarinFIgo/Movies-App/configs"
+	"github.com/NakarinFIgo/Movies-App/internal/entities"
+	"github.com/NakarinFIgo/Movies-App/internal/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+// userLookupDB คืน err จาก GetUserByID ทุกครั้ง
+type userLookupDB struct {
+	repository.DatabaseRepo
+	err error
+}
+
+func (db userLookupDB) GetUserByID(id int) (*entities.User, error) {
+	return nil, db.err
+}
+
+func TestLoginAdminUserLookupErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "not found", err: repository.ErrUserNotFound, want: http.StatusNotFound},
+		{name: "database error", err: context.DeadlineExceeded, want: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Handler{App: configs.Application{DB: userLookupDB{err: tt.err}}}
+
+			app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+			app.Post("/users/:id/unlock", h.UnlockUserLogin)
+			app.Get("/users/:id/login-attempts", h.UserLoginAttempts)
+
+			for _, req := range []*http.Request{
+				httptest.NewRequest(http.MethodPost, "/users/7/unlock", nil),
+				httptest.NewRequest(http.MethodGet, "/users/7/login-attempts", nil),
+			} {
+				resp, err := app.Test(req)
+				if err != nil {
+					t.Fatal(err)
+				}
+				resp.Body.Close()
+
+				if resp.StatusCode != tt.want {
+					t.Errorf("%s %s: status = %d, want %d", req.Method, req.URL.Path, resp.StatusCode, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,471 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/NakarinFIgo/Movies-App/internal/credentials"
+	"github.com/NakarinFIgo/Movies-App/internal/entities"
+	"github.com/NakarinFIgo/Movies-App/internal/repository"
+	"github.com/NakarinFIgo/Movies-App/pkg/mailer"
+	"github.com/NakarinFIgo/Movies-App/pkg/middlewares"
+	"github.com/NakarinFIgo/Movies-App/pkg/rbac"
+	"github.com/NakarinFIgo/Movies-App/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// UserProfile is the public view of a user; it never includes the password hash
+type UserProfile struct {
+	ID          int      `json:"id"`
+	FirstName   string   `json:"first_name"`
+	LastName    string   `json:"last_name"`
+	Email       string   `json:"email"`
+	Role        string   `json:"role"`
+	Permissions []string `json:"permissions"`
+	// DeletionScheduledAt มีค่าเมื่อผู้ใช้ขอลบบัญชี บัญชีจะถูกลบเมื่อถึงเวลานี้
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"`
+}
+
+func userProfile(user *entities.User) UserProfile {
+	return UserProfile{
+		ID:          user.ID,
+		FirstName:   user.FirstName,
+		LastName:    user.LastName,
+		Email:       user.Email,
+		Role:        user.Role,
+		Permissions: rbac.Effective(user.Role, user.Permissions),
+
+		DeletionScheduledAt: user.DeletionScheduledAt,
+	}
+}
+
+// maxNameLength คือความยาวของคอลัมน์ first_name และ last_name
+const maxNameLength = 255
+
+// checkName ตัดช่องว่างของชื่อและเพิ่ม error ของ field ถ้าชื่อว่างหรือยาวเกินคอลัมน์
+func checkName(errs utils.FieldErrors, field, name string) string {
+	name = strings.TrimSpace(name)
+	switch {
+	case name == "":
+		errs.Add(field, fmt.Errorf("%s is required", field))
+	case utf8.RuneCountInString(name) > maxNameLength:
+		errs.Add(field, fmt.Errorf("%s must be at most %d characters", field, maxNameLength))
+	}
+	return name
+}
+
+// Me แสดงข้อมูลของผู้ใช้ที่ login อยู่
+// @Summary แสดงข้อมูลของผู้ใช้ที่ login อยู่
+// @Description ดึงข้อมูลผู้ใช้เจ้าของ access token พร้อมบทบาทและสิทธิ์ปัจจุบัน
+// @Tags Authentication
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} UserProfile "Current user"
+// @Failure 401 {object} utils.Problem "Unauthorized"
+// @Router /api/v1/me [get]
+func (h *Handler) Me(c *fiber.Ctx) error {
+	userID, ok := middlewares.UserIDFromContext(c)
+	if !ok {
+		return utils.ErrorJSON(c, errors.New("unauthorized"), http.StatusUnauthorized)
+	}
+
+	user, err := h.App.DB.GetUserByID(userID)
+	if err != nil {
+		return utils.ErrorJSON(c, errors.New("unknown user"), http.StatusUnauthorized)
+	}
+
+	return utils.WriteJSON(c, fiber.StatusOK, userProfile(user))
+}
+
+// UpdateProfilePayload is the request payload for updating the current user; omitted fields are unchanged
+type UpdateProfilePayload struct {
+	// Example: "John"
+	FirstName *string `json:"first_name"`
+	// Example: "Doe"
+	LastName *string `json:"last_name"`
+	// อีเมลใหม่ จะเปลี่ยนหลังยืนยันจากลิงก์ที่ส่งไปที่อีเมลใหม่
+	// Example: "john.new@example.com"
+	Email *string `json:"email"`
+}
+
+// ChangePasswordPayload is the request payload for changing the current user's password
+type ChangePasswordPayload struct {
+	// Required: true
+	CurrentPassword string `json:"current_password"`
+	// Required: true
+	NewPassword string `json:"new_password"`
+}
+
+// DeleteAccountPayload is the request payload for deleting the current user's account
+type DeleteAccountPayload struct {
+	// Required: true
+	Password string `json:"password"`
+}
+
+// UpdateMe แก้ไขข้อมูลของผู้ใช้ที่ login อยู่
+// @Summary แก้ไขชื่อและอีเมลของผู้ใช้ที่ login อยู่
+// @Description แก้ไขเฉพาะ field ที่ส่งมา การเปลี่ยนอีเมลจะส่งลิงก์ยืนยันไปที่อีเมลใหม่และแจ้งอีเมลเดิม อีเมลของบัญชีจะเปลี่ยนเมื่อกดลิงก์แล้วเท่านั้น
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param requestPayload body UpdateProfilePayload true "Fields to change"
+// @Success 200 {object} map[string]interface{} "Updated profile" example({"message":"profile updated","data":{"id":1}})
+// @Failure 400 {object} utils.Problem "Bad Request"
+// @Failure 401 {object} utils.Problem "Unauthorized"
+// @Failure 422 {object} utils.Problem "Invalid fields"
+// @Failure 429 {object} utils.Problem "Too many email changes"
+// @Router /api/v1/me [patch]
+func (h *Handler) UpdateMe(c *fiber.Ctx) error {
+	user, err := h.currentUser(c)
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusUnauthorized)
+	}
+
+	var payload UpdateProfilePayload
+	if err := utils.ReadJSON(c, &payload); err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	errs := utils.FieldErrors{}
+	firstName, lastName := user.FirstName, user.LastName
+	if payload.FirstName != nil {
+		firstName = checkName(errs, "first_name", *payload.FirstName)
+	}
+	if payload.LastName != nil {
+		lastName = checkName(errs, "last_name", *payload.LastName)
+	}
+
+	var newEmail string
+	if payload.Email != nil {
+		email, err := credentials.NormalizeEmail(*payload.Email)
+		if err != nil {
+			errs.Add("email", err)
+		} else if !strings.EqualFold(email, user.Email) {
+			newEmail = email
+		}
+	}
+	if len(errs) > 0 {
+		return utils.ValidationErrorJSON(c, errs)
+	}
+
+	// ตรวจอีเมลก่อนบันทึกชื่อ request ที่ผิดจะได้ไม่เปลี่ยนอะไรเลย
+	if newEmail != "" {
+		if existing, _ := h.App.DB.GetUserByEmail(newEmail); existing != nil {
+			return utils.ValidationErrorJSON(c, utils.FieldErrors{"email": repository.ErrEmailTaken.Error()})
+		}
+
+		now := time.Now()
+		recent, err := h.App.DB.CountEmailVerificationsSince(user.ID, now.Add(-verificationResendInterval))
+		if err != nil {
+			return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+		}
+		hourly, err := h.App.DB.CountEmailVerificationsSince(user.ID, now.Add(-time.Hour))
+		if err != nil {
+			return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+		}
+		if recent > 0 || hourly >= verificationHourlyLimit {
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(verificationResendInterval.Seconds())))
+			return utils.ErrorJSON(c, errors.New("too many email changes, try again later"), http.StatusTooManyRequests)
+		}
+	}
+
+	if firstName != user.FirstName || lastName != user.LastName {
+		if err := h.App.DB.UpdateUserProfile(user.ID, firstName, lastName); err != nil {
+			return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+		}
+		user.FirstName, user.LastName = firstName, lastName
+	}
+
+	message := "profile updated"
+
+	if newEmail != "" {
+		token, err := middlewares.GenerateOpaqueToken()
+		if err != nil {
+			return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+		}
+
+		verification := entities.EmailVerification{
+			UserID:    user.ID,
+			TokenHash: middlewares.HashToken(token),
+			NewEmail:  &newEmail,
+			ExpiresAt: time.Now().Add(h.App.EmailVerificationTTL),
+		}
+		if err := h.App.DB.InsertEmailVerification(verification); err != nil {
+			return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+		}
+
+		go h.sendEmailChange(user, token, newEmail)
+
+		message = "profile updated, check your new email address to confirm the change"
+	}
+
+	resp := utils.JSONResponse{
+		Error:   false,
+		Message: message,
+		Data:    userProfile(user),
+	}
+
+	return utils.WriteJSON(c, fiber.StatusOK, resp)
+}
+
+// confirmPassword ตรวจรหัสผ่านปัจจุบันก่อนทำรายการที่สำคัญ ใช้ตัวนับเดียวกับ login
+// access token ที่หลุดไปจึงใช้เดารหัสผ่านไม่ได้ ถ้าไม่ผ่านจะเขียน response แล้วคืน false
+func (h *Handler) confirmPassword(c *fiber.Ctx, user *entities.User, password string) (bool, error) {
+	decision, err := h.App.LoginGuard.Check(user.Email, c.IP())
+	if err != nil {
+		return false, utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+	if !decision.Allowed {
+		return false, h.loginBlocked(c, user.Email, decision)
+	}
+
+	valid, err := user.PasswordMatches(password)
+	if err != nil || !valid {
+		if err := h.App.LoginGuard.Failure(user.Email, c.IP()); err != nil {
+			return false, utils.ErrorJSON(c, err, http.StatusInternalServerError)
+		}
+		return false, utils.ErrorJSON(c, errors.New("current password is incorrect"))
+	}
+
+	h.resetLoginFailures(user.Email, user.ID)
+	return true, nil
+}
+
+// ChangeMyPassword เปลี่ยนรหัสผ่านของผู้ใช้ที่ login อยู่
+// @Summary เปลี่ยนรหัสผ่าน
+// @Description ต้องใส่รหัสผ่านปัจจุบัน หลังเปลี่ยนแล้วทุก session อื่นจะถูก revoke ส่วน session ที่ใช้เรียก API นี้ยังใช้ได้ต่อ
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param requestPayload body ChangePasswordPayload true "Current and new password"
+// @Success 200 {object} map[string]interface{} "Password changed" example({"message":"password changed","data":{"revoked":2}})
+// @Failure 400 {object} utils.Problem "Bad Request"
+// @Failure 422 {object} utils.Problem "New password does not meet the policy"
+// @Failure 401 {object} utils.Problem "Unauthorized"
+// @Failure 429 {object} utils.Problem "Too many failed attempts, see Retry-After"
+// @Router /api/v1/me/password [post]
+func (h *Handler) ChangeMyPassword(c *fiber.Ctx) error {
+	user, err := h.currentUser(c)
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusUnauthorized)
+	}
+
+	var payload ChangePasswordPayload
+	if err := utils.ReadJSON(c, &payload); err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	if err := h.App.PasswordPolicy.Check(payload.NewPassword, user.Email); err != nil {
+		return utils.ValidationErrorJSON(c, utils.FieldErrors{"new_password": err.Error()})
+	}
+
+	if ok, err := h.confirmPassword(c, user, payload.CurrentPassword); !ok {
+		return err
+	}
+
+	hashedPassword, err := h.App.PasswordPolicy.Hash(payload.NewPassword)
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+
+	var sessionID string
+	if claims, ok := middlewares.ClaimsFromContext(c); ok {
+		sessionID = claims.SessionID
+	}
+
+	revoked, err := h.App.DB.ChangePassword(user.ID, hashedPassword, sessionID)
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+
+	resp := utils.JSONResponse{
+		Error:   false,
+		Message: "password changed",
+		Data:    fiber.Map{"revoked": revoked},
+	}
+
+	return utils.WriteJSON(c, fiber.StatusOK, resp)
+}
+
+// DeleteMe ขอลบบัญชีของผู้ใช้ที่ login อยู่
+// @Summary ขอลบบัญชี
+// @Description ต้องใส่รหัสผ่านปัจจุบัน บัญชีจะถูกลบถาวรเมื่อพ้นช่วงผ่อนผัน ระหว่างนั้นทุก session ถูก revoke แต่ยัง login เพื่อยกเลิกได้ที่ DELETE /api/v1/me/deletion
+// @Description admin คนสุดท้ายลบบัญชีไม่ได้ ต้องตั้ง admin คนอื่นก่อน
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param requestPayload body DeleteAccountPayload true "Current password"
+// @Success 202 {object} map[string]interface{} "Deletion scheduled" example({"message":"account scheduled for deletion","data":{"deletion_scheduled_at":"2026-11-17T12:00:00Z"}})
+// @Failure 400 {object} utils.Problem "Bad Request"
+// @Failure 401 {object} utils.Problem "Unauthorized"
+// @Failure 409 {object} utils.Problem "Last admin"
+// @Failure 429 {object} utils.Problem "Too many failed attempts, see Retry-After"
+// @Router /api/v1/me [delete]
+func (h *Handler) DeleteMe(c *fiber.Ctx) error {
+	user, err := h.currentUser(c)
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusUnauthorized)
+	}
+
+	var payload DeleteAccountPayload
+	if err := utils.ReadJSON(c, &payload); err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	if ok, err := h.confirmPassword(c, user, payload.Password); !ok {
+		return err
+	}
+
+	at := time.Now().Add(h.App.AccountDeletionGrace)
+	if err := h.App.DB.ScheduleUserDeletion(user.ID, at); err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	c.Cookie(h.App.Auth.GetExpiredRefreshCookie())
+
+	go h.sendDeletionNotice(user, at)
+
+	resp := utils.JSONResponse{
+		Error:   false,
+		Message: "account scheduled for deletion",
+		Data:    fiber.Map{"deletion_scheduled_at": at},
+	}
+
+	return utils.WriteJSON(c, fiber.StatusAccepted, resp)
+}
+
+// sendDeletionNotice แจ้งผู้ใช้ว่าบัญชีจะถูกลบเมื่อใดและยกเลิกได้อย่างไร
+func (h *Handler) sendDeletionNotice(user *entities.User, at time.Time) {
+	ctx, cancel := context.WithTimeout(context.Background(), mailTimeout)
+	defer cancel()
+
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: "Your account will be deleted",
+		Body: fmt.Sprintf("Hi %s,\n\nYour account and its data will be deleted permanently on %s.\n\nIf you change your mind, sign in before then and cancel the deletion from your account settings.\n",
+			user.FirstName, at.UTC().Format(time.RFC1123)),
+	}
+	if err := h.App.Mailer.Send(ctx, msg); err != nil {
+		log.Printf("account deletion: failed to send notice to user %d: %v", user.ID, err)
+	}
+}
+
+// CancelMyDeletion ยกเลิกการลบบัญชี
+// @Summary ยกเลิกการลบบัญชี
+// @Description ยกเลิกการลบบัญชีที่ขอไว้ ใช้ได้ก่อนพ้นช่วงผ่อนผัน
+// @Tags Account
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} UserProfile "Current user"
+// @Failure 401 {object} utils.Problem "Unauthorized"
+// @Router /api/v1/me/deletion [delete]
+func (h *Handler) CancelMyDeletion(c *fiber.Ctx) error {
+	user, err := h.currentUser(c)
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusUnauthorized)
+	}
+
+	if user.DeletionScheduledAt != nil {
+		if err := h.App.DB.CancelUserDeletion(user.ID); err != nil {
+			return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+		}
+		user.DeletionScheduledAt = nil
+	}
+
+	return utils.WriteJSON(c, fiber.StatusOK, userProfile(user))
+}
+
+// ExportedProfile is the profile part of UserExport
+type ExportedProfile struct {
+	UserProfile
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// UserExport is everything stored about a user; secrets such as hashes are never included
+type UserExport struct {
+	ExportedAt   time.Time                `json:"exported_at"`
+	Profile      ExportedProfile          `json:"profile"`
+	TwoFactor    TwoFactorStatus          `json:"two_factor"`
+	Identities   []*entities.UserIdentity `json:"identities"`
+	Bookings     []*entities.Booking      `json:"bookings"`
+	Sessions     []*entities.Session      `json:"sessions"`
+	LoginHistory []*entities.LoginAttempt `json:"login_history"`
+	APIKeys      []*entities.APIKey       `json:"api_keys"`
+	AuditLog     []*entities.AuditLog     `json:"audit_log"`
+}
+
+// ExportMe ดาวน์โหลดข้อมูลทั้งหมดของผู้ใช้ที่ login อยู่
+// @Summary ดาวน์โหลดข้อมูลของฉัน
+// @Description รวมข้อมูลทั้งหมดที่เก็บเกี่ยวกับผู้ใช้เป็นไฟล์ JSON ได้แก่ โปรไฟล์ การจอง session ประวัติการ login บัญชีภายนอก API key ที่สร้าง และ audit log ของการกระทำของผู้ใช้ ไม่รวมรหัสผ่านหรือ secret ใดๆ
+// @Tags Account
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} UserExport "Export archive"
+// @Failure 401 {object} utils.Problem "Unauthorized"
+// @Router /api/v1/me/export [get]
+func (h *Handler) ExportMe(c *fiber.Ctx) error {
+	user, err := h.currentUser(c)
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusUnauthorized)
+	}
+
+	export := UserExport{
+		ExportedAt: time.Now().UTC(),
+		Profile: ExportedProfile{
+			UserProfile:     userProfile(user),
+			EmailVerifiedAt: user.EmailVerifiedAt,
+			CreatedAt:       user.CreatedAt,
+			UpdatedAt:       user.UpdatedAt,
+		},
+	}
+
+	t, err := h.userTOTP(user.ID)
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+	export.TwoFactor = TwoFactorStatus{
+		Enabled:  t != nil && t.ConfirmedAt != nil,
+		Required: h.twoFactorRequired(user),
+	}
+	if export.TwoFactor.Enabled {
+		if export.TwoFactor.RecoveryCodesRemaining, err = h.App.DB.CountRecoveryCodes(user.ID); err != nil {
+			return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+		}
+	}
+
+	if export.Identities, err = h.App.DB.UserIdentities(user.ID); err != nil {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+	if export.Bookings, err = h.App.DB.BookingsForUser(user.ID); err != nil {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+	if export.Sessions, err = h.App.DB.UserSessions(user.ID); err != nil {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+	if export.LoginHistory, err = h.App.DB.UserLoginAttempts(user.ID); err != nil {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+	if export.APIKeys, err = h.App.DB.APIKeysCreatedBy(user.ID); err != nil {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+	// limit -1 คือไม่จำกัดจำนวน
+	if export.AuditLog, err = h.App.DB.AuditLogs(entities.AuditActorUser, strconv.Itoa(user.ID), -1, 0); err != nil {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="account-%d-export.json"`, user.ID))
+
+	return utils.WriteJSON(c, fiber.StatusOK, export)
+}
@@ -0,0 +1,206 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/NakarinFIgo/Movies-App/internal/entities"
+	"github.com/NakarinFIgo/Movies-App/pkg/images"
+	"github.com/NakarinFIgo/Movies-App/pkg/storage"
+	"github.com/NakarinFIgo/Movies-App/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// ไฟล์ media ใช้ชื่อตาม hash ของเนื้อหา จึงให้ browser/CDN cache ได้ตลอดไป
+const mediaCacheControl = "public, max-age=31536000, immutable"
+
+// UploadMovieImage อัปโหลด poster หรือ backdrop ของหนัง
+// @Summary อัปโหลด poster หรือ backdrop ของหนัง
+// @Description อัปโหลดรูปแบบ multipart (JPEG, PNG หรือ WebP) ตรวจชนิดไฟล์จากเนื้อหาจริง สร้างรูปย่อหลายขนาด และตั้งเป็นรูปปัจจุบันของหนัง
+// @Tags Movies
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Movie ID"
+// @Param file formData file true "Image file"
+// @Param kind formData string false "poster หรือ backdrop (ค่าเริ่มต้น poster)"
+// @Success 201 {object} entities.MovieImage "Uploaded image with variants"
+// @Failure 400 {object} utils.Problem "Bad Request"
+// @Failure 404 {object} utils.Problem "Not Found"
+// @Failure 413 {object} utils.Problem "Payload Too Large"
+// @Failure 415 {object} utils.Problem "Unsupported Media Type"
+// @Router /api/v1/admin/movies/{id}/images [post]
+func (h *Handler) UploadMovieImage(c *fiber.Ctx) error {
+	movieID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	kind := c.FormValue("kind", entities.ImageKindPoster)
+	if kind != entities.ImageKindPoster && kind != entities.ImageKindBackdrop {
+		return utils.ErrorJSON(c, errors.New("kind must be poster or backdrop"))
+	}
+
+	if _, err := h.App.DB.OneMovie(movieID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ErrorJSON(c, err, http.StatusNotFound)
+		}
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return utils.ErrorJSON(c, errors.New("file is required"))
+	}
+
+	tooLarge := fmt.Errorf("file is larger than %d bytes", h.App.MediaMaxUploadBytes)
+	if fileHeader.Size > h.App.MediaMaxUploadBytes {
+		return utils.ErrorJSON(c, tooLarge, http.StatusRequestEntityTooLarge)
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, h.App.MediaMaxUploadBytes+1))
+	if err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+	if int64(len(data)) > h.App.MediaMaxUploadBytes {
+		return utils.ErrorJSON(c, tooLarge, http.StatusRequestEntityTooLarge)
+	}
+
+	contentType, ext, err := images.Sniff(data)
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusUnsupportedMediaType)
+	}
+
+	img, err := images.Decode(data)
+	if err != nil {
+		if errors.Is(err, images.ErrTooManyPixels) {
+			return utils.ErrorJSON(c, err, http.StatusUnprocessableEntity)
+		}
+		return utils.ErrorJSON(c, err, http.StatusUnsupportedMediaType)
+	}
+
+	variants, err := images.Resize(img, contentType, images.DefaultWidths)
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+
+	sum := sha256.Sum256(data)
+	base := fmt.Sprintf("movies/%d/%s/%s", movieID, kind, hex.EncodeToString(sum[:])[:16])
+
+	image := entities.MovieImage{
+		MovieID:     movieID,
+		Kind:        kind,
+		StorageKey:  base + ext,
+		ContentType: contentType,
+		Width:       img.Bounds().Dx(),
+		Height:      img.Bounds().Dy(),
+		SizeBytes:   len(data),
+		CreatedAt:   time.Now(),
+	}
+
+	// ชื่อไฟล์มาจาก hash ของเนื้อหา ถ้าบันทึกไม่สำเร็จจึงไม่ลบไฟล์ทิ้ง เพราะอาจเป็นไฟล์เดียวกับที่อัปโหลดไว้ก่อนแล้ว
+	ctx := c.UserContext()
+	if err := h.App.Storage.Put(ctx, image.StorageKey, bytes.NewReader(data)); err != nil {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+
+	for _, v := range variants {
+		key := fmt.Sprintf("%s_w%d%s", base, v.Width, v.Ext)
+		if err := h.App.Storage.Put(ctx, key, bytes.NewReader(v.Data)); err != nil {
+			return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+		}
+
+		image.Variants = append(image.Variants, &entities.MovieImageVariant{
+			StorageKey: key,
+			Width:      v.Width,
+			Height:     v.Height,
+		})
+	}
+
+	image.ID, err = h.App.DB.InsertMovieImage(image, h.App.Storage.URL(image.StorageKey))
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+
+	h.setImageURLs(&image)
+
+	return utils.WriteJSON(c, fiber.StatusCreated, image)
+}
+
+// MovieImages แสดงรูปที่อัปโหลดของหนัง
+// @Summary แสดงรูปที่อัปโหลดของหนัง
+// @Description ดึงรูป poster และ backdrop ที่อัปโหลดของหนังพร้อม URL ของรูปย่อแต่ละขนาด
+// @Tags Movies
+// @Produce json
+// @Param id path int true "Movie ID"
+// @Success 200 {array} entities.MovieImage "List of images"
+// @Failure 400 {object} utils.Problem "Bad Request"
+// @Router /api/v1/movies/{id}/images [get]
+func (h *Handler) MovieImages(c *fiber.Ctx) error {
+	movieID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	movieImages, err := h.App.DB.MovieImages(movieID)
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+
+	for _, image := range movieImages {
+		h.setImageURLs(image)
+	}
+
+	return utils.WriteJSON(c, fiber.StatusOK, movieImages)
+}
+
+// Media เสิร์ฟไฟล์จาก storage พร้อม cache header แบบยาว
+func (h *Handler) Media(c *fiber.Ctx) error {
+	key, err := storage.CleanKey(c.Params("*"))
+	if err != nil {
+		return c.SendStatus(fiber.StatusNotFound)
+	}
+
+	file, err := h.App.Storage.Open(c.UserContext(), key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return c.SendStatus(fiber.StatusNotFound)
+		}
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = fiber.MIMEOctetStream
+	}
+
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderCacheControl, mediaCacheControl)
+	c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+
+	// fasthttp จะปิดไฟล์ให้เองหลังส่ง response
+	return c.SendStream(file)
+}
+
+func (h *Handler) setImageURLs(image *entities.MovieImage) {
+	image.URL = h.App.Storage.URL(image.StorageKey)
+	for _, v := range image.Variants {
+		v.URL = h.App.Storage.URL(v.StorageKey)
+	}
+}
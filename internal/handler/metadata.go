@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/NakarinFIgo/Movies-App/internal/enrichment"
+	"github.com/NakarinFIgo/Movies-App/pkg/metadata"
+	"github.com/NakarinFIgo/Movies-App/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultCandidateLimit = 5
+	maxCandidateLimit     = 20
+)
+
+// ApplyCandidatePayload is the request payload for applying a metadata candidate
+type ApplyCandidatePayload struct {
+	// Required: true
+	// Example: "603"
+	ProviderID string `json:"provider_id"`
+}
+
+func (h *Handler) enricher() *enrichment.Enricher {
+	return &enrichment.Enricher{DB: h.App.DB, Provider: h.App.Metadata}
+}
+
+// metadataErrorStatus แปลง error จาก metadata provider เป็น HTTP status
+func metadataErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, enrichment.ErrNoProvider):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, metadata.ErrNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusBadGateway
+	}
+}
+
+// ImportTMDBMovie นำเข้าหนังจาก TMDB ด้วย TMDB ID
+// @Summary นำเข้าหนังจาก TMDB ด้วย TMDB ID
+// @Description ดึงชื่อ เรื่องย่อ วันฉาย ความยาว rating poster backdrop และ genre จาก TMDB แล้วบันทึกเป็นหนังใหม่ หรือ re-sync หนังที่มี tmdb_id เดียวกัน ใช้ preview=true เพื่อดูค่าที่จะบันทึกโดยยังไม่บันทึก
+// @Tags Movies
+// @Produce json
+// @Security BearerAuth
+// @Param tmdb_id path int true "TMDB movie ID"
+// @Param preview query bool false "แสดงค่าที่จะบันทึกโดยไม่บันทึก"
+// @Success 200 {object} enrichment.Proposal "Preview or re-synced movie"
+// @Success 201 {object} enrichment.Proposal "Imported movie"
+// @Failure 400 {object} utils.Problem "Bad Request"
+// @Failure 404 {object} utils.Problem "Not Found"
+// @Failure 502 {object} utils.Problem "Bad Gateway"
+// @Failure 503 {object} utils.Problem "Service Unavailable"
+// @Router /api/v1/admin/movies/import/tmdb/{tmdb_id} [post]
+func (h *Handler) ImportTMDBMovie(c *fiber.Ctx) error {
+	tmdbID, err := strconv.Atoi(c.Params("tmdb_id"))
+	if err != nil || tmdbID <= 0 {
+		return utils.ErrorJSON(c, errors.New("invalid tmdb_id"))
+	}
+
+	enricher := h.enricher()
+
+	proposal, err := enricher.Propose(c.UserContext(), tmdbID, nil)
+	if err != nil {
+		return utils.ErrorJSON(c, err, metadataErrorStatus(err))
+	}
+
+	if c.QueryBool("preview") {
+		return utils.WriteJSON(c, fiber.StatusOK, proposal)
+	}
+
+	created, err := enricher.Save(proposal)
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+
+	status := fiber.StatusOK
+	if created {
+		status = fiber.StatusCreated
+	}
+
+	return utils.WriteJSON(c, status, proposal)
+}
+
+// MetadataCandidates แสดงผลลัพธ์จาก metadata provider ที่ใกล้เคียงกับหนัง
+// @Summary แสดงผลลัพธ์จาก metadata provider ที่ใกล้เคียงกับหนัง
+// @Description ค้นหาหนังจาก provider ด้วยชื่อเรื่อง แล้วเรียงตามความใกล้เคียงของชื่อและปีที่ฉาย เพื่อให้ admin เลือกผลลัพธ์ที่ถูกต้อง
+// @Tags Movies
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Movie ID"
+// @Param limit query int false "จำนวนผลลัพธ์ (ค่าเริ่มต้น 5 สูงสุด 20)"
+// @Success 200 {array} metadata.Candidate "Ranked candidates"
+// @Failure 404 {object} utils.Problem "Not Found"
+// @Failure 502 {object} utils.Problem "Bad Gateway"
+// @Failure 503 {object} utils.Problem "Service Unavailable"
+// @Router /api/v1/admin/movies/{id}/metadata-candidates [get]
+func (h *Handler) MetadataCandidates(c *fiber.Ctx) error {
+	movieID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	if h.App.Metadata == nil {
+		return utils.ErrorJSON(c, enrichment.ErrNoProvider, http.StatusServiceUnavailable)
+	}
+
+	movie, err := h.App.DB.OneMovie(movieID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ErrorJSON(c, err, http.StatusNotFound)
+		}
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+
+	limit := c.QueryInt("limit", defaultCandidateLimit)
+	if limit <= 0 || limit > maxCandidateLimit {
+		limit = defaultCandidateLimit
+	}
+
+	results, err := h.App.Metadata.SearchMovies(c.UserContext(), movie.Title)
+	if err != nil {
+		return utils.ErrorJSON(c, err, metadataErrorStatus(err))
+	}
+
+	candidates := metadata.Rank(movie.Title, enrichment.ReleaseYear(movie), results)
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+
+	return utils.WriteJSON(c, fiber.StatusOK, candidates)
+}
+
+// ApplyMetadataCandidate ใช้ผลลัพธ์ที่ admin เลือกกับหนัง
+// @Summary ใช้ผลลัพธ์ที่ admin เลือกกับหนัง
+// @Description ดึงข้อมูลเต็มของผลลัพธ์ที่เลือกจาก provider แล้วบันทึกลงหนัง รวมถึง poster, backdrop, genre และ tmdb_id ใช้ preview=true เพื่อดูค่าที่จะบันทึกโดยยังไม่บันทึก
+// @Tags Movies
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Movie ID"
+// @Param preview query bool false "แสดงค่าที่จะบันทึกโดยไม่บันทึก"
+// @Param requestPayload body ApplyCandidatePayload true "Chosen candidate"
+// @Success 200 {object} enrichment.Proposal "Updated movie"
+// @Failure 400 {object} utils.Problem "Bad Request"
+// @Failure 404 {object} utils.Problem "Not Found"
+// @Failure 409 {object} utils.Problem "Conflict"
+// @Failure 503 {object} utils.Problem "Service Unavailable"
+// @Router /api/v1/admin/movies/{id}/metadata-candidates [post]
+func (h *Handler) ApplyMetadataCandidate(c *fiber.Ctx) error {
+	movieID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	var payload ApplyCandidatePayload
+	if err := utils.ReadJSON(c, &payload); err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	tmdbID, err := strconv.Atoi(payload.ProviderID)
+	if err != nil || tmdbID <= 0 {
+		return utils.ErrorJSON(c, errors.New("invalid provider_id"))
+	}
+
+	movie, err := h.App.DB.OneMovie(movieID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ErrorJSON(c, err, http.StatusNotFound)
+		}
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+
+	linked, err := h.App.DB.MovieByTMDBID(tmdbID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+	if linked != nil && linked.ID != movie.ID {
+		return utils.ErrorJSON(c, errors.New("another movie is already linked to this TMDB ID"), http.StatusConflict)
+	}
+
+	enricher := h.enricher()
+
+	proposal, err := enricher.Propose(c.UserContext(), tmdbID, movie)
+	if err != nil {
+		return utils.ErrorJSON(c, err, metadataErrorStatus(err))
+	}
+
+	if c.QueryBool("preview") {
+		return utils.WriteJSON(c, fiber.StatusOK, proposal)
+	}
+
+	if _, err := enricher.Save(proposal); err != nil {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+
+	return utils.WriteJSON(c, fiber.StatusOK, proposal)
+}
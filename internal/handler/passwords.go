@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/NakarinFIgo/Movies-App/internal/entities"
+	"github.com/NakarinFIgo/Movies-App/internal/repository"
+	"github.com/NakarinFIgo/Movies-App/pkg/mailer"
+	"github.com/NakarinFIgo/Movies-App/pkg/middlewares"
+	"github.com/NakarinFIgo/Movies-App/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+const mailTimeout = time.Second * 30
+
+// ForgotPasswordPayload is the request payload for requesting a password reset email
+type ForgotPasswordPayload struct {
+	// Required: true
+	// Example: "john@example.com"
+	Email string `json:"email"`
+}
+
+// ResetPasswordPayload is the request payload for setting a new password with a reset token
+type ResetPasswordPayload struct {
+	// Required: true
+	Token string `json:"token"`
+	// Required: true
+	// Example: "new-password123"
+	Password string `json:"password"`
+}
+
+// rehashPassword hash รหัสผ่านใหม่ด้วย bcrypt cost ปัจจุบันหลังผู้ใช้ login ด้วยรหัสผ่านที่ถูกต้อง
+// ถ้า hash ไม่สำเร็จจะ log ไว้แต่ไม่ทำให้ login ล้มเหลว
+func (h *Handler) rehashPassword(user *entities.User, password string) {
+	if !h.App.PasswordPolicy.NeedsRehash(user.Password) {
+		return
+	}
+
+	hash, err := h.App.PasswordPolicy.Hash(password)
+	if err != nil {
+		log.Printf("password rehash: user %d: %v", user.ID, err)
+		return
+	}
+	if err := h.App.DB.RehashPassword(user.ID, user.Password, hash); err != nil {
+		log.Printf("password rehash: user %d: %v", user.ID, err)
+		return
+	}
+	user.Password = hash
+}
+
+// ForgotPassword ขอลิงก์ตั้งรหัสผ่านใหม่
+// @Summary ขอลิงก์ตั้งรหัสผ่านใหม่
+// @Description ส่งลิงก์ตั้งรหัสผ่านใหม่ไปที่อีเมล ถ้าอีเมลนี้มีบัญชีอยู่ ตอบเหมือนกันทุกกรณีเพื่อไม่ให้รู้ว่าอีเมลใดมีบัญชี
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param requestPayload body ForgotPasswordPayload true "Email"
+// @Success 202 {object} map[string]interface{} "Accepted" example({"message":"if the email is registered, a reset link has been sent"})
+// @Failure 400 {object} utils.Problem "Bad Request"
+// @Router /api/v1/password/forgot [post]
+func (h *Handler) ForgotPassword(c *fiber.Ctx) error {
+	var payload ForgotPasswordPayload
+	if err := utils.ReadJSON(c, &payload); err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	email := strings.TrimSpace(payload.Email)
+	if email == "" {
+		return utils.ErrorJSON(c, errors.New("email is required"))
+	}
+
+	// ทำงานต่อใน background เพื่อให้เวลาตอบกลับเท่ากันไม่ว่าอีเมลจะมีบัญชีหรือไม่
+	go h.sendPasswordReset(email)
+
+	resp := utils.JSONResponse{
+		Error:   false,
+		Message: "if the email is registered, a reset link has been sent",
+	}
+
+	return utils.WriteJSON(c, fiber.StatusAccepted, resp)
+}
+
+func (h *Handler) sendPasswordReset(email string) {
+	user, err := h.App.DB.GetUserByEmail(email)
+	if err != nil {
+		return
+	}
+
+	link, err := h.passwordResetLink(user)
+	if err != nil {
+		log.Println("password reset:", err)
+		return
+	}
+
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nUse the link below to set a new password. It expires in %s and can only be used once.\n\n%s\n\nIf you did not ask to reset your password, you can ignore this email.\n",
+			user.FirstName, h.App.PasswordResetTTL, link),
+	}
+
+	h.sendPasswordResetMail(user, msg)
+}
+
+// passwordResetLink ออก reset token ใหม่ให้ผู้ใช้และคืนลิงก์สำหรับใส่ในอีเมล
+func (h *Handler) passwordResetLink(user *entities.User) (string, error) {
+	token, err := middlewares.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	reset := entities.PasswordReset{
+		UserID:    user.ID,
+		TokenHash: middlewares.HashToken(token),
+		ExpiresAt: time.Now().Add(h.App.PasswordResetTTL),
+	}
+	if err := h.App.DB.InsertPasswordReset(reset); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s/reset-password?token=%s", strings.TrimRight(h.App.FrontendURL, "/"), url.QueryEscape(token)), nil
+}
+
+func (h *Handler) sendPasswordResetMail(user *entities.User, msg mailer.Message) {
+	ctx, cancel := context.WithTimeout(context.Background(), mailTimeout)
+	defer cancel()
+
+	if err := h.App.Mailer.Send(ctx, msg); err != nil {
+		log.Printf("password reset: failed to send email to user %d: %v", user.ID, err)
+	}
+}
+
+// ResetPassword ตั้งรหัสผ่านใหม่ด้วย reset token
+// @Summary ตั้งรหัสผ่านใหม่ด้วย reset token
+// @Description ตั้งรหัสผ่านใหม่ด้วย token จากอีเมล token ใช้ได้ครั้งเดียว และทุก session ที่ login อยู่จะถูก revoke
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param requestPayload body ResetPasswordPayload true "Reset token and new password"
+// @Success 200 {object} map[string]interface{} "Password updated" example({"message":"password updated"})
+// @Failure 400 {object} utils.Problem "Bad Request"
+// @Failure 422 {object} utils.Problem "Password does not meet the policy"
+// @Router /api/v1/password/reset [post]
+func (h *Handler) ResetPassword(c *fiber.Ctx) error {
+	var payload ResetPasswordPayload
+	if err := utils.ReadJSON(c, &payload); err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	if payload.Token == "" {
+		return utils.ErrorJSON(c, repository.ErrResetTokenInvalid)
+	}
+	// ยังไม่รู้ว่า token เป็นของบัญชีใดจนกว่าจะใช้ token จึงไม่ได้ตรวจว่ารหัสผ่านมีอีเมลอยู่หรือไม่
+	if err := h.App.PasswordPolicy.Check(payload.Password, ""); err != nil {
+		return utils.ValidationErrorJSON(c, utils.FieldErrors{"password": err.Error()})
+	}
+
+	hashedPassword, err := h.App.PasswordPolicy.Hash(payload.Password)
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+
+	userID, err := h.App.DB.ResetPassword(middlewares.HashToken(payload.Token), hashedPassword)
+	if err != nil {
+		if errors.Is(err, repository.ErrResetTokenInvalid) {
+			return utils.ErrorJSON(c, err)
+		}
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+
+	// รหัสผ่านเดิมอาจรั่ว จึงบังคับให้ทุกอุปกรณ์ login ใหม่
+	if _, err := h.App.DB.RevokeUserSessions(userID); err != nil {
+		log.Printf("password reset: failed to revoke sessions of user %d: %v", userID, err)
+	}
+
+	resp := utils.JSONResponse{
+		Error:   false,
+		Message: "password updated",
+	}
+
+	return utils.WriteJSON(c, fiber.StatusOK, resp)
+}
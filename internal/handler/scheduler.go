@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/NakarinFIgo/Movies-App/internal/entities"
+	"github.com/NakarinFIgo/Movies-App/internal/scheduler"
+	"github.com/NakarinFIgo/Movies-App/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	defaultSchedulerRunLimit = 50
+	maxSchedulerRunLimit     = 200
+)
+
+// SchedulerStatus is the response of the scheduler status endpoint
+type SchedulerStatus struct {
+	Tasks []scheduler.Task         `json:"tasks"`
+	Runs  []*entities.SchedulerRun `json:"runs"`
+}
+
+// SchedulerStatus แสดง scheduled task และประวัติการรัน
+// @Summary แสดง scheduled task และประวัติการรัน
+// @Description แสดง task ทั้งหมดพร้อม schedule และเวลารันครั้งถัดไป และประวัติการรันล่าสุดจากทุก instance
+// @Tags Jobs
+// @Produce json
+// @Security BearerAuth
+// @Param task query string false "กรองประวัติตามชื่อ task"
+// @Param limit query int false "จำนวนประวัติ (ค่าเริ่มต้น 50 สูงสุด 200)"
+// @Success 200 {object} SchedulerStatus "Tasks and run history"
+// @Failure 404 {object} utils.Problem "Not Found"
+// @Failure 500 {object} utils.Problem "Internal Server Error"
+// @Router /api/v1/admin/scheduler [get]
+func (h *Handler) SchedulerStatus(c *fiber.Ctx) error {
+	var tasks []scheduler.Task
+	if h.App.Scheduler != nil {
+		tasks = h.App.Scheduler.Tasks()
+	}
+
+	task := c.Query("task")
+	if task != "" && (h.App.Scheduler == nil || !h.App.Scheduler.Has(task)) {
+		return utils.ErrorJSON(c, errors.New("unknown task"), http.StatusNotFound)
+	}
+
+	limit := c.QueryInt("limit", defaultSchedulerRunLimit)
+	if limit <= 0 || limit > maxSchedulerRunLimit {
+		limit = defaultSchedulerRunLimit
+	}
+
+	runs, err := h.App.DB.SchedulerRuns(task, limit)
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+
+	return utils.WriteJSON(c, fiber.StatusOK, SchedulerStatus{Tasks: tasks, Runs: runs})
+}
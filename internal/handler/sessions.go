@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/NakarinFIgo/Movies-App/internal/repository"
+	"github.com/NakarinFIgo/Movies-App/pkg/middlewares"
+	"github.com/NakarinFIgo/Movies-App/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// sessionErrorStatus แปลง error จาก repository เป็น HTTP status
+func sessionErrorStatus(err error) int {
+	if errors.Is(err, repository.ErrSessionNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+// listSessions ดึง session ที่ยังใช้งานได้ของผู้ใช้ และทำเครื่องหมาย session ของ token ที่เรียกอยู่
+func (h *Handler) listSessions(c *fiber.Ctx, userID int) error {
+	sessions, err := h.App.DB.ActiveSessions(userID)
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+
+	if claims, ok := middlewares.ClaimsFromContext(c); ok {
+		for _, s := range sessions {
+			s.Current = s.ID == claims.SessionID
+		}
+	}
+
+	return utils.WriteJSON(c, fiber.StatusOK, sessions)
+}
+
+func (h *Handler) revokeSession(c *fiber.Ctx, userID int, sessionID string) error {
+	if _, err := uuid.Parse(sessionID); err != nil {
+		return utils.ErrorJSON(c, repository.ErrSessionNotFound, http.StatusNotFound)
+	}
+
+	if err := h.App.DB.RevokeSession(userID, sessionID); err != nil {
+		return utils.ErrorJSON(c, err, sessionErrorStatus(err))
+	}
+
+	if claims, ok := middlewares.ClaimsFromContext(c); ok && claims.SessionID == sessionID {
+		c.Cookie(h.App.Auth.GetExpiredRefreshCookie())
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *Handler) revokeAllSessions(c *fiber.Ctx, userID int) error {
+	n, err := h.App.DB.RevokeUserSessions(userID)
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+
+	resp := utils.JSONResponse{
+		Error:   false,
+		Message: "sessions revoked",
+		Data:    fiber.Map{"revoked": n},
+	}
+
+	return utils.WriteJSON(c, fiber.StatusOK, resp)
+}
+
+// MySessions แสดงอุปกรณ์ที่ login อยู่
+// @Summary แสดงอุปกรณ์ที่ login อยู่
+// @Description แสดงการ login ที่ยังใช้งานได้ของผู้ใช้ พร้อม user agent, IP, เวลาที่ login และเวลาที่ใช้ล่าสุด
+// @Tags Sessions
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} entities.ActiveSession "Active sessions"
+// @Failure 401 {object} utils.Problem "Unauthorized"
+// @Router /api/v1/me/sessions [get]
+func (h *Handler) MySessions(c *fiber.Ctx) error {
+	userID, ok := middlewares.UserIDFromContext(c)
+	if !ok {
+		return utils.ErrorJSON(c, errors.New("unauthorized"), http.StatusUnauthorized)
+	}
+
+	return h.listSessions(c, userID)
+}
+
+// RevokeMySession ออกจากระบบบนอุปกรณ์หนึ่ง
+// @Summary ออกจากระบบบนอุปกรณ์หนึ่ง
+// @Description revoke refresh token ของ session นั้น access token ที่ออกไปแล้วยังใช้ได้จนหมดอายุ
+// @Tags Sessions
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Success 204 "Session revoked"
+// @Failure 404 {object} utils.Problem "Not Found"
+// @Router /api/v1/me/sessions/{id} [delete]
+func (h *Handler) RevokeMySession(c *fiber.Ctx) error {
+	userID, ok := middlewares.UserIDFromContext(c)
+	if !ok {
+		return utils.ErrorJSON(c, errors.New("unauthorized"), http.StatusUnauthorized)
+	}
+
+	return h.revokeSession(c, userID, c.Params("id"))
+}
+
+// RevokeMySessions ออกจากระบบทุกอุปกรณ์
+// @Summary ออกจากระบบทุกอุปกรณ์
+// @Description revoke refresh token ทุก session ของผู้ใช้ รวมถึง session ปัจจุบัน
+// @Tags Sessions
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Sessions revoked" example({"message":"sessions revoked","data":{"revoked":3}})
+// @Router /api/v1/me/sessions [delete]
+func (h *Handler) RevokeMySessions(c *fiber.Ctx) error {
+	userID, ok := middlewares.UserIDFromContext(c)
+	if !ok {
+		return utils.ErrorJSON(c, errors.New("unauthorized"), http.StatusUnauthorized)
+	}
+
+	c.Cookie(h.App.Auth.GetExpiredRefreshCookie())
+
+	return h.revokeAllSessions(c, userID)
+}
+
+// UserSessions แสดงอุปกรณ์ที่ผู้ใช้ login อยู่ (admin)
+// @Summary แสดงอุปกรณ์ที่ผู้ใช้ login อยู่
+// @Description ให้ admin ดูการ login ที่ยังใช้งานได้ของผู้ใช้คนใดก็ได้
+// @Tags Sessions
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {array} entities.ActiveSession "Active sessions"
+// @Failure 400 {object} utils.Problem "Bad Request"
+// @Router /api/v1/admin/users/{id}/sessions [get]
+func (h *Handler) UserSessions(c *fiber.Ctx) error {
+	userID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	return h.listSessions(c, userID)
+}
+
+// RevokeUserSession ออกจากระบบบนอุปกรณ์หนึ่งของผู้ใช้ (admin)
+// @Summary ออกจากระบบบนอุปกรณ์หนึ่งของผู้ใช้
+// @Description ให้ admin revoke session หนึ่งของผู้ใช้ เช่นเมื่อสงสัยว่าบัญชีถูกใช้โดยผู้อื่น
+// @Tags Sessions
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param session_id path string true "Session ID"
+// @Success 204 "Session revoked"
+// @Failure 404 {object} utils.Problem "Not Found"
+// @Router /api/v1/admin/users/{id}/sessions/{session_id} [delete]
+func (h *Handler) RevokeUserSession(c *fiber.Ctx) error {
+	userID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	return h.revokeSession(c, userID, c.Params("session_id"))
+}
+
+// RevokeUserSessions ออกจากระบบทุกอุปกรณ์ของผู้ใช้ (admin)
+// @Summary ออกจากระบบทุกอุปกรณ์ของผู้ใช้
+// @Description ให้ admin revoke ทุก session ของผู้ใช้
+// @Tags Sessions
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} map[string]interface{} "Sessions revoked" example({"message":"sessions revoked","data":{"revoked":3}})
+// @Router /api/v1/admin/users/{id}/sessions [delete]
+func (h *Handler) RevokeUserSessions(c *fiber.Ctx) error {
+	userID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	return h.revokeAllSessions(c, userID)
+}
@@ -0,0 +1,478 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NakarinFIgo/Movies-App/internal/entities"
+	"github.com/NakarinFIgo/Movies-App/internal/repository"
+	"github.com/NakarinFIgo/Movies-App/pkg/middlewares"
+	"github.com/NakarinFIgo/Movies-App/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// TheaterPayload is the request payload for creating a theater
+type TheaterPayload struct {
+	// Required: true
+	// Example: "Central World"
+	Name string `json:"name"`
+	// Example: "Bangkok"
+	Location string `json:"location"`
+}
+
+// SeatRowPayload describes one row of seats in a screen's seat map
+type SeatRowPayload struct {
+	// Required: true
+	// Example: "A"
+	Row string `json:"row"`
+	// Required: true
+	// Example: 12
+	Seats int `json:"seats"`
+	// Example: "standard"
+	Kind string `json:"kind"`
+}
+
+// ScreenPayload is the request payload for creating a screen with its seat map
+type ScreenPayload struct {
+	// Required: true
+	// Example: "Screen 1"
+	Name string           `json:"name"`
+	Rows []SeatRowPayload `json:"rows"`
+}
+
+// ShowtimePayload is the request payload for scheduling a showtime
+type ShowtimePayload struct {
+	// Required: true
+	MovieID int `json:"movie_id"`
+	// Required: true
+	ScreenID int `json:"screen_id"`
+	// Required: true
+	// Example: "2024-11-01T19:30:00+07:00"
+	StartsAt time.Time `json:"starts_at"`
+	// ถ้าไม่ระบุ จะคำนวณจาก runtime ของหนัง
+	EndsAt     *time.Time `json:"ends_at"`
+	PriceCents int        `json:"price_cents"`
+}
+
+// HoldSeatsPayload is the request payload for placing a seat hold
+type HoldSeatsPayload struct {
+	// Required: true
+	ShowtimeID int `json:"showtime_id"`
+	// Required: true
+	SeatIDs []int `json:"seat_ids"`
+}
+
+// MovieShowtimes แสดงรอบฉายของหนัง
+// @Summary แสดงรอบฉายของหนัง
+// @Description ดึงรอบฉายที่ยังไม่เริ่มของหนังตาม ID พร้อมข้อมูลโรงและสาขา
+// @Tags Showtimes
+// @Produce json
+// @Param id path int true "Movie ID"
+// @Success 200 {array} entities.Showtime "List of showtimes"
+// @Failure 400 {object} utils.Problem "Bad Request"
+// @Failure 500 {object} utils.Problem "Internal Server Error"
+// @Router /api/v1/movies/{id}/showtimes [get]
+func (h *Handler) MovieShowtimes(c *fiber.Ctx) error {
+	movieID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	showtimes, err := h.App.DB.ShowtimesForMovie(movieID, time.Now())
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+
+	return utils.WriteJSON(c, fiber.StatusOK, showtimes)
+}
+
+// ShowtimeSeats แสดงผังที่นั่งของรอบฉาย
+// @Summary แสดงผังที่นั่งของรอบฉาย
+// @Description ดึงผังที่นั่งของรอบฉายพร้อมสถานะว่าง/ไม่ว่างของแต่ละที่นั่ง
+// @Tags Showtimes
+// @Produce json
+// @Param id path int true "Showtime ID"
+// @Success 200 {array} entities.SeatAvailability "Seat map"
+// @Failure 404 {object} utils.Problem "Not Found"
+// @Router /api/v1/showtimes/{id}/seats [get]
+func (h *Handler) ShowtimeSeats(c *fiber.Ctx) error {
+	showtimeID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	seats, err := h.App.DB.SeatMap(showtimeID)
+	if err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	return utils.WriteJSON(c, fiber.StatusOK, seats)
+}
+
+// HoldSeats จองที่นั่งแบบ hold ชั่วคราว
+// @Summary จองที่นั่งแบบ hold ชั่วคราว
+// @Description hold ที่นั่งของรอบฉายไว้ตามเวลาที่กำหนด ต้องยืนยันก่อนหมดเวลา ไม่เช่นนั้นที่นั่งจะถูกปล่อย
+// @Tags Bookings
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param requestPayload body HoldSeatsPayload true "Seats to hold"
+// @Success 201 {object} entities.Booking "Held booking"
+// @Failure 409 {object} utils.Problem "Conflict"
+// @Failure 422 {object} utils.Problem "Unprocessable Entity"
+// @Router /api/v1/bookings [post]
+func (h *Handler) HoldSeats(c *fiber.Ctx) error {
+	userID, ok := middlewares.UserIDFromContext(c)
+	if !ok {
+		return utils.ErrorJSON(c, errors.New("unauthorized"), http.StatusUnauthorized)
+	}
+
+	var payload HoldSeatsPayload
+	if err := utils.ReadJSON(c, &payload); err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	// ตัดที่นั่งซ้ำออก เพื่อไม่ให้ชน unique index ของตัวเอง
+	seen := make(map[int]bool)
+	var seatIDs []int
+	for _, id := range payload.SeatIDs {
+		if !seen[id] {
+			seen[id] = true
+			seatIDs = append(seatIDs, id)
+		}
+	}
+	if len(seatIDs) == 0 {
+		return utils.ErrorJSON(c, errors.New("seat_ids is required"), http.StatusUnprocessableEntity)
+	}
+
+	booking, err := h.App.DB.HoldSeats(userID, payload.ShowtimeID, seatIDs, time.Now().Add(h.App.BookingHoldTTL))
+	if err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	return utils.WriteJSON(c, fiber.StatusCreated, booking)
+}
+
+// ConfirmBooking ยืนยันการจอง
+// @Summary ยืนยันการจอง
+// @Description ยืนยันการจองที่ยัง hold อยู่ให้เป็นการจองจริง
+// @Tags Bookings
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Booking ID"
+// @Success 200 {object} entities.Booking "Confirmed booking"
+// @Failure 404 {object} utils.Problem "Not Found"
+// @Failure 409 {object} utils.Problem "Conflict"
+// @Failure 410 {object} utils.Problem "Gone"
+// @Router /api/v1/bookings/{id}/confirm [post]
+func (h *Handler) ConfirmBooking(c *fiber.Ctx) error {
+	userID, ok := middlewares.UserIDFromContext(c)
+	if !ok {
+		return utils.ErrorJSON(c, errors.New("unauthorized"), http.StatusUnauthorized)
+	}
+
+	bookingID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	booking, err := h.App.DB.ConfirmBooking(userID, bookingID)
+	if err != nil {
+		if errors.Is(err, repository.ErrHoldExpired) {
+			return utils.ErrorJSON(c, err, http.StatusGone)
+		}
+		return utils.ErrorJSON(c, err)
+	}
+
+	return utils.WriteJSON(c, fiber.StatusOK, booking)
+}
+
+// CancelBooking ยกเลิกการจองที่ยัง hold อยู่
+// @Summary ยกเลิกการจอง
+// @Description ยกเลิกการจองที่ยัง hold อยู่และปล่อยที่นั่งคืน
+// @Tags Bookings
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Booking ID"
+// @Success 202 {object} map[string]interface{} "Booking cancelled" example({"message":"booking cancelled"})
+// @Failure 404 {object} utils.Problem "Not Found"
+// @Failure 409 {object} utils.Problem "Conflict"
+// @Router /api/v1/bookings/{id} [delete]
+func (h *Handler) CancelBooking(c *fiber.Ctx) error {
+	userID, ok := middlewares.UserIDFromContext(c)
+	if !ok {
+		return utils.ErrorJSON(c, errors.New("unauthorized"), http.StatusUnauthorized)
+	}
+
+	bookingID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	if err := h.App.DB.CancelBooking(userID, bookingID); err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	resp := utils.JSONResponse{
+		Error:   false,
+		Message: "booking cancelled",
+	}
+
+	return utils.WriteJSON(c, fiber.StatusAccepted, resp)
+}
+
+// MyBookings แสดงการจองของผู้ใช้
+// @Summary แสดงการจองของผู้ใช้
+// @Description ดึงการจองทั้งหมดของผู้ใช้ที่ login อยู่
+// @Tags Bookings
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} entities.Booking "List of bookings"
+// @Failure 500 {object} utils.Problem "Internal Server Error"
+// @Router /api/v1/bookings [get]
+func (h *Handler) MyBookings(c *fiber.Ctx) error {
+	userID, ok := middlewares.UserIDFromContext(c)
+	if !ok {
+		return utils.ErrorJSON(c, errors.New("unauthorized"), http.StatusUnauthorized)
+	}
+
+	bookings, err := h.App.DB.BookingsForUser(userID)
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+
+	return utils.WriteJSON(c, fiber.StatusOK, bookings)
+}
+
+// AllTheaters แสดงสาขาโรงภาพยนตร์ทั้งหมด
+// @Summary แสดงสาขาโรงภาพยนตร์ทั้งหมด
+// @Description ดึงสาขาโรงภาพยนตร์ทั้งหมดพร้อมโรงฉาย
+// @Tags Showtimes
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} entities.Theater "List of theaters"
+// @Failure 500 {object} utils.Problem "Internal Server Error"
+// @Router /api/v1/admin/theaters [get]
+func (h *Handler) AllTheaters(c *fiber.Ctx) error {
+	theaters, err := h.App.DB.AllTheaters()
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+
+	return utils.WriteJSON(c, fiber.StatusOK, theaters)
+}
+
+// InsertTheater เพิ่มสาขาโรงภาพยนตร์
+// @Summary เพิ่มสาขาโรงภาพยนตร์
+// @Description เพิ่มสาขาโรงภาพยนตร์ใหม่
+// @Tags Showtimes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param requestPayload body TheaterPayload true "Theater data"
+// @Success 201 {object} map[string]interface{} "Theater created" example({"message":"theater created","data":{"id":1}})
+// @Failure 400 {object} utils.Problem "Bad Request"
+// @Router /api/v1/admin/theaters [post]
+func (h *Handler) InsertTheater(c *fiber.Ctx) error {
+	var payload TheaterPayload
+	if err := utils.ReadJSON(c, &payload); err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	if strings.TrimSpace(payload.Name) == "" {
+		return utils.ErrorJSON(c, errors.New("name is required"))
+	}
+
+	theater := entities.Theater{
+		Name:      strings.TrimSpace(payload.Name),
+		Location:  payload.Location,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	newID, err := h.App.DB.InsertTheater(theater)
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+
+	resp := utils.JSONResponse{
+		Error:   false,
+		Message: "theater created",
+		Data:    fiber.Map{"id": newID},
+	}
+
+	return utils.WriteJSON(c, fiber.StatusCreated, resp)
+}
+
+// InsertScreen เพิ่มโรงฉายพร้อมผังที่นั่ง
+// @Summary เพิ่มโรงฉายพร้อมผังที่นั่ง
+// @Description เพิ่มโรงฉายในสาขา โดยสร้างที่นั่งตามแถวที่กำหนด เช่น แถว A มี 12 ที่นั่ง จะได้ A1 ถึง A12
+// @Tags Showtimes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Theater ID"
+// @Param requestPayload body ScreenPayload true "Screen and seat map"
+// @Success 201 {object} map[string]interface{} "Screen created" example({"message":"screen created","data":{"id":1}})
+// @Failure 400 {object} utils.Problem "Bad Request"
+// @Failure 404 {object} utils.Problem "Not Found"
+// @Router /api/v1/admin/theaters/{id}/screens [post]
+func (h *Handler) InsertScreen(c *fiber.Ctx) error {
+	theaterID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	var payload ScreenPayload
+	if err := utils.ReadJSON(c, &payload); err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	if strings.TrimSpace(payload.Name) == "" {
+		return utils.ErrorJSON(c, errors.New("name is required"))
+	}
+	if len(payload.Rows) == 0 {
+		return utils.ErrorJSON(c, errors.New("seat map is required"))
+	}
+
+	screen := entities.Screen{
+		TheaterID: theaterID,
+		Name:      strings.TrimSpace(payload.Name),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	rows := make(map[string]bool)
+	for _, row := range payload.Rows {
+		label := strings.ToUpper(strings.TrimSpace(row.Row))
+		if label == "" || row.Seats <= 0 {
+			return utils.ErrorJSON(c, errors.New("each row needs a label and a positive number of seats"))
+		}
+		if rows[label] {
+			return utils.ErrorJSON(c, fmt.Errorf("row %s is defined more than once", label))
+		}
+		rows[label] = true
+
+		kind := row.Kind
+		if kind == "" {
+			kind = "standard"
+		}
+
+		for n := 1; n <= row.Seats; n++ {
+			screen.Seats = append(screen.Seats, &entities.Seat{
+				RowLabel:   label,
+				SeatNumber: n,
+				Kind:       kind,
+			})
+		}
+	}
+
+	newID, err := h.App.DB.InsertScreen(screen)
+	if err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	resp := utils.JSONResponse{
+		Error:   false,
+		Message: "screen created",
+		Data:    fiber.Map{"id": newID},
+	}
+
+	return utils.WriteJSON(c, fiber.StatusCreated, resp)
+}
+
+// InsertShowtime เพิ่มรอบฉาย
+// @Summary เพิ่มรอบฉาย
+// @Description เพิ่มรอบฉายของหนังในโรงฉาย รอบที่เวลาซ้อนกันในโรงเดียวกันจะถูกปฏิเสธ
+// @Tags Showtimes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param requestPayload body ShowtimePayload true "Showtime data"
+// @Success 201 {object} map[string]interface{} "Showtime created" example({"message":"showtime created","data":{"id":1}})
+// @Failure 400 {object} utils.Problem "Bad Request"
+// @Failure 404 {object} utils.Problem "Not Found"
+// @Failure 409 {object} utils.Problem "Conflict"
+// @Router /api/v1/admin/showtimes [post]
+func (h *Handler) InsertShowtime(c *fiber.Ctx) error {
+	var payload ShowtimePayload
+	if err := utils.ReadJSON(c, &payload); err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	if payload.StartsAt.IsZero() {
+		return utils.ErrorJSON(c, errors.New("starts_at is required"))
+	}
+	if payload.PriceCents < 0 {
+		return utils.ErrorJSON(c, errors.New("price_cents must not be negative"))
+	}
+
+	movie, err := h.App.DB.OneMovie(payload.MovieID)
+	if err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	endsAt := payload.StartsAt.Add(time.Duration(movie.RunTime) * time.Minute)
+	if payload.EndsAt != nil {
+		endsAt = *payload.EndsAt
+	}
+	if !endsAt.After(payload.StartsAt) {
+		return utils.ErrorJSON(c, errors.New("ends_at must be after starts_at"))
+	}
+
+	showtime := entities.Showtime{
+		MovieID:    movie.ID,
+		ScreenID:   payload.ScreenID,
+		StartsAt:   payload.StartsAt,
+		EndsAt:     endsAt,
+		PriceCents: payload.PriceCents,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+
+	newID, err := h.App.DB.InsertShowtime(showtime)
+	if err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	resp := utils.JSONResponse{
+		Error:   false,
+		Message: "showtime created",
+		Data:    fiber.Map{"id": newID},
+	}
+
+	return utils.WriteJSON(c, fiber.StatusCreated, resp)
+}
+
+// DeleteShowtime ลบรอบฉาย
+// @Summary ลบรอบฉาย
+// @Description ลบรอบฉายที่ยังไม่มีการจอง
+// @Tags Showtimes
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Showtime ID"
+// @Success 202 {object} map[string]interface{} "Showtime deleted" example({"message":"showtime deleted"})
+// @Failure 404 {object} utils.Problem "Not Found"
+// @Failure 409 {object} utils.Problem "Conflict"
+// @Router /api/v1/admin/showtimes/{id} [delete]
+func (h *Handler) DeleteShowtime(c *fiber.Ctx) error {
+	showtimeID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	if err := h.App.DB.DeleteShowtime(showtimeID); err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	resp := utils.JSONResponse{
+		Error:   false,
+		Message: "showtime deleted",
+	}
+
+	return utils.WriteJSON(c, fiber.StatusAccepted, resp)
+}
@@ -0,0 +1,364 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NakarinFIgo/Movies-App/internal/credentials"
+	"github.com/NakarinFIgo/Movies-App/internal/entities"
+	"github.com/NakarinFIgo/Movies-App/internal/repository"
+	"github.com/NakarinFIgo/Movies-App/internal/sso"
+	"github.com/NakarinFIgo/Movies-App/pkg/middlewares"
+	"github.com/NakarinFIgo/Movies-App/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/oauth2"
+)
+
+// CodeIdentityNotLinked คือรหัส error เมื่อบัญชีจาก provider ยังไม่ได้ผูกกับผู้ใช้และสร้างผู้ใช้ใหม่ไม่ได้
+const CodeIdentityNotLinked = "identity_not_linked"
+
+const (
+	// oidcStateCookie ผูก state กับ browser ที่เริ่ม login กัน login CSRF
+	oidcStateCookie = "oidc_state"
+	oidcCookiePath  = "/api/v1/auth/oidc"
+	// oidcStateTTL คือเวลาที่ผู้ใช้มีให้ login ที่ provider ให้เสร็จ
+	oidcStateTTL = 10 * time.Minute
+)
+
+// OIDCProvider is an identity provider that users can log in with
+type OIDCProvider struct {
+	Name     string `json:"name"`
+	LoginURL string `json:"login_url"`
+}
+
+// OIDCProviders แสดง identity provider ที่ login ได้
+// @Summary identity provider ที่ login ได้
+// @Description แสดงชื่อ provider ที่ตั้งค่าไว้และ URL สำหรับเริ่ม login
+// @Tags Authentication
+// @Produce json
+// @Success 200 {array} OIDCProvider
+// @Router /api/v1/auth/oidc/providers [get]
+func (h *Handler) OIDCProviders(c *fiber.Ctx) error {
+	providers := make([]OIDCProvider, 0, len(h.App.OIDCProviders))
+	for _, name := range h.App.OIDCProviders.Names() {
+		providers = append(providers, OIDCProvider{
+			Name:     name,
+			LoginURL: oidcCookiePath + "/" + name + "/login",
+		})
+	}
+	return utils.WriteJSON(c, http.StatusOK, providers)
+}
+
+// OIDCLogin เริ่ม login ผ่าน identity provider
+// @Summary เริ่ม login ผ่าน identity provider
+// @Description สร้าง state, nonce และ PKCE verifier เก็บไว้ฝั่ง server แล้ว redirect ไปหน้า login ของ provider
+// @Tags Authentication
+// @Param provider path string true "ชื่อ provider"
+// @Success 302 "Redirect ไปที่ provider"
+// @Failure 404 {object} utils.Problem "Unknown provider"
+// @Failure 502 {object} utils.Problem "Provider unavailable"
+// @Router /api/v1/auth/oidc/{provider}/login [get]
+func (h *Handler) OIDCLogin(c *fiber.Ctx) error {
+	provider, err := h.App.OIDCProviders.Get(c.Params("provider"))
+	if err != nil {
+		return utils.ErrorJSON(c, err, fiber.StatusNotFound)
+	}
+
+	state, err := middlewares.GenerateOpaqueToken()
+	if err != nil {
+		return utils.ErrorJSON(c, err, fiber.StatusInternalServerError)
+	}
+	nonce, err := middlewares.GenerateOpaqueToken()
+	if err != nil {
+		return utils.ErrorJSON(c, err, fiber.StatusInternalServerError)
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	authURL, err := provider.AuthURL(c.UserContext(), state, nonce, verifier)
+	if err != nil {
+		log.Println("oidc:", err)
+		return utils.ErrorJSON(c, errors.New("identity provider is unavailable"), fiber.StatusBadGateway)
+	}
+
+	now := time.Now()
+	err = h.App.DB.InsertOIDCState(entities.OIDCState{
+		StateHash:    middlewares.HashToken(state),
+		Provider:     provider.Config.Name,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		CreatedAt:    now,
+		ExpiresAt:    now.Add(oidcStateTTL),
+	})
+	if err != nil {
+		return utils.ErrorJSON(c, err, fiber.StatusInternalServerError)
+	}
+
+	// ใช้ Lax เพราะ callback เป็นการ redirect ข้ามเว็บมาจาก provider cookie แบบ Strict จะไม่ถูกส่งมา
+	c.Cookie(&fiber.Cookie{
+		Name:     oidcStateCookie,
+		Value:    state,
+		Path:     oidcCookiePath,
+		Domain:   h.App.Auth.CookieDomain,
+		MaxAge:   int(oidcStateTTL.Seconds()),
+		Expires:  now.Add(oidcStateTTL),
+		SameSite: fiber.CookieSameSiteLaxMode,
+		HTTPOnly: true,
+		Secure:   true,
+	})
+
+	return c.Redirect(authURL, fiber.StatusFound)
+}
+
+// OIDCCallback รับผลการ login จาก identity provider
+// @Summary callback ของ identity provider
+// @Description ตรวจ state กับ cookie แลก code เป็น token ตรวจ ID token แล้วผูกบัญชีกับผู้ใช้ ผลลัพธ์เหมือน /api/v1/login รวมถึงการขอรหัส 2FA
+// @Description บัญชีที่ยังไม่ได้ผูกจะผูกกับผู้ใช้ที่อีเมลตรงกันและยืนยันอีเมลแล้ว หรือสร้างผู้ใช้ใหม่ถ้า provider เปิด auto create
+// @Tags Authentication
+// @Produce json
+// @Param provider path string true "ชื่อ provider"
+// @Param code query string true "Authorization code"
+// @Param state query string true "State ที่ส่งไปตอนเริ่ม login"
+// @Success 202 {object} LoginResponse "Token pairs, or TwoFactorChallenge when the account uses 2FA"
+// @Failure 401 {object} utils.Problem "Provider rejected the login"
+// @Failure 403 {object} utils.Problem "Identity not linked or account disabled"
+// @Failure 404 {object} utils.Problem "Unknown provider"
+// @Failure 422 {object} utils.Problem "Invalid state"
+// @Failure 429 {object} utils.Problem "Too many failed attempts, see Retry-After"
+// @Router /api/v1/auth/oidc/{provider}/callback [get]
+func (h *Handler) OIDCCallback(c *fiber.Ctx) error {
+	provider, err := h.App.OIDCProviders.Get(c.Params("provider"))
+	if err != nil {
+		return utils.ErrorJSON(c, err, fiber.StatusNotFound)
+	}
+
+	cookieState := c.Cookies(oidcStateCookie)
+	c.Cookie(&fiber.Cookie{
+		Name:     oidcStateCookie,
+		Path:     oidcCookiePath,
+		Domain:   h.App.Auth.CookieDomain,
+		MaxAge:   -1,
+		Expires:  time.Unix(0, 0),
+		SameSite: fiber.CookieSameSiteLaxMode,
+		HTTPOnly: true,
+		Secure:   true,
+	})
+
+	// ข้อความจาก provider มาจาก query string ที่ใครก็แก้ได้ จึง log ไว้และตอบข้อความคงที่
+	if errCode := c.Query("error"); errCode != "" {
+		log.Printf("oidc: provider %s returned error %q: %q", provider.Config.Name, errCode, c.Query("error_description"))
+		return utils.ErrorJSON(c, errIdentityProviderFailed, fiber.StatusUnauthorized)
+	}
+
+	state := c.Query("state")
+	if state == "" || subtle.ConstantTimeCompare([]byte(state), []byte(cookieState)) != 1 {
+		return utils.ErrorJSON(c, repository.ErrOIDCStateInvalid)
+	}
+
+	stored, err := h.App.DB.ConsumeOIDCState(middlewares.HashToken(state))
+	if err != nil {
+		if errors.Is(err, repository.ErrOIDCStateInvalid) {
+			return utils.ErrorJSON(c, err)
+		}
+		return utils.ErrorJSON(c, err, fiber.StatusInternalServerError)
+	}
+	if stored.Provider != provider.Config.Name {
+		return utils.ErrorJSON(c, repository.ErrOIDCStateInvalid)
+	}
+
+	identity, err := provider.Exchange(c.UserContext(), c.Query("code"), stored.CodeVerifier, stored.Nonce)
+	if err != nil {
+		switch {
+		case errors.Is(err, sso.ErrEmailNotVerified), errors.Is(err, sso.ErrDomainNotAllowed), errors.Is(err, sso.ErrMissingEmailClaim):
+			return utils.ErrorJSON(c, err, fiber.StatusForbidden)
+		default:
+			log.Println("oidc:", err)
+			return utils.ErrorJSON(c, errIdentityProviderFailed, fiber.StatusUnauthorized)
+		}
+	}
+
+	user, err := h.identityUser(provider, identity)
+	if err != nil {
+		if errors.Is(err, errIdentityNotLinked) {
+			return utils.ErrorCodeJSON(c, err, CodeIdentityNotLinked, fiber.StatusForbidden)
+		}
+		return utils.ErrorJSON(c, err, fiber.StatusInternalServerError)
+	}
+
+	// บัญชีที่ถูกล็อกจากการเดารหัสผ่านหรือรหัส 2FA ต้อง login ผ่าน provider ไม่ได้เช่นกัน
+	// ไม่อย่างนั้นจะใช้ provider ข้ามการล็อกไปเดารหัส 2FA ต่อได้
+	email := credentials.LookupEmail(user.Email)
+	decision, err := h.App.LoginGuard.Check(email, c.IP())
+	if err != nil {
+		return utils.ErrorJSON(c, err, fiber.StatusInternalServerError)
+	}
+	if !decision.Allowed {
+		return h.loginBlocked(c, email, decision)
+	}
+
+	if user.DisabledAt != nil {
+		h.recordLoginAttempt(c, email, &user.ID, entities.LoginAccountDisabled)
+		return utils.ErrorCodeJSON(c, errAccountDisabled, CodeAccountDisabled, fiber.StatusForbidden)
+	}
+
+	// provider ยืนยันตัวตนแทนรหัสผ่านแล้ว แต่บัญชีที่ใช้ 2FA ยังต้องยืนยันรหัสอีกขั้นเหมือน /login
+	// และเหมือน /login ตัวนับการ login ผิดจะถูกล้างเมื่อไม่ต้องใช้ 2FA หรือหลังรหัส 2FA ถูกต้องเท่านั้น
+	challenge, err := h.twoFactorChallenge(user)
+	if err != nil {
+		return utils.ErrorJSON(c, err, fiber.StatusInternalServerError)
+	}
+	if challenge != nil {
+		h.recordLoginAttempt(c, email, &user.ID, entities.LoginTwoFactorRequired)
+		return utils.WriteJSON(c, http.StatusAccepted, challenge)
+	}
+
+	h.resetLoginFailures(email, user.ID)
+
+	return h.completeLogin(c, email, user, nil)
+}
+
+var (
+	errIdentityNotLinked = errors.New("no account is linked to this identity")
+	// errIdentityProviderFailed ใช้แทนรายละเอียดจาก provider ซึ่งส่งให้ client ไม่ได้
+	errIdentityProviderFailed = errors.New("identity provider login failed")
+)
+
+// identityUser หาผู้ใช้ของบัญชีจาก provider ถ้ายังไม่ได้ผูกจะผูกกับผู้ใช้ที่อีเมลตรงกัน
+// หรือสร้างผู้ใช้ใหม่ถ้า provider เปิด AutoCreate
+func (h *Handler) identityUser(provider *sso.Provider, identity *sso.Identity) (*entities.User, error) {
+	now := time.Now()
+
+	linked, err := h.App.DB.UserIdentity(identity.Provider, identity.Subject)
+	switch {
+	case err == nil:
+		if err := h.App.DB.TouchUserIdentity(linked.ID, identity.Email, now); err != nil {
+			log.Println("oidc:", err)
+		}
+		return h.App.DB.GetUserByID(linked.UserID)
+	case !errors.Is(err, repository.ErrIdentityNotFound):
+		return nil, err
+	}
+
+	user, _ := h.App.DB.GetUserByEmail(identity.Email)
+	if user != nil {
+		// ผูกเฉพาะบัญชีที่ยืนยันอีเมลแล้ว ไม่อย่างนั้นคนที่สมัครด้วยอีเมลของผู้อื่นไว้ก่อน
+		// จะได้บัญชีที่เจ้าของอีเมลจริง login ผ่าน provider เข้ามาใช้
+		if user.EmailVerifiedAt == nil {
+			return nil, errIdentityNotLinked
+		}
+	} else {
+		if !provider.Config.AutoCreate {
+			return nil, errIdentityNotLinked
+		}
+		if user, err = h.createIdentityUser(provider, identity, now); err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = h.App.DB.InsertUserIdentity(entities.UserIdentity{
+		UserID:      user.ID,
+		Provider:    identity.Provider,
+		Subject:     identity.Subject,
+		Email:       identity.Email,
+		CreatedAt:   now,
+		LastLoginAt: &now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// createIdentityUser สร้างผู้ใช้ใหม่จากข้อมูลของ provider รหัสผ่านเป็นค่าสุ่มที่ไม่มีใครรู้
+// ผู้ใช้ตั้งรหัสผ่านเองได้ภายหลังผ่าน /password/forgot
+func (h *Handler) createIdentityUser(provider *sso.Provider, identity *sso.Identity, now time.Time) (*entities.User, error) {
+	secret, err := middlewares.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	hashedPassword, err := h.App.PasswordPolicy.Hash(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	firstName, lastName := identity.GivenName, identity.FamilyName
+	if firstName == "" {
+		firstName, lastName, _ = strings.Cut(identity.Name, " ")
+	}
+	if firstName == "" {
+		firstName, _, _ = strings.Cut(identity.Email, "@")
+	}
+
+	user := entities.User{
+		FirstName:       firstName,
+		LastName:        lastName,
+		Email:           credentials.LookupEmail(identity.Email),
+		Password:        hashedPassword,
+		Role:            provider.Config.DefaultRole,
+		EmailVerifiedAt: &now,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	user.ID, err = h.App.DB.InsertUser(user)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// MyIdentities แสดงบัญชีจาก identity provider ที่ผูกกับผู้ใช้
+// @Summary บัญชีภายนอกที่ผูกไว้
+// @Description แสดงบัญชีจาก identity provider ที่ผูกกับผู้ใช้ที่ login อยู่
+// @Tags Authentication
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} entities.UserIdentity
+// @Failure 401 {object} utils.Problem "Unauthorized"
+// @Router /api/v1/me/identities [get]
+func (h *Handler) MyIdentities(c *fiber.Ctx) error {
+	userID, ok := middlewares.UserIDFromContext(c)
+	if !ok {
+		return utils.ErrorJSON(c, errors.New("unauthorized"), fiber.StatusUnauthorized)
+	}
+
+	identities, err := h.App.DB.UserIdentities(userID)
+	if err != nil {
+		return utils.ErrorJSON(c, err, fiber.StatusInternalServerError)
+	}
+	return utils.WriteJSON(c, http.StatusOK, identities)
+}
+
+// UnlinkIdentity ยกเลิกการผูกบัญชีจาก identity provider
+// @Summary ยกเลิกการผูกบัญชีภายนอก
+// @Description ยกเลิกการผูกบัญชีจาก identity provider กับผู้ใช้ที่ login อยู่
+// @Tags Authentication
+// @Security BearerAuth
+// @Param id path int true "Identity ID"
+// @Success 204
+// @Failure 401 {object} utils.Problem "Unauthorized"
+// @Failure 404 {object} utils.Problem "Not found"
+// @Router /api/v1/me/identities/{id} [delete]
+func (h *Handler) UnlinkIdentity(c *fiber.Ctx) error {
+	userID, ok := middlewares.UserIDFromContext(c)
+	if !ok {
+		return utils.ErrorJSON(c, errors.New("unauthorized"), fiber.StatusUnauthorized)
+	}
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	if err := h.App.DB.DeleteUserIdentity(userID, id); err != nil {
+		if errors.Is(err, repository.ErrIdentityNotFound) {
+			return utils.ErrorJSON(c, err, fiber.StatusNotFound)
+		}
+		return utils.ErrorJSON(c, err, fiber.StatusInternalServerError)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
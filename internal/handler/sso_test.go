@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/NakarinFIgo/Movies-App/configs"
+	"github.com/NakarinFIgo/Movies-App/internal/entities"
+	"github.com/NakarinFIgo/Movies-App/internal/repository"
+	"github.com/NakarinFIgo/Movies-App/internal/sso"
+	"github.com/NakarinFIgo/Movies-App/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// stateOnlyDB ทำให้ test ล้มถ้า callback ไปใช้ state ในฐานข้อมูลทั้งที่ cookie ไม่ตรง
+type stateOnlyDB struct {
+	repository.DatabaseRepo
+	t *testing.T
+}
+
+func (db stateOnlyDB) ConsumeOIDCState(stateHash string) (*entities.OIDCState, error) {
+	db.t.Error("ConsumeOIDCState called although the state cookie did not match")
+	return nil, repository.ErrOIDCStateInvalid
+}
+
+func newOIDCTestApp(t *testing.T) *fiber.App {
+	h := &Handler{App: configs.Application{
+		DB: stateOnlyDB{t: t},
+		OIDCProviders: sso.Registry{
+			"test": sso.NewProvider(sso.ProviderConfig{Name: "test", Issuer: "http://127.0.0.1:0"}, nil),
+		},
+	}}
+
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Get("/api/v1/auth/oidc/:provider/callback", h.OIDCCallback)
+	return app
+}
+
+func TestOIDCCallbackRejectsStateMismatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		cookie string
+	}{
+		{name: "no cookie", query: "?code=abc&state=state-a"},
+		{name: "different cookie", query: "?code=abc&state=state-a", cookie: "state-b"},
+		{name: "no state", query: "?code=abc", cookie: "state-a"},
+		{name: "empty state and cookie", query: "?code=abc&state="},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newOIDCTestApp(t)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/auth/oidc/test/callback"+tt.query, nil)
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: oidcStateCookie, Value: tt.cookie})
+			}
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusUnprocessableEntity {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusUnprocessableEntity)
+			}
+
+			var problem utils.Problem
+			if err := json.NewDecoder(resp.Body).Decode(&problem); err != nil {
+				t.Fatal(err)
+			}
+			if problem.Detail != repository.ErrOIDCStateInvalid.Error() {
+				t.Errorf("detail = %q, want %q", problem.Detail, repository.ErrOIDCStateInvalid.Error())
+			}
+
+			// cookie ของ state ต้องถูกลบเสมอ แม้ state ไม่ตรง
+			var cleared bool
+			for _, c := range resp.Cookies() {
+				if c.Name == oidcStateCookie && c.Value == "" && (c.MaxAge < 0 || c.Expires.Before(time.Now())) {
+					cleared = true
+				}
+			}
+			if !cleared {
+				t.Error("state cookie was not cleared")
+			}
+		})
+	}
+}
+
+func TestOIDCCallbackUnknownProvider(t *testing.T) {
+	app := newOIDCTestApp(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/auth/oidc/other/callback?code=abc&state=s", nil)
+	req.AddCookie(&http.Cookie{Name: oidcStateCookie, Value: "s"})
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+}
+
+func TestOIDCCallbackHidesProviderError(t *testing.T) {
+	app := newOIDCTestApp(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/auth/oidc/test/callback?error=access_denied&error_description=Call+support+at+evil.example", nil)
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusUnauthorized)
+	}
+
+	var problem utils.Problem
+	if err := json.NewDecoder(resp.Body).Decode(&problem); err != nil {
+		t.Fatal(err)
+	}
+	if problem.Detail != errIdentityProviderFailed.Error() {
+		t.Errorf("detail = %q, want %q", problem.Detail, errIdentityProviderFailed.Error())
+	}
+}
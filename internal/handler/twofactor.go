@@ -0,0 +1,560 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/NakarinFIgo/Movies-App/internal/entities"
+	"github.com/NakarinFIgo/Movies-App/internal/repository"
+	"github.com/NakarinFIgo/Movies-App/pkg/middlewares"
+	"github.com/NakarinFIgo/Movies-App/pkg/twofactor"
+	"github.com/NakarinFIgo/Movies-App/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// CodeTwoFactorRequired คือรหัส error เมื่อบทบาทของผู้ใช้บังคับใช้ 2FA จึงปิดไม่ได้
+const CodeTwoFactorRequired = "two_factor_required"
+
+var (
+	errInvalidTwoFactorCode = errors.New("invalid two-factor code")
+	errTwoFactorCodeMissing = errors.New("code or recovery_code is required")
+)
+
+// TwoFactorChallenge is returned by Login instead of tokens when a second factor is needed
+type TwoFactorChallenge struct {
+	TwoFactorRequired bool `json:"two_factor_required"`
+	// EnrollmentRequired เป็น true เมื่อบทบาทบังคับใช้ 2FA แต่ผู้ใช้ยังไม่ได้ลงทะเบียน
+	// ให้เรียก /api/v1/login/2fa/enroll ก่อน แล้วส่งรหัสแรกที่ /api/v1/login/2fa
+	EnrollmentRequired bool   `json:"enrollment_required"`
+	ChallengeToken     string `json:"challenge_token"`
+	// ExpiresIn คืออายุของ challenge_token เป็นวินาที
+	ExpiresIn int `json:"expires_in"`
+}
+
+// TwoFactorLoginPayload is the request payload for the second login step
+type TwoFactorLoginPayload struct {
+	// Required: true
+	ChallengeToken string `json:"challenge_token"`
+	// Example: "123456"
+	Code string `json:"code"`
+	// Example: "abcde-fghij"
+	RecoveryCode string `json:"recovery_code"`
+}
+
+// ChallengeTokenPayload is the request payload carrying only a challenge token
+type ChallengeTokenPayload struct {
+	// Required: true
+	ChallengeToken string `json:"challenge_token"`
+}
+
+// TwoFactorCodePayload is the request payload for confirming, disabling and regenerating with a code
+type TwoFactorCodePayload struct {
+	// Example: "123456"
+	Code string `json:"code"`
+	// Example: "abcde-fghij"
+	RecoveryCode string `json:"recovery_code"`
+}
+
+// TwoFactorEnrollment is the secret and otpauth URI shown when enrolling an authenticator app
+type TwoFactorEnrollment struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// TwoFactorStatus is the two-factor state of the current user
+type TwoFactorStatus struct {
+	Enabled                bool  `json:"enabled"`
+	Required               bool  `json:"required"`
+	RecoveryCodesRemaining int64 `json:"recovery_codes_remaining"`
+}
+
+// RecoveryCodesResponse is the list of new recovery codes, shown only once
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// twoFactorRequired บอกว่าบทบาทของผู้ใช้บังคับใช้ 2FA หรือไม่
+func (h *Handler) twoFactorRequired(user *entities.User) bool {
+	return slices.Contains(h.App.TwoFactorRoles, user.Role)
+}
+
+// userTOTP ดึง TOTP ของผู้ใช้ คืน nil ถ้ายังไม่เคยลงทะเบียน
+func (h *Handler) userTOTP(userID int) (*entities.UserTOTP, error) {
+	t, err := h.App.DB.UserTOTP(userID)
+	if errors.Is(err, repository.ErrTOTPNotFound) {
+		return nil, nil
+	}
+	return t, err
+}
+
+// twoFactorChallenge คืน challenge ถ้าผู้ใช้ต้องยืนยันรหัส 2FA ก่อนได้ token หรือ nil ถ้าไม่ต้อง
+func (h *Handler) twoFactorChallenge(user *entities.User) (*TwoFactorChallenge, error) {
+	t, err := h.userTOTP(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	enabled := t != nil && t.ConfirmedAt != nil
+	if !enabled && !h.twoFactorRequired(user) {
+		return nil, nil
+	}
+
+	token, err := h.App.Auth.GenerateChallengeToken(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TwoFactorChallenge{
+		TwoFactorRequired:  true,
+		EnrollmentRequired: !enabled,
+		ChallengeToken:     token,
+		ExpiresIn:          int(h.App.Auth.ChallengeExpiry.Seconds()),
+	}, nil
+}
+
+// challengeUser ตรวจ challenge token และคืนผู้ใช้เจ้าของ token
+func (h *Handler) challengeUser(token string) (*entities.User, error) {
+	claims, err := h.App.Auth.VerifyToken(token, middlewares.TokenTypeChallenge)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := claims.UserID()
+	if err != nil {
+		return nil, middlewares.ErrInvalidToken
+	}
+
+	// บัญชีที่ถูกปิดหลังได้ challenge token ไปแล้วต้อง login ต่อไม่ได้
+	user, err := h.App.DB.GetUserByID(userID)
+	if err != nil || user.DisabledAt != nil {
+		return nil, middlewares.ErrInvalidToken
+	}
+	return user, nil
+}
+
+// currentUser ดึงผู้ใช้เจ้าของ access token
+func (h *Handler) currentUser(c *fiber.Ctx) (*entities.User, error) {
+	userID, ok := middlewares.UserIDFromContext(c)
+	if !ok {
+		return nil, errors.New("unauthorized")
+	}
+
+	user, err := h.App.DB.GetUserByID(userID)
+	if err != nil {
+		return nil, errors.New("unknown user")
+	}
+	return user, nil
+}
+
+// newRecoveryCodes สุ่ม recovery code ชุดใหม่ คืนทั้งตัวรหัสสำหรับแสดงผู้ใช้และ hash สำหรับเก็บ
+func newRecoveryCodes() (codes, hashes []string, err error) {
+	codes, err = twofactor.RecoveryCodes()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hashes = make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = middlewares.HashToken(twofactor.NormalizeRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+// checkSecondFactor ตรวจรหัส TOTP หรือ recovery code ของ TOTP ที่เปิดใช้แล้ว
+func (h *Handler) checkSecondFactor(t *entities.UserTOTP, code, recoveryCode string) error {
+	switch {
+	case code != "":
+		step, ok := twofactor.Validate(code, t.Secret, time.Now())
+		if !ok {
+			return errInvalidTwoFactorCode
+		}
+		if err := h.App.DB.UseTOTPStep(t.UserID, step); err != nil {
+			if errors.Is(err, repository.ErrTOTPCodeReused) {
+				return errInvalidTwoFactorCode
+			}
+			return err
+		}
+		return nil
+	case recoveryCode != "":
+		hash := middlewares.HashToken(twofactor.NormalizeRecoveryCode(recoveryCode))
+		if err := h.App.DB.UseRecoveryCode(t.UserID, hash); err != nil {
+			if errors.Is(err, repository.ErrRecoveryCodeInvalid) {
+				return errInvalidTwoFactorCode
+			}
+			return err
+		}
+		return nil
+	default:
+		return errTwoFactorCodeMissing
+	}
+}
+
+// verifyTwoFactor ตรวจรหัสโดยนับรหัสที่ผิดรวมกับการ login ผิด เพื่อไม่ให้เดารหัส 6 หลักได้
+// ถ้าไม่ผ่านจะเขียน response แล้วและคืน ok เป็น false
+func (h *Handler) verifyTwoFactor(c *fiber.Ctx, user *entities.User, check func() error) (ok bool, err error) {
+	decision, err := h.App.LoginGuard.Check(user.Email, c.IP())
+	if err != nil {
+		return false, utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+	if !decision.Allowed {
+		return false, h.loginBlocked(c, user.Email, decision)
+	}
+
+	if err := check(); err != nil {
+		if errors.Is(err, errTwoFactorCodeMissing) {
+			return false, utils.ErrorJSON(c, err)
+		}
+		if !errors.Is(err, errInvalidTwoFactorCode) {
+			return false, utils.ErrorJSON(c, err, http.StatusInternalServerError)
+		}
+
+		h.recordLoginAttempt(c, user.Email, &user.ID, entities.LoginInvalidTwoFactor)
+		if err := h.App.LoginGuard.Failure(user.Email, c.IP()); err != nil {
+			return false, utils.ErrorJSON(c, err, http.StatusInternalServerError)
+		}
+		return false, utils.ErrorJSON(c, err)
+	}
+
+	h.resetLoginFailures(user.Email, user.ID)
+	return true, nil
+}
+
+// enroll สร้าง secret ใหม่ให้ผู้ใช้ รอการยืนยันด้วยรหัสแรก
+func (h *Handler) enroll(c *fiber.Ctx, user *entities.User) error {
+	secret, uri, err := twofactor.Generate(h.App.TwoFactorIssuer, user.Email)
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+
+	if err := h.App.DB.SaveTOTPSecret(user.ID, secret); err != nil {
+		if errors.Is(err, repository.ErrTOTPAlreadyEnabled) {
+			return utils.ErrorJSON(c, err, http.StatusConflict)
+		}
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+
+	return utils.WriteJSON(c, fiber.StatusOK, TwoFactorEnrollment{Secret: secret, OTPAuthURI: uri})
+}
+
+// confirmEnrollment ตรวจรหัสแรกกับ secret ที่รอยืนยัน แล้วเปิดใช้ 2FA คืน recovery code ชุดแรก
+func (h *Handler) confirmEnrollment(t *entities.UserTOTP, code string) ([]string, error) {
+	if code == "" {
+		return nil, errTwoFactorCodeMissing
+	}
+
+	step, ok := twofactor.Validate(code, t.Secret, time.Now())
+	if !ok {
+		return nil, errInvalidTwoFactorCode
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.App.DB.EnableTOTP(t.UserID, step, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// LoginTwoFactor ยืนยันรหัส 2FA เพื่อ login ให้เสร็จ
+// @Summary ยืนยันรหัส 2FA เพื่อ login ให้เสร็จ
+// @Description ขั้นที่สองของการ login ส่ง challenge_token ที่ได้จาก /api/v1/login พร้อมรหัส TOTP หรือ recovery code แล้วจึงได้ TokenPairs ถ้าบทบาทบังคับใช้ 2FA แต่ยังไม่ได้ลงทะเบียน ให้เรียก /api/v1/login/2fa/enroll ก่อน แล้วส่งรหัสแรกที่นี่ จะได้ recovery code ชุดแรกพร้อม token รหัสที่ผิดนับรวมกับการ login ผิด
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param requestPayload body TwoFactorLoginPayload true "Challenge token and code"
+// @Success 202 {object} LoginResponse "Token pairs"
+// @Failure 400 {object} utils.Problem "Bad Request"
+// @Failure 401 {object} utils.Problem "Unauthorized"
+// @Failure 429 {object} utils.Problem "Too many failed attempts, see Retry-After"
+// @Router /api/v1/login/2fa [post]
+func (h *Handler) LoginTwoFactor(c *fiber.Ctx) error {
+	var payload TwoFactorLoginPayload
+	if err := utils.ReadJSON(c, &payload); err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	user, err := h.challengeUser(payload.ChallengeToken)
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusUnauthorized)
+	}
+
+	t, err := h.userTOTP(user.ID)
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+	if t == nil {
+		return utils.ErrorJSON(c, repository.ErrTOTPNotFound)
+	}
+
+	// ยังไม่ได้เปิดใช้ รหัสนี้คือรหัสแรกของการลงทะเบียนระหว่าง login
+	if t.ConfirmedAt == nil {
+		var codes []string
+		ok, err := h.verifyTwoFactor(c, user, func() (err error) {
+			codes, err = h.confirmEnrollment(t, payload.Code)
+			return err
+		})
+		if !ok {
+			return err
+		}
+		return h.completeLogin(c, user.Email, user, codes)
+	}
+
+	ok, err := h.verifyTwoFactor(c, user, func() error {
+		return h.checkSecondFactor(t, payload.Code, payload.RecoveryCode)
+	})
+	if !ok {
+		return err
+	}
+
+	return h.completeLogin(c, user.Email, user, nil)
+}
+
+// LoginTwoFactorEnroll ลงทะเบียน 2FA ระหว่าง login
+// @Summary ลงทะเบียน 2FA ระหว่าง login
+// @Description ใช้เมื่อ /api/v1/login ตอบ enrollment_required เพราะบทบาทบังคับใช้ 2FA คืน secret และ otpauth URI สำหรับแอป authenticator จากนั้นส่งรหัสแรกที่ /api/v1/login/2fa
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param requestPayload body ChallengeTokenPayload true "Challenge token"
+// @Success 200 {object} TwoFactorEnrollment "Secret and otpauth URI"
+// @Failure 401 {object} utils.Problem "Unauthorized"
+// @Failure 409 {object} utils.Problem "Conflict"
+// @Router /api/v1/login/2fa/enroll [post]
+func (h *Handler) LoginTwoFactorEnroll(c *fiber.Ctx) error {
+	var payload ChallengeTokenPayload
+	if err := utils.ReadJSON(c, &payload); err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	user, err := h.challengeUser(payload.ChallengeToken)
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusUnauthorized)
+	}
+
+	return h.enroll(c, user)
+}
+
+// MyTwoFactor แสดงสถานะ 2FA ของผู้ใช้
+// @Summary แสดงสถานะ 2FA
+// @Description บอกว่าผู้ใช้เปิดใช้ 2FA แล้วหรือยัง บทบาทบังคับใช้หรือไม่ และเหลือ recovery code กี่ชุด
+// @Tags Two-Factor
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} TwoFactorStatus "Two-factor status"
+// @Failure 401 {object} utils.Problem "Unauthorized"
+// @Router /api/v1/me/2fa [get]
+func (h *Handler) MyTwoFactor(c *fiber.Ctx) error {
+	user, err := h.currentUser(c)
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusUnauthorized)
+	}
+
+	t, err := h.userTOTP(user.ID)
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+
+	status := TwoFactorStatus{
+		Enabled:  t != nil && t.ConfirmedAt != nil,
+		Required: h.twoFactorRequired(user),
+	}
+	if status.Enabled {
+		status.RecoveryCodesRemaining, err = h.App.DB.CountRecoveryCodes(user.ID)
+		if err != nil {
+			return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+		}
+	}
+
+	return utils.WriteJSON(c, fiber.StatusOK, status)
+}
+
+// EnrollTwoFactor เริ่มลงทะเบียน 2FA
+// @Summary เริ่มลงทะเบียน 2FA
+// @Description สร้าง secret ใหม่และคืน otpauth URI สำหรับสแกนในแอป authenticator 2FA ยังไม่เปิดใช้จนกว่าจะยืนยันด้วยรหัสแรกที่ /api/v1/me/2fa/confirm
+// @Tags Two-Factor
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} TwoFactorEnrollment "Secret and otpauth URI"
+// @Failure 409 {object} utils.Problem "Conflict"
+// @Router /api/v1/me/2fa/enroll [post]
+func (h *Handler) EnrollTwoFactor(c *fiber.Ctx) error {
+	user, err := h.currentUser(c)
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusUnauthorized)
+	}
+
+	return h.enroll(c, user)
+}
+
+// ConfirmTwoFactor ยืนยันการลงทะเบียน 2FA ด้วยรหัสแรก
+// @Summary ยืนยันการลงทะเบียน 2FA
+// @Description ตรวจรหัสแรกจากแอป authenticator แล้วเปิดใช้ 2FA คืน recovery code ที่ใช้แทนรหัสได้ครั้งละหนึ่งรหัส แสดงครั้งเดียวเท่านั้น
+// @Tags Two-Factor
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param requestPayload body TwoFactorCodePayload true "First code"
+// @Success 200 {object} RecoveryCodesResponse "Recovery codes"
+// @Failure 400 {object} utils.Problem "Bad Request"
+// @Failure 409 {object} utils.Problem "Conflict"
+// @Router /api/v1/me/2fa/confirm [post]
+func (h *Handler) ConfirmTwoFactor(c *fiber.Ctx) error {
+	user, err := h.currentUser(c)
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusUnauthorized)
+	}
+
+	var payload TwoFactorCodePayload
+	if err := utils.ReadJSON(c, &payload); err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	t, err := h.userTOTP(user.ID)
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+	if t == nil {
+		return utils.ErrorJSON(c, repository.ErrTOTPNotFound)
+	}
+	if t.ConfirmedAt != nil {
+		return utils.ErrorJSON(c, repository.ErrTOTPAlreadyEnabled, http.StatusConflict)
+	}
+
+	var codes []string
+	ok, err := h.verifyTwoFactor(c, user, func() (err error) {
+		codes, err = h.confirmEnrollment(t, payload.Code)
+		return err
+	})
+	if !ok {
+		return err
+	}
+
+	return utils.WriteJSON(c, fiber.StatusOK, RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// RegenerateRecoveryCodes ออก recovery code ชุดใหม่
+// @Summary ออก recovery code ชุดใหม่
+// @Description ยืนยันด้วยรหัส TOTP หรือ recovery code แล้วแทนที่ recovery code ทั้งหมดด้วยชุดใหม่ ชุดเดิมใช้ไม่ได้อีก
+// @Tags Two-Factor
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param requestPayload body TwoFactorCodePayload true "Current code"
+// @Success 200 {object} RecoveryCodesResponse "Recovery codes"
+// @Failure 400 {object} utils.Problem "Bad Request"
+// @Router /api/v1/me/2fa/recovery-codes [post]
+func (h *Handler) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	user, err := h.currentUser(c)
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusUnauthorized)
+	}
+
+	var payload TwoFactorCodePayload
+	if err := utils.ReadJSON(c, &payload); err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	t, err := h.userTOTP(user.ID)
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+	if t == nil || t.ConfirmedAt == nil {
+		return utils.ErrorJSON(c, repository.ErrTOTPNotFound)
+	}
+
+	ok, err := h.verifyTwoFactor(c, user, func() error {
+		return h.checkSecondFactor(t, payload.Code, payload.RecoveryCode)
+	})
+	if !ok {
+		return err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+	if err := h.App.DB.ReplaceRecoveryCodes(user.ID, hashes); err != nil {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+
+	return utils.WriteJSON(c, fiber.StatusOK, RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// DisableTwoFactor ปิด 2FA
+// @Summary ปิด 2FA
+// @Description ยืนยันด้วยรหัส TOTP หรือ recovery code แล้วลบ secret และ recovery code ทั้งหมด ปิดไม่ได้ถ้าบทบาทบังคับใช้ 2FA
+// @Tags Two-Factor
+// @Accept json
+// @Security BearerAuth
+// @Param requestPayload body TwoFactorCodePayload true "Current code"
+// @Success 204 "Two-factor authentication disabled"
+// @Failure 400 {object} utils.Problem "Bad Request"
+// @Failure 403 {object} utils.Problem "Required by role"
+// @Router /api/v1/me/2fa [delete]
+func (h *Handler) DisableTwoFactor(c *fiber.Ctx) error {
+	user, err := h.currentUser(c)
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusUnauthorized)
+	}
+
+	if h.twoFactorRequired(user) {
+		return utils.ErrorCodeJSON(c, errors.New("two-factor authentication is required for your role"), CodeTwoFactorRequired, http.StatusForbidden)
+	}
+
+	var payload TwoFactorCodePayload
+	if err := utils.ReadJSON(c, &payload); err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	t, err := h.userTOTP(user.ID)
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+	if t == nil || t.ConfirmedAt == nil {
+		return utils.ErrorJSON(c, repository.ErrTOTPNotFound)
+	}
+
+	ok, err := h.verifyTwoFactor(c, user, func() error {
+		return h.checkSecondFactor(t, payload.Code, payload.RecoveryCode)
+	})
+	if !ok {
+		return err
+	}
+
+	if err := h.App.DB.DisableTOTP(user.ID); err != nil {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ResetUserTwoFactor ล้าง 2FA ของผู้ใช้ (admin)
+// @Summary ล้าง 2FA ของผู้ใช้
+// @Description ให้ admin ลบ 2FA ของผู้ใช้ที่ทำอุปกรณ์และ recovery code หาย ถ้าบทบาทบังคับใช้ 2FA ผู้ใช้จะต้องลงทะเบียนใหม่ตอน login ครั้งถัดไป
+// @Tags Two-Factor
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 204 "Two-factor authentication reset"
+// @Failure 404 {object} utils.Problem "Not Found"
+// @Router /api/v1/admin/users/{id}/2fa [delete]
+func (h *Handler) ResetUserTwoFactor(c *fiber.Ctx) error {
+	userID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	if err := h.App.DB.DisableTOTP(userID); err != nil {
+		if errors.Is(err, repository.ErrTOTPNotFound) {
+			return utils.ErrorJSON(c, err, http.StatusNotFound)
+		}
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,358 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NakarinFIgo/Movies-App/internal/entities"
+	"github.com/NakarinFIgo/Movies-App/pkg/mailer"
+	"github.com/NakarinFIgo/Movies-App/pkg/middlewares"
+	"github.com/NakarinFIgo/Movies-App/pkg/rbac"
+	"github.com/NakarinFIgo/Movies-App/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// CodeAccountDisabled คือรหัส error ที่ Login ตอบเมื่อ admin ปิดบัญชีไว้
+const CodeAccountDisabled = "account_disabled"
+
+const (
+	defaultUserLimit = 50
+	maxUserLimit     = 200
+)
+
+var (
+	errDisableSelf     = errors.New("cannot disable your own account")
+	errAccountDisabled = errors.New("account has been disabled")
+)
+
+// AdminUser is the admin view of a user in the user list
+type AdminUser struct {
+	UserProfile
+	// Status คือ active, disabled, unverified หรือ pending_deletion
+	Status          string     `json:"status"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	DisabledAt      *time.Time `json:"disabled_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// AdminUserDetail is the admin view of one user with their security settings
+type AdminUserDetail struct {
+	AdminUser
+	TwoFactorEnabled bool                      `json:"two_factor_enabled"`
+	Identities       []*entities.UserIdentity  `json:"identities"`
+	Sessions         []*entities.ActiveSession `json:"sessions"`
+}
+
+// UpdateUserRolePayload is the request payload for changing a user's role
+type UpdateUserRolePayload struct {
+	// Required: true
+	// Example: "editor"
+	Role string `json:"role"`
+	// สิทธิ์ที่ให้เพิ่มจากสิทธิ์ของบทบาท
+	// Example: ["movies:delete"]
+	Permissions []string `json:"permissions"`
+}
+
+// userStatus คืนสถานะของบัญชี ถ้าเข้าหลายสถานะจะใช้สถานะที่มาก่อนใน disabled, pending_deletion, unverified
+func userStatus(user *entities.User) string {
+	switch {
+	case user.DisabledAt != nil:
+		return entities.UserStatusDisabled
+	case user.DeletionScheduledAt != nil:
+		return entities.UserStatusPendingDeletion
+	case user.EmailVerifiedAt == nil:
+		return entities.UserStatusUnverified
+	default:
+		return entities.UserStatusActive
+	}
+}
+
+func adminUser(user *entities.User) AdminUser {
+	return AdminUser{
+		UserProfile:     userProfile(user),
+		Status:          userStatus(user),
+		EmailVerifiedAt: user.EmailVerifiedAt,
+		DisabledAt:      user.DisabledAt,
+		CreatedAt:       user.CreatedAt,
+	}
+}
+
+// AllUsers ค้นหาผู้ใช้ (admin)
+// @Summary ค้นหาผู้ใช้
+// @Description ค้นจากชื่อหรืออีเมล กรองตามบทบาทและสถานะได้ จำนวนผู้ใช้ทั้งหมดที่ตรงเงื่อนไขอยู่ใน header X-Total-Count
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Param q query string false "ชื่อหรืออีเมล"
+// @Param role query string false "บทบาท" Enums(admin, editor, viewer)
+// @Param status query string false "สถานะของบัญชี" Enums(active, disabled, unverified, pending_deletion)
+// @Param limit query int false "จำนวนรายการ (ค่าเริ่มต้น 50 สูงสุด 200)"
+// @Param offset query int false "ข้ามกี่รายการ"
+// @Success 200 {array} AdminUser "List of users"
+// @Header 200 {integer} X-Total-Count "จำนวนผู้ใช้ทั้งหมดที่ตรงเงื่อนไข"
+// @Failure 400 {object} utils.Problem "Bad Request"
+// @Router /api/v1/admin/users [get]
+func (h *Handler) AllUsers(c *fiber.Ctx) error {
+	role := c.Query("role")
+	if role != "" && !rbac.ValidRole(role) {
+		return utils.ErrorJSON(c, errors.New("invalid role"))
+	}
+
+	status := c.Query("status")
+	switch status {
+	case "", entities.UserStatusActive, entities.UserStatusDisabled, entities.UserStatusUnverified, entities.UserStatusPendingDeletion:
+	default:
+		return utils.ErrorJSON(c, errors.New("invalid status"))
+	}
+
+	limit := c.QueryInt("limit", defaultUserLimit)
+	if limit <= 0 || limit > maxUserLimit {
+		limit = defaultUserLimit
+	}
+	offset := c.QueryInt("offset", 0)
+	if offset < 0 {
+		offset = 0
+	}
+
+	users, total, err := h.App.DB.SearchUsers(strings.TrimSpace(c.Query("q")), role, status, limit, offset)
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+
+	out := make([]AdminUser, len(users))
+	for i, user := range users {
+		out[i] = adminUser(user)
+	}
+
+	c.Set("X-Total-Count", strconv.FormatInt(total, 10))
+	return utils.WriteJSON(c, fiber.StatusOK, out)
+}
+
+// GetUser แสดงรายละเอียดของผู้ใช้ (admin)
+// @Summary แสดงรายละเอียดของผู้ใช้
+// @Description แสดงข้อมูลบัญชี สถานะ 2FA บัญชีภายนอกที่ผูกไว้ และ session ที่ยังใช้งานได้
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} AdminUserDetail "User"
+// @Failure 404 {object} utils.Problem "Not Found"
+// @Router /api/v1/admin/users/{id} [get]
+func (h *Handler) GetUser(c *fiber.Ctx) error {
+	userID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	user, err := h.App.DB.GetUserByID(userID)
+	if err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	t, err := h.userTOTP(user.ID)
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+
+	identities, err := h.App.DB.UserIdentities(user.ID)
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+
+	sessions, err := h.App.DB.ActiveSessions(user.ID)
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+
+	detail := AdminUserDetail{
+		AdminUser:        adminUser(user),
+		TwoFactorEnabled: t != nil && t.ConfirmedAt != nil,
+		Identities:       identities,
+		Sessions:         sessions,
+	}
+
+	return utils.WriteJSON(c, fiber.StatusOK, detail)
+}
+
+// UpdateUserRole เปลี่ยนบทบาทของผู้ใช้ (admin)
+// @Summary เปลี่ยนบทบาทของผู้ใช้
+// @Description เปลี่ยนบทบาทและสิทธิ์เพิ่มเติม มีผลกับ token ที่ออกหลังจากนี้ เอาบทบาท admin ออกจาก admin คนสุดท้ายไม่ได้
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param requestPayload body UpdateUserRolePayload true "Role and extra permissions"
+// @Success 200 {object} AdminUser "Updated user"
+// @Failure 400 {object} utils.Problem "Bad Request"
+// @Failure 404 {object} utils.Problem "Not Found"
+// @Failure 409 {object} utils.Problem "Last admin"
+// @Router /api/v1/admin/users/{id}/role [put]
+func (h *Handler) UpdateUserRole(c *fiber.Ctx) error {
+	userID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	user, err := h.App.DB.GetUserByID(userID)
+	if err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	var payload UpdateUserRolePayload
+	if err := utils.ReadJSON(c, &payload); err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	if !rbac.ValidRole(payload.Role) {
+		return utils.ErrorJSON(c, errors.New("invalid role"))
+	}
+	for _, p := range payload.Permissions {
+		if !rbac.ValidPermission(p) {
+			return utils.ErrorJSON(c, fmt.Errorf("invalid permission %q", p))
+		}
+	}
+	if payload.Permissions == nil {
+		payload.Permissions = []string{}
+	}
+
+	if err := h.App.DB.UpdateUserRole(user.ID, payload.Role, payload.Permissions); err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	user.Role, user.Permissions = payload.Role, payload.Permissions
+	return utils.WriteJSON(c, fiber.StatusOK, adminUser(user))
+}
+
+// DisableUser ปิดบัญชีของผู้ใช้ (admin)
+// @Summary ปิดบัญชีของผู้ใช้
+// @Description บัญชีที่ถูกปิดจะ login ไม่ได้และทุก session ถูก revoke ทันที ปิดบัญชีของตัวเองไม่ได้
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} map[string]interface{} "Disabled" example({"message":"account disabled"})
+// @Failure 404 {object} utils.Problem "Not Found"
+// @Failure 409 {object} utils.Problem "Own account"
+// @Router /api/v1/admin/users/{id}/disable [post]
+func (h *Handler) DisableUser(c *fiber.Ctx) error {
+	userID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	user, err := h.App.DB.GetUserByID(userID)
+	if err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	if adminID, ok := middlewares.UserIDFromContext(c); ok && adminID == user.ID {
+		return utils.ErrorJSON(c, errDisableSelf, http.StatusConflict)
+	}
+
+	return h.setUserDisabled(c, user, true)
+}
+
+// EnableUser เปิดบัญชีที่ถูกปิดไว้ (admin)
+// @Summary เปิดบัญชีที่ถูกปิดไว้
+// @Description ให้ผู้ใช้ login ได้อีกครั้ง session ที่ถูก revoke ตอนปิดบัญชีจะไม่กลับมา
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} map[string]interface{} "Enabled" example({"message":"account enabled"})
+// @Failure 404 {object} utils.Problem "Not Found"
+// @Router /api/v1/admin/users/{id}/enable [post]
+func (h *Handler) EnableUser(c *fiber.Ctx) error {
+	userID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	user, err := h.App.DB.GetUserByID(userID)
+	if err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	return h.setUserDisabled(c, user, false)
+}
+
+func (h *Handler) setUserDisabled(c *fiber.Ctx, user *entities.User, disabled bool) error {
+	if err := h.App.DB.SetUserDisabled(user.ID, disabled); err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	message := "account enabled"
+	if disabled {
+		message = "account disabled"
+	}
+
+	resp := utils.JSONResponse{
+		Error:   false,
+		Message: message,
+	}
+
+	return utils.WriteJSON(c, fiber.StatusOK, resp)
+}
+
+// ForceUserPasswordReset บังคับให้ผู้ใช้ตั้งรหัสผ่านใหม่ (admin)
+// @Summary บังคับให้ผู้ใช้ตั้งรหัสผ่านใหม่
+// @Description เปลี่ยนรหัสผ่านเดิมเป็นค่าสุ่มที่ไม่มีใครรู้ revoke ทุก session แล้วส่งลิงก์ตั้งรหัสผ่านใหม่ไปที่อีเมลของผู้ใช้
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 202 {object} map[string]interface{} "Reset link sent" example({"message":"password reset, a reset link has been sent","data":{"revoked":2}})
+// @Failure 404 {object} utils.Problem "Not Found"
+// @Router /api/v1/admin/users/{id}/password-reset [post]
+func (h *Handler) ForceUserPasswordReset(c *fiber.Ctx) error {
+	userID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	user, err := h.App.DB.GetUserByID(userID)
+	if err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	secret, err := middlewares.GenerateOpaqueToken()
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+	hashedPassword, err := h.App.PasswordPolicy.Hash(secret)
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+
+	// ChangePassword ทำให้ลิงก์ reset เดิมใช้ไม่ได้ด้วย จึงต้องออกลิงก์ใหม่หลังจากนี้
+	revoked, err := h.App.DB.ChangePassword(user.ID, hashedPassword, "")
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+
+	link, err := h.passwordResetLink(user)
+	if err != nil {
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: "Your password has been reset",
+		Body: fmt.Sprintf("Hi %s,\n\nAn administrator has reset your password and signed you out on all devices. Use the link below to set a new password. It expires in %s and can only be used once.\n\n%s\n",
+			user.FirstName, h.App.PasswordResetTTL, link),
+	}
+	go h.sendPasswordResetMail(user, msg)
+
+	resp := utils.JSONResponse{
+		Error:   false,
+		Message: "password reset, a reset link has been sent",
+		Data:    fiber.Map{"revoked": revoked},
+	}
+
+	return utils.WriteJSON(c, fiber.StatusAccepted, resp)
+}
@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/NakarinFIgo/Movies-App/internal/entities"
+	"github.com/NakarinFIgo/Movies-App/internal/repository"
+	"github.com/NakarinFIgo/Movies-App/pkg/mailer"
+	"github.com/NakarinFIgo/Movies-App/pkg/middlewares"
+	"github.com/NakarinFIgo/Movies-App/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// CodeEmailNotVerified คือรหัส error ที่ Login ตอบเมื่อบัญชียังไม่ได้ยืนยันอีเมล
+const CodeEmailNotVerified = "email_not_verified"
+
+// การจำกัดการส่งลิงก์ยืนยันอีเมลซ้ำต่อบัญชี
+const (
+	verificationResendInterval = time.Minute
+	verificationHourlyLimit    = 5
+)
+
+// ResendVerificationPayload is the request payload for resending the verification email
+type ResendVerificationPayload struct {
+	// Required: true
+	// Example: "john@example.com"
+	Email string `json:"email"`
+}
+
+// sendEmailVerification สร้างลิงก์ยืนยันอีเมลใหม่และส่งให้ผู้ใช้
+func (h *Handler) sendEmailVerification(user *entities.User) {
+	token, err := middlewares.GenerateOpaqueToken()
+	if err != nil {
+		log.Println("email verification:", err)
+		return
+	}
+
+	verification := entities.EmailVerification{
+		UserID:    user.ID,
+		TokenHash: middlewares.HashToken(token),
+		ExpiresAt: time.Now().Add(h.App.EmailVerificationTTL),
+	}
+	if err := h.App.DB.InsertEmailVerification(verification); err != nil {
+		log.Println("email verification:", err)
+		return
+	}
+
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm your email address by opening the link below. It expires in %s.\n\n%s\n\nIf you did not create an account, you can ignore this email.\n",
+			user.FirstName, h.App.EmailVerificationTTL, h.verificationLink(token)),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), mailTimeout)
+	defer cancel()
+
+	if err := h.App.Mailer.Send(ctx, msg); err != nil {
+		log.Printf("email verification: failed to send email to user %d: %v", user.ID, err)
+	}
+}
+
+// verificationLink คือลิงก์ในหน้าเว็บที่ส่ง token ต่อให้ /api/v1/verify-email
+func (h *Handler) verificationLink(token string) string {
+	return fmt.Sprintf("%s/verify-email?token=%s", strings.TrimRight(h.App.FrontendURL, "/"), url.QueryEscape(token))
+}
+
+// sendEmailChange ส่งลิงก์ยืนยันไปที่อีเมลใหม่ และแจ้งอีเมลเดิมว่ามีการขอเปลี่ยนอีเมล
+// อีเมลของบัญชียังเป็นอีเมลเดิมจนกว่าจะกดลิงก์
+func (h *Handler) sendEmailChange(user *entities.User, token, newEmail string) {
+	ctx, cancel := context.WithTimeout(context.Background(), mailTimeout)
+	defer cancel()
+
+	confirm := mailer.Message{
+		To:      newEmail,
+		Subject: "Confirm your new email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm that you want to use this address for your account by opening the link below. It expires in %s.\n\n%s\n\nIf you did not ask for this change, you can ignore this email.\n",
+			user.FirstName, h.App.EmailVerificationTTL, h.verificationLink(token)),
+	}
+	if err := h.App.Mailer.Send(ctx, confirm); err != nil {
+		log.Printf("email change: failed to send email to user %d: %v", user.ID, err)
+	}
+
+	notice := mailer.Message{
+		To:      user.Email,
+		Subject: "Your email address is being changed",
+		Body: fmt.Sprintf("Hi %s,\n\nSomeone asked to change the email address of your account to %s. The change takes effect once the new address is confirmed.\n\nIf this was not you, change your password and sign out of all devices.\n",
+			user.FirstName, newEmail),
+	}
+	if err := h.App.Mailer.Send(ctx, notice); err != nil {
+		log.Printf("email change: failed to send notice to user %d: %v", user.ID, err)
+	}
+}
+
+// resendEmailVerification ส่งลิงก์ใหม่ถ้าบัญชียังไม่ยืนยันและยังไม่เกินจำนวนที่กำหนด
+func (h *Handler) resendEmailVerification(email string) {
+	user, err := h.App.DB.GetUserByEmail(email)
+	if err != nil || user.EmailVerifiedAt != nil {
+		return
+	}
+
+	now := time.Now()
+
+	recent, err := h.App.DB.CountEmailVerificationsSince(user.ID, now.Add(-verificationResendInterval))
+	if err != nil || recent > 0 {
+		return
+	}
+
+	hourly, err := h.App.DB.CountEmailVerificationsSince(user.ID, now.Add(-time.Hour))
+	if err != nil || hourly >= verificationHourlyLimit {
+		return
+	}
+
+	h.sendEmailVerification(user)
+}
+
+// VerifyEmail ยืนยันอีเมลด้วย token จากลิงก์
+// @Summary ยืนยันอีเมล
+// @Description ยืนยันอีเมลด้วย token จากลิงก์ที่ส่งไปตอนสมัคร หลังยืนยันแล้วจึง login ได้ ถ้าเป็นลิงก์เปลี่ยนอีเมลจะเปลี่ยนอีเมลของบัญชีเป็นอีเมลใหม่
+// @Tags Authentication
+// @Produce json
+// @Param token query string true "Verification token"
+// @Success 200 {object} map[string]interface{} "Email verified" example({"message":"email verified"})
+// @Failure 400 {object} utils.Problem "Bad Request"
+// @Router /api/v1/verify-email [get]
+func (h *Handler) VerifyEmail(c *fiber.Ctx) error {
+	token := c.Query("token")
+	if token == "" {
+		return utils.ErrorJSON(c, repository.ErrVerificationTokenInvalid)
+	}
+
+	if _, err := h.App.DB.VerifyEmail(middlewares.HashToken(token)); err != nil {
+		if errors.Is(err, repository.ErrVerificationTokenInvalid) || errors.Is(err, repository.ErrEmailTaken) {
+			return utils.ErrorJSON(c, err)
+		}
+		return utils.ErrorJSON(c, err, http.StatusInternalServerError)
+	}
+
+	resp := utils.JSONResponse{
+		Error:   false,
+		Message: "email verified",
+	}
+
+	return utils.WriteJSON(c, fiber.StatusOK, resp)
+}
+
+// ResendVerification ส่งลิงก์ยืนยันอีเมลอีกครั้ง
+// @Summary ส่งลิงก์ยืนยันอีเมลอีกครั้ง
+// @Description ส่งลิงก์ยืนยันใหม่ถ้าบัญชียังไม่ได้ยืนยัน ส่งได้ไม่เกินหนึ่งครั้งต่อนาทีและห้าครั้งต่อชั่วโมง ตอบเหมือนกันทุกกรณีเพื่อไม่ให้รู้ว่าอีเมลใดมีบัญชี
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param requestPayload body ResendVerificationPayload true "Email"
+// @Success 202 {object} map[string]interface{} "Accepted" example({"message":"if the account exists and is not verified, a new link has been sent"})
+// @Failure 400 {object} utils.Problem "Bad Request"
+// @Router /api/v1/verify-email/resend [post]
+func (h *Handler) ResendVerification(c *fiber.Ctx) error {
+	var payload ResendVerificationPayload
+	if err := utils.ReadJSON(c, &payload); err != nil {
+		return utils.ErrorJSON(c, err)
+	}
+
+	email := strings.TrimSpace(payload.Email)
+	if email == "" {
+		return utils.ErrorJSON(c, errors.New("email is required"))
+	}
+
+	go h.resendEmailVerification(email)
+
+	resp := utils.JSONResponse{
+		Error:   false,
+		Message: "if the account exists and is not verified, a new link has been sent",
+	}
+
+	return utils.WriteJSON(c, fiber.StatusAccepted, resp)
+}
@@ -0,0 +1,196 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/NakarinFIgo/Movies-App/internal/entities"
+	"github.com/NakarinFIgo/Movies-App/internal/repository"
+)
+
+// ชนิดของ job ที่ระบบรู้จัก
+const (
+	// KindEnrichMovie ดึง poster และ metadata ที่ยังขาดของหนังจาก metadata provider
+	KindEnrichMovie = "movie.enrich"
+	// KindResyncMovie ดึง metadata ของหนังที่ผูกกับ TMDB แล้วมาอัปเดตใหม่
+	KindResyncMovie = "movie.resync"
+)
+
+const (
+	DefaultMaxAttempts = 5
+
+	defaultWorkers      = 2
+	defaultPollInterval = time.Second * 2
+	defaultJobTimeout   = time.Minute
+	defaultRetryBackoff = time.Second * 30
+	maxRetryBackoff     = time.Hour
+)
+
+// ErrPermanent ใช้ห่อ error ที่ลองใหม่ก็ไม่มีทางสำเร็จ job จะถูกย้ายไป dead ทันที
+var ErrPermanent = errors.New("permanent job failure")
+
+// Permanent ห่อ err ให้ worker รู้ว่าไม่ต้องลองใหม่
+func Permanent(err error) error {
+	return fmt.Errorf("%w: %v", ErrPermanent, err)
+}
+
+type HandlerFunc func(ctx context.Context, job *entities.Job) error
+
+// EnrichMoviePayload คือ payload ของ KindEnrichMovie และ KindResyncMovie
+type EnrichMoviePayload struct {
+	MovieID int `json:"movie_id"`
+}
+
+// New สร้าง job พร้อม payload แบบ JSON เพื่อส่งเข้า DatabaseRepo.EnqueueJob
+func New(kind string, payload interface{}) (entities.Job, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return entities.Job{}, err
+	}
+
+	return entities.Job{
+		Kind:        kind,
+		Payload:     data,
+		MaxAttempts: DefaultMaxAttempts,
+	}, nil
+}
+
+// Pool คือกลุ่ม worker goroutine ที่ดึงงานจากตาราง jobs มารัน
+type Pool struct {
+	DB           repository.DatabaseRepo
+	Workers      int
+	PollInterval time.Duration
+	JobTimeout   time.Duration
+	RetryBackoff time.Duration
+
+	handlers map[string]HandlerFunc
+}
+
+func NewPool(db repository.DatabaseRepo, workers int) *Pool {
+	if workers <= 0 {
+		workers = defaultWorkers
+	}
+
+	return &Pool{
+		DB:           db,
+		Workers:      workers,
+		PollInterval: defaultPollInterval,
+		JobTimeout:   defaultJobTimeout,
+		RetryBackoff: defaultRetryBackoff,
+		handlers:     make(map[string]HandlerFunc),
+	}
+}
+
+// Handle ลงทะเบียน handler ของ job แต่ละชนิด ต้องเรียกก่อน Start
+func (p *Pool) Handle(kind string, fn HandlerFunc) {
+	p.handlers[kind] = fn
+}
+
+// Start เริ่ม worker ทั้งหมด และหยุดเมื่อ ctx ถูก cancel
+func (p *Pool) Start(ctx context.Context) {
+	host, _ := os.Hostname()
+
+	for i := 0; i < p.Workers; i++ {
+		go p.work(ctx, fmt.Sprintf("%s-%d-%d", host, os.Getpid(), i))
+	}
+
+	go p.reapStale(ctx)
+}
+
+func (p *Pool) work(ctx context.Context, workerID string) {
+	for ctx.Err() == nil {
+		job, err := p.DB.ClaimJob(workerID)
+		if err != nil {
+			log.Println("jobs: claim failed:", err)
+			sleep(ctx, p.PollInterval)
+			continue
+		}
+		if job == nil {
+			sleep(ctx, p.PollInterval)
+			continue
+		}
+
+		p.run(ctx, workerID, job)
+	}
+}
+
+func (p *Pool) run(ctx context.Context, workerID string, job *entities.Job) {
+	handler, ok := p.handlers[job.Kind]
+	if !ok {
+		p.finish(workerID, job, Permanent(fmt.Errorf("no handler for job kind %q", job.Kind)))
+		return
+	}
+
+	jobCtx, cancel := context.WithTimeout(ctx, p.JobTimeout)
+	defer cancel()
+
+	p.finish(workerID, job, safeCall(jobCtx, handler, job))
+}
+
+func (p *Pool) finish(workerID string, job *entities.Job, err error) {
+	var updateErr error
+
+	switch {
+	case err == nil:
+		updateErr = p.DB.CompleteJob(job.ID, workerID)
+	case errors.Is(err, ErrPermanent) || job.Attempts >= job.MaxAttempts:
+		log.Printf("jobs: job %d (%s) moved to dead letter after %d attempts: %v", job.ID, job.Kind, job.Attempts, err)
+		updateErr = p.DB.DeadLetterJob(job.ID, workerID, err.Error())
+	default:
+		updateErr = p.DB.RetryJobAt(job.ID, workerID, err.Error(), time.Now().Add(p.backoff(job.Attempts)))
+	}
+
+	switch {
+	case errors.Is(updateErr, repository.ErrJobLockLost):
+		log.Printf("jobs: job %d (%s) was requeued while running, result of %s discarded", job.ID, job.Kind, workerID)
+	case updateErr != nil:
+		log.Printf("jobs: failed to update job %d: %v", job.ID, updateErr)
+	}
+}
+
+// backoff คือเวลารอก่อนลองใหม่ เพิ่มเป็นสองเท่าทุกครั้ง (30s, 1m, 2m, ...) แต่ไม่เกิน maxRetryBackoff
+func (p *Pool) backoff(attempts int) time.Duration {
+	wait := p.RetryBackoff
+	for i := 1; i < attempts && wait < maxRetryBackoff; i++ {
+		wait *= 2
+	}
+	if wait > maxRetryBackoff {
+		wait = maxRetryBackoff
+	}
+	return wait
+}
+
+// reapStale คืน job ที่ worker ถือไว้นานเกินไปกลับเข้าคิว
+func (p *Pool) reapStale(ctx context.Context) {
+	lockTimeout := p.JobTimeout * 2
+
+	for ctx.Err() == nil {
+		if n, err := p.DB.RequeueStaleJobs(lockTimeout); err != nil {
+			log.Println("jobs: requeue stale jobs failed:", err)
+		} else if n > 0 {
+			log.Printf("jobs: requeued %d stale jobs", n)
+		}
+		sleep(ctx, lockTimeout)
+	}
+}
+
+func safeCall(ctx context.Context, handler HandlerFunc, job *entities.Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return handler(ctx, job)
+}
+
+func sleep(ctx context.Context, d time.Duration) {
+	select {
+	case <-ctx.Done():
+	case <-time.After(d):
+	}
+}
@@ -0,0 +1,202 @@
+// Package loginguard ป้องกันการเดารหัสผ่าน โดยนับการ login ผิดแยกตามบัญชีและตาม IP
+// บัญชีที่ login ผิดติดกันจะต้องรอนานขึ้นเรื่อย ๆ ก่อนลองใหม่ และถูกล็อกชั่วคราวเมื่อผิดครบจำนวนที่กำหนด
+package loginguard
+
+import (
+	"strings"
+	"time"
+
+	"github.com/NakarinFIgo/Movies-App/internal/entities"
+)
+
+// Clock คืนเวลาปัจจุบัน แยกออกมาเพื่อให้ test ควบคุมเวลาได้
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock ใช้เวลาจริงของเครื่อง
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time { return time.Now() }
+
+// Store เก็บตัวนับการ login ผิด PostgresRepository ใช้เป็น Store ได้โดยตรง
+// UpdateLoginThrottle ต้องอ่าน แก้ และบันทึกตัวนับของ key แบบ atomic
+// การ login ผิดพร้อมกันหลายครั้งจึงนับครบทุกครั้ง key ที่ยังไม่มีจะส่งตัวนับว่างให้ update
+type Store interface {
+	LoginThrottles(keys ...string) (map[string]*entities.LoginThrottle, error)
+	UpdateLoginThrottle(key string, update func(t *entities.LoginThrottle)) error
+	DeleteLoginThrottles(keys ...string) error
+}
+
+// Policy คือเกณฑ์การหน่วงและการล็อก ค่า 0 ใน MaxAccountFailures หรือ MaxIPFailures หมายถึงไม่ล็อก
+type Policy struct {
+	// จำนวนครั้งที่ผิดติดกันก่อนล็อกบัญชี
+	MaxAccountFailures int
+	// จำนวนครั้งที่ผิดจาก IP เดียวก่อนล็อก IP นั้น
+	MaxIPFailures int
+	// การ login ผิดที่เก่ากว่านี้จะไม่นับรวม
+	Window time.Duration
+	// ระยะเวลาที่ล็อก
+	Lockout time.Duration
+	// เวลารอหลังผิดครั้งแรก เพิ่มเป็นสองเท่าทุกครั้งที่ผิด จนถึง MaxDelay
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+// DefaultPolicy คือค่าเริ่มต้นเมื่อไม่ได้ตั้งค่าใน .env
+var DefaultPolicy = Policy{
+	MaxAccountFailures: 5,
+	MaxIPFailures:      20,
+	Window:             15 * time.Minute,
+	Lockout:            15 * time.Minute,
+	BaseDelay:          time.Second,
+	MaxDelay:           30 * time.Second,
+}
+
+// Decision คือผลของ Check ถ้า Allowed เป็น false ให้ลองใหม่หลัง RetryAfter
+type Decision struct {
+	Allowed    bool
+	Locked     bool
+	RetryAfter time.Duration
+}
+
+// Guard ตัดสินว่าการ login ครั้งนี้ทำได้หรือไม่ และบันทึกผลลง Store
+type Guard struct {
+	Store  Store
+	Policy Policy
+	Clock  Clock
+}
+
+// New สร้าง Guard ที่ใช้เวลาจริง
+func New(store Store, policy Policy) *Guard {
+	return &Guard{Store: store, Policy: policy, Clock: SystemClock{}}
+}
+
+// AccountKey คือ key ของตัวนับรายบัญชี อีเมลไม่สนตัวพิมพ์ใหญ่เล็ก
+func AccountKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+// IPKey คือ key ของตัวนับราย IP
+func IPKey(ip string) string {
+	return "ip:" + ip
+}
+
+// Check ตรวจว่าบัญชีหรือ IP ถูกล็อกหรือยังอยู่ในช่วงที่ต้องรอหรือไม่
+func (g *Guard) Check(email, ip string) (Decision, error) {
+	accountKey, ipKey := AccountKey(email), IPKey(ip)
+
+	throttles, err := g.Store.LoginThrottles(accountKey, ipKey)
+	if err != nil {
+		return Decision{}, err
+	}
+
+	now := g.Clock.Now()
+	decision := Decision{Allowed: true}
+
+	for key, t := range throttles {
+		if t.LockedUntil != nil && now.Before(*t.LockedUntil) {
+			decision.Allowed = false
+			decision.Locked = true
+			decision.RetryAfter = maxDuration(decision.RetryAfter, t.LockedUntil.Sub(now))
+			continue
+		}
+
+		// IP หนึ่งอาจมีผู้ใช้หลายคน (NAT) จึงหน่วงเฉพาะรายบัญชี และใช้แค่การล็อกกับ IP
+		if key != accountKey || g.expired(t, now) {
+			continue
+		}
+
+		if wait := t.LastFailureAt.Add(g.delay(t.Failures)).Sub(now); wait > 0 {
+			decision.Allowed = false
+			decision.RetryAfter = maxDuration(decision.RetryAfter, wait)
+		}
+	}
+
+	return decision, nil
+}
+
+// Failure นับการ login ผิดหนึ่งครั้ง และล็อกถ้าครบจำนวน
+func (g *Guard) Failure(email, ip string) error {
+	now := g.Clock.Now()
+
+	for _, counter := range []struct {
+		key   string
+		limit int
+	}{
+		{AccountKey(email), g.Policy.MaxAccountFailures},
+		{IPKey(ip), g.Policy.MaxIPFailures},
+	} {
+		err := g.Store.UpdateLoginThrottle(counter.key, func(t *entities.LoginThrottle) {
+			if g.expired(t, now) {
+				*t = entities.LoginThrottle{Key: counter.key}
+			}
+
+			t.Failures++
+			t.LastFailureAt = now
+			if counter.limit > 0 && t.Failures >= counter.limit {
+				until := now.Add(g.Policy.Lockout)
+				t.LockedUntil = &until
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Success ล้างตัวนับของบัญชีหลัง login สำเร็จ ตัวนับของ IP ยังคงอยู่
+// เพื่อไม่ให้ผู้โจมตีล้างตัวนับ IP ได้ด้วยการ login บัญชีของตัวเอง
+func (g *Guard) Success(email string) error {
+	return g.Store.DeleteLoginThrottles(AccountKey(email))
+}
+
+// Unlock ปลดล็อกบัญชี ใช้โดย admin
+func (g *Guard) Unlock(email string) error {
+	return g.Store.DeleteLoginThrottles(AccountKey(email))
+}
+
+// UnlockIP ปลดล็อก IP ใช้โดย admin
+func (g *Guard) UnlockIP(ip string) error {
+	return g.Store.DeleteLoginThrottles(IPKey(ip))
+}
+
+// expired บอกว่าการ login ผิดครั้งล่าสุดเก่าเกิน Window และไม่ได้ถูกล็อกอยู่
+func (g *Guard) expired(t *entities.LoginThrottle, now time.Time) bool {
+	if t.LockedUntil != nil && now.Before(*t.LockedUntil) {
+		return false
+	}
+	if t.LockedUntil != nil {
+		// ล็อกหมดอายุแล้ว เริ่มนับใหม่
+		return true
+	}
+	return now.Sub(t.LastFailureAt) > g.Policy.Window
+}
+
+// delay คือเวลาที่ต้องรอหลังผิด failures ครั้ง: BaseDelay, 2×, 4×, ... ไม่เกิน MaxDelay
+func (g *Guard) delay(failures int) time.Duration {
+	if failures <= 0 || g.Policy.BaseDelay <= 0 {
+		return 0
+	}
+
+	d := g.Policy.BaseDelay
+	for i := 1; i < failures; i++ {
+		d *= 2
+		if g.Policy.MaxDelay > 0 && d >= g.Policy.MaxDelay {
+			return g.Policy.MaxDelay
+		}
+	}
+	if g.Policy.MaxDelay > 0 && d > g.Policy.MaxDelay {
+		return g.Policy.MaxDelay
+	}
+	return d
+}
+
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
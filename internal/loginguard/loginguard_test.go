@@ -0,0 +1,260 @@
+package loginguard
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NakarinFIgo/Movies-App/internal/entities"
+)
+
+// memoryStore เก็บตัวนับในหน่วยความจำแทน Postgres
+type memoryStore struct {
+	mu        sync.Mutex
+	throttles map[string]entities.LoginThrottle
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{throttles: make(map[string]entities.LoginThrottle)}
+}
+
+func (s *memoryStore) LoginThrottles(keys ...string) (map[string]*entities.LoginThrottle, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make(map[string]*entities.LoginThrottle)
+	for _, key := range keys {
+		if t, ok := s.throttles[key]; ok {
+			result[key] = &t
+		}
+	}
+	return result, nil
+}
+
+func (s *memoryStore) UpdateLoginThrottle(key string, update func(t *entities.LoginThrottle)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := s.throttles[key]
+	t.Key = key
+	update(&t)
+	s.throttles[key] = t
+	return nil
+}
+
+func (s *memoryStore) DeleteLoginThrottles(keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range keys {
+		delete(s.throttles, key)
+	}
+	return nil
+}
+
+// fakeClock คือนาฬิกาที่เดินเมื่อเรียก Advance เท่านั้น
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+var testPolicy = Policy{
+	MaxAccountFailures: 4,
+	MaxIPFailures:      6,
+	Window:             10 * time.Minute,
+	Lockout:            15 * time.Minute,
+	BaseDelay:          time.Second,
+	MaxDelay:           4 * time.Second,
+}
+
+const (
+	testEmail = "user@example.com"
+	testIP    = "192.0.2.1"
+)
+
+func newTestGuard() (*Guard, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)}
+	return &Guard{Store: newMemoryStore(), Policy: testPolicy, Clock: clock}, clock
+}
+
+func fail(t *testing.T, g *Guard, email, ip string, times int) {
+	t.Helper()
+	for i := 0; i < times; i++ {
+		if err := g.Failure(email, ip); err != nil {
+			t.Fatalf("Failure: %v", err)
+		}
+	}
+}
+
+func check(t *testing.T, g *Guard, email, ip string) Decision {
+	t.Helper()
+	d, err := g.Check(email, ip)
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	return d
+}
+
+func TestDelayDoublesAfterEachFailure(t *testing.T) {
+	for failures, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second} {
+		g, _ := newTestGuard()
+		fail(t, g, testEmail, testIP, failures)
+
+		d := check(t, g, testEmail, testIP)
+		if d.Allowed || d.Locked {
+			t.Fatalf("after %d failures: got %+v, want delayed", failures, d)
+		}
+		if d.RetryAfter != want {
+			t.Errorf("after %d failures: RetryAfter = %v, want %v", failures, d.RetryAfter, want)
+		}
+	}
+}
+
+func TestDelayIsCappedAtMaxDelay(t *testing.T) {
+	g, _ := newTestGuard()
+	g.Policy.MaxAccountFailures = 0
+
+	fail(t, g, testEmail, testIP, 5)
+
+	d := check(t, g, testEmail, testIP)
+	if d.Locked {
+		t.Fatalf("got locked with MaxAccountFailures 0: %+v", d)
+	}
+	if d.RetryAfter != testPolicy.MaxDelay {
+		t.Errorf("RetryAfter = %v, want %v", d.RetryAfter, testPolicy.MaxDelay)
+	}
+}
+
+func TestDelayExpires(t *testing.T) {
+	g, clock := newTestGuard()
+	fail(t, g, testEmail, testIP, 2)
+
+	clock.Advance(2 * time.Second)
+
+	if d := check(t, g, testEmail, testIP); !d.Allowed {
+		t.Errorf("after the delay: got %+v, want allowed", d)
+	}
+}
+
+func TestLockoutAtThreshold(t *testing.T) {
+	g, clock := newTestGuard()
+
+	fail(t, g, testEmail, testIP, testPolicy.MaxAccountFailures-1)
+	if d := check(t, g, testEmail, testIP); d.Locked {
+		t.Fatalf("locked before the threshold: %+v", d)
+	}
+
+	fail(t, g, testEmail, testIP, 1)
+	d := check(t, g, testEmail, testIP)
+	if d.Allowed || !d.Locked {
+		t.Fatalf("at the threshold: got %+v, want locked", d)
+	}
+	if d.RetryAfter != testPolicy.Lockout {
+		t.Errorf("RetryAfter = %v, want %v", d.RetryAfter, testPolicy.Lockout)
+	}
+
+	clock.Advance(testPolicy.Lockout)
+	if d := check(t, g, testEmail, testIP); !d.Allowed {
+		t.Errorf("after the lockout: got %+v, want allowed", d)
+	}
+}
+
+func TestFailuresOutsideWindowAreForgotten(t *testing.T) {
+	g, clock := newTestGuard()
+
+	fail(t, g, testEmail, testIP, testPolicy.MaxAccountFailures-1)
+	clock.Advance(testPolicy.Window + time.Second)
+
+	if d := check(t, g, testEmail, testIP); !d.Allowed {
+		t.Fatalf("after the window: got %+v, want allowed", d)
+	}
+
+	// นับใหม่จากหนึ่ง จึงยังไม่ล็อกและรอแค่ BaseDelay
+	fail(t, g, testEmail, testIP, 1)
+	d := check(t, g, testEmail, testIP)
+	if d.Locked {
+		t.Fatalf("locked by failures outside the window: %+v", d)
+	}
+	if d.RetryAfter != testPolicy.BaseDelay {
+		t.Errorf("RetryAfter = %v, want %v", d.RetryAfter, testPolicy.BaseDelay)
+	}
+}
+
+func TestSuccessKeepsIPCounter(t *testing.T) {
+	g, _ := newTestGuard()
+
+	// ลองหลายบัญชีจาก IP เดียว แต่ละบัญชียังไม่ถึงเกณฑ์ แต่ IP ถึงเกณฑ์
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		fail(t, g, email, testIP, 2)
+	}
+	if err := g.Success("a@example.com"); err != nil {
+		t.Fatalf("Success: %v", err)
+	}
+
+	d := check(t, g, "a@example.com", testIP)
+	if d.Allowed || !d.Locked {
+		t.Errorf("IP lock after Success: got %+v, want locked", d)
+	}
+
+	if d := check(t, g, "a@example.com", "198.51.100.7"); !d.Allowed {
+		t.Errorf("account counter after Success: got %+v, want allowed", d)
+	}
+}
+
+func TestUnlock(t *testing.T) {
+	g, _ := newTestGuard()
+
+	fail(t, g, testEmail, testIP, testPolicy.MaxAccountFailures)
+	if d := check(t, g, testEmail, "198.51.100.7"); !d.Locked {
+		t.Fatalf("got %+v, want locked", d)
+	}
+
+	if err := g.Unlock(testEmail); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	if d := check(t, g, testEmail, "198.51.100.7"); !d.Allowed {
+		t.Errorf("after Unlock: got %+v, want allowed", d)
+	}
+}
+
+func TestUnlockIP(t *testing.T) {
+	g, _ := newTestGuard()
+
+	fail(t, g, testEmail, testIP, testPolicy.MaxIPFailures)
+	if err := g.Unlock(testEmail); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	if d := check(t, g, "other@example.com", testIP); !d.Locked {
+		t.Fatalf("got %+v, want IP locked", d)
+	}
+
+	if err := g.UnlockIP(testIP); err != nil {
+		t.Fatalf("UnlockIP: %v", err)
+	}
+	if d := check(t, g, "other@example.com", testIP); !d.Allowed {
+		t.Errorf("after UnlockIP: got %+v, want allowed", d)
+	}
+}
+
+func TestConcurrentFailuresAreAllCounted(t *testing.T) {
+	g, _ := newTestGuard()
+
+	var wg sync.WaitGroup
+	for i := 0; i < testPolicy.MaxAccountFailures; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := g.Failure(testEmail, testIP); err != nil {
+				t.Errorf("Failure: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if d := check(t, g, testEmail, testIP); !d.Locked {
+		t.Errorf("got %+v, want locked after %d parallel failures", d, testPolicy.MaxAccountFailures)
+	}
+}
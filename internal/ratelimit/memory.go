@@ -0,0 +1,56 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+
+	"github.com/NakarinFIgo/Movies-App/internal/entities"
+)
+
+// sweepInterval คือระยะห่างขั้นต่ำของการลบ bucket ที่เต็มแล้วออกจากหน่วยความจำ
+const sweepInterval = time.Minute
+
+// MemoryStore เก็บ bucket ในหน่วยความจำของโปรเซส ใช้ได้เมื่อรัน instance เดียว
+// ถ้ารันหลาย instance แต่ละตัวจะนับแยกกัน ให้ใช้ PostgresRepository แทน
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*entities.RateLimitBucket
+	lastSweep time.Time
+}
+
+// NewMemoryStore สร้าง MemoryStore ที่ว่าง
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*entities.RateLimitBucket)}
+}
+
+func (s *MemoryStore) TakeRateLimitToken(key string, capacity int, period time.Duration) (entities.RateLimitBucket, bool, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &entities.RateLimitBucket{Key: key, Tokens: float64(capacity), RefilledAt: now}
+		s.buckets[key] = b
+	}
+
+	allowed := b.Take(capacity, period, now)
+	return *b, allowed, nil
+}
+
+// sweep ลบ bucket ที่เต็มแล้ว เพราะผลเหมือนกับ key ที่ไม่เคยส่ง request มาก่อน
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		if !now.Before(b.ExpiresAt) {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+// Package ratelimit จำกัดจำนวน request ด้วย token bucket แยกตาม IP ผู้ใช้ หรือ API key
+// แต่ละกลุ่ม route มีนโยบายของตัวเอง ตัวนับเก็บใน Store ซึ่งเป็นหน่วยความจำหรือ Postgres ก็ได้
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NakarinFIgo/Movies-App/internal/entities"
+	"github.com/NakarinFIgo/Movies-App/pkg/middlewares"
+	"github.com/NakarinFIgo/Movies-App/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// CodeRateLimited คือรหัส error เมื่อส่ง request เกินนโยบาย
+const CodeRateLimited = "rate_limited"
+
+var ErrRateLimited = errors.New("too many requests, try again later")
+
+// สิ่งที่ใช้แยกตัวนับของแต่ละนโยบาย
+const (
+	// KeyIP นับตาม IP ของ client
+	KeyIP = "ip"
+	// KeyUser นับตามผู้ใช้หรือ API key ที่ยืนยันตัวตนแล้ว ถ้ายังไม่ได้ยืนยันตัวตนจะนับตาม IP
+	KeyUser = "user"
+	// KeyAPIKey นับตาม API key ส่วน request อื่นนับตาม IP
+	KeyAPIKey = "api_key"
+)
+
+// ชื่อนโยบายของกลุ่ม route ที่ตั้งค่าได้ด้วย RATE_LIMIT_<NAME>
+const (
+	PolicyDefault = "default"
+	PolicyLogin   = "login"
+	PolicyAccount = "account"
+	PolicyAdmin   = "admin"
+)
+
+// Store เก็บ token bucket PostgresRepository ใช้เป็น Store ได้โดยตรง
+type Store interface {
+	TakeRateLimitToken(key string, capacity int, period time.Duration) (entities.RateLimitBucket, bool, error)
+}
+
+// Policy คือนโยบายของกลุ่ม route หนึ่ง ให้ส่งได้ Requests ครั้งต่อ Period ส่งติดกันได้ไม่เกิน Requests
+// Requests เป็น 0 คือไม่จำกัด
+type Policy struct {
+	Name     string
+	Requests int
+	Period   time.Duration
+	KeyBy    string
+}
+
+// DefaultPolicies คือนโยบายเริ่มต้นเมื่อไม่ได้ตั้งค่าใน .env
+var DefaultPolicies = map[string]Policy{
+	PolicyDefault: {Name: PolicyDefault, Requests: 300, Period: time.Minute, KeyBy: KeyIP},
+	PolicyLogin:   {Name: PolicyLogin, Requests: 10, Period: time.Minute, KeyBy: KeyIP},
+	PolicyAccount: {Name: PolicyAccount, Requests: 20, Period: time.Hour, KeyBy: KeyIP},
+	PolicyAdmin:   {Name: PolicyAdmin, Requests: 120, Period: time.Minute, KeyBy: KeyUser},
+}
+
+// ParseLimit อ่านค่ารูปแบบ "<requests>/<period>" เช่น "10/1m" หรือ "off" เพื่อไม่จำกัด
+func ParseLimit(s string) (requests int, period time.Duration, err error) {
+	if strings.EqualFold(strings.TrimSpace(s), "off") {
+		return 0, 0, nil
+	}
+
+	n, d, ok := strings.Cut(s, "/")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid rate limit %q, expected <requests>/<period> such as 10/1m", s)
+	}
+	requests, err = strconv.Atoi(strings.TrimSpace(n))
+	if err != nil || requests <= 0 {
+		return 0, 0, fmt.Errorf("invalid request count in rate limit %q", s)
+	}
+	period, err = time.ParseDuration(strings.TrimSpace(d))
+	if err != nil || period <= 0 {
+		return 0, 0, fmt.Errorf("invalid period in rate limit %q", s)
+	}
+	return requests, period, nil
+}
+
+// key คือ key ของ bucket ของ request นี้ภายใต้นโยบาย
+func (p Policy) key(c *fiber.Ctx) string {
+	if claims, ok := middlewares.ClaimsFromContext(c); ok {
+		switch {
+		case claims.IsAPIKey() && (p.KeyBy == KeyAPIKey || p.KeyBy == KeyUser):
+			return p.Name + ":apikey:" + claims.ID
+		case !claims.IsAPIKey() && p.KeyBy == KeyUser:
+			return p.Name + ":user:" + claims.Subject
+		}
+	}
+	return p.Name + ":ip:" + c.IP()
+}
+
+// Middleware จำกัด request ตาม policy ทุก response มี header RateLimit-* และ request ที่เกินจะได้ 429 พร้อม Retry-After
+// นโยบายที่นับตามผู้ใช้หรือ API key ต้องใช้หลัง AuthRequired
+func Middleware(store Store, policy Policy) fiber.Handler {
+	if policy.Requests <= 0 || policy.Period <= 0 {
+		return func(c *fiber.Ctx) error {
+			return c.Next()
+		}
+	}
+
+	perSecond := float64(policy.Requests) / policy.Period.Seconds()
+	policyHeader := fmt.Sprintf("%d;w=%d", policy.Requests, int(math.Ceil(policy.Period.Seconds())))
+
+	return func(c *fiber.Ctx) error {
+		bucket, allowed, err := store.TakeRateLimitToken(policy.key(c), policy.Requests, policy.Period)
+		if err != nil {
+			// ให้ request ผ่านเมื่อนับไม่ได้ ดีกว่าทำให้ทั้ง API ใช้ไม่ได้ตาม database
+			log.Printf("rate limit %s: %v", policy.Name, err)
+			return c.Next()
+		}
+
+		c.Set("RateLimit-Policy", policyHeader)
+		c.Set("RateLimit-Limit", strconv.Itoa(policy.Requests))
+		c.Set("RateLimit-Remaining", strconv.Itoa(int(bucket.Tokens)))
+		c.Set("RateLimit-Reset", strconv.Itoa(seconds(time.Until(bucket.ExpiresAt))))
+
+		if !allowed {
+			wait := time.Duration((1 - bucket.Tokens) / perSecond * float64(time.Second))
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(max(1, seconds(wait))))
+			return utils.ErrorCodeJSON(c, ErrRateLimited, CodeRateLimited, fiber.StatusTooManyRequests)
+		}
+
+		return c.Next()
+	}
+}
+
+// seconds ปัด d ขึ้นเป็นวินาทีเต็ม ค่าติดลบเป็น 0
+func seconds(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return int(math.Ceil(d.Seconds()))
+}
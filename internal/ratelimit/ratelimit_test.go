@@ -0,0 +1,170 @@
+package ratelimit
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/NakarinFIgo/Movies-App/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		in       string
+		requests int
+		period   time.Duration
+		wantErr  bool
+	}{
+		{in: "10/1m", requests: 10, period: time.Minute},
+		{in: " 300 / 1m ", requests: 300, period: time.Minute},
+		{in: "20/1h", requests: 20, period: time.Hour},
+		{in: "5/30s", requests: 5, period: 30 * time.Second},
+		{in: "off"},
+		{in: "OFF"},
+		{in: "10", wantErr: true},
+		{in: "", wantErr: true},
+		{in: "0/1m", wantErr: true},
+		{in: "-1/1m", wantErr: true},
+		{in: "ten/1m", wantErr: true},
+		{in: "10/0s", wantErr: true},
+		{in: "10/-1m", wantErr: true},
+		{in: "10/minute", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		requests, period, err := ParseLimit(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseLimit(%q) err = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if requests != tt.requests || period != tt.period {
+			t.Errorf("ParseLimit(%q) = %d, %v, want %d, %v", tt.in, requests, period, tt.requests, tt.period)
+		}
+	}
+}
+
+// newLimitedApp รัน app บน loopback จริง เพราะ app.Test ไม่ได้ใช้ RemoteAddr ของ request
+// c.IP() จึงเป็น 127.0.0.1 เสมอเมื่อไม่ได้เชื่อ proxy header
+func newLimitedApp(t *testing.T, policy Policy, cfg fiber.Config) string {
+	t.Helper()
+
+	cfg.DisableStartupMessage = true
+	cfg.ErrorHandler = func(c *fiber.Ctx, err error) error {
+		status := fiber.StatusInternalServerError
+		var httpErr *utils.HTTPError
+		if errors.As(err, &httpErr) {
+			status = httpErr.Status
+		}
+		return c.Status(status).SendString(err.Error())
+	}
+
+	app := fiber.New(cfg)
+	app.Use(Middleware(NewMemoryStore(), policy))
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendString(c.IP())
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = app.Listener(ln) }()
+	t.Cleanup(func() { _ = app.Shutdown() })
+
+	return "http://" + ln.Addr().String() + "/"
+}
+
+func send(t *testing.T, url string, header map[string]string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp
+}
+
+func TestMiddleware(t *testing.T) {
+	url := newLimitedApp(t, Policy{Name: "test", Requests: 2, Period: time.Minute, KeyBy: KeyIP}, fiber.Config{})
+
+	for i := 0; i < 2; i++ {
+		resp := send(t, url, nil)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("request %d: status %d, want 200", i+1, resp.StatusCode)
+		}
+		if got := resp.Header.Get("RateLimit-Remaining"); got != strconv.Itoa(1-i) {
+			t.Errorf("request %d: RateLimit-Remaining = %q, want %d", i+1, got, 1-i)
+		}
+	}
+
+	resp := send(t, url, nil)
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("status %d, want 429", resp.StatusCode)
+	}
+	if resp.Header.Get(fiber.HeaderRetryAfter) == "" {
+		t.Error("429 without Retry-After")
+	}
+	if got := resp.Header.Get("RateLimit-Policy"); got != "2;w=60" {
+		t.Errorf("RateLimit-Policy = %q, want 2;w=60", got)
+	}
+}
+
+func TestMiddlewareOff(t *testing.T) {
+	url := newLimitedApp(t, Policy{Name: "test"}, fiber.Config{})
+
+	for i := 0; i < 5; i++ {
+		if resp := send(t, url, nil); resp.StatusCode != http.StatusOK {
+			t.Fatalf("status %d with the policy off, want 200", resp.StatusCode)
+		}
+	}
+}
+
+// proxyConfig ตรงกับที่ main.go ตั้งเมื่อกำหนด PROXY_HEADER และ TRUSTED_PROXIES
+func proxyConfig(trusted ...string) fiber.Config {
+	return fiber.Config{
+		ProxyHeader:             fiber.HeaderXForwardedFor,
+		EnableTrustedProxyCheck: true,
+		TrustedProxies:          trusted,
+		EnableIPValidation:      true,
+	}
+}
+
+func TestMiddlewareTrustedProxy(t *testing.T) {
+	url := newLimitedApp(t, Policy{Name: "test", Requests: 1, Period: time.Minute, KeyBy: KeyIP}, proxyConfig("127.0.0.0/8"))
+
+	// client สองคนหลัง proxy เดียวกันนับแยกกัน
+	for _, client := range []string{"203.0.113.1", "203.0.113.2"} {
+		resp := send(t, url, map[string]string{fiber.HeaderXForwardedFor: client})
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("client %s behind the proxy: status %d, want 200", client, resp.StatusCode)
+		}
+	}
+	resp := send(t, url, map[string]string{fiber.HeaderXForwardedFor: "203.0.113.1"})
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("repeat from 203.0.113.1: status %d, want 429", resp.StatusCode)
+	}
+}
+
+func TestMiddlewareIgnoresHeaderFromUntrustedClient(t *testing.T) {
+	url := newLimitedApp(t, Policy{Name: "test", Requests: 1, Period: time.Minute, KeyBy: KeyIP}, proxyConfig("10.0.0.0/8"))
+
+	send(t, url, map[string]string{fiber.HeaderXForwardedFor: "203.0.113.1"})
+
+	// เปลี่ยน header เพื่อหลบตัวนับไม่ได้ เพราะ client ไม่ได้อยู่ใน TRUSTED_PROXIES
+	resp := send(t, url, map[string]string{fiber.HeaderXForwardedFor: "203.0.113.99"})
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("spoofed header: status %d, want 429", resp.StatusCode)
+	}
+}
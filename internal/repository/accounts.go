@@ -0,0 +1,276 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/NakarinFIgo/Movies-App/internal/entities"
+	"gorm.io/gorm"
+)
+
+var ErrUserNotFound = notFoundError("user not found")
+
+// UpdateUserProfile เปลี่ยนชื่อและนามสกุลของผู้ใช้
+func (m *PostgresRepository) UpdateUserProfile(id int, firstName, lastName string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	result := m.DB.WithContext(ctx).Model(&entities.User{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"first_name": firstName,
+			"last_name":  lastName,
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// RehashPassword เปลี่ยน hash ของรหัสผ่านเดิมเป็น hash ที่ใช้ cost ใหม่ ไม่ revoke session
+// ถ้ารหัสผ่านถูกเปลี่ยนไปแล้วหลังอ่าน oldHash จะไม่ทำอะไร
+func (m *PostgresRepository) RehashPassword(userID int, oldHash, newHash string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	return m.DB.WithContext(ctx).Model(&entities.User{}).
+		Where("id = ? AND password = ?", userID, oldHash).
+		Update("password", newHash).Error
+}
+
+// ChangePassword ตั้งรหัสผ่านใหม่ revoke ทุก session ของผู้ใช้ยกเว้น keepFamilyID
+// และทำให้ลิงก์ตั้งรหัสผ่านใหม่ที่ค้างอยู่ใช้ไม่ได้ คืนจำนวน refresh token ที่ถูก revoke
+func (m *PostgresRepository) ChangePassword(userID int, passwordHash, keepFamilyID string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	var revoked int64
+
+	err := m.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		err := tx.Model(&entities.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"password":   passwordHash,
+			"updated_at": now,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&entities.PasswordReset{}).
+			Where("user_id = ? AND used_at IS NULL", userID).
+			Update("used_at", now).Error
+		if err != nil {
+			return err
+		}
+
+		query := tx.Model(&entities.Session{}).Where("user_id = ? AND revoked_at IS NULL", userID)
+		if keepFamilyID != "" {
+			query = query.Where("family_id <> ?", keepFamilyID)
+		}
+		result := query.Update("revoked_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		revoked = result.RowsAffected
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return revoked, nil
+}
+
+// ScheduleUserDeletion ตั้งเวลาลบบัญชีและ revoke ทุก session ของผู้ใช้
+// คืน ErrLastAdmin ถ้าผู้ใช้เป็น admin คนสุดท้ายที่ยังไม่ได้ขอลบบัญชี
+func (m *PostgresRepository) ScheduleUserDeletion(userID int, at time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	return m.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		last, err := isLastAdmin(tx, userID)
+		if err != nil {
+			return err
+		}
+		if last {
+			return ErrLastAdmin
+		}
+
+		now := time.Now()
+
+		result := tx.Model(&entities.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"deletion_scheduled_at": at,
+			"updated_at":            now,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrUserNotFound
+		}
+
+		return tx.Model(&entities.Session{}).
+			Where("user_id = ? AND revoked_at IS NULL", userID).
+			Update("revoked_at", now).Error
+	})
+}
+
+// CancelUserDeletion ยกเลิกการลบบัญชีที่ตั้งเวลาไว้
+func (m *PostgresRepository) CancelUserDeletion(userID int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	return m.DB.WithContext(ctx).Model(&entities.User{}).
+		Where("id = ?", userID).
+		Updates(map[string]interface{}{
+			"deletion_scheduled_at": nil,
+			"updated_at":            time.Now(),
+		}).Error
+}
+
+// PurgeDeletedUsers ลบบัญชีที่ถึงเวลาลบแล้ว ข้อมูลที่ผูกกับผู้ใช้ถูกลบตาม foreign key
+// ส่วนประวัติการ login จะเหลือไว้โดยไม่มี user id
+func (m *PostgresRepository) PurgeDeletedUsers(now time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	result := m.DB.WithContext(ctx).
+		Where("deletion_scheduled_at <= ?", now).
+		Delete(&entities.User{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+// UserSessions ดึง refresh token ทุกตัวที่ยังเก็บไว้ของผู้ใช้ ใช้ตอน export ข้อมูล
+func (m *PostgresRepository) UserSessions(userID int) ([]*entities.Session, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	var sessions []*entities.Session
+	err := m.DB.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at desc, id desc").
+		Find(&sessions).Error
+	if err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// UserLoginAttempts ดึงประวัติการ login ทั้งหมดของผู้ใช้ ใช้ตอน export ข้อมูล
+func (m *PostgresRepository) UserLoginAttempts(userID int) ([]*entities.LoginAttempt, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	var attempts []*entities.LoginAttempt
+	err := m.DB.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at desc, id desc").
+		Find(&attempts).Error
+	if err != nil {
+		return nil, err
+	}
+	return attempts, nil
+}
+
+// APIKeysCreatedBy ดึง API key ที่ผู้ใช้สร้าง
+func (m *PostgresRepository) APIKeysCreatedBy(userID int) ([]*entities.APIKey, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	var keys []*entities.APIKey
+	err := m.DB.WithContext(ctx).
+		Where("created_by = ?", userID).
+		Order("id").
+		Find(&keys).Error
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// SearchUsers ค้นหาผู้ใช้จากชื่อหรืออีเมล กรองตามบทบาทและสถานะ (entities.UserStatus*)
+// คืนผู้ใช้ในหน้าที่ขอและจำนวนผู้ใช้ทั้งหมดที่ตรงเงื่อนไข
+func (m *PostgresRepository) SearchUsers(search, role, status string, limit, offset int) ([]*entities.User, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	query := m.DB.WithContext(ctx).Model(&entities.User{})
+
+	if search != "" {
+		pattern := "%" + escapeLike(search) + "%"
+		query = query.Where("email ILIKE ? OR first_name ILIKE ? OR last_name ILIKE ? OR (first_name || ' ' || last_name) ILIKE ?",
+			pattern, pattern, pattern, pattern)
+	}
+	if role != "" {
+		query = query.Where("role = ?", role)
+	}
+
+	switch status {
+	case entities.UserStatusActive:
+		query = query.Where("disabled_at IS NULL AND email_verified_at IS NOT NULL AND deletion_scheduled_at IS NULL")
+	case entities.UserStatusDisabled:
+		query = query.Where("disabled_at IS NOT NULL")
+	case entities.UserStatusUnverified:
+		query = query.Where("email_verified_at IS NULL")
+	case entities.UserStatusPendingDeletion:
+		query = query.Where("deletion_scheduled_at IS NOT NULL")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []*entities.User
+	if err := query.Order("id").Limit(limit).Offset(offset).Find(&users).Error; err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+// escapeLike ใส่ escape ให้ตัวอักษรพิเศษของ LIKE ค่าที่ผู้ใช้ค้นจึงเป็นข้อความธรรมดา
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// SetUserDisabled ปิดหรือเปิดบัญชี การปิดบัญชีจะ revoke ทุก session ของผู้ใช้ด้วย
+func (m *PostgresRepository) SetUserDisabled(userID int, disabled bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	return m.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		var disabledAt interface{}
+		if disabled {
+			disabledAt = now
+		}
+
+		result := tx.Model(&entities.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"disabled_at": disabledAt,
+			"updated_at":  now,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrUserNotFound
+		}
+
+		if !disabled {
+			return nil
+		}
+		return tx.Model(&entities.Session{}).
+			Where("user_id = ? AND revoked_at IS NULL", userID).
+			Update("revoked_at", now).Error
+	})
+}
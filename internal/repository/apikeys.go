@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/NakarinFIgo/Movies-App/internal/entities"
+	"gorm.io/gorm"
+)
+
+var ErrAPIKeyNotFound = notFoundError("api key not found")
+
+// apiKeyTouchInterval คือระยะห่างขั้นต่ำของการอัปเดต last_used_at ไม่ให้เขียน database ทุก request
+const apiKeyTouchInterval = time.Minute
+
+func (m *PostgresRepository) InsertAPIKey(key entities.APIKey) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	if key.CreatedAt.IsZero() {
+		key.CreatedAt = time.Now()
+	}
+
+	if err := m.DB.WithContext(ctx).Create(&key).Error; err != nil {
+		return 0, err
+	}
+	return key.ID, nil
+}
+
+// AllAPIKeys ดึง API key ทั้งหมด รวมที่ถูก revoke หรือหมดอายุแล้ว
+func (m *PostgresRepository) AllAPIKeys() ([]*entities.APIKey, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	var keys []*entities.APIKey
+	if err := m.DB.WithContext(ctx).Order("id desc").Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// APIKeyByHash ดึง API key จาก hash ของ key ไม่ตรวจว่าถูก revoke หรือหมดอายุ
+func (m *PostgresRepository) APIKeyByHash(keyHash string) (*entities.APIKey, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	var key entities.APIKey
+	err := m.DB.WithContext(ctx).Where("key_hash = ?", keyHash).First(&key).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+// RevokeAPIKey ทำให้ API key ใช้ไม่ได้อีก
+func (m *PostgresRepository) RevokeAPIKey(id int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	res := m.DB.WithContext(ctx).Model(&entities.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// TouchAPIKey บันทึกเวลาที่ใช้ key ล่าสุด อัปเดตไม่เกินนาทีละครั้ง
+func (m *PostgresRepository) TouchAPIKey(id int64, at time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	return m.DB.WithContext(ctx).Model(&entities.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, at.Add(-apiKeyTouchInterval)).
+		Update("last_used_at", at).Error
+}
+
+func (m *PostgresRepository) InsertAuditLog(entry entities.AuditLog) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+
+	return m.DB.WithContext(ctx).Create(&entry).Error
+}
+
+// PurgeAuditLogs ลบบันทึกที่เก่ากว่า before
+func (m *PostgresRepository) PurgeAuditLogs(before time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	result := m.DB.WithContext(ctx).
+		Where("created_at < ?", before).
+		Delete(&entities.AuditLog{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+// AuditLogs ดึงบันทึกล่าสุด กรองตามประเภทและ ID ของผู้กระทำได้
+func (m *PostgresRepository) AuditLogs(actorType, actorID string, limit, offset int) ([]*entities.AuditLog, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	var logs []*entities.AuditLog
+
+	query := m.DB.WithContext(ctx).Order("id desc").Limit(limit).Offset(offset)
+	if actorType != "" {
+		query = query.Where("actor_type = ?", actorType)
+	}
+	if actorID != "" {
+		query = query.Where("actor_id = ?", actorID)
+	}
+
+	if err := query.Find(&logs).Error; err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
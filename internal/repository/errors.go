@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// ประเภทของ error จาก repository ใช้กับ errors.Is เพื่อเลือก HTTP status โดยไม่ต้องรู้จัก error ทุกตัว
+var (
+	ErrNotFound   = errors.New("not found")
+	ErrConflict   = errors.New("conflict")
+	ErrValidation = errors.New("invalid data")
+)
+
+// Error คือ error ของ repository ที่ระบุประเภทไว้ใน Kind ข้อความใน Msg ส่งให้ client ได้
+type Error struct {
+	Kind error
+	Msg  string
+	// Err คือ error ต้นทาง เช่น gorm.ErrRecordNotFound ให้โค้ดที่ตรวจ error เดิมยังใช้ได้
+	Err error
+}
+
+func (e *Error) Error() string {
+	return e.Msg
+}
+
+func (e *Error) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.Kind}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+func notFoundError(msg string) error {
+	return &Error{Kind: ErrNotFound, Msg: msg}
+}
+
+func conflictError(msg string) error {
+	return &Error{Kind: ErrConflict, Msg: msg}
+}
+
+func validationError(msg string) error {
+	return &Error{Kind: ErrValidation, Msg: msg}
+}
+
+// Postgres error codes ที่ใช้แยกประเภทของ constraint violation
+const (
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
+	pgExclusionViolation  = "23P01"
+	pgCheckViolation      = "23514"
+	pgNotNullViolation    = "23502"
+)
+
+func pgErrorCode(err error) string {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code
+	}
+	return ""
+}
+
+// Kind คืนประเภทของ err คือ ErrNotFound, ErrConflict หรือ ErrValidation
+// รวมถึง error ดิบจาก GORM และ Postgres ที่ยังไม่ได้แปลง คืน nil ถ้าไม่รู้จัก
+func Kind(err error) error {
+	for _, kind := range []error{ErrNotFound, ErrConflict, ErrValidation} {
+		if errors.Is(err, kind) {
+			return kind
+		}
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	switch pgErrorCode(err) {
+	case pgUniqueViolation, pgForeignKeyViolation, pgExclusionViolation:
+		return ErrConflict
+	case pgCheckViolation, pgNotNullViolation:
+		return ErrValidation
+	}
+	return nil
+}
+
+// IsDatabaseError บอกว่า err มาจากฐานข้อมูลหรือการเชื่อมต่อ ข้อความของ error แบบนี้
+// อาจมี SQL ชื่อตาราง หรือชื่อ host จึงห้ามส่งให้ client
+// error ชนิด *Error ไม่นับ เพราะข้อความถูกเขียนไว้ให้ client อ่านได้
+func IsDatabaseError(err error) bool {
+	var repoErr *Error
+	if errors.As(err, &repoErr) {
+		return false
+	}
+
+	var pgErr *pgconn.PgError
+	var connErr *pgconn.ConnectError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &pgErr), errors.As(err, &connErr), errors.As(err, &netErr):
+		return true
+	case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, gorm.ErrInvalidTransaction),
+		errors.Is(err, gorm.ErrInvalidData), errors.Is(err, gorm.ErrInvalidField),
+		errors.Is(err, gorm.ErrMissingWhereClause), errors.Is(err, gorm.ErrUnsupportedRelation),
+		errors.Is(err, gorm.ErrPrimaryKeyRequired), errors.Is(err, gorm.ErrInvalidValue),
+		errors.Is(err, context.DeadlineExceeded):
+		return true
+	}
+	return false
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/NakarinFIgo/Movies-App/internal/entities"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrOIDCStateInvalid = validationError("invalid or expired login state")
+	ErrIdentityNotFound = notFoundError("identity not linked")
+)
+
+func (m *PostgresRepository) InsertOIDCState(state entities.OIDCState) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	if state.CreatedAt.IsZero() {
+		state.CreatedAt = time.Now()
+	}
+
+	return m.DB.WithContext(ctx).Create(&state).Error
+}
+
+// PurgeOIDCStates ลบ state ที่หมดอายุก่อน before ซึ่งผู้ใช้เริ่ม login แล้วไม่กลับมาที่ callback
+func (m *PostgresRepository) PurgeOIDCStates(before time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	result := m.DB.WithContext(ctx).
+		Where("expires_at < ?", before).
+		Delete(&entities.OIDCState{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+// ConsumeOIDCState ดึงและลบ state ในคราวเดียว state จึงใช้ได้ครั้งเดียว
+func (m *PostgresRepository) ConsumeOIDCState(stateHash string) (*entities.OIDCState, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	var states []entities.OIDCState
+	err := m.DB.WithContext(ctx).
+		Clauses(clause.Returning{}).
+		Where("state_hash = ?", stateHash).
+		Delete(&states).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(states) == 0 || !time.Now().Before(states[0].ExpiresAt) {
+		return nil, ErrOIDCStateInvalid
+	}
+	return &states[0], nil
+}
+
+// UserIdentity ดึงการผูกบัญชีจาก provider และ subject
+func (m *PostgresRepository) UserIdentity(provider, subject string) (*entities.UserIdentity, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	var identity entities.UserIdentity
+	err := m.DB.WithContext(ctx).
+		Where("provider = ? AND subject = ?", provider, subject).
+		First(&identity).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrIdentityNotFound
+		}
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// UserIdentities ดึงบัญชีภายนอกทั้งหมดที่ผูกกับผู้ใช้
+func (m *PostgresRepository) UserIdentities(userID int) ([]*entities.UserIdentity, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	var identities []*entities.UserIdentity
+	err := m.DB.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("id").
+		Find(&identities).Error
+	if err != nil {
+		return nil, err
+	}
+	return identities, nil
+}
+
+func (m *PostgresRepository) InsertUserIdentity(identity entities.UserIdentity) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	if identity.CreatedAt.IsZero() {
+		identity.CreatedAt = time.Now()
+	}
+
+	if err := m.DB.WithContext(ctx).Create(&identity).Error; err != nil {
+		return 0, err
+	}
+	return identity.ID, nil
+}
+
+// TouchUserIdentity บันทึกเวลา login และอีเมลล่าสุดที่ provider ส่งมา
+func (m *PostgresRepository) TouchUserIdentity(id int64, email string, at time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	return m.DB.WithContext(ctx).Model(&entities.UserIdentity{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"email": email, "last_login_at": at}).Error
+}
+
+// DeleteUserIdentity ยกเลิกการผูกบัญชีภายนอกของผู้ใช้
+func (m *PostgresRepository) DeleteUserIdentity(userID int, id int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	res := m.DB.WithContext(ctx).
+		Where("id = ? AND user_id = ?", id, userID).
+		Delete(&entities.UserIdentity{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrIdentityNotFound
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/NakarinFIgo/Movies-App/internal/entities"
+	"gorm.io/gorm"
+)
+
+// InsertMovieImage บันทึกรูปพร้อมรูปย่อ และตั้งรูปนี้เป็น poster หรือ backdrop ปัจจุบันของหนัง
+func (m *PostgresRepository) InsertMovieImage(image entities.MovieImage, publicURL string) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	column := "image"
+	if image.Kind == entities.ImageKindBackdrop {
+		column = "backdrop"
+	}
+
+	err := m.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&image).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&entities.Movie{}).Where("id = ?", image.MovieID).Updates(map[string]interface{}{
+			column:       publicURL,
+			"updated_at": time.Now(),
+		}).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return image.ID, nil
+}
+
+func (m *PostgresRepository) MovieImages(movieID int) ([]*entities.MovieImage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	var images []*entities.MovieImage
+
+	result := m.DB.WithContext(ctx).
+		Preload("Variants", func(db *gorm.DB) *gorm.DB {
+			return db.Order("width")
+		}).
+		Where("movie_id = ?", movieID).
+		Order("created_at desc").
+		Find(&images)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return images, nil
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/NakarinFIgo/Movies-App/internal/entities"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInviteNotFound = notFoundError("invite not found")
+	ErrInviteInvalid  = validationError("invalid or expired invite code")
+)
+
+// InsertInvite บันทึกรหัสเชิญใหม่
+func (m *PostgresRepository) InsertInvite(invite entities.Invite) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	if invite.CreatedAt.IsZero() {
+		invite.CreatedAt = time.Now()
+	}
+
+	if err := m.DB.WithContext(ctx).Create(&invite).Error; err != nil {
+		return 0, err
+	}
+	return invite.ID, nil
+}
+
+// AllInvites ดึงรหัสเชิญล่าสุดก่อน รวมที่หมดอายุ ใช้ครบ หรือถูก revoke แล้ว
+func (m *PostgresRepository) AllInvites(limit, offset int) ([]*entities.Invite, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	var invites []*entities.Invite
+	if err := m.DB.WithContext(ctx).Order("id desc").Limit(limit).Offset(offset).Find(&invites).Error; err != nil {
+		return nil, err
+	}
+	return invites, nil
+}
+
+// Invite ดึงรหัสเชิญจาก ID
+func (m *PostgresRepository) Invite(id int64) (*entities.Invite, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	var invite entities.Invite
+	if err := m.DB.WithContext(ctx).First(&invite, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInviteNotFound
+		}
+		return nil, err
+	}
+	return &invite, nil
+}
+
+// InviteRedemptions ดึงการสมัครทั้งหมดที่ใช้รหัสเชิญนี้ ตามลำดับเวลา
+func (m *PostgresRepository) InviteRedemptions(inviteID int64) ([]*entities.InviteRedemption, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	var redemptions []*entities.InviteRedemption
+	err := m.DB.WithContext(ctx).Where("invite_id = ?", inviteID).Order("redeemed_at").Find(&redemptions).Error
+	if err != nil {
+		return nil, err
+	}
+	return redemptions, nil
+}
+
+// RevokeInvite ทำให้รหัสเชิญใช้สมัครไม่ได้อีก ผู้ใช้ที่สมัครไปแล้วไม่ได้รับผลกระทบ
+func (m *PostgresRepository) RevokeInvite(id int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	res := m.DB.WithContext(ctx).Model(&entities.Invite{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrInviteNotFound
+	}
+	return nil
+}
+
+// RegisterWithInvite สร้างผู้ใช้ด้วยบทบาทของรหัสเชิญ นับการใช้รหัสและบันทึกการสมัครใน transaction เดียวกัน
+// คืน ErrInviteInvalid ถ้ารหัสไม่มีอยู่ หมดอายุ ใช้ครบแล้ว หรือถูก revoke
+func (m *PostgresRepository) RegisterWithInvite(user entities.User, codeHash string) (*entities.User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	err := m.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		// ล็อกแถวไว้ การสมัครพร้อมกันจึงใช้รหัสเกิน max_uses ไม่ได้
+		var invite entities.Invite
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("code_hash = ?", codeHash).
+			First(&invite).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInviteInvalid
+			}
+			return err
+		}
+		if invite.RevokedAt != nil || !invite.ExpiresAt.After(now) || invite.Uses >= invite.MaxUses {
+			return ErrInviteInvalid
+		}
+
+		user.Role = invite.Role
+		if err := tx.Create(&user).Error; err != nil {
+			if pgErrorCode(err) == pgUniqueViolation {
+				return ErrEmailTaken
+			}
+			return err
+		}
+
+		if err := tx.Model(&invite).Update("uses", gorm.Expr("uses + 1")).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&entities.InviteRedemption{
+			InviteID:   invite.ID,
+			UserID:     user.ID,
+			RedeemedAt: now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
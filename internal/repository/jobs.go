@@ -0,0 +1,232 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/NakarinFIgo/Movies-App/internal/entities"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrJobNotFound ยังเป็น gorm.ErrRecordNotFound ด้วย เหมือน ErrMovieNotFound
+	ErrJobNotFound error = &Error{Kind: ErrNotFound, Msg: "job not found", Err: gorm.ErrRecordNotFound}
+	// ErrJobNotDead หมายถึงสั่ง requeue job ที่ยังไม่ได้ล้มเหลวจนเป็น dead
+	ErrJobNotDead = conflictError("only dead jobs can be requeued")
+	// ErrJobLockLost หมายถึง job ไม่ได้อยู่กับ worker นี้แล้ว เช่นถูก RequeueStaleJobs คืนเข้าคิวไปก่อนรันเสร็จ
+	ErrJobLockLost = conflictError("job is no longer locked by this worker")
+)
+
+func (m *PostgresRepository) EnqueueJob(job entities.Job) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	now := time.Now()
+	job.Status = entities.JobStatusPending
+	if job.RunAt.IsZero() {
+		job.RunAt = now
+	}
+	job.CreatedAt = now
+	job.UpdatedAt = now
+
+	if err := m.DB.WithContext(ctx).Create(&job).Error; err != nil {
+		return 0, err
+	}
+	return job.ID, nil
+}
+
+// ClaimJob จอง job ที่ถึงเวลารันหนึ่งงานให้ worker โดยใช้ FOR UPDATE SKIP LOCKED
+// worker หลายตัว (หรือหลาย instance) จึงดึงงานพร้อมกันได้โดยไม่ได้งานซ้ำกัน คืน nil ถ้าไม่มีงาน
+func (m *PostgresRepository) ClaimJob(workerID string) (*entities.Job, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	var job entities.Job
+
+	err := m.DB.WithContext(ctx).Raw(`
+		UPDATE jobs
+		SET status = 'running', attempts = attempts + 1, locked_by = ?, locked_at = now(), updated_at = now()
+		WHERE id = (
+			SELECT id FROM jobs
+			WHERE status = 'pending' AND run_at <= now()
+			ORDER BY run_at, id
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1
+		)
+		RETURNING *`, workerID).Scan(&job).Error
+	if err != nil {
+		return nil, err
+	}
+	if job.ID == 0 {
+		return nil, nil
+	}
+
+	return &job, nil
+}
+
+// CompleteJob ปิด job ที่ workerID ถือไว้ว่าสำเร็จ
+func (m *PostgresRepository) CompleteJob(id int64, workerID string) error {
+	return m.finishJob(id, workerID, map[string]interface{}{
+		"status":      entities.JobStatusSucceeded,
+		"last_error":  "",
+		"locked_by":   "",
+		"locked_at":   nil,
+		"finished_at": time.Now(),
+		"updated_at":  time.Now(),
+	})
+}
+
+// RetryJobAt คืน job ที่ workerID ถือไว้กลับเข้าคิวให้รันใหม่เมื่อถึงเวลา runAt
+func (m *PostgresRepository) RetryJobAt(id int64, workerID, lastError string, runAt time.Time) error {
+	return m.finishJob(id, workerID, map[string]interface{}{
+		"status":     entities.JobStatusPending,
+		"last_error": lastError,
+		"run_at":     runAt,
+		"locked_by":  "",
+		"locked_at":  nil,
+		"updated_at": time.Now(),
+	})
+}
+
+// DeadLetterJob ย้าย job ที่ workerID ถือไว้และลองครบแล้วไปสถานะ dead
+func (m *PostgresRepository) DeadLetterJob(id int64, workerID, lastError string) error {
+	return m.finishJob(id, workerID, map[string]interface{}{
+		"status":      entities.JobStatusDead,
+		"last_error":  lastError,
+		"locked_by":   "",
+		"locked_at":   nil,
+		"finished_at": time.Now(),
+		"updated_at":  time.Now(),
+	})
+}
+
+// finishJob อัปเดต job เฉพาะเมื่อยัง running และถูกจองโดย workerID
+// ถ้า job ถูกคืนเข้าคิวแล้ว worker อื่นอาจถือไว้อยู่ จึงห้ามเขียนทับผลของ worker นั้น
+func (m *PostgresRepository) finishJob(id int64, workerID string, values map[string]interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	result := m.DB.WithContext(ctx).Model(&entities.Job{}).
+		Where("id = ? AND status = ? AND locked_by = ?", id, entities.JobStatusRunning, workerID).
+		Updates(values)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrJobLockLost
+	}
+	return nil
+}
+
+// RequeueStaleJobs คืน job ที่ค้างสถานะ running นานเกิน lockTimeout กลับเข้าคิว เช่นเมื่อ worker ตายกลางคัน
+func (m *PostgresRepository) RequeueStaleJobs(lockTimeout time.Duration) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	result := m.DB.WithContext(ctx).Model(&entities.Job{}).
+		Where("status = ? AND locked_at < ?", entities.JobStatusRunning, time.Now().Add(-lockTimeout)).
+		Updates(map[string]interface{}{
+			"status":      gorm.Expr("CASE WHEN attempts >= max_attempts THEN 'dead' ELSE 'pending' END"),
+			"finished_at": gorm.Expr("CASE WHEN attempts >= max_attempts THEN now() ELSE NULL END"),
+			"last_error":  "worker did not finish the job before the lock timeout",
+			"locked_by":   "",
+			"locked_at":   nil,
+			"updated_at":  time.Now(),
+		})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+// RequeueJob ให้ admin สั่งรัน job ที่ dead ใหม่ โดยเริ่มนับจำนวนครั้งใหม่และล้าง error เดิม
+func (m *PostgresRepository) RequeueJob(id int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	return m.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var job entities.Job
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&job, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrJobNotFound
+			}
+			return err
+		}
+		if job.Status != entities.JobStatusDead {
+			return ErrJobNotDead
+		}
+
+		return tx.Model(&job).Updates(map[string]interface{}{
+			"status":      entities.JobStatusPending,
+			"attempts":    0,
+			"last_error":  "",
+			"run_at":      time.Now(),
+			"finished_at": nil,
+			"updated_at":  time.Now(),
+		}).Error
+	})
+}
+
+func (m *PostgresRepository) OneJob(id int64) (*entities.Job, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	var job entities.Job
+	if err := m.DB.WithContext(ctx).First(&job, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrJobNotFound
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+// AllJobs ดึงรายการ job ล่าสุด กรองตาม status ได้ (status ว่างคือทุกสถานะ)
+func (m *PostgresRepository) AllJobs(status string, limit, offset int) ([]*entities.Job, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	var jobs []*entities.Job
+
+	query := m.DB.WithContext(ctx).Order("id desc").Limit(limit).Offset(offset)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// JobStats นับจำนวน job แยกตามสถานะ
+func (m *PostgresRepository) JobStats() (map[string]int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+
+	err := m.DB.WithContext(ctx).Model(&entities.Job{}).
+		Select("status, count(*) AS count").
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	stats := map[string]int64{
+		entities.JobStatusPending:   0,
+		entities.JobStatusRunning:   0,
+		entities.JobStatusSucceeded: 0,
+		entities.JobStatusDead:      0,
+	}
+	for _, row := range rows {
+		stats[row.Status] = row.Count
+	}
+	return stats, nil
+}
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/NakarinFIgo/Movies-App/internal/entities"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (m *PostgresRepository) RecordLoginAttempt(attempt entities.LoginAttempt) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	if attempt.CreatedAt.IsZero() {
+		attempt.CreatedAt = time.Now()
+	}
+
+	return m.DB.WithContext(ctx).Create(&attempt).Error
+}
+
+// LoginThrottles ดึงตัวนับการ login ผิดตาม key ที่ระบุ key ที่ไม่มีจะไม่อยู่ใน map
+func (m *PostgresRepository) LoginThrottles(keys ...string) (map[string]*entities.LoginThrottle, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	var throttles []*entities.LoginThrottle
+	if err := m.DB.WithContext(ctx).Where("key IN ?", keys).Find(&throttles).Error; err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]*entities.LoginThrottle, len(throttles))
+	for _, t := range throttles {
+		result[t.Key] = t
+	}
+	return result, nil
+}
+
+// UpdateLoginThrottle ล็อกตัวนับของ key แล้วให้ update แก้ไขและบันทึกใน transaction เดียว
+// การ login ผิดพร้อมกันจึงรอกันและนับครบทุกครั้ง key ที่ยังไม่มีจะเริ่มจากตัวนับว่าง
+func (m *PostgresRepository) UpdateLoginThrottle(key string, update func(t *entities.LoginThrottle)) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	return m.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// สร้างแถวว่างก่อนถ้ายังไม่มี เพื่อให้มีแถวให้ล็อกเสมอ
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&entities.LoginThrottle{Key: key}).Error
+		if err != nil {
+			return err
+		}
+
+		var throttle entities.LoginThrottle
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("key = ?", key).
+			First(&throttle).Error
+		if err != nil {
+			return err
+		}
+
+		update(&throttle)
+		throttle.Key = key
+
+		return tx.Select("*").Save(&throttle).Error
+	})
+}
+
+func (m *PostgresRepository) DeleteLoginThrottles(keys ...string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	return m.DB.WithContext(ctx).Where("key IN ?", keys).Delete(&entities.LoginThrottle{}).Error
+}
+
+// LoginAttempts ดึงประวัติการ login ล่าสุดของอีเมล
+func (m *PostgresRepository) LoginAttempts(email string, limit int) ([]*entities.LoginAttempt, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	var attempts []*entities.LoginAttempt
+	err := m.DB.WithContext(ctx).
+		Where("lower(email) = lower(?)", email).
+		Order("created_at desc, id desc").
+		Limit(limit).
+		Find(&attempts).Error
+	if err != nil {
+		return nil, err
+	}
+	return attempts, nil
+}
+
+// PurgeLoginThrottles ลบตัวนับที่ login ผิดครั้งล่าสุดก่อน before และไม่ได้ล็อกอยู่
+func (m *PostgresRepository) PurgeLoginThrottles(before time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	result := m.DB.WithContext(ctx).
+		Where("last_failure_at < ? AND (locked_until IS NULL OR locked_until < ?)", before, before).
+		Delete(&entities.LoginThrottle{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+// PurgeLoginAttempts ลบประวัติการ login ที่เก่ากว่า before
+func (m *PostgresRepository) PurgeLoginAttempts(before time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	result := m.DB.WithContext(ctx).
+		Where("created_at < ?", before).
+		Delete(&entities.LoginAttempt{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,214 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/NakarinFIgo/Movies-App/internal/entities"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrResetTokenInvalid        = validationError("invalid or expired reset token")
+	ErrVerificationTokenInvalid = validationError("invalid or expired verification token")
+	ErrEmailTaken               = conflictError("email already exists")
+)
+
+func (m *PostgresRepository) InsertPasswordReset(reset entities.PasswordReset) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	if reset.CreatedAt.IsZero() {
+		reset.CreatedAt = time.Now()
+	}
+
+	return m.DB.WithContext(ctx).Create(&reset).Error
+}
+
+// PurgePasswordResets ลบ reset token ที่หมดอายุก่อน before ทั้งที่ใช้แล้วและยังไม่ได้ใช้
+func (m *PostgresRepository) PurgePasswordResets(before time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	result := m.DB.WithContext(ctx).
+		Where("expires_at < ?", before).
+		Delete(&entities.PasswordReset{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+// ResetPassword ใช้ reset token (tokenHash) ตั้งรหัสผ่านใหม่ แล้วทำให้ reset token ทุกตัวของผู้ใช้ใช้ไม่ได้อีก
+// คืน user id ของเจ้าของ token
+func (m *PostgresRepository) ResetPassword(tokenHash, passwordHash string) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	var userID int
+
+	err := m.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var reset entities.PasswordReset
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ?", tokenHash).
+			First(&reset).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrResetTokenInvalid
+			}
+			return err
+		}
+
+		now := time.Now()
+		if reset.UsedAt != nil || !reset.ExpiresAt.After(now) {
+			return ErrResetTokenInvalid
+		}
+
+		err = tx.Model(&entities.User{}).Where("id = ?", reset.UserID).Updates(map[string]interface{}{
+			"password":   passwordHash,
+			"updated_at": now,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&entities.PasswordReset{}).
+			Where("user_id = ? AND used_at IS NULL", reset.UserID).
+			Update("used_at", now).Error
+		if err != nil {
+			return err
+		}
+
+		userID = reset.UserID
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return userID, nil
+}
+
+func (m *PostgresRepository) InsertEmailVerification(verification entities.EmailVerification) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	if verification.CreatedAt.IsZero() {
+		verification.CreatedAt = time.Now()
+	}
+
+	return m.DB.WithContext(ctx).Create(&verification).Error
+}
+
+// CountEmailVerificationsSince นับจำนวนลิงก์ยืนยันอีเมลที่ส่งให้ผู้ใช้ตั้งแต่ since ใช้จำกัดการส่งซ้ำ
+// PurgeEmailVerifications ลบลิงก์ยืนยันอีเมลที่หมดอายุก่อน before
+func (m *PostgresRepository) PurgeEmailVerifications(before time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	result := m.DB.WithContext(ctx).
+		Where("expires_at < ?", before).
+		Delete(&entities.EmailVerification{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+func (m *PostgresRepository) CountEmailVerificationsSince(userID int, since time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	var count int64
+	err := m.DB.WithContext(ctx).Model(&entities.EmailVerification{}).
+		Where("user_id = ? AND created_at >= ?", userID, since).
+		Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// VerifyEmail ยืนยันอีเมลด้วย token (tokenHash) แล้วทำให้ลิงก์ยืนยันทุกอันของผู้ใช้ใช้ไม่ได้อีก
+// ถ้าเป็นลิงก์เปลี่ยนอีเมลจะเปลี่ยนอีเมลของผู้ใช้เป็นอีเมลใหม่ด้วย คืน user id ของเจ้าของ token
+func (m *PostgresRepository) VerifyEmail(tokenHash string) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	var userID int
+
+	err := m.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var verification entities.EmailVerification
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ?", tokenHash).
+			First(&verification).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrVerificationTokenInvalid
+			}
+			return err
+		}
+
+		now := time.Now()
+		if verification.UsedAt != nil || !verification.ExpiresAt.After(now) {
+			return ErrVerificationTokenInvalid
+		}
+
+		if verification.NewEmail != nil {
+			err = changeEmail(tx, verification.UserID, *verification.NewEmail, now)
+		} else {
+			err = tx.Model(&entities.User{}).
+				Where("id = ? AND email_verified_at IS NULL", verification.UserID).
+				Updates(map[string]interface{}{
+					"email_verified_at": now,
+					"updated_at":        now,
+				}).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&entities.EmailVerification{}).
+			Where("user_id = ? AND used_at IS NULL", verification.UserID).
+			Update("used_at", now).Error
+		if err != nil {
+			return err
+		}
+
+		userID = verification.UserID
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return userID, nil
+}
+
+// changeEmail เปลี่ยนอีเมลของผู้ใช้ที่ยืนยันแล้ว ถ้ามีบัญชีอื่นใช้อีเมลนี้ไปก่อนจะคืน ErrEmailTaken
+func changeEmail(tx *gorm.DB, userID int, email string, now time.Time) error {
+	var taken int64
+	err := tx.Model(&entities.User{}).
+		Where("lower(email) = lower(?) AND id <> ?", email, userID).
+		Count(&taken).Error
+	if err != nil {
+		return err
+	}
+	if taken > 0 {
+		return ErrEmailTaken
+	}
+
+	err = tx.Model(&entities.User{}).
+		Where("id = ?", userID).
+		Updates(map[string]interface{}{
+			"email":             email,
+			"email_verified_at": now,
+			"updated_at":        now,
+		}).Error
+	if pgErrorCode(err) == pgUniqueViolation {
+		return ErrEmailTaken
+	}
+	return err
+}
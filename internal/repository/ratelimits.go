@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/NakarinFIgo/Movies-App/internal/entities"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TakeRateLimitToken หัก token หนึ่งตัวจาก bucket ของ key คืน bucket หลังหักและผลว่าหักได้หรือไม่
+// ล็อกแถวไว้ตลอด transaction หลาย instance ที่ใช้ database เดียวกันจึงนับร่วมกันได้ถูกต้อง
+func (m *PostgresRepository) TakeRateLimitToken(key string, capacity int, period time.Duration) (entities.RateLimitBucket, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	var bucket entities.RateLimitBucket
+	var allowed bool
+
+	err := m.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		// key ใหม่เริ่มด้วย bucket ที่เต็ม ถ้ามีอยู่แล้วจะไม่ทำอะไร
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&entities.RateLimitBucket{
+			Key:        key,
+			Tokens:     float64(capacity),
+			RefilledAt: now,
+			ExpiresAt:  now,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("key = ?", key).
+			First(&bucket).Error
+		if err != nil {
+			return err
+		}
+
+		allowed = bucket.Take(capacity, period, now)
+		return tx.Save(&bucket).Error
+	})
+	if err != nil {
+		return entities.RateLimitBucket{}, false, err
+	}
+	return bucket, allowed, nil
+}
+
+// PurgeRateLimitBuckets ลบ bucket ที่เต็มก่อน before ซึ่งให้ผลเหมือน key ที่ไม่เคยส่ง request มาก่อน
+func (m *PostgresRepository) PurgeRateLimitBuckets(before time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	result := m.DB.WithContext(ctx).
+		Where("expires_at < ?", before).
+		Delete(&entities.RateLimitBucket{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
@@ -3,11 +3,13 @@ package repository
 import (
 	"context"
 	"errors"
-	"fmt"
+	"slices"
 	"time"
 
 	"github.com/NakarinFIgo/Movies-App/internal/entities"
+	"github.com/NakarinFIgo/Movies-App/pkg/rbac"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type PostgresRepository struct {
@@ -16,6 +18,12 @@ type PostgresRepository struct {
 
 const dbTimeout = time.Second * 5
 
+// ErrMovieNotFound ยังเป็น gorm.ErrRecordNotFound ด้วย โค้ดเดิมที่ตรวจ error ของ GORM จึงใช้ได้ต่อ
+var ErrMovieNotFound error = &Error{Kind: ErrNotFound, Msg: "movie not found", Err: gorm.ErrRecordNotFound}
+
+// ErrMovieHasBookings หมายถึงรอบฉายของหนังมีการจองอยู่ ลบหนังไม่ได้เพราะจะทำให้ประวัติการจองหายไป
+var ErrMovieHasBookings = conflictError("movie has bookings")
+
 func (m *PostgresRepository) GetUserByEmail(email string) (*entities.User, error) {
 
 	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
@@ -23,11 +31,12 @@ func (m *PostgresRepository) GetUserByEmail(email string) (*entities.User, error
 
 	var user entities.User
 
-	err := m.DB.WithContext(ctx).Where("email = ?", email).First(&user).Error
+	// อีเมลไม่สนตัวพิมพ์ใหญ่เล็ก ตรงกับ unique index users_email_lower_idx
+	err := m.DB.WithContext(ctx).Where("lower(email) = lower(?)", email).First(&user).Error
 
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, err
 	}
@@ -46,7 +55,7 @@ func (m *PostgresRepository) GetUserByID(id int) (*entities.User, error) {
 
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, err
 	}
@@ -73,11 +82,76 @@ func (m *PostgresRepository) InsertUser(user entities.User) (int, error) {
 	defer cancel()
 
 	if err := m.DB.WithContext(ctx).Create(&user).Error; err != nil {
+		if pgErrorCode(err) == pgUniqueViolation {
+			return 0, ErrEmailTaken
+		}
 		return 0, err
 	}
 	return user.ID, nil
 }
 
+// CountUsersByRole นับจำนวนผู้ใช้ที่มีบทบาท role
+func (m *PostgresRepository) CountUsersByRole(role string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	var count int64
+	if err := m.DB.WithContext(ctx).Model(&entities.User{}).Where("role = ?", role).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// ErrLastAdmin คือ error เมื่อจะเอาบทบาท admin ออกจาก admin คนสุดท้ายหรือลบบัญชีของ admin คนสุดท้าย
+var ErrLastAdmin = conflictError("cannot remove the last admin")
+
+// isLastAdmin บอกว่า id เป็น admin คนเดียวที่ยังไม่ได้ขอลบบัญชี ต้องเรียกใน transaction
+// แถวของ admin ทุกคนถูกล็อกก่อนนับ การลดบทบาทหรือลบ admin สองคนพร้อมกันจึงต้องรอกัน
+// และรายการที่สองจะเห็นว่าเหลือ admin คนเดียวแล้ว
+func isLastAdmin(tx *gorm.DB, id int) (bool, error) {
+	var adminIDs []int
+	err := tx.Model(&entities.User{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("role = ? AND deletion_scheduled_at IS NULL", rbac.RoleAdmin).
+		Pluck("id", &adminIDs).Error
+	if err != nil {
+		return false, err
+	}
+	return len(adminIDs) <= 1 && slices.Contains(adminIDs, id), nil
+}
+
+// UpdateUserRole เปลี่ยนบทบาทและสิทธิ์เพิ่มเติมของผู้ใช้ มีผลกับ token ที่ออกหลังจากนี้
+// คืน ErrLastAdmin ถ้าผู้ใช้เป็น admin คนสุดท้ายและบทบาทใหม่ไม่ใช่ admin
+func (m *PostgresRepository) UpdateUserRole(id int, role string, permissions []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	return m.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if role != rbac.RoleAdmin {
+			last, err := isLastAdmin(tx, id)
+			if err != nil {
+				return err
+			}
+			if last {
+				return ErrLastAdmin
+			}
+		}
+
+		user := entities.User{Role: role, Permissions: permissions, UpdatedAt: time.Now()}
+
+		result := tx.Model(&entities.User{ID: id}).
+			Select("Role", "Permissions", "UpdatedAt").
+			Updates(&user)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrUserNotFound
+		}
+		return nil
+	})
+}
+
 func (m *PostgresRepository) OneMovie(id int) (*entities.Movie, error) {
 	var movie entities.Movie
 
@@ -85,7 +159,7 @@ func (m *PostgresRepository) OneMovie(id int) (*entities.Movie, error) {
 	err := m.DB.Preload("Genres").First(&movie, id).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, err
+			return nil, ErrMovieNotFound
 		}
 		return nil, err
 	}
@@ -98,6 +172,9 @@ func (m *PostgresRepository) OneMovieForEdit(id int) (*entities.Movie, []*entiti
 
 	// ใช้ GORM ในการค้นหาหนังโดย ID
 	if err := m.DB.Where("id = ?", id).First(&movie).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrMovieNotFound
+		}
 		return nil, nil, err
 	}
 
@@ -142,6 +219,34 @@ func (m *PostgresRepository) AllGenres() ([]*entities.Genre, error) {
 	return genre, nil
 }
 
+// MissingGenreIDs คืน genre id ใน ids ที่ไม่มีอยู่ในฐานข้อมูล ตามลำดับที่ส่งมา
+func (m *PostgresRepository) MissingGenreIDs(ids []int) ([]int, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	var found []int
+	if err := m.DB.WithContext(ctx).Model(&entities.Genre{}).Where("id IN ?", ids).Pluck("id", &found).Error; err != nil {
+		return nil, err
+	}
+
+	exists := make(map[int]bool, len(found))
+	for _, id := range found {
+		exists[id] = true
+	}
+
+	var missing []int
+	for _, id := range ids {
+		if !exists[id] {
+			missing = append(missing, id)
+		}
+	}
+	return missing, nil
+}
+
 func (m *PostgresRepository) InsertMovie(movie entities.Movie) (int, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
 	defer cancel()
@@ -152,43 +257,112 @@ func (m *PostgresRepository) InsertMovie(movie entities.Movie) (int, error) {
 	return movie.ID, nil
 }
 
+// UpdateMovie เขียนทุกคอลัมน์ของหนังตาม movie รวมถึงค่าว่าง เช่น description ว่างคือล้างค่า
+// ผู้เรียกต้องโหลดหนังมาก่อนแล้วแก้เฉพาะ field ที่ต้องการ
 func (m *PostgresRepository) UpdateMovie(movie entities.Movie) error {
-	if err := m.DB.Model(&movie).Where("id = ?", movie.ID).Updates(entities.Movie{
-		Title:       movie.Title,
-		Description: movie.Description,
-		ReleaseDate: movie.ReleaseDate,
-		RunTime:     movie.RunTime,
-		MPAARating:  movie.MPAARating,
-		UpdatedAt:   movie.UpdatedAt,
-		Image:       movie.Image,
-	}).Error; err != nil {
-		return err
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	// วันฉายที่ยังไม่ได้กรอกเก็บเป็น NULL ไม่ใช่ 0001-01-01
+	var releaseDate interface{}
+	if !movie.ReleaseDate.IsZero() {
+		releaseDate = movie.ReleaseDate
 	}
-	return nil
+
+	return m.DB.WithContext(ctx).Model(&entities.Movie{}).Where("id = ?", movie.ID).Updates(map[string]interface{}{
+		"title":        movie.Title,
+		"description":  movie.Description,
+		"release_date": releaseDate,
+		"runtime":      movie.RunTime,
+		"mpaa_rating":  movie.MPAARating,
+		"image":        movie.Image,
+		"backdrop":     movie.Backdrop,
+		"tmdb_id":      movie.TMDBID,
+		"updated_at":   movie.UpdatedAt,
+	}).Error
 }
 
 func (m *PostgresRepository) UpdateMovieGenres(id int, genreIDs []int) error {
 	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
 	defer cancel()
 
-	var movie entities.Movie
-	if err := m.DB.WithContext(ctx).Begin().First(&movie, id); err != nil {
-		m.DB.WithContext(ctx).Begin().Rollback()
+	return m.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var movie entities.Movie
+		if err := tx.First(&movie, id).Error; err != nil {
+			return err
+		}
 
-	}
+		genres := []*entities.Genre{}
+		if len(genreIDs) > 0 {
+			if err := tx.Where("id IN ?", genreIDs).Find(&genres).Error; err != nil {
+				return err
+			}
+		}
 
-	var genres []*entities.Genre
-	if err := m.DB.WithContext(ctx).Begin().Where("id IN ?", genreIDs).Find(&genres).Error; err != nil {
-		m.DB.WithContext(ctx).Begin().Rollback()
-		return err
-	}
+		return tx.Model(&movie).Association("Genres").Replace(genres)
+	})
+}
+
+// FillMovie เติมข้อมูลจาก fill เฉพาะคอลัมน์ที่หนังยังว่างอยู่ในฐานข้อมูลตอนนี้ ค่าว่างใน fill จะไม่ถูกเขียน
+// genreIDs จะถูกบันทึกเฉพาะเมื่อหนังยังไม่มี genre ใช้กับงานเบื้องหลังที่ดึงข้อมูลนาน
+// เพื่อไม่ให้ทับสิ่งที่ admin แก้ระหว่างนั้น
+func (m *PostgresRepository) FillMovie(id int, fill entities.Movie, genreIDs []int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
 
-	if err := m.DB.WithContext(ctx).Begin().Model(&movie).Association("Genres").Replace(genres); err != nil {
-		m.DB.WithContext(ctx).Begin().Rollback()
-		return err
+	values := map[string]interface{}{}
+	fillText := func(column, value string) {
+		if value != "" {
+			values[column] = gorm.Expr("CASE WHEN "+column+" IS NULL OR "+column+" = '' THEN ? ELSE "+column+" END", value)
+		}
+	}
+	fillText("description", fill.Description)
+	fillText("mpaa_rating", fill.MPAARating)
+	fillText("image", fill.Image)
+	fillText("backdrop", fill.Backdrop)
+	if !fill.ReleaseDate.IsZero() {
+		values["release_date"] = gorm.Expr("COALESCE(release_date, ?)", fill.ReleaseDate)
+	}
+	if fill.RunTime > 0 {
+		values["runtime"] = gorm.Expr("CASE WHEN runtime IS NULL OR runtime = 0 THEN ? ELSE runtime END", fill.RunTime)
+	}
+	if fill.TMDBID != nil {
+		values["tmdb_id"] = gorm.Expr("COALESCE(tmdb_id, ?)", *fill.TMDBID)
 	}
 
-	return nil
+	return m.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var movie entities.Movie
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&movie, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrMovieNotFound
+			}
+			return err
+		}
+
+		if len(values) > 0 {
+			values["updated_at"] = time.Now()
+			if err := tx.Model(&movie).Updates(values).Error; err != nil {
+				return err
+			}
+		}
+
+		if len(genreIDs) == 0 {
+			return nil
+		}
+		var genreCount int64
+		if err := tx.Table("movies_genres").Where("movie_id = ?", id).Count(&genreCount).Error; err != nil {
+			return err
+		}
+		if genreCount > 0 {
+			return nil
+		}
+
+		genres := []*entities.Genre{}
+		if err := tx.Where("id IN ?", genreIDs).Find(&genres).Error; err != nil {
+			return err
+		}
+		return tx.Model(&movie).Association("Genres").Append(genres)
+	})
 }
 
 func (m *PostgresRepository) DeleteMovie(id int) error {
@@ -198,13 +372,54 @@ func (m *PostgresRepository) DeleteMovie(id int) error {
 	var movie entities.Movie
 	result := m.DB.WithContext(ctx).First(&movie, id)
 	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return ErrMovieNotFound
+		}
 		return result.Error
 	}
 
+	// รอบฉายถูกลบตามหนัง (cascade) แต่ bookings.showtime_id เป็น RESTRICT
 	result = m.DB.WithContext(ctx).Delete(&movie)
 	if result.Error != nil {
+		if pgErrorCode(result.Error) == pgForeignKeyViolation {
+			return ErrMovieHasBookings
+		}
 		return result.Error
 	}
 
 	return nil
 }
+
+func (m *PostgresRepository) MovieByTMDBID(tmdbID int) (*entities.Movie, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	var movie entities.Movie
+
+	err := m.DB.WithContext(ctx).Preload("Genres").Where("tmdb_id = ?", tmdbID).First(&movie).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMovieNotFound
+		}
+		return nil, err
+	}
+
+	return &movie, nil
+}
+
+// GenresByTMDBIDs ดึง genre ของเราที่ map กับ genre id ของ TMDB
+func (m *PostgresRepository) GenresByTMDBIDs(tmdbIDs []int) ([]*entities.Genre, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	var genres []*entities.Genre
+	if len(tmdbIDs) == 0 {
+		return genres, nil
+	}
+
+	result := m.DB.WithContext(ctx).Where("tmdb_id IN ?", tmdbIDs).Order("genre").Find(&genres)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return genres, nil
+}
@@ -1,17 +1,136 @@
 package repository
 
-import "github.com/NakarinFIgo/Movies-App/internal/entities"
+import (
+	"context"
+	"time"
+
+	"github.com/NakarinFIgo/Movies-App/internal/entities"
+)
 
 type DatabaseRepo interface {
 	GetUserByEmail(email string) (*entities.User, error)
 	GetUserByID(id int) (*entities.User, error)
+	CountUsersByRole(role string) (int64, error)
+	UpdateUserRole(id int, role string, permissions []string) error
+	SearchUsers(search, role, status string, limit, offset int) ([]*entities.User, int64, error)
+	SetUserDisabled(userID int, disabled bool) error
+	RehashPassword(userID int, oldHash, newHash string) error
+	UpdateUserProfile(id int, firstName, lastName string) error
+	ChangePassword(userID int, passwordHash, keepFamilyID string) (int64, error)
+	ScheduleUserDeletion(userID int, at time.Time) error
+	CancelUserDeletion(userID int) error
+	PurgeDeletedUsers(now time.Time) (int64, error)
+	UserSessions(userID int) ([]*entities.Session, error)
+	UserLoginAttempts(userID int) ([]*entities.LoginAttempt, error)
+	APIKeysCreatedBy(userID int) ([]*entities.APIKey, error)
+
+	InsertSession(session entities.Session) (int64, error)
+	RotateSession(tokenHash string, userID int, next entities.Session) (*entities.Session, error)
+	RevokeSessionFamily(tokenHash string) error
+	SessionByTokenHash(tokenHash string) (*entities.Session, error)
+	ActiveSessions(userID int) ([]*entities.ActiveSession, error)
+	RevokeSession(userID int, familyID string) error
+	RevokeUserSessions(userID int) (int64, error)
+	PurgeSessions(before time.Time) (int64, error)
+
+	InsertPasswordReset(reset entities.PasswordReset) error
+	ResetPassword(tokenHash, passwordHash string) (int, error)
+	PurgePasswordResets(before time.Time) (int64, error)
+	InsertEmailVerification(verification entities.EmailVerification) error
+	CountEmailVerificationsSince(userID int, since time.Time) (int64, error)
+	VerifyEmail(tokenHash string) (int, error)
+	PurgeEmailVerifications(before time.Time) (int64, error)
+
+	RecordLoginAttempt(attempt entities.LoginAttempt) error
+	LoginThrottles(keys ...string) (map[string]*entities.LoginThrottle, error)
+	UpdateLoginThrottle(key string, update func(t *entities.LoginThrottle)) error
+	DeleteLoginThrottles(keys ...string) error
+	LoginAttempts(email string, limit int) ([]*entities.LoginAttempt, error)
+	PurgeLoginThrottles(before time.Time) (int64, error)
+	PurgeLoginAttempts(before time.Time) (int64, error)
+	TakeRateLimitToken(key string, capacity int, period time.Duration) (entities.RateLimitBucket, bool, error)
+	PurgeRateLimitBuckets(before time.Time) (int64, error)
+
+	UserTOTP(userID int) (*entities.UserTOTP, error)
+	SaveTOTPSecret(userID int, secret string) error
+	EnableTOTP(userID int, step int64, codeHashes []string) error
+	UseTOTPStep(userID int, step int64) error
+	UseRecoveryCode(userID int, codeHash string) error
+	ReplaceRecoveryCodes(userID int, codeHashes []string) error
+	CountRecoveryCodes(userID int) (int64, error)
+	DisableTOTP(userID int) error
+
+	InsertAPIKey(key entities.APIKey) (int64, error)
+	AllAPIKeys() ([]*entities.APIKey, error)
+	APIKeyByHash(keyHash string) (*entities.APIKey, error)
+	RevokeAPIKey(id int64) error
+	TouchAPIKey(id int64, at time.Time) error
+
+	InsertOIDCState(state entities.OIDCState) error
+	ConsumeOIDCState(stateHash string) (*entities.OIDCState, error)
+	PurgeOIDCStates(before time.Time) (int64, error)
+	UserIdentity(provider, subject string) (*entities.UserIdentity, error)
+	UserIdentities(userID int) ([]*entities.UserIdentity, error)
+	InsertUserIdentity(identity entities.UserIdentity) (int64, error)
+	TouchUserIdentity(id int64, email string, at time.Time) error
+	DeleteUserIdentity(userID int, id int64) error
+
+	InsertInvite(invite entities.Invite) (int64, error)
+	AllInvites(limit, offset int) ([]*entities.Invite, error)
+	Invite(id int64) (*entities.Invite, error)
+	InviteRedemptions(inviteID int64) ([]*entities.InviteRedemption, error)
+	RevokeInvite(id int64) error
+	RegisterWithInvite(user entities.User, codeHash string) (*entities.User, error)
+
+	InsertAuditLog(entry entities.AuditLog) error
+	AuditLogs(actorType, actorID string, limit, offset int) ([]*entities.AuditLog, error)
+	PurgeAuditLogs(before time.Time) (int64, error)
 	InsertUser(user entities.User) (int, error)
 	AllMovies() ([]*entities.Movie, error)
 	AllGenres() ([]*entities.Genre, error)
+	MissingGenreIDs(ids []int) ([]int, error)
 	InsertMovie(movie entities.Movie) (int, error)
 	UpdateMovie(movie entities.Movie) error
+	FillMovie(id int, fill entities.Movie, genreIDs []int) error
 	UpdateMovieGenres(id int, genreIDs []int) error
 	DeleteMovie(id int) error
 	OneMovie(id int) (*entities.Movie, error)
 	OneMovieForEdit(id int) (*entities.Movie, []*entities.Genre, error)
+	MovieByTMDBID(tmdbID int) (*entities.Movie, error)
+	GenresByTMDBIDs(tmdbIDs []int) ([]*entities.Genre, error)
+
+	AllTheaters() ([]*entities.Theater, error)
+	InsertTheater(theater entities.Theater) (int, error)
+	InsertScreen(screen entities.Screen) (int, error)
+	InsertShowtime(showtime entities.Showtime) (int, error)
+	DeleteShowtime(id int) error
+	OneShowtime(id int) (*entities.Showtime, error)
+	ShowtimesForMovie(movieID int, from time.Time) ([]*entities.Showtime, error)
+	SeatMap(showtimeID int) ([]*entities.SeatAvailability, error)
+	HoldSeats(userID, showtimeID int, seatIDs []int, expiresAt time.Time) (*entities.Booking, error)
+	ConfirmBooking(userID, bookingID int) (*entities.Booking, error)
+	CancelBooking(userID, bookingID int) error
+	BookingsForUser(userID int) ([]*entities.Booking, error)
+	ExpireSeatHolds() (int64, error)
+
+	InsertMovieImage(image entities.MovieImage, publicURL string) (int, error)
+	MovieImages(movieID int) ([]*entities.MovieImage, error)
+
+	EnqueueJob(job entities.Job) (int64, error)
+	ClaimJob(workerID string) (*entities.Job, error)
+	CompleteJob(id int64, workerID string) error
+	RetryJobAt(id int64, workerID, lastError string, runAt time.Time) error
+	DeadLetterJob(id int64, workerID, lastError string) error
+	RequeueStaleJobs(lockTimeout time.Duration) (int64, error)
+	RequeueJob(id int64) error
+	OneJob(id int64) (*entities.Job, error)
+	AllJobs(status string, limit, offset int) ([]*entities.Job, error)
+	JobStats() (map[string]int64, error)
+
+	TryAdvisoryLock(ctx context.Context, key int64) (unlock func(), ok bool, err error)
+	InsertSchedulerRun(run *entities.SchedulerRun) (bool, error)
+	FinishSchedulerRun(id int64, status, detail, lastError string) error
+	SchedulerRuns(task string, limit int) ([]*entities.SchedulerRun, error)
+	PurgeHistory(before time.Time) (int64, error)
+	MovieIDsWithTMDBID() ([]int, error)
 }
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"time"
+
+	"github.com/NakarinFIgo/Movies-App/internal/entities"
+	"gorm.io/gorm/clause"
+)
+
+// TryAdvisoryLock ขอ session-level advisory lock ของ Postgres ด้วย key โดยไม่รอ
+// lock ผูกกับ connection จึงต้องจอง connection ไว้จนกว่าจะเรียก unlock
+// คืน ok เป็น false ถ้ามี session อื่นถือ lock อยู่
+func (m *PostgresRepository) TryAdvisoryLock(ctx context.Context, key int64) (unlock func(), ok bool, err error) {
+	sqlDB, err := m.DB.DB()
+	if err != nil {
+		return nil, false, err
+	}
+
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var locked bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&locked); err != nil {
+		conn.Close()
+		return nil, false, err
+	}
+	if !locked {
+		conn.Close()
+		return nil, false, nil
+	}
+
+	unlock = func() {
+		ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+		defer cancel()
+
+		if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", key); err != nil {
+			// ปลด lock ไม่สำเร็จ ทิ้ง connection นี้ไปเลยเพื่อให้ Postgres ปล่อย lock เอง
+			// แทนที่จะคืน connection ที่ยังถือ lock อยู่กลับเข้า pool
+			_ = conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+		}
+		conn.Close()
+	}
+
+	return unlock, true, nil
+}
+
+// InsertSchedulerRun บันทึกการเริ่มรัน task คืน false ถ้ารอบเวลานี้มี instance อื่นรันไปแล้ว
+func (m *PostgresRepository) InsertSchedulerRun(run *entities.SchedulerRun) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	result := m.DB.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(run)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (m *PostgresRepository) FinishSchedulerRun(id int64, status, detail, lastError string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	return m.DB.WithContext(ctx).Model(&entities.SchedulerRun{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":      status,
+		"detail":      detail,
+		"error":       lastError,
+		"finished_at": time.Now(),
+	}).Error
+}
+
+// SchedulerRuns ดึงประวัติการรันล่าสุด กรองตามชื่อ task ได้ (task ว่างคือทุก task)
+func (m *PostgresRepository) SchedulerRuns(task string, limit int) ([]*entities.SchedulerRun, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	var runs []*entities.SchedulerRun
+
+	query := m.DB.WithContext(ctx).Order("started_at desc, id desc").Limit(limit)
+	if task != "" {
+		query = query.Where("task = ?", task)
+	}
+
+	if err := query.Find(&runs).Error; err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+// PurgeHistory ลบ job ที่จบแล้ว และประวัติการรัน task ที่เก่ากว่า before
+func (m *PostgresRepository) PurgeHistory(before time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	jobs := m.DB.WithContext(ctx).
+		Where("status IN ? AND finished_at < ?", []string{entities.JobStatusSucceeded, entities.JobStatusDead}, before).
+		Delete(&entities.Job{})
+	if jobs.Error != nil {
+		return 0, jobs.Error
+	}
+
+	runs := m.DB.WithContext(ctx).
+		Where("finished_at < ?", before).
+		Delete(&entities.SchedulerRun{})
+	if runs.Error != nil {
+		return 0, runs.Error
+	}
+
+	return jobs.RowsAffected + runs.RowsAffected, nil
+}
+
+// MovieIDsWithTMDBID คืน ID ของหนังทุกเรื่องที่ผูกกับ TMDB แล้ว
+func (m *PostgresRepository) MovieIDsWithTMDBID() ([]int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	var ids []int
+	err := m.DB.WithContext(ctx).Model(&entities.Movie{}).
+		Where("tmdb_id IS NOT NULL").
+		Order("id").
+		Pluck("id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/NakarinFIgo/Movies-App/internal/entities"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrSessionNotFound = notFoundError("session not found")
+	ErrSessionRevoked  = conflictError("session has been revoked")
+	ErrSessionExpired  = conflictError("session has expired")
+	// ErrSessionReused หมายถึงมีการใช้ refresh token ที่ rotated ไปแล้ว ทั้ง family ถูก revoke แล้ว
+	ErrSessionReused = conflictError("refresh token reuse detected")
+)
+
+func (m *PostgresRepository) InsertSession(session entities.Session) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	if session.CreatedAt.IsZero() {
+		session.CreatedAt = time.Now()
+	}
+
+	if err := m.DB.WithContext(ctx).Create(&session).Error; err != nil {
+		return 0, err
+	}
+	return session.ID, nil
+}
+
+// RotateSession แลก refresh token เดิม (tokenHash) เป็น token ใหม่ใน family เดียวกัน
+// ถ้า token เดิมเคย rotated ไปแล้วจะ revoke ทั้ง family แล้วคืน ErrSessionReused
+func (m *PostgresRepository) RotateSession(tokenHash string, userID int, next entities.Session) (*entities.Session, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	// การ revoke เมื่อพบ token ซ้ำต้อง commit ด้วย จึงไม่คืน error ออกจาก transaction ในกรณีนั้น
+	reused := false
+
+	err := m.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var current entities.Session
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ?", tokenHash).
+			First(&current).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrSessionNotFound
+			}
+			return err
+		}
+
+		now := time.Now()
+
+		switch {
+		case current.UserID != userID:
+			return ErrSessionNotFound
+		case current.RevokedAt != nil:
+			return ErrSessionRevoked
+		case current.RotatedAt != nil:
+			reused = true
+			return revokeFamily(tx, current.FamilyID, now)
+		case !current.ExpiresAt.After(now):
+			return ErrSessionExpired
+		}
+
+		if err := tx.Model(&current).Update("rotated_at", now).Error; err != nil {
+			return err
+		}
+
+		next.UserID = current.UserID
+		next.FamilyID = current.FamilyID
+		next.CreatedAt = now
+
+		return tx.Create(&next).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	if reused {
+		return nil, ErrSessionReused
+	}
+
+	return &next, nil
+}
+
+// SessionByTokenHash ดึง refresh token จาก hash
+func (m *PostgresRepository) SessionByTokenHash(tokenHash string) (*entities.Session, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	var session entities.Session
+	if err := m.DB.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+// ActiveSessions แสดงการ login ที่ยังใช้งานได้ของผู้ใช้ เรียงจากที่ใช้ล่าสุด
+func (m *PostgresRepository) ActiveSessions(userID int) ([]*entities.ActiveSession, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	var sessions []*entities.ActiveSession
+
+	err := m.DB.WithContext(ctx).Raw(`
+		SELECT
+			family_id AS id,
+			(array_agg(user_agent ORDER BY created_at DESC))[1] AS user_agent,
+			(array_agg(ip_address ORDER BY created_at DESC))[1] AS ip_address,
+			min(created_at) AS created_at,
+			max(created_at) AS last_used_at,
+			max(expires_at) AS expires_at
+		FROM sessions
+		WHERE user_id = ?
+		GROUP BY family_id
+		HAVING bool_and(revoked_at IS NULL) AND max(expires_at) > now()
+		ORDER BY last_used_at DESC`, userID).Scan(&sessions).Error
+	if err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// RevokeSession revoke การ login หนึ่งครั้ง (ทั้ง family) ของผู้ใช้
+func (m *PostgresRepository) RevokeSession(userID int, familyID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	result := m.DB.WithContext(ctx).Model(&entities.Session{}).
+		Where("user_id = ? AND family_id = ? AND revoked_at IS NULL", userID, familyID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// RevokeSessionFamily revoke refresh token ทุกตัวใน family ของ token นี้ ใช้ตอน logout
+func (m *PostgresRepository) RevokeSessionFamily(tokenHash string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	return m.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var current entities.Session
+		if err := tx.Where("token_hash = ?", tokenHash).First(&current).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrSessionNotFound
+			}
+			return err
+		}
+
+		return revokeFamily(tx, current.FamilyID, time.Now())
+	})
+}
+
+// RevokeUserSessions revoke ทุก session ของผู้ใช้
+func (m *PostgresRepository) RevokeUserSessions(userID int) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	result := m.DB.WithContext(ctx).Model(&entities.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+// PurgeSessions ลบ refresh token ที่หมดอายุหรือถูก revoke ก่อน before
+// token ที่ rotated แล้วแต่ยังไม่หมดอายุต้องเก็บไว้เพื่อตรวจการใช้ซ้ำ
+func (m *PostgresRepository) PurgeSessions(before time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	sessions := m.DB.WithContext(ctx).
+		Where("expires_at < ? OR revoked_at < ?", before, before).
+		Delete(&entities.Session{})
+	if sessions.Error != nil {
+		return 0, sessions.Error
+	}
+
+	return sessions.RowsAffected, nil
+}
+
+func revokeFamily(tx *gorm.DB, familyID string, now time.Time) error {
+	return tx.Model(&entities.Session{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", now).Error
+}
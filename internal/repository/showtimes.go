@@ -0,0 +1,352 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/NakarinFIgo/Movies-App/internal/entities"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrSeatUnavailable     = conflictError("one or more seats are no longer available")
+	ErrInvalidSeats        = validationError("seats do not belong to the showtime's screen")
+	ErrShowtimeStarted     = conflictError("showtime has already started")
+	ErrShowtimeOverlap     = conflictError("screen already has a showtime in this time range")
+	ErrShowtimeHasBookings = conflictError("showtime already has bookings")
+	ErrBookingNotFound     = notFoundError("booking not found")
+	ErrBookingNotHeld      = conflictError("booking is not on hold")
+	ErrHoldExpired         = conflictError("seat hold has expired")
+)
+
+// expireHoldsSQL ปล่อยที่นั่งของการจองแบบ hold ที่หมดเวลาแล้ว
+const expireHoldsSQL = `
+WITH expired AS (
+	UPDATE bookings SET status = 'expired', updated_at = now()
+	WHERE status = 'held' AND expires_at <= now() %s
+	RETURNING id
+)
+UPDATE booking_seats SET released = true WHERE booking_id IN (SELECT id FROM expired)`
+
+func (m *PostgresRepository) AllTheaters() ([]*entities.Theater, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	var theaters []*entities.Theater
+
+	result := m.DB.WithContext(ctx).Preload("Screens").Order("name").Find(&theaters)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return theaters, nil
+}
+
+func (m *PostgresRepository) InsertTheater(theater entities.Theater) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	if err := m.DB.WithContext(ctx).Create(&theater).Error; err != nil {
+		return 0, err
+	}
+	return theater.ID, nil
+}
+
+// InsertScreen บันทึกโรงฉายพร้อมผังที่นั่งทั้งหมดใน transaction เดียว
+func (m *PostgresRepository) InsertScreen(screen entities.Screen) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	if err := m.DB.WithContext(ctx).Create(&screen).Error; err != nil {
+		if pgErrorCode(err) == pgForeignKeyViolation {
+			return 0, gorm.ErrRecordNotFound
+		}
+		return 0, err
+	}
+	return screen.ID, nil
+}
+
+func (m *PostgresRepository) InsertShowtime(showtime entities.Showtime) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	if err := m.DB.WithContext(ctx).Omit(clause.Associations).Create(&showtime).Error; err != nil {
+		switch pgErrorCode(err) {
+		case pgExclusionViolation:
+			return 0, ErrShowtimeOverlap
+		case pgForeignKeyViolation:
+			return 0, gorm.ErrRecordNotFound
+		}
+		return 0, err
+	}
+	return showtime.ID, nil
+}
+
+func (m *PostgresRepository) DeleteShowtime(id int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	result := m.DB.WithContext(ctx).Delete(&entities.Showtime{}, id)
+	if result.Error != nil {
+		if pgErrorCode(result.Error) == pgForeignKeyViolation {
+			return ErrShowtimeHasBookings
+		}
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (m *PostgresRepository) OneShowtime(id int) (*entities.Showtime, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	var showtime entities.Showtime
+
+	err := m.DB.WithContext(ctx).Preload("Movie").Preload("Screen.Theater").First(&showtime, id).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &showtime, nil
+}
+
+// ShowtimesForMovie ดึงรอบฉายของหนังที่เริ่มหลังเวลา from เรียงตามเวลาฉาย
+func (m *PostgresRepository) ShowtimesForMovie(movieID int, from time.Time) ([]*entities.Showtime, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	var showtimes []*entities.Showtime
+
+	result := m.DB.WithContext(ctx).
+		Preload("Screen.Theater").
+		Where("movie_id = ? AND starts_at >= ?", movieID, from).
+		Order("starts_at").
+		Find(&showtimes)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return showtimes, nil
+}
+
+// SeatMap ดึงผังที่นั่งของรอบฉาย โดยที่นั่งที่ถูก hold (ยังไม่หมดเวลา) หรือจองแล้วจะไม่ว่าง
+func (m *PostgresRepository) SeatMap(showtimeID int) ([]*entities.SeatAvailability, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	var showtime entities.Showtime
+	if err := m.DB.WithContext(ctx).First(&showtime, showtimeID).Error; err != nil {
+		return nil, err
+	}
+
+	var seats []*entities.SeatAvailability
+	err := m.DB.WithContext(ctx).Raw(`
+		SELECT s.id, s.screen_id, s.row_label, s.seat_number, s.kind,
+			NOT EXISTS (
+				SELECT 1 FROM booking_seats bs
+				JOIN bookings b ON b.id = bs.booking_id
+				WHERE bs.showtime_id = ? AND bs.seat_id = s.id AND NOT bs.released
+				AND (b.status = 'confirmed' OR (b.status = 'held' AND b.expires_at > now()))
+			) AS available
+		FROM seats s
+		WHERE s.screen_id = ?
+		ORDER BY s.row_label, s.seat_number`, showtimeID, showtime.ScreenID).
+		Scan(&seats).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return seats, nil
+}
+
+// HoldSeats สร้างการจองแบบ hold ที่มีเวลาหมดอายุ
+// การกันจองซ้ำใช้ unique index (showtime_id, seat_id) WHERE NOT released ใน Postgres
+// ดังนั้น request ที่แข่งกันจองที่นั่งเดียวกันจะสำเร็จได้เพียงอันเดียว
+func (m *PostgresRepository) HoldSeats(userID, showtimeID int, seatIDs []int, expiresAt time.Time) (*entities.Booking, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	booking := entities.Booking{
+		ShowtimeID: showtimeID,
+		UserID:     userID,
+		Status:     entities.BookingStatusHeld,
+		ExpiresAt:  expiresAt,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+
+	err := m.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// ปล่อยที่นั่งที่ hold หมดเวลาแล้วของรอบนี้ก่อน เพื่อให้จองต่อได้ทันที
+		if err := tx.Exec(fmt.Sprintf(expireHoldsSQL, "AND showtime_id = ?"), showtimeID).Error; err != nil {
+			return err
+		}
+
+		var showtime entities.Showtime
+		if err := tx.First(&showtime, showtimeID).Error; err != nil {
+			return err
+		}
+		if !showtime.StartsAt.After(time.Now()) {
+			return ErrShowtimeStarted
+		}
+
+		var count int64
+		if err := tx.Model(&entities.Seat{}).
+			Where("id IN ? AND screen_id = ?", seatIDs, showtime.ScreenID).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if int(count) != len(seatIDs) {
+			return ErrInvalidSeats
+		}
+
+		if err := tx.Omit(clause.Associations).Create(&booking).Error; err != nil {
+			return err
+		}
+
+		for _, seatID := range seatIDs {
+			booking.Seats = append(booking.Seats, &entities.BookingSeat{
+				BookingID:  booking.ID,
+				ShowtimeID: showtimeID,
+				SeatID:     seatID,
+			})
+		}
+
+		if err := tx.Create(&booking.Seats).Error; err != nil {
+			if pgErrorCode(err) == pgUniqueViolation {
+				return ErrSeatUnavailable
+			}
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &booking, nil
+}
+
+// ConfirmBooking ยืนยันการจองที่ยัง hold อยู่ ถ้า hold หมดเวลาแล้วจะปล่อยที่นั่งและคืน ErrHoldExpired
+func (m *PostgresRepository) ConfirmBooking(userID, bookingID int) (*entities.Booking, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	var booking entities.Booking
+	expired := false
+
+	err := m.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND user_id = ?", bookingID, userID).
+			First(&booking).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrBookingNotFound
+			}
+			return err
+		}
+
+		if booking.Status != entities.BookingStatusHeld {
+			return ErrBookingNotHeld
+		}
+
+		now := time.Now()
+		if !booking.ExpiresAt.After(now) {
+			expired = true
+			return releaseBooking(tx, &booking, entities.BookingStatusExpired)
+		}
+
+		booking.Status = entities.BookingStatusConfirmed
+		booking.ConfirmedAt = &now
+		booking.UpdatedAt = now
+
+		return tx.Model(&booking).Updates(map[string]interface{}{
+			"status":       booking.Status,
+			"confirmed_at": booking.ConfirmedAt,
+			"updated_at":   booking.UpdatedAt,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	if expired {
+		return nil, ErrHoldExpired
+	}
+
+	return &booking, nil
+}
+
+// CancelBooking ยกเลิกการจองที่ยัง hold อยู่ และปล่อยที่นั่งคืน
+func (m *PostgresRepository) CancelBooking(userID, bookingID int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	return m.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var booking entities.Booking
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND user_id = ?", bookingID, userID).
+			First(&booking).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrBookingNotFound
+			}
+			return err
+		}
+
+		if booking.Status != entities.BookingStatusHeld {
+			return ErrBookingNotHeld
+		}
+
+		return releaseBooking(tx, &booking, entities.BookingStatusCancelled)
+	})
+}
+
+func releaseBooking(tx *gorm.DB, booking *entities.Booking, status string) error {
+	booking.Status = status
+	booking.UpdatedAt = time.Now()
+
+	if err := tx.Model(booking).Updates(map[string]interface{}{
+		"status":     booking.Status,
+		"updated_at": booking.UpdatedAt,
+	}).Error; err != nil {
+		return err
+	}
+
+	return tx.Model(&entities.BookingSeat{}).
+		Where("booking_id = ?", booking.ID).
+		Update("released", true).Error
+}
+
+func (m *PostgresRepository) BookingsForUser(userID int) ([]*entities.Booking, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	var bookings []*entities.Booking
+
+	result := m.DB.WithContext(ctx).
+		Preload("Seats.Seat").
+		Preload("Showtime.Movie").
+		Preload("Showtime.Screen.Theater").
+		Where("user_id = ?", userID).
+		Order("created_at desc").
+		Find(&bookings)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return bookings, nil
+}
+
+// ExpireSeatHolds ปล่อยที่นั่งของ hold ที่หมดเวลาแล้วทุกรอบฉาย คืนจำนวนที่นั่งที่ถูกปล่อย
+func (m *PostgresRepository) ExpireSeatHolds() (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	result := m.DB.WithContext(ctx).Exec(fmt.Sprintf(expireHoldsSQL, ""))
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/NakarinFIgo/Movies-App/internal/entities"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrTOTPNotFound        = notFoundError("two-factor authentication is not set up")
+	ErrTOTPAlreadyEnabled  = conflictError("two-factor authentication is already enabled")
+	ErrTOTPCodeReused      = validationError("two-factor code has already been used")
+	ErrRecoveryCodeInvalid = validationError("invalid or used recovery code")
+)
+
+// UserTOTP ดึง TOTP ของผู้ใช้ ทั้งที่ยืนยันแล้วและที่ยังลงทะเบียนค้างอยู่
+func (m *PostgresRepository) UserTOTP(userID int) (*entities.UserTOTP, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	var t entities.UserTOTP
+	err := m.DB.WithContext(ctx).Where("user_id = ?", userID).First(&t).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTOTPNotFound
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+// SaveTOTPSecret เริ่มลงทะเบียน TOTP ด้วย secret ใหม่ แทนที่การลงทะเบียนที่ค้างอยู่
+// ถ้าผู้ใช้เปิดใช้ TOTP อยู่แล้วจะคืน ErrTOTPAlreadyEnabled
+func (m *PostgresRepository) SaveTOTPSecret(userID int, secret string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	return m.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing entities.UserTOTP
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ?", userID).
+			First(&existing).Error
+		switch {
+		case err == nil && existing.ConfirmedAt != nil:
+			return ErrTOTPAlreadyEnabled
+		case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+			return err
+		}
+
+		t := entities.UserTOTP{
+			UserID:    userID,
+			Secret:    secret,
+			CreatedAt: time.Now(),
+		}
+		return tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&t).Error
+	})
+}
+
+// EnableTOTP ยืนยันการลงทะเบียน TOTP ด้วยรหัสแรก (step) และแทนที่ recovery code ทั้งหมดด้วยชุดใหม่
+func (m *PostgresRepository) EnableTOTP(userID int, step int64, codeHashes []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	now := time.Now()
+
+	return m.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&entities.UserTOTP{}).
+			Where("user_id = ? AND confirmed_at IS NULL", userID).
+			Updates(map[string]interface{}{"confirmed_at": now, "last_used_step": step})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrTOTPNotFound
+		}
+
+		return replaceRecoveryCodes(tx, userID, codeHashes, now)
+	})
+}
+
+// UseTOTPStep บันทึกว่ารหัสของช่วงเวลา step ถูกใช้แล้ว
+// คืน ErrTOTPCodeReused ถ้ารหัสของช่วงนี้หรือช่วงหลังจากนี้ถูกใช้ไปแล้ว
+func (m *PostgresRepository) UseTOTPStep(userID int, step int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	res := m.DB.WithContext(ctx).Model(&entities.UserTOTP{}).
+		Where("user_id = ? AND confirmed_at IS NOT NULL AND last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrTOTPCodeReused
+	}
+	return nil
+}
+
+// UseRecoveryCode ใช้ recovery code ที่ยังไม่ถูกใช้ของผู้ใช้
+func (m *PostgresRepository) UseRecoveryCode(userID int, codeHash string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	res := m.DB.WithContext(ctx).Model(&entities.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrRecoveryCodeInvalid
+	}
+	return nil
+}
+
+// ReplaceRecoveryCodes ลบ recovery code เดิมทั้งหมดของผู้ใช้และเก็บชุดใหม่
+func (m *PostgresRepository) ReplaceRecoveryCodes(userID int, codeHashes []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	return m.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return replaceRecoveryCodes(tx, userID, codeHashes, time.Now())
+	})
+}
+
+// CountRecoveryCodes นับ recovery code ที่ยังใช้ได้ของผู้ใช้
+func (m *PostgresRepository) CountRecoveryCodes(userID int) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	var n int64
+	err := m.DB.WithContext(ctx).Model(&entities.RecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&n).Error
+	return n, err
+}
+
+// DisableTOTP ลบ TOTP และ recovery code ทั้งหมดของผู้ใช้
+func (m *PostgresRepository) DisableTOTP(userID int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	return m.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&entities.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		res := tx.Where("user_id = ?", userID).Delete(&entities.UserTOTP{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrTOTPNotFound
+		}
+		return nil
+	})
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userID int, codeHashes []string, now time.Time) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&entities.RecoveryCode{}).Error; err != nil {
+		return err
+	}
+
+	codes := make([]entities.RecoveryCode, len(codeHashes))
+	for i, hash := range codeHashes {
+		codes[i] = entities.RecoveryCode{UserID: userID, CodeHash: hash, CreatedAt: now}
+	}
+	if len(codes) == 0 {
+		return nil
+	}
+	return tx.Create(&codes).Error
+}
@@ -0,0 +1,203 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/NakarinFIgo/Movies-App/internal/entities"
+	"github.com/NakarinFIgo/Movies-App/internal/repository"
+	"github.com/robfig/cron/v3"
+)
+
+// Disabled คือค่า schedule ที่ใช้ปิด task
+const Disabled = "off"
+
+const defaultTaskTimeout = time.Minute * 30
+
+var ErrScheduleTooFrequent = errors.New("schedules more frequent than once a minute are not supported")
+
+// TaskFunc คืนสรุปผลสั้นๆ ที่จะบันทึกลงประวัติการรัน
+type TaskFunc func(ctx context.Context) (string, error)
+
+// Task คือข้อมูลของ task ที่แสดงให้ admin ดู
+type Task struct {
+	Name     string     `json:"name"`
+	Schedule string     `json:"schedule"`
+	NextRun  *time.Time `json:"next_run,omitempty"`
+}
+
+type task struct {
+	name     string
+	schedule string
+	fn       TaskFunc
+	entry    cron.EntryID
+}
+
+// Scheduler รัน task ตาม cron schedule (5 ช่องแบบ crontab หรือ @daily, @every 1h)
+// ทุก instance ของ API รัน scheduler แต่ในแต่ละรอบจะมี instance เดียวที่ได้รันแต่ละ task
+// โดยใช้ advisory lock ของ Postgres และ unique (task, scheduled_for) ในตาราง scheduler_runs
+type Scheduler struct {
+	DB      repository.DatabaseRepo
+	Timeout time.Duration
+
+	cron     *cron.Cron
+	ctx      context.Context
+	instance string
+
+	mu    sync.Mutex
+	tasks []*task
+}
+
+func New(db repository.DatabaseRepo) *Scheduler {
+	host, _ := os.Hostname()
+
+	return &Scheduler{
+		DB:       db,
+		Timeout:  defaultTaskTimeout,
+		cron:     cron.New(),
+		ctx:      context.Background(),
+		instance: fmt.Sprintf("%s-%d", host, os.Getpid()),
+	}
+}
+
+// Add ลงทะเบียน task ต้องเรียกก่อน Start ถ้า spec เป็น Disabled task จะแสดงในรายการแต่ไม่ถูกรัน
+func (s *Scheduler) Add(name, spec string, fn TaskFunc) error {
+	t := &task{name: name, schedule: spec, fn: fn}
+
+	if spec != Disabled {
+		schedule, err := cron.ParseStandard(spec)
+		if err != nil {
+			return fmt.Errorf("task %s: %w", name, err)
+		}
+
+		next := schedule.Next(time.Now())
+		if schedule.Next(next).Sub(next) < time.Minute {
+			return fmt.Errorf("task %s: %w", name, ErrScheduleTooFrequent)
+		}
+
+		t.entry = s.cron.Schedule(schedule, cron.FuncJob(func() { s.run(t) }))
+	}
+
+	s.mu.Lock()
+	s.tasks = append(s.tasks, t)
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Start เริ่มรัน task ตาม schedule และหยุดเมื่อ ctx ถูก cancel
+func (s *Scheduler) Start(ctx context.Context) {
+	s.ctx = ctx
+	s.cron.Start()
+
+	go func() {
+		<-ctx.Done()
+		<-s.cron.Stop().Done()
+	}()
+}
+
+// Tasks คืนรายการ task ทั้งหมดพร้อมเวลารันครั้งถัดไปบน instance นี้
+func (s *Scheduler) Tasks() []Task {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tasks := make([]Task, 0, len(s.tasks))
+	for _, t := range s.tasks {
+		info := Task{Name: t.name, Schedule: t.schedule}
+		if t.entry != 0 {
+			if next := s.cron.Entry(t.entry).Next; !next.IsZero() {
+				info.NextRun = &next
+			}
+		}
+		tasks = append(tasks, info)
+	}
+
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].Name < tasks[j].Name })
+
+	return tasks
+}
+
+// Has บอกว่ามี task ชื่อนี้หรือไม่
+func (s *Scheduler) Has(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, t := range s.tasks {
+		if t.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Scheduler) run(t *task) {
+	// schedule ละเอียดสุดคือนาที ทุก instance จึงได้รอบเวลาเดียวกันแม้นาฬิกาต่างกันเล็กน้อย
+	scheduledFor := time.Now().Truncate(time.Minute)
+
+	ctx, cancel := context.WithTimeout(s.ctx, s.Timeout)
+	defer cancel()
+
+	unlock, ok, err := s.DB.TryAdvisoryLock(ctx, lockKey(t.name))
+	if err != nil {
+		log.Printf("scheduler: %s: failed to acquire lock: %v", t.name, err)
+		return
+	}
+	if !ok {
+		// instance อื่นกำลังรัน task นี้อยู่
+		return
+	}
+	defer unlock()
+
+	run := &entities.SchedulerRun{
+		Task:         t.name,
+		Instance:     s.instance,
+		Status:       entities.SchedulerRunRunning,
+		ScheduledFor: scheduledFor,
+		StartedAt:    time.Now(),
+	}
+
+	inserted, err := s.DB.InsertSchedulerRun(run)
+	if err != nil {
+		log.Printf("scheduler: %s: failed to record run: %v", t.name, err)
+		return
+	}
+	if !inserted {
+		// instance อื่นรันรอบนี้ไปแล้ว
+		return
+	}
+
+	detail, err := safeCall(ctx, t.fn)
+
+	status, lastError := entities.SchedulerRunSucceeded, ""
+	if err != nil {
+		status, lastError = entities.SchedulerRunFailed, err.Error()
+		log.Printf("scheduler: %s failed: %v", t.name, err)
+	}
+
+	if err := s.DB.FinishSchedulerRun(run.ID, status, detail, lastError); err != nil {
+		log.Printf("scheduler: %s: failed to record result: %v", t.name, err)
+	}
+}
+
+// lockKey แปลงชื่อ task เป็น key ของ advisory lock
+func lockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("scheduler:" + name))
+	return int64(h.Sum64())
+}
+
+func safeCall(ctx context.Context, fn TaskFunc) (detail string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("task panicked: %v", r)
+		}
+	}()
+	return fn(ctx)
+}
@@ -0,0 +1,160 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/NakarinFIgo/Movies-App/internal/jobs"
+	"github.com/NakarinFIgo/Movies-App/internal/repository"
+)
+
+// ชื่อของ task ที่ระบบมี
+const (
+	TaskResyncMovies            = "movies-resync"
+	TaskExpireSeatHolds         = "seat-holds-expire"
+	TaskPurgeHistory            = "history-purge"
+	TaskPurgeSessions           = "sessions-purge"
+	TaskPurgeAccounts           = "accounts-purge"
+	TaskPurgePasswordResets     = "password-resets-purge"
+	TaskPurgeEmailVerifications = "email-verifications-purge"
+	TaskPurgeLoginThrottles     = "login-throttles-purge"
+	TaskPurgeLoginAttempts      = "login-attempts-purge"
+	TaskPurgeAuditLogs          = "audit-logs-purge"
+	TaskPurgeOIDCStates         = "oidc-states-purge"
+	TaskPurgeRateLimitBuckets   = "rate-limit-buckets-purge"
+)
+
+// DefaultSchedules คือ schedule ของแต่ละ task เมื่อไม่ได้ตั้งค่าไว้
+var DefaultSchedules = map[string]string{
+	TaskResyncMovies:            "0 4 * * *",
+	TaskExpireSeatHolds:         "* * * * *",
+	TaskPurgeHistory:            "30 3 * * *",
+	TaskPurgeSessions:           "15 * * * *",
+	TaskPurgeAccounts:           "45 * * * *",
+	TaskPurgePasswordResets:     "20 * * * *",
+	TaskPurgeEmailVerifications: "25 * * * *",
+	TaskPurgeLoginThrottles:     "35 * * * *",
+	TaskPurgeLoginAttempts:      "40 3 * * *",
+	TaskPurgeAuditLogs:          "50 3 * * *",
+	TaskPurgeOIDCStates:         "5 * * * *",
+	TaskPurgeRateLimitBuckets:   "10 * * * *",
+}
+
+// ResyncMovies ส่ง job re-sync metadata ของหนังทุกเรื่องที่มี tmdb_id เข้าคิว
+// ตัวงานจริงรันใน job worker จึงลองใหม่ได้เป็นรายเรื่องเมื่อ provider ล่ม
+func ResyncMovies(db repository.DatabaseRepo) TaskFunc {
+	return func(ctx context.Context) (string, error) {
+		ids, err := db.MovieIDsWithTMDBID()
+		if err != nil {
+			return "", err
+		}
+
+		for i, id := range ids {
+			if err := ctx.Err(); err != nil {
+				return fmt.Sprintf("enqueued %d of %d movies", i, len(ids)), err
+			}
+
+			job, err := jobs.New(jobs.KindResyncMovie, jobs.EnrichMoviePayload{MovieID: id})
+			if err != nil {
+				return "", err
+			}
+			if _, err := db.EnqueueJob(job); err != nil {
+				return fmt.Sprintf("enqueued %d of %d movies", i, len(ids)), err
+			}
+		}
+
+		return fmt.Sprintf("enqueued %d movies", len(ids)), nil
+	}
+}
+
+// ExpireSeatHolds ปล่อยที่นั่งของ hold ที่หมดเวลา (การจองจะปล่อย hold ที่หมดเวลาของรอบนั้นเองด้วย)
+func ExpireSeatHolds(db repository.DatabaseRepo) TaskFunc {
+	return func(ctx context.Context) (string, error) {
+		n, err := db.ExpireSeatHolds()
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("expired %d holds", n), nil
+	}
+}
+
+// PurgeHistory ลบ job ที่จบแล้วและประวัติการรัน task ที่เก่ากว่า retention
+func PurgeHistory(db repository.DatabaseRepo, retention time.Duration) TaskFunc {
+	return func(ctx context.Context) (string, error) {
+		n, err := db.PurgeHistory(time.Now().Add(-retention))
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("purged %d rows", n), nil
+	}
+}
+
+// PurgeSessions ลบ refresh token ที่หมดอายุหรือถูก revoke มานานกว่า retention
+func PurgeSessions(db repository.DatabaseRepo, retention time.Duration) TaskFunc {
+	return func(ctx context.Context) (string, error) {
+		n, err := db.PurgeSessions(time.Now().Add(-retention))
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("purged %d tokens", n), nil
+	}
+}
+
+// PurgeAccounts ลบบัญชีที่ผู้ใช้ขอลบและพ้นช่วงผ่อนผันแล้ว
+func PurgeAccounts(db repository.DatabaseRepo) TaskFunc {
+	return func(ctx context.Context) (string, error) {
+		n, err := db.PurgeDeletedUsers(time.Now())
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("deleted %d accounts", n), nil
+	}
+}
+
+// PurgePasswordResets ลบ reset token ที่หมดอายุมานานกว่า retention
+func PurgePasswordResets(db repository.DatabaseRepo, retention time.Duration) TaskFunc {
+	return purgeBefore(db.PurgePasswordResets, retention, "reset tokens")
+}
+
+// PurgeEmailVerifications ลบลิงก์ยืนยันอีเมลที่หมดอายุมานานกว่า retention
+func PurgeEmailVerifications(db repository.DatabaseRepo, retention time.Duration) TaskFunc {
+	return purgeBefore(db.PurgeEmailVerifications, retention, "verification links")
+}
+
+// PurgeLoginThrottles ลบตัวนับการ login ผิดที่ไม่ได้ใช้และไม่ได้ล็อกมานานกว่า retention
+func PurgeLoginThrottles(db repository.DatabaseRepo, retention time.Duration) TaskFunc {
+	return purgeBefore(db.PurgeLoginThrottles, retention, "login throttles")
+}
+
+// PurgeLoginAttempts ลบประวัติการ login ที่เก่ากว่า retention
+func PurgeLoginAttempts(db repository.DatabaseRepo, retention time.Duration) TaskFunc {
+	return purgeBefore(db.PurgeLoginAttempts, retention, "login attempts")
+}
+
+// PurgeAuditLogs ลบ audit log ที่เก่ากว่า retention
+func PurgeAuditLogs(db repository.DatabaseRepo, retention time.Duration) TaskFunc {
+	return purgeBefore(db.PurgeAuditLogs, retention, "audit log entries")
+}
+
+// PurgeOIDCStates ลบ state ของการ login ผ่าน identity provider ที่หมดอายุแล้ว
+func PurgeOIDCStates(db repository.DatabaseRepo) TaskFunc {
+	return purgeBefore(db.PurgeOIDCStates, 0, "login states")
+}
+
+// PurgeRateLimitBuckets ลบ bucket ของ rate limit ใน Postgres ที่เต็มแล้ว
+func PurgeRateLimitBuckets(db repository.DatabaseRepo) TaskFunc {
+	return purgeBefore(db.PurgeRateLimitBuckets, 0, "rate limit buckets")
+}
+
+// purgeBefore คือ task ที่ลบแถวที่เก่ากว่า retention ด้วย purge แต่ละตารางจึงมี task
+// และ timeout ของตัวเอง ตารางที่ใหญ่หรือช้าไม่ทำให้ตารางอื่นไม่ถูกลบ
+func purgeBefore(purge func(before time.Time) (int64, error), retention time.Duration, what string) TaskFunc {
+	return func(ctx context.Context) (string, error) {
+		n, err := purge(time.Now().Add(-retention))
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("purged %d %s", n, what), nil
+	}
+}
@@ -0,0 +1,220 @@
+// Package sso ให้ผู้ใช้ login ผ่าน identity provider ภายนอกด้วย OpenID Connect
+// ใช้ authorization code flow แบบมี PKCE ตรวจ state และ nonce และตรวจ ID token กับ JWKS ของ provider
+package sso
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+var (
+	ErrUnknownProvider   = errors.New("unknown identity provider")
+	ErrMissingIDToken    = errors.New("identity provider did not return an id token")
+	ErrNonceMismatch     = errors.New("id token nonce does not match")
+	ErrEmailNotVerified  = errors.New("identity provider has not verified the email address")
+	ErrDomainNotAllowed  = errors.New("email domain is not allowed for this identity provider")
+	ErrMissingEmailClaim = errors.New("id token has no email claim")
+)
+
+// ProviderConfig คือการตั้งค่าของ identity provider หนึ่งตัว
+type ProviderConfig struct {
+	// Name คือชื่อที่ใช้ใน URL เช่น /api/v1/auth/oidc/{name}/login
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	// Scopes ที่ขอเพิ่มจาก openid ค่าเริ่มต้นคือ email และ profile
+	Scopes []string
+	// AllowedDomains จำกัดโดเมนของอีเมลที่ login ได้ ว่างคือไม่จำกัด
+	AllowedDomains []string
+	// AutoCreate สร้างผู้ใช้ใหม่ให้อัตโนมัติเมื่อยังไม่มีบัญชีที่อีเมลตรงกัน
+	AutoCreate bool
+	// DefaultRole คือบทบาทของผู้ใช้ที่สร้างอัตโนมัติ
+	DefaultRole string
+}
+
+// Identity คือข้อมูลผู้ใช้จาก ID token ที่ตรวจแล้ว
+type Identity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	GivenName     string
+	FamilyName    string
+	Name          string
+}
+
+// Provider คือ identity provider หนึ่งตัว โหลด discovery document ครั้งแรกที่ใช้
+// เพื่อไม่ให้ API start ไม่ขึ้นเมื่อ provider ล่มชั่วคราว
+type Provider struct {
+	Config ProviderConfig
+	// HTTPClient ใช้คุยกับ provider เป็น nil ได้ ใช้ http.DefaultClient
+	HTTPClient *http.Client
+
+	mu       sync.Mutex
+	provider *oidc.Provider
+}
+
+// NewProvider สร้าง Provider จากการตั้งค่า
+func NewProvider(cfg ProviderConfig, client *http.Client) *Provider {
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"email", "profile"}
+	}
+	return &Provider{Config: cfg, HTTPClient: client}
+}
+
+func (p *Provider) context(ctx context.Context) context.Context {
+	if p.HTTPClient == nil {
+		return ctx
+	}
+	ctx = oidc.ClientContext(ctx, p.HTTPClient)
+	return context.WithValue(ctx, oauth2.HTTPClient, p.HTTPClient)
+}
+
+// discover โหลด discovery document ของ provider ถ้ายังไม่เคยโหลดสำเร็จ
+func (p *Provider) discover(ctx context.Context) (*oidc.Provider, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.provider != nil {
+		return p.provider, nil
+	}
+
+	// context ของ discovery ถูกใช้ต่อตอนดึง JWKS จึงต้องไม่ใช่ context ของ request
+	provider, err := oidc.NewProvider(p.context(context.Background()), p.Config.Issuer)
+	if err != nil {
+		return nil, fmt.Errorf("oidc discovery for %s: %w", p.Config.Name, err)
+	}
+
+	p.provider = provider
+	return provider, nil
+}
+
+func (p *Provider) oauth2Config(provider *oidc.Provider) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     p.Config.ClientID,
+		ClientSecret: p.Config.ClientSecret,
+		RedirectURL:  p.Config.RedirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       append([]string{oidc.ScopeOpenID}, p.Config.Scopes...),
+	}
+}
+
+// AuthURL คืน URL ของหน้า login ของ provider พร้อม state, nonce และ PKCE challenge ของ verifier
+func (p *Provider) AuthURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	provider, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return p.oauth2Config(provider).AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier)), nil
+}
+
+// Exchange แลก authorization code เป็น token แล้วตรวจ ID token ทั้งลายเซ็นจาก JWKS, iss, aud, exp และ nonce
+func (p *Provider) Exchange(ctx context.Context, code, verifier, nonce string) (*Identity, error) {
+	provider, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = p.context(ctx)
+
+	token, err := p.oauth2Config(provider).Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("oidc code exchange: %w", err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, ErrMissingIDToken
+	}
+
+	idToken, err := provider.Verifier(&oidc.Config{ClientID: p.Config.ClientID}).Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("oidc id token: %w", err)
+	}
+	if idToken.Nonce != nonce {
+		return nil, ErrNonceMismatch
+	}
+
+	var claims struct {
+		Email         string `json:"email"`
+		EmailVerified *bool  `json:"email_verified"`
+		GivenName     string `json:"given_name"`
+		FamilyName    string `json:"family_name"`
+		Name          string `json:"name"`
+	}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, err
+	}
+
+	identity := &Identity{
+		Provider:   p.Config.Name,
+		Subject:    idToken.Subject,
+		Email:      strings.TrimSpace(claims.Email),
+		GivenName:  claims.GivenName,
+		FamilyName: claims.FamilyName,
+		Name:       claims.Name,
+		// provider บางตัวไม่ส่ง email_verified ถือว่ายังไม่ยืนยัน
+		EmailVerified: claims.EmailVerified != nil && *claims.EmailVerified,
+	}
+
+	if err := p.checkEmail(identity); err != nil {
+		return nil, err
+	}
+	return identity, nil
+}
+
+// checkEmail ตรวจว่ามีอีเมลที่ provider ยืนยันแล้ว และอยู่ในโดเมนที่อนุญาต
+func (p *Provider) checkEmail(identity *Identity) error {
+	if identity.Email == "" {
+		return ErrMissingEmailClaim
+	}
+	if !identity.EmailVerified {
+		return ErrEmailNotVerified
+	}
+
+	if len(p.Config.AllowedDomains) == 0 {
+		return nil
+	}
+
+	at := strings.LastIndexByte(identity.Email, '@')
+	if at < 0 {
+		return ErrDomainNotAllowed
+	}
+	if !slices.Contains(p.Config.AllowedDomains, strings.ToLower(identity.Email[at+1:])) {
+		return ErrDomainNotAllowed
+	}
+	return nil
+}
+
+// Registry คือ provider ทั้งหมดที่ตั้งค่าไว้ ตามชื่อ
+type Registry map[string]*Provider
+
+// Get คืน provider ตามชื่อ
+func (r Registry) Get(name string) (*Provider, error) {
+	p, ok := r[name]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	return p, nil
+}
+
+// Names คืนชื่อ provider ทั้งหมดเรียงตามตัวอักษร
+func (r Registry) Names() []string {
+	names := make([]string, 0, len(r))
+	for name := range r {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
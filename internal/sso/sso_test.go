@@ -0,0 +1,275 @@
+package sso
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	testClientID = "movies-app"
+	testKeyID    = "test-key"
+	testCode     = "auth-code"
+	testVerifier = "verifier-0123456789-0123456789-0123456789-0123"
+	testNonce    = "nonce-123"
+)
+
+// testIdP คือ identity provider จำลองที่ให้ discovery, JWKS และ token endpoint
+// token endpoint ตรวจ PKCE กับ code_challenge ที่ได้จาก AuthURL และออก ID token จาก claims
+type testIdP struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu        sync.Mutex
+	challenge string
+	claims    jwt.MapClaims
+}
+
+func newTestIdP(t *testing.T) *testIdP {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	idp := &testIdP{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", idp.discovery)
+	mux.HandleFunc("/jwks", idp.jwks)
+	mux.HandleFunc("/token", idp.token)
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+
+	idp.claims = idp.defaultClaims()
+	return idp
+}
+
+func (idp *testIdP) defaultClaims() jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":            idp.server.URL,
+		"sub":            "user-1",
+		"aud":            testClientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Hour).Unix(),
+		"nonce":          testNonce,
+		"email":          "jane@example.com",
+		"email_verified": true,
+		"given_name":     "Jane",
+		"family_name":    "Doe",
+	}
+}
+
+func (idp *testIdP) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                idp.server.URL,
+		"authorization_endpoint":                idp.server.URL + "/authorize",
+		"token_endpoint":                        idp.server.URL + "/token",
+		"jwks_uri":                              idp.server.URL + "/jwks",
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+	})
+}
+
+func (idp *testIdP) jwks(w http.ResponseWriter, r *http.Request) {
+	pub := idp.key.PublicKey
+	writeJSON(w, http.StatusOK, map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"alg": "RS256",
+			"use": "sig",
+			"kid": testKeyID,
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+func (idp *testIdP) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	idp.mu.Lock()
+	challenge, claims := idp.challenge, idp.claims
+	idp.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if r.PostForm.Get("code") != testCode || base64.RawURLEncoding.EncodeToString(sum[:]) != challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = testKeyID
+	idToken, err := token.SignedString(idp.key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": "access-token",
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idToken,
+	})
+}
+
+func (idp *testIdP) setClaim(name string, value any) {
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+	idp.claims[name] = value
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// authorize เรียก AuthURL แล้วเก็บ code_challenge ให้ IdP เหมือนผู้ใช้ถูก redirect ไปหน้า login
+func (idp *testIdP) authorize(t *testing.T, p *Provider) url.Values {
+	t.Helper()
+
+	authURL, err := p.AuthURL(context.Background(), "state-123", testNonce, testVerifier)
+	if err != nil {
+		t.Fatalf("AuthURL: %v", err)
+	}
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	query := u.Query()
+	idp.mu.Lock()
+	idp.challenge = query.Get("code_challenge")
+	idp.mu.Unlock()
+	return query
+}
+
+func (idp *testIdP) provider(domains ...string) *Provider {
+	return NewProvider(ProviderConfig{
+		Name:           "test",
+		Issuer:         idp.server.URL,
+		ClientID:       testClientID,
+		ClientSecret:   "secret",
+		RedirectURL:    "http://localhost/callback",
+		AllowedDomains: domains,
+	}, idp.server.Client())
+}
+
+func TestAuthURL(t *testing.T) {
+	idp := newTestIdP(t)
+
+	query := idp.authorize(t, idp.provider())
+
+	want := map[string]string{
+		"client_id":             testClientID,
+		"state":                 "state-123",
+		"nonce":                 testNonce,
+		"code_challenge_method": "S256",
+	}
+	for name, value := range want {
+		if got := query.Get(name); got != value {
+			t.Errorf("%s = %q, want %q", name, got, value)
+		}
+	}
+	if query.Get("code_challenge") == "" || query.Get("code_challenge") == testVerifier {
+		t.Errorf("code_challenge = %q, want S256 of the verifier", query.Get("code_challenge"))
+	}
+}
+
+func TestExchange(t *testing.T) {
+	idp := newTestIdP(t)
+	p := idp.provider("example.com")
+	idp.authorize(t, p)
+
+	identity, err := p.Exchange(context.Background(), testCode, testVerifier, testNonce)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+
+	want := Identity{
+		Provider:      "test",
+		Subject:       "user-1",
+		Email:         "jane@example.com",
+		EmailVerified: true,
+		GivenName:     "Jane",
+		FamilyName:    "Doe",
+	}
+	if *identity != want {
+		t.Errorf("identity = %+v, want %+v", *identity, want)
+	}
+}
+
+func TestExchangeRejectsWrongVerifier(t *testing.T) {
+	idp := newTestIdP(t)
+	p := idp.provider()
+	idp.authorize(t, p)
+
+	if _, err := p.Exchange(context.Background(), testCode, "wrong-verifier-0123456789-0123456789-012345", testNonce); err == nil {
+		t.Fatal("Exchange with the wrong PKCE verifier succeeded")
+	}
+}
+
+func TestExchangeRejectsInvalidIDToken(t *testing.T) {
+	tests := []struct {
+		name    string
+		claim   string
+		value   any
+		domains []string
+		want    error
+	}{
+		{name: "wrong nonce", claim: "nonce", value: "other-nonce", want: ErrNonceMismatch},
+		{name: "wrong audience", claim: "aud", value: "other-client"},
+		{name: "wrong issuer", claim: "iss", value: "https://evil.example.com"},
+		{name: "expired", claim: "exp", value: time.Now().Add(-time.Hour).Unix()},
+		{name: "unverified email", claim: "email_verified", value: false, want: ErrEmailNotVerified},
+		{name: "missing email_verified", claim: "email_verified", value: nil, want: ErrEmailNotVerified},
+		{name: "missing email", claim: "email", value: "", want: ErrMissingEmailClaim},
+		{name: "disallowed domain", claim: "email", value: "jane@evil.com", domains: []string{"example.com"}, want: ErrDomainNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp := newTestIdP(t)
+			idp.setClaim(tt.claim, tt.value)
+			p := idp.provider(tt.domains...)
+			idp.authorize(t, p)
+
+			identity, err := p.Exchange(context.Background(), testCode, testVerifier, testNonce)
+			if err == nil {
+				t.Fatalf("Exchange succeeded with %+v", identity)
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	r := Registry{"okta": &Provider{}, "google": &Provider{}}
+
+	if _, err := r.Get("github"); !errors.Is(err, ErrUnknownProvider) {
+		t.Errorf("Get unknown: err = %v, want %v", err, ErrUnknownProvider)
+	}
+	if names := r.Names(); len(names) != 2 || names[0] != "google" || names[1] != "okta" {
+		t.Errorf("Names = %v, want [google okta]", names)
+	}
+}
@@ -0,0 +1,109 @@
+package images
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+var (
+	ErrUnsupportedType = errors.New("unsupported image type, use JPEG, PNG or WebP")
+	ErrTooManyPixels   = errors.New("image dimensions are too large")
+)
+
+// DefaultWidths คือความกว้างของรูปย่อที่สร้างให้ทุกรูปที่อัปโหลด
+var DefaultWidths = []int{185, 342, 500, 780}
+
+// MaxPixels จำกัดขนาดรูปหลัง decode เพื่อกัน decompression bomb
+const MaxPixels = 40_000_000
+
+const jpegQuality = 85
+
+var extensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
+// Sniff ตรวจชนิดไฟล์จากเนื้อหาจริง ไม่เชื่อ Content-Type ที่ client ส่งมา
+func Sniff(data []byte) (contentType, ext string, err error) {
+	contentType = http.DetectContentType(data)
+	ext, ok := extensions[contentType]
+	if !ok {
+		return "", "", ErrUnsupportedType
+	}
+	return contentType, ext, nil
+}
+
+// Decode อ่านขนาดรูปก่อน decode จริง เพื่อปฏิเสธรูปที่ใหญ่เกิน MaxPixels
+func Decode(data []byte) (image.Image, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedType, err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > MaxPixels {
+		return nil, ErrTooManyPixels
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedType, err)
+	}
+	return img, nil
+}
+
+type Variant struct {
+	Width       int
+	Height      int
+	ContentType string
+	Ext         string
+	Data        []byte
+}
+
+// Resize สร้างรูปย่อตามความกว้างที่กำหนดโดยคงสัดส่วนเดิม ข้ามความกว้างที่ไม่เล็กกว่ารูปต้นฉบับ
+// รูป PNG จะถูก encode เป็น PNG เพื่อคงพื้นหลังโปร่งใส ส่วนรูปอื่นเป็น JPEG
+func Resize(img image.Image, contentType string, widths []int) ([]Variant, error) {
+	bounds := img.Bounds()
+
+	var variants []Variant
+	for _, width := range widths {
+		if width <= 0 || width >= bounds.Dx() {
+			continue
+		}
+
+		height := bounds.Dy() * width / bounds.Dx()
+		if height < 1 {
+			height = 1
+		}
+
+		dst := image.NewRGBA(image.Rect(0, 0, width, height))
+		draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Over, nil)
+
+		variant := Variant{Width: width, Height: height}
+
+		var buf bytes.Buffer
+		if contentType == "image/png" {
+			if err := png.Encode(&buf, dst); err != nil {
+				return nil, err
+			}
+			variant.ContentType, variant.Ext = "image/png", ".png"
+		} else {
+			if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: jpegQuality}); err != nil {
+				return nil, err
+			}
+			variant.ContentType, variant.Ext = "image/jpeg", ".jpg"
+		}
+		variant.Data = buf.Bytes()
+
+		variants = append(variants, variant)
+	}
+
+	return variants, nil
+}
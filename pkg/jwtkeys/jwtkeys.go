@@ -0,0 +1,373 @@
+// Package jwtkeys จัดการกุญแจแบบ asymmetric (RS256 หรือ EdDSA) สำหรับเซ็นและตรวจ JWT
+//
+// กุญแจเก็บเป็นไฟล์ PEM ในไดเรกทอรีเดียว ชื่อไฟล์คือ kid เช่น 20261018T120000123Z-3f9a.pem
+// ไฟล์ที่เป็น private key ใช้ได้ทั้งเซ็นและตรวจ ไฟล์ที่เป็น public key ใช้ตรวจได้อย่างเดียว
+// ไฟล์ current เก็บ kid ของกุญแจที่ใช้เซ็น token ใหม่
+// กุญแจเก่าที่ยังอยู่ในไดเรกทอรีใช้ตรวจ token ที่ออกไปแล้วได้ จึงเปลี่ยนกุญแจได้โดยไม่ทำให้ทุกคนหลุดจากระบบ
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// อัลกอริทึมที่รองรับ ตรงกับค่า alg ใน header ของ JWT
+const (
+	RS256 = "RS256"
+	EdDSA = "EdDSA"
+)
+
+// CurrentFile คือชื่อไฟล์ที่เก็บ kid ของกุญแจที่ใช้เซ็น
+const CurrentFile = "current"
+
+// rsaBits คือขนาดของ RSA key ที่สร้างใหม่
+const rsaBits = 2048
+
+var (
+	ErrNoKeys             = errors.New("no signing keys found")
+	ErrUnknownKey         = errors.New("unknown key id")
+	ErrNoSigningKey       = errors.New("signing key has no private key")
+	ErrUnsupportedKey     = errors.New("unsupported key type, use RSA or Ed25519")
+	ErrUnsupportedAlg     = errors.New("unsupported algorithm, use RS256 or EdDSA")
+	ErrInvalidKeyID       = errors.New("invalid key id")
+	ErrCurrentKeyNotFound = errors.New("current key not found in key directory")
+)
+
+// Key คือกุญแจหนึ่งตัว Private เป็น nil ถ้าเป็นกุญแจที่ใช้ตรวจได้อย่างเดียว
+type Key struct {
+	ID        string
+	Algorithm string
+	Private   crypto.Signer
+	Public    crypto.PublicKey
+}
+
+// Method คืนวิธีเซ็นของ golang-jwt ที่ตรงกับอัลกอริทึมของกุญแจ
+func (k *Key) Method() jwt.SigningMethod {
+	if k.Algorithm == EdDSA {
+		return jwt.SigningMethodEdDSA
+	}
+	return jwt.SigningMethodRS256
+}
+
+// KeySet คือกุญแจทั้งหมดที่ใช้ตรวจ token และกุญแจตัวที่ใช้เซ็น
+type KeySet struct {
+	Signing *Key
+	keys    map[string]*Key
+}
+
+// NewKeySet สร้าง KeySet จากกุญแจที่โหลดแล้ว signingID คือ kid ของกุญแจที่ใช้เซ็น
+func NewKeySet(keys []*Key, signingID string) (*KeySet, error) {
+	if len(keys) == 0 {
+		return nil, ErrNoKeys
+	}
+
+	set := &KeySet{keys: make(map[string]*Key, len(keys))}
+	for _, k := range keys {
+		set.keys[k.ID] = k
+	}
+
+	signing, ok := set.keys[signingID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrCurrentKeyNotFound, signingID)
+	}
+	if signing.Private == nil {
+		return nil, fmt.Errorf("%w: %q", ErrNoSigningKey, signingID)
+	}
+	set.Signing = signing
+
+	return set, nil
+}
+
+// Key คืนกุญแจตาม kid
+func (s *KeySet) Key(id string) (*Key, error) {
+	k, ok := s.keys[id]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	return k, nil
+}
+
+// Algorithms คืนอัลกอริทึมทั้งหมดที่มีกุญแจใช้ตรวจ
+func (s *KeySet) Algorithms() []string {
+	var algs []string
+	for _, k := range s.keys {
+		if !slices.Contains(algs, k.Algorithm) {
+			algs = append(algs, k.Algorithm)
+		}
+	}
+	slices.Sort(algs)
+	return algs
+}
+
+// IDs คืน kid ทั้งหมดเรียงจากเก่าไปใหม่
+func (s *KeySet) IDs() []string {
+	ids := make([]string, 0, len(s.keys))
+	for id := range s.keys {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// JWK คือ public key หนึ่งตัวในรูป JSON Web Key (RFC 7517)
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	// N และ E ใช้กับ RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Curve และ X ใช้กับ Ed25519
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+}
+
+// JWKS คือเอกสาร /.well-known/jwks.json
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS คืน public key ทั้งหมดสำหรับให้ service อื่นใช้ตรวจ token
+func (s *KeySet) JWKS() JWKS {
+	doc := JWKS{Keys: []JWK{}}
+	for _, id := range s.IDs() {
+		k := s.keys[id]
+		jwk := JWK{KeyID: k.ID, Use: "sig", Algorithm: k.Algorithm}
+
+		switch pub := k.Public.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		default:
+			continue
+		}
+
+		doc.Keys = append(doc.Keys, jwk)
+	}
+	return doc
+}
+
+// NewKeyID สร้าง kid ใหม่จากเวลาที่สร้าง เรียงตามตัวอักษรได้ตามลำดับเวลา
+func NewKeyID(now time.Time) (string, error) {
+	b := make([]byte, 2)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	now = now.UTC()
+	return fmt.Sprintf("%s%03dZ-%s", now.Format("20060102T150405"), now.Nanosecond()/int(time.Millisecond), hex.EncodeToString(b)), nil
+}
+
+// validKeyID กัน kid ที่มีตัวอักษรของ path เพราะ kid ถูกใช้เป็นชื่อไฟล์
+func validKeyID(id string) bool {
+	return id != "" && id != CurrentFile && !strings.ContainsAny(id, `/\.`) && !strings.HasPrefix(id, "-")
+}
+
+// Generate สร้างกุญแจใหม่ด้วยอัลกอริทึม alg
+func Generate(id, alg string) (*Key, error) {
+	if !validKeyID(id) {
+		return nil, ErrInvalidKeyID
+	}
+
+	var private crypto.Signer
+	var err error
+
+	switch alg {
+	case RS256:
+		private, err = rsa.GenerateKey(rand.Reader, rsaBits)
+	case EdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, ErrUnsupportedAlg
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &Key{ID: id, Algorithm: alg, Private: private, Public: private.Public()}, nil
+}
+
+// ParsePEM อ่านกุญแจจาก PEM ที่เป็น private key แบบ PKCS#8 หรือ PKCS#1 หรือ public key แบบ PKIX
+func ParsePEM(id string, data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("key %s: no PEM block found", id)
+	}
+
+	var parsed any
+	var err error
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("key %s: unexpected PEM block %q", id, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("key %s: %w", id, err)
+	}
+
+	k := &Key{ID: id}
+	switch key := parsed.(type) {
+	case *rsa.PrivateKey:
+		k.Algorithm, k.Private, k.Public = RS256, key, key.Public()
+	case ed25519.PrivateKey:
+		k.Algorithm, k.Private, k.Public = EdDSA, key, key.Public()
+	case *rsa.PublicKey:
+		k.Algorithm, k.Public = RS256, key
+	case ed25519.PublicKey:
+		k.Algorithm, k.Public = EdDSA, key
+	default:
+		return nil, fmt.Errorf("key %s: %w", id, ErrUnsupportedKey)
+	}
+	return k, nil
+}
+
+// EncodePEM เขียนกุญแจเป็น PEM ถ้า publicOnly เป็น true หรือไม่มี private key จะเขียนเฉพาะ public key
+func EncodePEM(k *Key, publicOnly bool) ([]byte, error) {
+	if k.Private != nil && !publicOnly {
+		der, err := x509.MarshalPKCS8PrivateKey(k.Private)
+		if err != nil {
+			return nil, err
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(k.Public)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
+
+// LoadDir โหลดกุญแจทุกไฟล์ *.pem ในไดเรกทอรี และใช้กุญแจตาม kid ในไฟล์ current เป็นกุญแจที่ใช้เซ็น
+func LoadDir(dir string) (*KeySet, error) {
+	keys, err := ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := ReadCurrent(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewKeySet(keys, current)
+}
+
+// ReadDir โหลดกุญแจทุกไฟล์ *.pem ในไดเรกทอรี เรียงตาม kid จากเก่าไปใหม่
+func ReadDir(dir string) ([]*Key, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(paths)
+
+	keys := make([]*Key, 0, len(paths))
+	for _, path := range paths {
+		id := strings.TrimSuffix(filepath.Base(path), ".pem")
+		if !validKeyID(id) {
+			return nil, fmt.Errorf("%s: %w", path, ErrInvalidKeyID)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		k, err := ParsePEM(id, data)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+// ReadCurrent อ่าน kid ของกุญแจที่ใช้เซ็นจากไฟล์ current
+func ReadCurrent(dir string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, CurrentFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", ErrNoKeys
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// WriteKey เขียนกุญแจลงไดเรกทอรีเป็นไฟล์ <kid>.pem ที่อ่านได้เฉพาะเจ้าของ
+func WriteKey(dir string, k *Key, publicOnly bool) error {
+	if !validKeyID(k.ID) {
+		return ErrInvalidKeyID
+	}
+
+	data, err := EncodePEM(k, publicOnly)
+	if err != nil {
+		return err
+	}
+	return writeFile(filepath.Join(dir, k.ID+".pem"), data)
+}
+
+// WriteCurrent ตั้งกุญแจ kid เป็นกุญแจที่ใช้เซ็น
+func WriteCurrent(dir, id string) error {
+	if !validKeyID(id) {
+		return ErrInvalidKeyID
+	}
+	return writeFile(filepath.Join(dir, CurrentFile), []byte(id+"\n"))
+}
+
+// RemoveKey ลบไฟล์ของกุญแจ kid
+func RemoveKey(dir, id string) error {
+	if !validKeyID(id) {
+		return ErrInvalidKeyID
+	}
+	return os.Remove(filepath.Join(dir, id+".pem"))
+}
+
+// writeFile เขียนไฟล์ใหม่ข้างกันแล้ว rename ทับ server ที่อ่านไฟล์อยู่จึงไม่เห็นไฟล์ที่เขียนไม่ครบ
+func writeFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
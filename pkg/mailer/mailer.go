@@ -0,0 +1,129 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Message คืออีเมลแบบข้อความธรรมดาหนึ่งฉบับ
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender ส่งอีเมล มีทั้งแบบ SMTP และแบบเขียนไฟล์/log สำหรับ development และ test
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+var ErrInvalidAddress = errors.New("mailer: invalid address")
+
+// SMTP ส่งอีเมลผ่าน SMTP server ใช้ STARTTLS ถ้า server รองรับ
+type SMTP struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+func (s *SMTP) Send(ctx context.Context, msg Message) error {
+	if err := checkHeader(msg.To); err != nil {
+		return err
+	}
+
+	addr := net.JoinHostPort(s.Host, strconv.Itoa(s.Port))
+
+	var auth smtp.Auth
+	if s.Username != "" {
+		auth = smtp.PlainAuth("", s.Username, s.Password, s.Host)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(addr, auth, s.From, []string{msg.To}, format(s.From, msg))
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-done:
+		return err
+	}
+}
+
+// File เขียนอีเมลแต่ละฉบับเป็นไฟล์ .eml ใน Dir ใช้ตรวจอีเมลได้โดยไม่ต้องมี SMTP server
+type File struct {
+	Dir  string
+	From string
+}
+
+func (f *File) Send(ctx context.Context, msg Message) error {
+	if err := checkHeader(msg.To); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(f.Dir, 0o755); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405.000000000"), sanitize(msg.To))
+
+	return os.WriteFile(filepath.Join(f.Dir, name), format(f.From, msg), 0o600)
+}
+
+// Log พิมพ์อีเมลลง log แทนการส่งจริง
+type Log struct {
+	From string
+}
+
+func (l *Log) Send(ctx context.Context, msg Message) error {
+	if err := checkHeader(msg.To); err != nil {
+		return err
+	}
+
+	log.Printf("mailer: to=%s subject=%q\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+func format(from string, msg Message) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+// checkHeader กัน header injection จากค่าที่มาจากผู้ใช้
+func checkHeader(value string) error {
+	if value == "" || strings.ContainsAny(value, "\r\n") {
+		return ErrInvalidAddress
+	}
+	return nil
+}
+
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_', r == '@':
+			return r
+		default:
+			return '_'
+		}
+	}, s)
+}
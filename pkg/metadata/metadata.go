@@ -0,0 +1,47 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrNotFound = errors.New("metadata: no matching movie found")
+
+// Provider คือแหล่งข้อมูล metadata ของหนังจากภายนอก เช่น TMDB
+// ค่า Provider ที่เป็น nil หมายถึงไม่ได้ตั้งค่า provider ไว้ ซึ่งเป็น configuration ที่ใช้ได้
+type Provider interface {
+	// Name คือชื่อของ provider เช่น "tmdb"
+	Name() string
+	// SearchMovies ค้นหาหนังด้วยชื่อ ผลลัพธ์เรียงตามที่ provider ส่งมา
+	SearchMovies(ctx context.Context, title string) ([]Movie, error)
+	// MovieDetails ดึงข้อมูลเต็มของหนังด้วย ID ของ provider คืน ErrNotFound ถ้าไม่พบ
+	MovieDetails(ctx context.Context, providerID string) (*MovieDetails, error)
+}
+
+// Movie คือผลลัพธ์หนึ่งรายการจาก provider
+type Movie struct {
+	ProviderID  string `json:"provider_id"`
+	Title       string `json:"title"`
+	ReleaseDate string `json:"release_date"`
+	Overview    string `json:"overview"`
+	PosterPath  string `json:"poster_path"`
+	PosterURL   string `json:"poster_url"`
+}
+
+// MovieDetails คือข้อมูลเต็มของหนังหนึ่งเรื่องจาก provider
+type MovieDetails struct {
+	ProviderID    string  `json:"provider_id"`
+	Title         string  `json:"title"`
+	Overview      string  `json:"overview"`
+	ReleaseDate   string  `json:"release_date"`
+	Runtime       int     `json:"runtime"`
+	Certification string  `json:"certification"`
+	PosterPath    string  `json:"poster_path"`
+	BackdropPath  string  `json:"backdrop_path"`
+	Genres        []Genre `json:"genres"`
+}
+
+type Genre struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
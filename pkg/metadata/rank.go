@@ -0,0 +1,136 @@
+package metadata
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// น้ำหนักของคะแนนแต่ละส่วน ชื่อเรื่องสำคัญกว่าปีที่ฉาย
+const (
+	titleWeight = 0.7
+	yearWeight  = 0.3
+
+	// yearWindow คือจำนวนปีที่ต่างกันแล้วคะแนนปีเหลือศูนย์
+	yearWindow = 5
+	// unknownYearScore ใช้เมื่อไม่รู้ปีฝั่งใดฝั่งหนึ่ง ไม่ให้ได้หรือเสียคะแนน
+	unknownYearScore = 0.5
+)
+
+// MinAutoMatchScore คือคะแนนขั้นต่ำที่ยอมเลือกผลลัพธ์ให้อัตโนมัติโดยไม่ต้องให้ admin เลือก
+const MinAutoMatchScore = 0.6
+
+// Candidate คือผลลัพธ์จาก provider พร้อมคะแนนความใกล้เคียงกับหนังของเรา
+type Candidate struct {
+	Movie
+	Year  int     `json:"year"`
+	Score float64 `json:"score"`
+}
+
+// Rank ให้คะแนนผลลัพธ์จาก provider ตามความใกล้เคียงของชื่อเรื่องและปีที่ฉาย แล้วเรียงจากมากไปน้อย
+// year เป็น 0 ได้ถ้าไม่รู้ปีที่ฉาย ผลลัพธ์ที่คะแนนเท่ากันจะคงลำดับเดิมของ provider
+func Rank(title string, year int, movies []Movie) []Candidate {
+	wanted := normalizeTitle(title)
+
+	candidates := make([]Candidate, 0, len(movies))
+	for _, m := range movies {
+		c := Candidate{Movie: m, Year: releaseYear(m.ReleaseDate)}
+		c.Score = titleWeight*titleSimilarity(wanted, normalizeTitle(m.Title)) + yearWeight*yearScore(year, c.Year)
+		candidates = append(candidates, c)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+
+	return candidates
+}
+
+func releaseYear(releaseDate string) int {
+	if len(releaseDate) < 4 {
+		return 0
+	}
+	year, err := strconv.Atoi(releaseDate[:4])
+	if err != nil {
+		return 0
+	}
+	return year
+}
+
+func yearScore(want, got int) float64 {
+	if want == 0 || got == 0 {
+		return unknownYearScore
+	}
+
+	diff := want - got
+	if diff < 0 {
+		diff = -diff
+	}
+	if diff >= yearWindow {
+		return 0
+	}
+	return 1 - float64(diff)/yearWindow
+}
+
+// normalizeTitle ทำให้ชื่อเรื่องเทียบกันได้ ตัดตัวพิมพ์ใหญ่ เครื่องหมาย และ article นำหน้า
+func normalizeTitle(title string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(title) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		case r == '&':
+			b.WriteString(" and ")
+		default:
+			b.WriteRune(' ')
+		}
+	}
+
+	words := strings.Fields(b.String())
+	if len(words) > 1 {
+		switch words[0] {
+		case "the", "a", "an":
+			words = words[1:]
+		}
+	}
+
+	return strings.Join(words, " ")
+}
+
+// titleSimilarity คือ 1 - (Levenshtein distance / ความยาวของชื่อที่ยาวกว่า)
+func titleSimilarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 && len(rb) == 0 {
+		return 1
+	}
+
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
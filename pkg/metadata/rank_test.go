@@ -0,0 +1,211 @@
+package metadata
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// loadSearchFixture อ่านผลการค้นหาของ TMDB จาก testdata ในลำดับเดียวกับที่ TMDB ส่งมา
+func loadSearchFixture(t *testing.T, name string) []Movie {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var resp tmdbSearchResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+
+	movies := make([]Movie, 0, len(resp.Results))
+	for _, r := range resp.Results {
+		movies = append(movies, Movie{ProviderID: strconv.Itoa(r.ID), Title: r.Title, ReleaseDate: r.ReleaseDate})
+	}
+	return movies
+}
+
+func TestRank(t *testing.T) {
+	tests := []struct {
+		name    string
+		fixture string
+		title   string
+		year    int
+		// want คือ ProviderID ตามลำดับที่คาดไว้
+		want []string
+	}{
+		{
+			name:    "remake picked by year",
+			fixture: "search_the_thing.json",
+			title:   "The Thing",
+			year:    2011,
+			want:    []string{"60935", "1091", "10785"},
+		},
+		{
+			name:    "original picked by year",
+			fixture: "search_the_thing.json",
+			title:   "The Thing",
+			year:    1982,
+			want:    []string{"1091", "60935", "10785"},
+		},
+		{
+			name:    "title outweighs year",
+			fixture: "search_the_thing.json",
+			title:   "The Thing",
+			year:    1951,
+			want:    []string{"1091", "60935", "10785"},
+		},
+		{
+			name:    "unknown year keeps provider order for equal titles",
+			fixture: "search_the_thing.json",
+			title:   "the thing",
+			year:    0,
+			want:    []string{"1091", "60935", "10785"},
+		},
+		{
+			// ปีที่ไม่รู้ได้คะแนนกลาง ๆ จึงอยู่เหนือปีที่ห่างเกิน yearWindow
+			name:    "nearest year wins among same titles",
+			fixture: "search_little_women.json",
+			title:   "Little Women",
+			year:    1995,
+			want:    []string{"9587", "520946", "331482", "25430", "38762"},
+		},
+		{
+			name:    "exact year among same titles",
+			fixture: "search_little_women.json",
+			title:   "Little Women",
+			year:    1949,
+			want:    []string{"25430", "520946", "331482", "9587", "38762"},
+		},
+		{
+			name:    "years outside the window tie and keep provider order",
+			fixture: "search_little_women.json",
+			title:   "Little Women",
+			year:    1941,
+			want:    []string{"520946", "331482", "9587", "25430", "38762"},
+		},
+		{
+			name:    "exact title beats a sequel from the wanted year",
+			fixture: "search_dune.json",
+			title:   "Dune",
+			year:    2024,
+			want:    []string{"438631", "841", "693134"},
+		},
+		{
+			name:    "older film with the same title",
+			fixture: "search_dune.json",
+			title:   "DUNE",
+			year:    1984,
+			want:    []string{"841", "438631", "693134"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			candidates := Rank(tt.title, tt.year, loadSearchFixture(t, tt.fixture))
+
+			got := make([]string, len(candidates))
+			for i, c := range candidates {
+				got[i] = c.ProviderID
+			}
+			if !equalStrings(got, tt.want) {
+				t.Errorf("order = %v, want %v", got, tt.want)
+			}
+
+			for i := 1; i < len(candidates); i++ {
+				if candidates[i].Score > candidates[i-1].Score {
+					t.Errorf("candidate %d scores %.3f above candidate %d (%.3f)", i, candidates[i].Score, i-1, candidates[i-1].Score)
+				}
+			}
+		})
+	}
+}
+
+func TestRankExactMatchScoresOne(t *testing.T) {
+	candidates := Rank("The Thing", 1982, loadSearchFixture(t, "search_the_thing.json"))
+
+	top := candidates[0]
+	if top.ProviderID != "1091" || top.Year != 1982 {
+		t.Fatalf("top = %+v, want The Thing (1982)", top)
+	}
+	if top.Score < 0.999 {
+		t.Errorf("score = %.3f, want 1", top.Score)
+	}
+	if top.Score < MinAutoMatchScore {
+		t.Errorf("exact match scores below MinAutoMatchScore")
+	}
+}
+
+func TestNormalizeTitle(t *testing.T) {
+	tests := map[string]string{
+		"The Thing":                    "thing",
+		"  the   THING ":               "thing",
+		"Dune: Part Two":               "dune part two",
+		"Fast & Furious":               "fast and furious",
+		"A Quiet Place":                "quiet place",
+		"The":                          "the",
+		"Amélie":                       "amélie",
+		"Spider-Man: No Way Home":      "spider man no way home",
+		"Star Wars: Episode IV (1977)": "star wars episode iv 1977",
+	}
+
+	for in, want := range tests {
+		if got := normalizeTitle(in); got != want {
+			t.Errorf("normalizeTitle(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestYearScore(t *testing.T) {
+	tests := []struct {
+		want, got int
+		score     float64
+	}{
+		{1994, 1994, 1},
+		{1994, 1995, 0.8},
+		{1995, 1994, 0.8},
+		{1994, 1998, 0.2},
+		{1994, 1999, 0},
+		{1994, 2019, 0},
+		{0, 1994, unknownYearScore},
+		{1994, 0, unknownYearScore},
+	}
+
+	for _, tt := range tests {
+		if got := yearScore(tt.want, tt.got); got < tt.score-1e-9 || got > tt.score+1e-9 {
+			t.Errorf("yearScore(%d, %d) = %v, want %v", tt.want, tt.got, got, tt.score)
+		}
+	}
+}
+
+func TestReleaseYear(t *testing.T) {
+	tests := map[string]int{
+		"1982-06-25": 1982,
+		"2011":       2011,
+		"":           0,
+		"198":        0,
+		"unknown":    0,
+	}
+
+	for in, want := range tests {
+		if got := releaseYear(in); got != want {
+			t.Errorf("releaseYear(%q) = %d, want %d", in, got, want)
+		}
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
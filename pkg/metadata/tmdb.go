@@ -0,0 +1,360 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultTMDBBaseURL      = "https://api.themoviedb.org/3"
+	DefaultTMDBImageBaseURL = "https://image.tmdb.org/t/p"
+
+	// posterSize คือขนาดรูป poster ที่ใช้แสดงในรายการผลลัพธ์
+	posterSize = "w342"
+
+	defaultTimeout      = time.Second * 5
+	defaultMaxRetries   = 2
+	defaultRetryBackoff = time.Millisecond * 250
+	defaultCacheTTL     = time.Hour
+	// maxRetryAfter คือเวลารอสูงสุดที่ยอมตาม Retry-After ถ้า TMDB ให้รอนานกว่านี้จะไม่ลองใหม่
+	maxRetryAfter    = time.Second * 10
+	maxCacheEntries  = 1000
+	maxResponseBytes = 5 << 20
+)
+
+// TMDBConfig คือการตั้งค่าของ TMDB provider ค่าที่เป็นศูนย์จะใช้ค่าเริ่มต้น
+// BaseURL เปลี่ยนได้ เช่นชี้ไปที่ httptest server ตอนทดสอบ และ MaxRetries ที่ติดลบคือไม่ลองใหม่
+type TMDBConfig struct {
+	APIKey       string
+	BaseURL      string
+	ImageBaseURL string
+	Timeout      time.Duration
+	MaxRetries   int
+	RetryBackoff time.Duration
+	CacheTTL     time.Duration
+}
+
+type TMDB struct {
+	apiKey     string
+	baseURL    string
+	imageURL   string
+	client     *http.Client
+	maxRetries int
+	backoff    time.Duration
+	cache      *responseCache
+}
+
+func NewTMDB(cfg TMDBConfig) *TMDB {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = DefaultTMDBBaseURL
+	}
+	if cfg.ImageBaseURL == "" {
+		cfg.ImageBaseURL = DefaultTMDBImageBaseURL
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	} else if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = defaultMaxRetries
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = defaultRetryBackoff
+	}
+	if cfg.CacheTTL <= 0 {
+		cfg.CacheTTL = defaultCacheTTL
+	}
+
+	return &TMDB{
+		apiKey:     cfg.APIKey,
+		baseURL:    strings.TrimSuffix(cfg.BaseURL, "/"),
+		imageURL:   strings.TrimSuffix(cfg.ImageBaseURL, "/"),
+		client:     &http.Client{Timeout: cfg.Timeout},
+		maxRetries: cfg.MaxRetries,
+		backoff:    cfg.RetryBackoff,
+		cache:      newResponseCache(cfg.CacheTTL),
+	}
+}
+
+func (t *TMDB) Name() string {
+	return "tmdb"
+}
+
+type tmdbSearchResponse struct {
+	Page    int `json:"page"`
+	Results []struct {
+		ID          int    `json:"id"`
+		Title       string `json:"title"`
+		ReleaseDate string `json:"release_date"`
+		Overview    string `json:"overview"`
+		PosterPath  string `json:"poster_path"`
+	} `json:"results"`
+	TotalPages int `json:"total_pages"`
+}
+
+func (t *TMDB) SearchMovies(ctx context.Context, title string) ([]Movie, error) {
+	var resp tmdbSearchResponse
+	if err := t.get(ctx, "/search/movie", url.Values{"query": {title}}, &resp); err != nil {
+		return nil, err
+	}
+
+	movies := make([]Movie, 0, len(resp.Results))
+	for _, r := range resp.Results {
+		movies = append(movies, Movie{
+			ProviderID:  strconv.Itoa(r.ID),
+			Title:       r.Title,
+			ReleaseDate: r.ReleaseDate,
+			Overview:    r.Overview,
+			PosterPath:  r.PosterPath,
+			PosterURL:   t.posterURL(r.PosterPath),
+		})
+	}
+
+	return movies, nil
+}
+
+func (t *TMDB) posterURL(path string) string {
+	if path == "" {
+		return ""
+	}
+	return t.imageURL + "/" + posterSize + path
+}
+
+type tmdbMovieResponse struct {
+	ID           int     `json:"id"`
+	Title        string  `json:"title"`
+	Overview     string  `json:"overview"`
+	ReleaseDate  string  `json:"release_date"`
+	Runtime      int     `json:"runtime"`
+	PosterPath   string  `json:"poster_path"`
+	BackdropPath string  `json:"backdrop_path"`
+	Genres       []Genre `json:"genres"`
+	ReleaseDates struct {
+		Results []struct {
+			Country      string `json:"iso_3166_1"`
+			ReleaseDates []struct {
+				Certification string `json:"certification"`
+				Type          int    `json:"type"`
+			} `json:"release_dates"`
+		} `json:"results"`
+	} `json:"release_dates"`
+}
+
+// certificationCountry คือประเทศที่ใช้ rating ซึ่งตรงกับ mpaa_rating ของเรา
+const certificationCountry = "US"
+
+// tmdbTheatricalRelease คือ release type ของการฉายโรงใน TMDB
+const tmdbTheatricalRelease = 3
+
+func (t *TMDB) MovieDetails(ctx context.Context, providerID string) (*MovieDetails, error) {
+	if _, err := strconv.Atoi(providerID); err != nil {
+		return nil, ErrNotFound
+	}
+
+	var resp tmdbMovieResponse
+	query := url.Values{"append_to_response": {"release_dates"}}
+	if err := t.get(ctx, "/movie/"+providerID, query, &resp); err != nil {
+		return nil, err
+	}
+
+	details := &MovieDetails{
+		ProviderID:   strconv.Itoa(resp.ID),
+		Title:        resp.Title,
+		Overview:     resp.Overview,
+		ReleaseDate:  resp.ReleaseDate,
+		Runtime:      resp.Runtime,
+		PosterPath:   resp.PosterPath,
+		BackdropPath: resp.BackdropPath,
+		Genres:       resp.Genres,
+	}
+
+	// ใช้ rating ของรอบฉายโรงก่อน ถ้าไม่มีจึงใช้ rating แรกที่ไม่ว่าง
+	for _, country := range resp.ReleaseDates.Results {
+		if country.Country != certificationCountry {
+			continue
+		}
+		for _, rd := range country.ReleaseDates {
+			if rd.Certification == "" {
+				continue
+			}
+			if details.Certification == "" || rd.Type == tmdbTheatricalRelease {
+				details.Certification = rd.Certification
+			}
+			if rd.Type == tmdbTheatricalRelease {
+				break
+			}
+		}
+	}
+
+	return details, nil
+}
+
+// get เรียก TMDB API และ decode JSON ลง out โดยใช้ cache ก่อน
+// cache key ไม่รวม api_key เพื่อไม่ให้ key หลุดไปอยู่ในหน่วยความจำหรือ log
+func (t *TMDB) get(ctx context.Context, path string, query url.Values, out interface{}) error {
+	cacheKey := path + "?" + query.Encode()
+
+	body, ok := t.cache.get(cacheKey)
+	if !ok {
+		q := url.Values{}
+		for k, v := range query {
+			q[k] = v
+		}
+		q.Set("api_key", t.apiKey)
+
+		var err error
+		body, err = t.doWithRetry(ctx, t.baseURL+path+"?"+q.Encode())
+		if err != nil {
+			return err
+		}
+		t.cache.set(cacheKey, body)
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("tmdb: decode response: %w", err)
+	}
+	return nil
+}
+
+// doWithRetry ลองใหม่เมื่อเกิด network error, 429 หรือ 5xx โดยรอแบบ exponential backoff
+// ถ้า TMDB ส่ง Retry-After มาและนานกว่า backoff จะรอตาม Retry-After แทน
+func (t *TMDB) doWithRetry(ctx context.Context, target string) ([]byte, error) {
+	var lastErr error
+	var retryAfter time.Duration
+
+	for attempt := 0; attempt <= t.maxRetries; attempt++ {
+		if attempt > 0 {
+			wait := max(t.backoff<<(attempt-1), retryAfter)
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(wait):
+			}
+		}
+
+		body, after, retry, err := t.do(ctx, target)
+		if err == nil {
+			return body, nil
+		}
+		lastErr = err
+		retryAfter = after
+
+		if !retry || retryAfter > maxRetryAfter || ctx.Err() != nil {
+			break
+		}
+	}
+
+	return nil, lastErr
+}
+
+func (t *TMDB) do(ctx context.Context, target string) (body []byte, retryAfter time.Duration, retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		// url.Error มี URL ที่รวม api_key อยู่ จึงส่งต่อเฉพาะ error ด้านใน
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, 0, true, fmt.Errorf("tmdb: request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err = io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return nil, 0, true, fmt.Errorf("tmdb: read response: %w", err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return body, 0, false, nil
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, 0, false, ErrNotFound
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return nil, parseRetryAfter(resp.Header.Get("Retry-After")), true, fmt.Errorf("tmdb: unexpected status %d", resp.StatusCode)
+	default:
+		return nil, 0, false, fmt.Errorf("tmdb: unexpected status %d", resp.StatusCode)
+	}
+}
+
+// parseRetryAfter อ่าน Retry-After ซึ่งเป็นจำนวนวินาทีหรือวันเวลาแบบ HTTP คืน 0 ถ้าไม่มีหรืออ่านไม่ได้
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0)
+	}
+	return 0
+}
+
+type cacheEntry struct {
+	body    []byte
+	expires time.Time
+}
+
+type responseCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]cacheEntry
+}
+
+func newResponseCache(ttl time.Duration) *responseCache {
+	return &responseCache{ttl: ttl, entries: make(map[string]cacheEntry)}
+}
+
+func (c *responseCache) get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.body, true
+}
+
+func (c *responseCache) set(key string, body []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.entries) >= maxCacheEntries {
+		now := time.Now()
+		for k, e := range c.entries {
+			if now.After(e.expires) {
+				delete(c.entries, k)
+			}
+		}
+		// ถ้ายังเต็มอยู่ ล้าง cache ทั้งหมด ดีกว่าปล่อยให้โตไม่จำกัด
+		if len(c.entries) >= maxCacheEntries {
+			c.entries = make(map[string]cacheEntry)
+		}
+	}
+
+	c.entries[key] = cacheEntry{body: body, expires: time.Now().Add(c.ttl)}
+}
@@ -0,0 +1,279 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const testAPIKey = "secret-api-key-123"
+
+// fakeTMDB ตอบตาม statuses ทีละครั้ง ครั้งที่เกินจำนวนใช้ status สุดท้าย และนับจำนวน request
+type fakeTMDB struct {
+	server     *httptest.Server
+	calls      atomic.Int32
+	statuses   []int
+	retryAfter string
+	body       string
+	lastQuery  atomic.Value
+}
+
+func newFakeTMDB(t *testing.T, body string, statuses ...int) *fakeTMDB {
+	t.Helper()
+
+	f := &fakeTMDB{statuses: statuses, body: body}
+	f.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(f.calls.Add(1))
+		f.lastQuery.Store(r.URL.Path + "?" + r.URL.RawQuery)
+
+		status := http.StatusOK
+		if len(f.statuses) > 0 {
+			status = f.statuses[min(n, len(f.statuses))-1]
+		}
+		if status != http.StatusOK {
+			if f.retryAfter != "" {
+				w.Header().Set("Retry-After", f.retryAfter)
+			}
+			// TMDB ใส่ api_key กลับมาใน error บางแบบ client ต้องไม่ส่งต่อ body นี้
+			http.Error(w, `{"status_message":"bad key `+r.URL.Query().Get("api_key")+`"}`, status)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(f.body))
+	}))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeTMDB) client() *TMDB {
+	return NewTMDB(TMDBConfig{
+		APIKey:       testAPIKey,
+		BaseURL:      f.server.URL + "/3/",
+		ImageBaseURL: "https://images.example.com/t/p/",
+		RetryBackoff: time.Millisecond,
+	})
+}
+
+func readFixture(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestTMDBSearchMoviesUsesBaseURL(t *testing.T) {
+	f := newFakeTMDB(t, readFixture(t, "search_the_thing.json"))
+
+	movies, err := f.client().SearchMovies(context.Background(), "The Thing")
+	if err != nil {
+		t.Fatalf("SearchMovies: %v", err)
+	}
+
+	query, _ := f.lastQuery.Load().(string)
+	if !strings.HasPrefix(query, "/3/search/movie?") || !strings.Contains(query, "query=The+Thing") || !strings.Contains(query, "api_key="+testAPIKey) {
+		t.Errorf("request = %q, want /3/search/movie with query and api_key", query)
+	}
+
+	if len(movies) != 3 {
+		t.Fatalf("got %d movies, want 3", len(movies))
+	}
+	want := Movie{
+		ProviderID:  "1091",
+		Title:       "The Thing",
+		ReleaseDate: "1982-06-25",
+		Overview:    movies[0].Overview,
+		PosterPath:  "/tzGY49kseSE9QAKk47uuDGwnSCu.jpg",
+		PosterURL:   "https://images.example.com/t/p/w342/tzGY49kseSE9QAKk47uuDGwnSCu.jpg",
+	}
+	if movies[0] != want {
+		t.Errorf("movies[0] = %+v, want %+v", movies[0], want)
+	}
+}
+
+func TestTMDBMovieDetails(t *testing.T) {
+	f := newFakeTMDB(t, `{
+		"id": 1091, "title": "The Thing", "release_date": "1982-06-25", "runtime": 109,
+		"genres": [{"id": 27, "name": "Horror"}],
+		"release_dates": {"results": [
+			{"iso_3166_1": "GB", "release_dates": [{"certification": "18", "type": 3}]},
+			{"iso_3166_1": "US", "release_dates": [
+				{"certification": "NR", "type": 1},
+				{"certification": "R", "type": 3},
+				{"certification": "TV-MA", "type": 4}
+			]}
+		]}
+	}`)
+
+	details, err := f.client().MovieDetails(context.Background(), "1091")
+	if err != nil {
+		t.Fatalf("MovieDetails: %v", err)
+	}
+
+	query, _ := f.lastQuery.Load().(string)
+	if !strings.HasPrefix(query, "/3/movie/1091?") || !strings.Contains(query, "append_to_response=release_dates") {
+		t.Errorf("request = %q", query)
+	}
+	if details.Certification != "R" || details.Runtime != 109 || len(details.Genres) != 1 {
+		t.Errorf("details = %+v, want US theatrical certification R", details)
+	}
+}
+
+func TestTMDBRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		statuses  []int
+		wantErr   bool
+		wantCalls int32
+	}{
+		{name: "429 then ok", statuses: []int{429, 200}, wantCalls: 2},
+		{name: "5xx then ok", statuses: []int{500, 503, 200}, wantCalls: 3},
+		{name: "gives up after MaxRetries", statuses: []int{502}, wantErr: true, wantCalls: 3},
+		{name: "4xx is not retried", statuses: []int{401}, wantErr: true, wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeTMDB(t, `{"results": []}`, tt.statuses...)
+
+			_, err := f.client().SearchMovies(context.Background(), "Dune")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := f.calls.Load(); got != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestTMDBRetryAfter(t *testing.T) {
+	f := newFakeTMDB(t, `{"results": []}`, http.StatusTooManyRequests, http.StatusOK)
+	f.retryAfter = "1"
+
+	start := time.Now()
+	if _, err := f.client().SearchMovies(context.Background(), "Dune"); err != nil {
+		t.Fatalf("SearchMovies: %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("retried after %v, want at least the 1s Retry-After", elapsed)
+	}
+	if got := f.calls.Load(); got != 2 {
+		t.Errorf("calls = %d, want 2", got)
+	}
+}
+
+func TestTMDBRetryAfterTooLong(t *testing.T) {
+	f := newFakeTMDB(t, `{"results": []}`, http.StatusTooManyRequests, http.StatusOK)
+	f.retryAfter = "120"
+
+	if _, err := f.client().SearchMovies(context.Background(), "Dune"); err == nil {
+		t.Fatal("SearchMovies succeeded, want the 429 error")
+	}
+	if got := f.calls.Load(); got != 1 {
+		t.Errorf("calls = %d, want 1 when Retry-After exceeds %v", got, maxRetryAfter)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := map[string]time.Duration{
+		"":     0,
+		"3":    3 * time.Second,
+		"-1":   0,
+		"soon": 0,
+		"120":  2 * time.Minute,
+		time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat): 0,
+	}
+
+	for in, want := range tests {
+		if got := parseRetryAfter(in); got != want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", in, got, want)
+		}
+	}
+
+	future := time.Now().Add(5 * time.Second).UTC().Format(http.TimeFormat)
+	if got := parseRetryAfter(future); got <= 0 || got > 5*time.Second {
+		t.Errorf("parseRetryAfter(%q) = %v, want up to 5s", future, got)
+	}
+}
+
+func TestTMDBNotFound(t *testing.T) {
+	f := newFakeTMDB(t, "", http.StatusNotFound)
+	client := f.client()
+
+	if _, err := client.MovieDetails(context.Background(), "999999"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("err = %v, want ErrNotFound", err)
+	}
+	if got := f.calls.Load(); got != 1 {
+		t.Errorf("calls = %d, want 1 because 404 is not retried", got)
+	}
+
+	if _, err := client.MovieDetails(context.Background(), "../search/movie"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("non-numeric id: err = %v, want ErrNotFound", err)
+	}
+	if got := f.calls.Load(); got != 1 {
+		t.Errorf("calls = %d, non-numeric id must not reach TMDB", got)
+	}
+}
+
+func TestTMDBCache(t *testing.T) {
+	f := newFakeTMDB(t, readFixture(t, "search_dune.json"))
+	client := f.client()
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.SearchMovies(context.Background(), "Dune"); err != nil {
+			t.Fatalf("SearchMovies: %v", err)
+		}
+	}
+	if got := f.calls.Load(); got != 1 {
+		t.Errorf("calls = %d, want 1 for repeated searches", got)
+	}
+
+	if _, err := client.SearchMovies(context.Background(), "Dune: Part Two"); err != nil {
+		t.Fatalf("SearchMovies: %v", err)
+	}
+	if got := f.calls.Load(); got != 2 {
+		t.Errorf("calls = %d, want 2 after a different search", got)
+	}
+}
+
+func TestTMDBErrorsHideAPIKey(t *testing.T) {
+	t.Run("error status", func(t *testing.T) {
+		f := newFakeTMDB(t, "", http.StatusUnauthorized)
+
+		_, err := f.client().SearchMovies(context.Background(), "Dune")
+		if err == nil || strings.Contains(err.Error(), testAPIKey) {
+			t.Errorf("err = %v, want an error without the api key", err)
+		}
+	})
+
+	t.Run("network error", func(t *testing.T) {
+		f := newFakeTMDB(t, "")
+		client := f.client()
+		f.server.Close()
+
+		_, err := client.SearchMovies(context.Background(), "Dune")
+		if err == nil || strings.Contains(err.Error(), testAPIKey) {
+			t.Errorf("err = %v, want an error without the api key", err)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		f := newFakeTMDB(t, `{"results": []}`)
+		client := NewTMDB(TMDBConfig{APIKey: testAPIKey, BaseURL: f.server.URL, Timeout: time.Nanosecond, MaxRetries: -1})
+
+		_, err := client.SearchMovies(context.Background(), "Dune")
+		if err == nil || strings.Contains(err.Error(), testAPIKey) {
+			t.Errorf("err = %v, want an error without the api key", err)
+		}
+	})
+}
@@ -1,30 +1,63 @@
 package middlewares
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/NakarinFIgo/Movies-App/pkg/jwtkeys"
+	"github.com/NakarinFIgo/Movies-App/pkg/utils"
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
 )
 
 type Auth struct {
-	Issuer        string
-	Audience      string
+	Issuer   string
+	Audience string
+	// Keys คือกุญแจ RS256 หรือ EdDSA ที่ใช้เซ็นและตรวจ token ถ้าเป็น nil จะใช้ HS256 กับ Secret
+	Keys          *jwtkeys.KeySet
 	Secret        string
 	TokenExpiry   time.Duration
 	RefreshExpiry time.Duration
-	CookieDomain  string
-	CookiePath    string
-	CookieName    string
+	// ChallengeExpiry คืออายุของ challenge token ระหว่างขั้นรหัสผ่านกับขั้นรหัส 2FA
+	ChallengeExpiry time.Duration
+	CookieDomain    string
+	CookiePath      string
+	CookieName      string
+	// APIKeys ตรวจ header Authorization: ApiKey ... ถ้าเป็น nil จะรับเฉพาะ Bearer token
+	APIKeys APIKeyAuthenticator
+}
+
+// APIKeyAuthenticator ตรวจ API key และคืน claims ที่มีสิทธิ์ของ key นั้น
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(key string) (*Claims, error)
+}
+
+// RateLimitError คือ error เมื่อใช้ API key เกินจำนวน request ที่กำหนด
+type RateLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return "rate limit exceeded"
 }
 
 type JWTUser struct {
 	ID        int    `json:"id"`
 	FirstName string `json:"first_name"`
 	LastName  string `json:"last_name"`
+	// Role และ Permissions ถูกฝังใน access token เพื่อให้ RequirePermission ตรวจได้โดยไม่ต้องอ่าน database
+	Role        string   `json:"role"`
+	Permissions []string `json:"permissions"`
+	// SessionID คือ family ID ของ refresh token ที่ออกพร้อมกัน
+	SessionID string `json:"session_id"`
 }
 
 type TokenPairs struct {
@@ -32,36 +65,82 @@ type TokenPairs struct {
 	RefreshToken string `json:"refresh_token"`
 }
 
+// ชนิดของ token เก็บใน claim token_type เพื่อไม่ให้ใช้ refresh token แทน access token ได้
+const (
+	TokenTypeAccess    = "access"
+	TokenTypeRefresh   = "refresh"
+	TokenTypeChallenge = "challenge"
+	// TokenTypeAPIKey ใช้กับ claims ที่สร้างจาก API key ไม่มี sub และ jti คือ id ของ key
+	TokenTypeAPIKey = "api_key"
+)
+
+// Claims คือ claims ของทั้ง access token และ refresh token
 type Claims struct {
 	jwt.RegisteredClaims
+	TokenType   string   `json:"token_type"`
+	Name        string   `json:"name,omitempty"`
+	Role        string   `json:"role,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+	SessionID   string   `json:"sid,omitempty"`
 }
 
-func (j *Auth) GenerateTokenPair(user *JWTUser) (TokenPairs, error) {
+// UserID คืน user id จาก claim sub
+func (c *Claims) UserID() (int, error) {
+	return strconv.Atoi(c.Subject)
+}
+
+// IsAPIKey บอกว่า claims มาจาก API key ไม่ใช่ access token ของผู้ใช้
+func (c *Claims) IsAPIKey() bool {
+	return c.TokenType == TokenTypeAPIKey
+}
 
-	token := jwt.New(jwt.SigningMethodHS256)
+// HasPermission บอกว่า token มีสิทธิ์ permission หรือไม่
+func (c *Claims) HasPermission(permission string) bool {
+	for _, p := range c.Permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
 
-	claims := token.Claims.(jwt.MapClaims)
-	claims["name"] = fmt.Sprintf("%s %s", user.FirstName, user.LastName)
-	claims["sub"] = fmt.Sprint(user.ID)
-	claims["aud"] = j.Audience
-	claims["iss"] = j.Issuer
-	claims["iat"] = time.Now().UTC().Unix()
-	claims["typ"] = "JWT"
+func (j *Auth) registeredClaims(user *JWTUser, now time.Time, expiry time.Duration) jwt.RegisteredClaims {
+	return jwt.RegisteredClaims{
+		Issuer:    j.Issuer,
+		Subject:   fmt.Sprint(user.ID),
+		Audience:  jwt.ClaimStrings{j.Audience},
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(expiry)),
+	}
+}
 
-	claims["exp"] = time.Now().UTC().Add(j.TokenExpiry).Unix()
+func (j *Auth) GenerateTokenPair(user *JWTUser) (TokenPairs, error) {
+	now := time.Now().UTC()
+
+	accessClaims := Claims{
+		RegisteredClaims: j.registeredClaims(user, now, j.TokenExpiry),
+		TokenType:        TokenTypeAccess,
+		Name:             fmt.Sprintf("%s %s", user.FirstName, user.LastName),
+		Role:             user.Role,
+		Permissions:      user.Permissions,
+		SessionID:        user.SessionID,
+	}
 
-	signedAccessToken, err := token.SignedString([]byte(j.Secret))
+	signedAccessToken, err := j.sign(accessClaims)
 	if err != nil {
 		return TokenPairs{}, err
 	}
 
-	refreshToken := jwt.New(jwt.SigningMethodHS256)
-	refreshTokenClaims := refreshToken.Claims.(jwt.MapClaims)
-	refreshTokenClaims["sub"] = fmt.Sprint(user.ID)
-	refreshTokenClaims["iat"] = time.Now().UTC().Unix()
+	refreshClaims := Claims{
+		RegisteredClaims: j.registeredClaims(user, now, j.RefreshExpiry),
+		TokenType:        TokenTypeRefresh,
+		SessionID:        user.SessionID,
+	}
+	// jti ทำให้ refresh token ทุกตัวไม่ซ้ำกัน แม้ออกให้ผู้ใช้คนเดิมในวินาทีเดียวกัน
+	refreshClaims.ID = uuid.NewString()
 
-	refreshTokenClaims["exp"] = time.Now().UTC().Add(j.RefreshExpiry).Unix()
-	signedRefreshToken, err := refreshToken.SignedString([]byte(j.Secret))
+	signedRefreshToken, err := j.sign(refreshClaims)
 	if err != nil {
 		return TokenPairs{}, err
 	}
@@ -74,6 +153,81 @@ func (j *Auth) GenerateTokenPair(user *JWTUser) (TokenPairs, error) {
 	return tokenPairs, nil
 }
 
+// GenerateChallengeToken ออก token อายุสั้นหลังผู้ใช้ใส่รหัสผ่านถูก ใช้ได้เฉพาะกับขั้นยืนยันรหัส 2FA
+func (j *Auth) GenerateChallengeToken(userID int) (string, error) {
+	now := time.Now().UTC()
+
+	claims := Claims{
+		RegisteredClaims: j.registeredClaims(&JWTUser{ID: userID}, now, j.ChallengeExpiry),
+		TokenType:        TokenTypeChallenge,
+	}
+	claims.ID = uuid.NewString()
+
+	return j.sign(claims)
+}
+
+// sign เซ็น claims ด้วยกุญแจที่ใช้เซ็นของ Keys และใส่ kid ใน header หรือใช้ HS256 ถ้าไม่ได้ตั้ง Keys
+func (j *Auth) sign(claims Claims) (string, error) {
+	if j.Keys == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(j.Secret))
+	}
+
+	key := j.Keys.Signing
+	token := jwt.NewWithClaims(key.Method(), claims)
+	token.Header["kid"] = key.ID
+
+	return token.SignedString(key.Private)
+}
+
+// verificationKey คืนกุญแจที่ใช้ตรวจ token ตาม kid ใน header
+// อัลกอริทึมใน header ต้องตรงกับของกุญแจ กันการใช้ public key เป็น secret ของ HS256
+func (j *Auth) verificationKey(token *jwt.Token) (interface{}, error) {
+	if j.Keys == nil {
+		return []byte(j.Secret), nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, err := j.Keys.Key(kid)
+	if err != nil {
+		return nil, err
+	}
+	if token.Method.Alg() != key.Algorithm {
+		return nil, ErrInvalidToken
+	}
+	return key.Public, nil
+}
+
+// validMethods คือ alg ที่ยอมรับใน header ของ token
+func (j *Auth) validMethods() []string {
+	if j.Keys == nil {
+		return []string{jwt.SigningMethodHS256.Alg()}
+	}
+	return j.Keys.Algorithms()
+}
+
+// JWKS คืน public key ที่ใช้ตรวจ token สำหรับ /.well-known/jwks.json ถ้าใช้ HS256 จะไม่มีกุญแจ
+func (j *Auth) JWKS() jwtkeys.JWKS {
+	if j.Keys == nil {
+		return jwtkeys.JWKS{Keys: []jwtkeys.JWK{}}
+	}
+	return j.Keys.JWKS()
+}
+
+// GenerateOpaqueToken สุ่ม token ที่เดาไม่ได้สำหรับลิงก์ในอีเมล เช่น reset password
+func GenerateOpaqueToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken คืน SHA-256 ของ token ใช้เก็บ refresh token ใน database แทนตัว token จริง
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
 // ฟังก์ชันสำหรับการ GetRefreshCookie
 func (j *Auth) GetRefreshCookie(refreshToken string) *fiber.Cookie {
 	return &fiber.Cookie{
@@ -104,57 +258,107 @@ func (j *Auth) GetExpiredRefreshCookie() *fiber.Cookie {
 	}
 }
 
-func (j *Auth) GetTokenFromHeaderVerify(c *fiber.Ctx) (string, *Claims, error) {
-
-	c.Set("Vary", "Authorization")
+var (
+	ErrNoAuthHeader       = errors.New("authorization header is required")
+	ErrInvalidAuthHeader  = errors.New("invalid token format")
+	ErrExpiredToken       = errors.New("expired token")
+	ErrInvalidToken       = errors.New("invalid token")
+	ErrInvalidIssuer      = errors.New("invalid issuer")
+	ErrInvalidAudience    = errors.New("invalid audience")
+	ErrUnexpectedTokenUse = errors.New("unexpected token type")
+)
 
-	authHeader := c.Get("Authorization")
+// VerifyToken ตรวจลายเซ็น, iss, aud, exp, nbf และชนิดของ token
+func (j *Auth) VerifyToken(tokenString, tokenType string) (*Claims, error) {
+	claims := &Claims{}
 
-	if authHeader == "" {
-		return "", nil, errors.New("no auth header")
+	parser := jwt.NewParser(jwt.WithValidMethods(j.validMethods()))
+	_, err := parser.ParseWithClaims(tokenString, claims, j.verificationKey)
+	if err != nil {
+		var validationErr *jwt.ValidationError
+		if errors.As(err, &validationErr) && validationErr.Errors&jwt.ValidationErrorExpired != 0 {
+			return nil, ErrExpiredToken
+		}
+		return nil, ErrInvalidToken
 	}
 
-	headerParts := strings.Split(authHeader, " ")
-	if len(headerParts) != 2 {
-		return "", nil, errors.New("invalid auth header")
-	}
+	now := time.Now()
 
-	if headerParts[0] != "Bearer" {
-		return "", nil, errors.New("invalid auth header")
+	// exp ต้องมีเสมอ ส่วน nbf ถูกตรวจโดย ParseWithClaims ถ้ามี
+	if !claims.VerifyExpiresAt(now, true) {
+		return nil, ErrExpiredToken
+	}
+	if !claims.VerifyIssuer(j.Issuer, true) {
+		return nil, ErrInvalidIssuer
+	}
+	if !claims.VerifyAudience(j.Audience, true) {
+		return nil, ErrInvalidAudience
+	}
+	if claims.TokenType != tokenType {
+		return nil, ErrUnexpectedTokenUse
 	}
 
-	token := headerParts[1]
-
-	claims := &Claims{}
+	return claims, nil
+}
 
-	_, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(j.Secret), nil
-	})
+// AccessTokenFromHeader อ่าน access token จาก header Authorization: Bearer ... แล้วตรวจสอบ
+func (j *Auth) AccessTokenFromHeader(c *fiber.Ctx) (*Claims, error) {
+	c.Set("Vary", "Authorization")
 
-	if err != nil {
-		if strings.HasPrefix(err.Error(), "token is expired by") {
-			return "", nil, errors.New("expired token")
-		}
-		return "", nil, err
+	authHeader := c.Get("Authorization")
+	if authHeader == "" {
+		return nil, ErrNoAuthHeader
 	}
 
-	if claims.Issuer != j.Issuer {
-		return "", nil, errors.New("invalid issuer")
+	token, ok := strings.CutPrefix(authHeader, "Bearer ")
+	if !ok || token == "" {
+		return nil, ErrInvalidAuthHeader
 	}
 
-	return token, claims, nil
-
+	return j.VerifyToken(token, TokenTypeAccess)
 }
 
+// AuthRequired ให้ผ่านเฉพาะ request ที่มี access token หรือ API key ที่ถูกต้อง แล้วเก็บ claims ไว้ใน c.Locals
+// ใช้ ClaimsFromContext หรือ UserIDFromContext เพื่ออ่านค่าใน handler
+// request ที่ใช้ API key จะไม่มี user id จึงใช้ route ที่ต้องการตัวผู้ใช้ไม่ได้
 func (j *Auth) AuthRequired() fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		_, _, err := j.GetTokenFromHeaderVerify(c)
+		if key, ok := strings.CutPrefix(c.Get("Authorization"), "ApiKey "); ok && j.APIKeys != nil {
+			c.Set("Vary", "Authorization")
+			return j.apiKeyRequired(c, key)
+		}
+
+		claims, err := j.AccessTokenFromHeader(c)
 		if err != nil {
-			return c.SendStatus(fiber.StatusUnauthorized)
+			return utils.ErrorJSON(c, err, fiber.StatusUnauthorized)
 		}
+
+		if _, err := claims.UserID(); err != nil {
+			return utils.ErrorJSON(c, ErrInvalidToken, fiber.StatusUnauthorized)
+		}
+
+		c.Locals(claimsKey, claims)
+
 		return c.Next()
 	}
 }
+
+func (j *Auth) apiKeyRequired(c *fiber.Ctx, key string) error {
+	claims, err := j.APIKeys.AuthenticateAPIKey(key)
+	if err != nil {
+		var limited *RateLimitError
+		if errors.As(err, &limited) {
+			seconds := int(limited.RetryAfter.Seconds())
+			if seconds < 1 {
+				seconds = 1
+			}
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(seconds))
+			return utils.ErrorJSON(c, err, fiber.StatusTooManyRequests)
+		}
+		return utils.ErrorJSON(c, err, fiber.StatusUnauthorized)
+	}
+
+	c.Locals(claimsKey, claims)
+
+	return c.Next()
+}
@@ -1,14 +1,12 @@
 package middlewares
 
 import (
-	"fmt"
+	"errors"
 	"net/http"
-	"os"
-	"strings"
 
+	"github.com/NakarinFIgo/Movies-App/pkg/utils"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
-	"github.com/golang-jwt/jwt/v4"
 )
 
 func Enablecors() fiber.Handler {
@@ -17,42 +15,47 @@ func Enablecors() fiber.Handler {
 		AllowCredentials: true,
 		AllowMethods:     "GET,POST,PUT,PATCH,DELETE,OPTIONS",
 		AllowHeaders:     "Origin,Authorization, Content-Type, Accept",
+		// ให้ JavaScript อ่าน header ที่บอกโควตาและ request ID ได้
+		ExposeHeaders: "RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, RateLimit-Policy, Retry-After, X-Request-ID",
 	})
 }
-func JwtMiddleware() fiber.Handler {
-	return func(c *fiber.Ctx) error {
-		// Get the Authorization header
-		tokenString := c.Get("Authorization")
 
-		// Check if the token is provided
-		if tokenString == "" {
-			return c.Status(http.StatusUnauthorized).JSON(fiber.Map{"error": "Authorization header is required"})
-		}
+const claimsKey = "claims"
 
-		// Check if the token is a Bearer token
-		if !strings.HasPrefix(tokenString, "Bearer ") {
-			return c.Status(http.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid token format"})
-		}
+// ClaimsFromContext คืนค่า claims ของ access token ที่ AuthRequired เก็บไว้ใน c.Locals
+func ClaimsFromContext(c *fiber.Ctx) (*Claims, bool) {
+	claims, ok := c.Locals(claimsKey).(*Claims)
+	return claims, ok
+}
 
-		// Remove the "Bearer " prefix to get the token
-		tokenString = strings.TrimPrefix(tokenString, "Bearer ")
+// UserIDFromContext คืนค่า user id ของ access token ที่ AuthRequired เก็บไว้ใน c.Locals
+func UserIDFromContext(c *fiber.Ctx) (int, bool) {
+	claims, ok := ClaimsFromContext(c)
+	if !ok {
+		return 0, false
+	}
 
-		// Parse and validate the token
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			// Ensure the signing method is valid
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			// Return the secret key for validation
-			return []byte(os.Getenv("JWT_SECRET")), nil
-		})
+	userID, err := claims.UserID()
+	if err != nil {
+		return 0, false
+	}
+	return userID, true
+}
+
+// RequirePermission ให้ผ่านเฉพาะ token ที่มีสิทธิ์ครบทุกข้อที่ระบุ ต้องใช้หลัง AuthRequired
+func RequirePermission(permissions ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := ClaimsFromContext(c)
+		if !ok {
+			return utils.ErrorJSON(c, ErrNoAuthHeader, http.StatusUnauthorized)
+		}
 
-		// Check for errors and token validity
-		if err != nil || !token.Valid {
-			return c.Status(http.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid token"})
+		for _, p := range permissions {
+			if !claims.HasPermission(p) {
+				return utils.ErrorJSON(c, errors.New("missing permission "+p), http.StatusForbidden)
+			}
 		}
 
-		// If valid, proceed to the next handler
 		return c.Next()
 	}
 }
@@ -0,0 +1,73 @@
+package rbac
+
+import "sort"
+
+// บทบาทของผู้ใช้
+const (
+	RoleAdmin  = "admin"
+	RoleEditor = "editor"
+	RoleViewer = "viewer"
+)
+
+// สิทธิ์ที่ใช้ตรวจในแต่ละ route
+const (
+	PermMoviesWrite  = "movies:write"
+	PermMoviesDelete = "movies:delete"
+	PermGenresManage = "genres:manage"
+	PermUsersManage  = "users:manage"
+	// PermSystemManage ใช้กับงานดูแลระบบ เช่น job queue และ scheduler
+	PermSystemManage = "system:manage"
+)
+
+// AllPermissions คือสิทธิ์ทั้งหมดที่ระบบรู้จัก
+var AllPermissions = []string{
+	PermMoviesWrite,
+	PermMoviesDelete,
+	PermGenresManage,
+	PermUsersManage,
+	PermSystemManage,
+}
+
+// RolePermissions คือสิทธิ์ที่แต่ละบทบาทได้โดยอัตโนมัติ
+var RolePermissions = map[string][]string{
+	RoleAdmin:  AllPermissions,
+	RoleEditor: {PermMoviesWrite, PermGenresManage},
+	RoleViewer: {},
+}
+
+// ValidRole บอกว่า role เป็นบทบาทที่ระบบรู้จักหรือไม่
+func ValidRole(role string) bool {
+	_, ok := RolePermissions[role]
+	return ok
+}
+
+// ValidPermission บอกว่า permission เป็นสิทธิ์ที่ระบบรู้จักหรือไม่
+func ValidPermission(permission string) bool {
+	for _, p := range AllPermissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Effective รวมสิทธิ์ของบทบาทกับสิทธิ์ที่ให้เพิ่มรายคน สิทธิ์ที่ไม่รู้จักจะถูกตัดทิ้ง
+func Effective(role string, extra []string) []string {
+	set := make(map[string]bool)
+	for _, p := range RolePermissions[role] {
+		set[p] = true
+	}
+	for _, p := range extra {
+		if ValidPermission(p) {
+			set[p] = true
+		}
+	}
+
+	permissions := make([]string, 0, len(set))
+	for p := range set {
+		permissions = append(permissions, p)
+	}
+	sort.Strings(permissions)
+
+	return permissions
+}
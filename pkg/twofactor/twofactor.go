@@ -0,0 +1,93 @@
+// Package twofactor สร้างและตรวจรหัส TOTP (RFC 6238) และ recovery code สำหรับการยืนยันตัวตนสองขั้น
+package twofactor
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base32"
+	"strings"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+)
+
+// Period คือช่วงเวลาของรหัสหนึ่งชุด
+const Period = 30
+
+// Skew คือจำนวนช่วงก่อนและหลังเวลาปัจจุบันที่ยอมรับ เผื่อนาฬิกาของมือถือคลาดเคลื่อน
+const Skew = 1
+
+// RecoveryCodeCount คือจำนวน recovery code ที่ออกให้ต่อครั้ง
+const RecoveryCodeCount = 10
+
+var validateOpts = totp.ValidateOpts{
+	Period:    Period,
+	Digits:    otp.DigitsSix,
+	Algorithm: otp.AlgorithmSHA1,
+}
+
+// Generate สุ่ม secret ใหม่ และคืน otpauth:// URI สำหรับสร้าง QR code ในแอป authenticator
+func Generate(issuer, account string) (secret, uri string, err error) {
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      issuer,
+		AccountName: account,
+		Period:      Period,
+		Digits:      otp.DigitsSix,
+		Algorithm:   otp.AlgorithmSHA1,
+	})
+	if err != nil {
+		return "", "", err
+	}
+	return key.Secret(), key.URL(), nil
+}
+
+// Validate ตรวจรหัสกับ secret ที่เวลา now ถ้าถูกต้องจะคืนลำดับช่วงเวลา (step) ของรหัสนั้น
+// ผู้เรียกต้องเก็บ step ล่าสุดที่ใช้ไปแล้วและปฏิเสธ step ที่ไม่มากกว่าเดิม เพื่อไม่ให้ใช้รหัสเดิมซ้ำ
+func Validate(code, secret string, now time.Time) (step int64, ok bool) {
+	code = strings.TrimSpace(code)
+	if len(code) != int(otp.DigitsSix) {
+		return 0, false
+	}
+
+	current := now.Unix() / Period
+	for offset := int64(-Skew); offset <= Skew; offset++ {
+		s := current + offset
+		expected, err := totp.GenerateCodeCustom(secret, time.Unix(s*Period, 0), validateOpts)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return s, true
+		}
+	}
+	return 0, false
+}
+
+// RecoveryCodes สุ่ม recovery code ชุดใหม่ในรูป xxxxx-xxxxx
+func RecoveryCodes() ([]string, error) {
+	codes := make([]string, RecoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		s := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b))[:10]
+		codes[i] = s[:5] + "-" + s[5:]
+	}
+	return codes, nil
+}
+
+// NormalizeRecoveryCode ตัดขีดและช่องว่างและทำเป็นตัวพิมพ์เล็ก ผู้ใช้จึงพิมพ์ได้ทั้งแบบมีและไม่มีขีด
+func NormalizeRecoveryCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '-' || r == ' ':
+			return -1
+		case r >= 'A' && r <= 'Z':
+			return r + ('a' - 'A')
+		default:
+			return r
+		}
+	}, code)
+}
@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// MIMEProblemJSON คือ content type ของ error response ตาม RFC 7807
+const MIMEProblemJSON = "application/problem+json"
+
+// Problem คือ error response ตาม RFC 7807 พร้อม field เพิ่มเติมของ API นี้
+type Problem struct {
+	// Type คือ URI ที่บอกชนิดของปัญหา
+	// Example: /problems/not_found
+	Type string `json:"type"`
+	// Example: Not Found
+	Title string `json:"title"`
+	// Example: 404
+	Status int `json:"status"`
+	// Detail คือคำอธิบายของปัญหาครั้งนี้ ไม่มีรายละเอียดภายในอย่าง SQL
+	// Example: movie not found
+	Detail string `json:"detail,omitempty"`
+	// Instance คือ path ของ request ที่เกิดปัญหา
+	// Example: /api/v1/movies/42
+	Instance string `json:"instance,omitempty"`
+	// RequestID ตรงกับ header X-Request-ID ใช้ค้น log ของ request นี้
+	RequestID string `json:"request_id,omitempty"`
+	// Code คือรหัสของ error ที่ client ใช้แยกกรณีได้โดยไม่ต้องอ่าน Detail
+	// Example: not_found
+	Code string `json:"code,omitempty"`
+	// Fields คือข้อความ error ของแต่ละ field มีเฉพาะเมื่อ Code เป็น validation_failed
+	Fields FieldErrors `json:"fields,omitempty"`
+}
+
+// HTTPError คือ error ที่ handler ระบุ status และรหัสไว้ให้ error handler กลาง
+// Status เป็น 0 คือให้ error handler เลือกจากประเภทของ Err
+type HTTPError struct {
+	Status int
+	Code   string
+	Err    error
+}
+
+func (e *HTTPError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *HTTPError) Unwrap() error {
+	return e.Err
+}
+
+// WriteProblem เขียน p เป็น application/problem+json
+func WriteProblem(c *fiber.Ctx, p Problem) error {
+	return c.Status(p.Status).JSON(p, MIMEProblemJSON)
+}
@@ -1,6 +1,14 @@
 package utils
 
-import "github.com/gofiber/fiber/v2"
+import (
+	"sort"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CodeValidationFailed คือรหัส error เมื่อ payload มี field ที่ไม่ถูกต้อง รายละเอียดอยู่ใน fields
+const CodeValidationFailed = "validation_failed"
 
 type JSONResponse struct {
 	Error   bool        `json:"error"`
@@ -8,6 +16,25 @@ type JSONResponse struct {
 	Data    interface{} `json:"data,omitempty"`
 }
 
+// FieldErrors คือข้อความ error ของแต่ละ field key คือชื่อ field ใน JSON
+type FieldErrors map[string]string
+
+// Add เพิ่ม error ของ field ถ้า field นั้นมี error อยู่แล้วจะเก็บอันแรกไว้
+func (e FieldErrors) Add(field string, err error) {
+	if _, ok := e[field]; !ok {
+		e[field] = err.Error()
+	}
+}
+
+func (e FieldErrors) Error() string {
+	fields := make([]string, 0, len(e))
+	for field, msg := range e {
+		fields = append(fields, field+": "+msg)
+	}
+	sort.Strings(fields)
+	return strings.Join(fields, "; ")
+}
+
 func WriteJSON(c *fiber.Ctx, status int, data interface{}) error {
 	return c.Status(status).JSON(data)
 }
@@ -19,16 +46,32 @@ func ReadJSON(c *fiber.Ctx, data interface{}) error {
 	return nil
 }
 
+// ErrorJSON ส่ง err ให้ error handler กลางของ app เขียนเป็น problem+json ทันที
+// status ไม่ระบุจะให้ error handler เลือกจากประเภทของ err ซึ่งถ้าไม่รู้จักจะเป็น 400
 func ErrorJSON(c *fiber.Ctx, err error, status ...int) error {
-	statusCode := fiber.StatusBadRequest
+	httpErr := &HTTPError{Err: err}
+	if len(status) > 0 {
+		httpErr.Status = status[0]
+	}
 
+	return c.App().ErrorHandler(c, httpErr)
+}
+
+// ErrorCodeJSON เหมือน ErrorJSON แต่ใส่รหัสของ error ไว้ใน code ด้วย
+func ErrorCodeJSON(c *fiber.Ctx, err error, code string, status ...int) error {
+	httpErr := &HTTPError{Err: err, Code: code}
 	if len(status) > 0 {
-		statusCode = status[0]
+		httpErr.Status = status[0]
 	}
 
-	var payload JSONResponse
-	payload.Error = true
-	payload.Message = err.Error()
+	return c.App().ErrorHandler(c, httpErr)
+}
 
-	return WriteJSON(c, statusCode, payload)
+// ValidationErrorJSON ตอบ 422 พร้อม error ของทุก field ที่ไม่ถูกต้อง
+func ValidationErrorJSON(c *fiber.Ctx, errs FieldErrors) error {
+	return c.App().ErrorHandler(c, &HTTPError{
+		Status: fiber.StatusUnprocessableEntity,
+		Code:   CodeValidationFailed,
+		Err:    errs,
+	})
 }
@@ -0,0 +1,131 @@
+// Package validation ตรวจ payload ของ request ตาม tag `validate` บน struct
+// และแปลงผลเป็น utils.FieldErrors ที่ใช้ชื่อ field ตาม JSON เพื่อตอบ 422 ได้ทันที
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/NakarinFIgo/Movies-App/pkg/utils"
+	"github.com/go-playground/validator/v10"
+)
+
+// ช่วงวันฉายที่รับได้ หนังเรื่องแรกของโลกฉายปี 1888 และไม่รับวันที่ไกลกว่า 10 ปีข้างหน้า
+var (
+	MinReleaseDate      = time.Date(1888, time.January, 1, 0, 0, 0, 0, time.UTC)
+	maxReleaseDateYears = 10
+)
+
+// MaxReleaseDate คือวันฉายที่ไกลที่สุดที่รับได้ นับจากวันนี้
+func MaxReleaseDate() time.Time {
+	return time.Now().UTC().AddDate(maxReleaseDateYears, 0, 0)
+}
+
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+
+	// ใช้ชื่อ field ตาม JSON ใน error ให้ตรงกับที่ client ส่งมา
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return f.Name
+		}
+		return name
+	})
+
+	// Date ที่ไม่ได้ส่งมาถือเป็นค่าว่าง omitempty และ required จึงใช้ได้ตามปกติ
+	v.RegisterCustomTypeFunc(func(f reflect.Value) interface{} {
+		d := f.Interface().(Date)
+		if d.IsZero() {
+			return nil
+		}
+		return d.Time
+	}, Date{})
+
+	_ = v.RegisterValidation("releasedate", func(fl validator.FieldLevel) bool {
+		t, ok := fl.Field().Interface().(time.Time)
+		if !ok {
+			return false
+		}
+		return !t.Before(MinReleaseDate) && !t.After(MaxReleaseDate())
+	})
+
+	_ = v.RegisterValidation("notblank", func(fl validator.FieldLevel) bool {
+		return strings.TrimSpace(fl.Field().String()) != ""
+	})
+
+	return v
+}
+
+// Struct ตรวจ s ตาม tag `validate` คืน error ของทุก field ที่ไม่ผ่านพร้อมกัน
+// ถ้าผ่านทั้งหมดจะได้ map ว่าง ผู้เรียกเพิ่ม error ที่ต้องตรวจกับฐานข้อมูลต่อได้
+func Struct(s interface{}) utils.FieldErrors {
+	errs := utils.FieldErrors{}
+
+	err := validate.Struct(s)
+	if err == nil {
+		return errs
+	}
+
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		// s ไม่ใช่ struct ถือเป็นความผิดของโค้ดที่เรียก ไม่ใช่ของ client
+		panic(err)
+	}
+
+	for _, fe := range verrs {
+		field := fieldPath(fe)
+		errs.Add(field, errors.New(message(field, fe)))
+	}
+	return errs
+}
+
+// fieldPath ตัดชื่อ struct นอกสุดออกจาก namespace เช่น MoviePayload.genres_array[0] เป็น genres_array[0]
+func fieldPath(fe validator.FieldError) string {
+	ns := fe.Namespace()
+	if _, rest, ok := strings.Cut(ns, "."); ok {
+		return rest
+	}
+	return fe.Field()
+}
+
+func message(field string, fe validator.FieldError) string {
+	unit := ""
+	switch fe.Kind() {
+	case reflect.String:
+		unit = " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		unit = " items"
+	}
+
+	switch fe.Tag() {
+	case "required", "notblank":
+		return fmt.Sprintf("%s is required", field)
+	case "min", "gte":
+		return fmt.Sprintf("%s must be at least %s%s", field, fe.Param(), unit)
+	case "max", "lte":
+		return fmt.Sprintf("%s must be at most %s%s", field, fe.Param(), unit)
+	case "gt":
+		return fmt.Sprintf("%s must be greater than %s", field, fe.Param())
+	case "lt":
+		return fmt.Sprintf("%s must be less than %s", field, fe.Param())
+	case "oneof":
+		return fmt.Sprintf("%s must be one of %s", field, strings.Join(strings.Fields(fe.Param()), ", "))
+	case "unique":
+		return fmt.Sprintf("%s must not contain duplicates", field)
+	case "url", "http_url":
+		return fmt.Sprintf("%s must be a valid URL", field)
+	case "releasedate":
+		return fmt.Sprintf("%s must be between %s and %s", field,
+			MinReleaseDate.Format(time.DateOnly), MaxReleaseDate().Format(time.DateOnly))
+	}
+	return fmt.Sprintf("%s is invalid", field)
+}
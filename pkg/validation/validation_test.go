@@ -0,0 +1,170 @@
+package validation
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+type moviePayload struct {
+	Title       string `json:"title" validate:"required,notblank,max=10"`
+	ReleaseDate Date   `json:"release_date" validate:"omitempty,releasedate"`
+	RunTime     int    `json:"runtime" validate:"omitempty,min=1,max=600"`
+	MPAARating  string `json:"mpaa_rating" validate:"omitempty,oneof=G PG PG-13 R"`
+	GenresArray []int  `json:"genres_array" validate:"omitempty,max=3,unique,dive,gt=0"`
+}
+
+func validPayload() moviePayload {
+	return moviePayload{
+		Title:       "Dune",
+		ReleaseDate: Date{time.Date(2021, time.October, 22, 0, 0, 0, 0, time.UTC)},
+		RunTime:     155,
+		MPAARating:  "PG-13",
+		GenresArray: []int{1, 2},
+	}
+}
+
+func TestStructValid(t *testing.T) {
+	if errs := Struct(validPayload()); len(errs) != 0 {
+		t.Errorf("errors = %v, want none", errs)
+	}
+
+	// field ที่ไม่ได้ส่งมาต้องผ่าน omitempty
+	if errs := Struct(moviePayload{Title: "Dune"}); len(errs) != 0 {
+		t.Errorf("errors = %v for a payload with only the title, want none", errs)
+	}
+}
+
+func TestStructReportsEveryField(t *testing.T) {
+	payload := moviePayload{
+		Title:       "   ",
+		ReleaseDate: Date{time.Date(1700, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		RunTime:     601,
+		MPAARating:  "X",
+		GenresArray: []int{1, 0},
+	}
+
+	want := map[string]string{
+		"title":           "title is required",
+		"release_date":    "release_date must be between 1888-01-01 and " + MaxReleaseDate().Format(time.DateOnly),
+		"runtime":         "runtime must be at most 600",
+		"mpaa_rating":     "mpaa_rating must be one of G, PG, PG-13, R",
+		"genres_array[1]": "genres_array[1] must be greater than 0",
+	}
+
+	errs := Struct(payload)
+	if len(errs) != len(want) {
+		t.Errorf("got %d field errors %v, want %d", len(errs), errs, len(want))
+	}
+	for field, msg := range want {
+		if errs[field] != msg {
+			t.Errorf("%s: got %q, want %q", field, errs[field], msg)
+		}
+	}
+}
+
+func TestStructMessages(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(p *moviePayload)
+		field  string
+		want   string
+	}{
+		{"missing title", func(p *moviePayload) { p.Title = "" }, "title", "title is required"},
+		{"long title", func(p *moviePayload) { p.Title = strings.Repeat("a", 11) }, "title", "title must be at most 10 characters"},
+		{"runtime too short", func(p *moviePayload) { p.RunTime = -1 }, "runtime", "runtime must be at least 1"},
+		{"too many genres", func(p *moviePayload) { p.GenresArray = []int{1, 2, 3, 4} }, "genres_array", "genres_array must be at most 3 items"},
+		{"duplicate genres", func(p *moviePayload) { p.GenresArray = []int{1, 1} }, "genres_array", "genres_array must not contain duplicates"},
+		{"rating not in list", func(p *moviePayload) { p.MPAARating = "pg-13" }, "mpaa_rating", "mpaa_rating must be one of G, PG, PG-13, R"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := validPayload()
+			tt.modify(&payload)
+
+			errs := Struct(payload)
+			if errs[tt.field] != tt.want {
+				t.Errorf("%s: got %q, want %q (all errors: %v)", tt.field, errs[tt.field], tt.want, errs)
+			}
+		})
+	}
+}
+
+func TestReleaseDateBounds(t *testing.T) {
+	latest := MaxReleaseDate()
+
+	tests := []struct {
+		name  string
+		date  time.Time
+		valid bool
+	}{
+		{"first film year", MinReleaseDate, true},
+		{"before the first film", MinReleaseDate.AddDate(0, 0, -1), false},
+		{"today", time.Now().UTC(), true},
+		{"just inside the future limit", latest.AddDate(0, 0, -1), true},
+		{"beyond the future limit", latest.AddDate(0, 0, 1), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := validPayload()
+			payload.ReleaseDate = Date{tt.date}
+
+			_, invalid := Struct(payload)["release_date"]
+			if invalid == tt.valid {
+				t.Errorf("release_date %s: invalid = %v, want valid %v", tt.date.Format(time.DateOnly), invalid, tt.valid)
+			}
+		})
+	}
+}
+
+func TestDateUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Time
+		wantErr bool
+	}{
+		{in: `"2024-08-28"`, want: time.Date(2024, time.August, 28, 0, 0, 0, 0, time.UTC)},
+		{in: `"2024-08-28T10:30:00Z"`, want: time.Date(2024, time.August, 28, 10, 30, 0, 0, time.UTC)},
+		{in: `"2024-08-28T10:30:00+07:00"`, want: time.Date(2024, time.August, 28, 3, 30, 0, 0, time.UTC)},
+		{in: `null`},
+		{in: `""`},
+		{in: `"28/08/2024"`, wantErr: true},
+		{in: `"2024-13-01"`, wantErr: true},
+		{in: `20240828`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		var d Date
+		err := json.Unmarshal([]byte(tt.in), &d)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Unmarshal(%s) err = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !d.Time.Equal(tt.want) {
+			t.Errorf("Unmarshal(%s) = %v, want %v", tt.in, d.Time, tt.want)
+		}
+	}
+}
+
+func TestDateMarshalJSON(t *testing.T) {
+	tests := []struct {
+		in   Date
+		want string
+	}{
+		{Date{time.Date(2024, time.August, 28, 10, 30, 0, 0, time.UTC)}, `"2024-08-28"`},
+		{Date{}, `null`},
+	}
+
+	for _, tt := range tests {
+		b, err := json.Marshal(tt.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != tt.want {
+			t.Errorf("Marshal(%v) = %s, want %s", tt.in.Time, b, tt.want)
+		}
+	}
+}